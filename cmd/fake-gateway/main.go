@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/paygate"
+)
+
+func main() {
+	// Parse command line flags
+	var (
+		url            = flag.String("url", "http://localhost:8080/api/v1/payments/callback", "Payment callback to notify")
+		secret         = flag.String("secret", "change-me-payment-webhook-secret", "Webhook secret shared with the loan engine")
+		virtualAccount = flag.String("va", "", "Virtual account the payment is made into")
+		amount         = flag.Float64("amount", 0, "Amount paid")
+		payer          = flag.String("payer", "", "Name of the payer (optional)")
+		repeat         = flag.Int("repeat", 1, "How many times to send the notification, as a gateway retrying would")
+	)
+	flag.Parse()
+
+	if *virtualAccount == "" || *amount <= 0 {
+		log.Fatal("va and amount flags are required")
+	}
+
+	gateway := paygate.NewFakeGateway(*secret)
+	payment := gateway.NewPayment(*virtualAccount, *amount, *payer)
+	fmt.Printf("Payment %s of %.2f into %s\n", payment.PaymentID, payment.Amount, payment.VirtualAccount)
+
+	for i := 0; i < *repeat; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		status, response, err := gateway.Notify(ctx, *url, payment)
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to send notification: %v", err)
+		}
+		fmt.Printf("%d %s\n", status, response)
+	}
+}
@@ -53,6 +53,8 @@ type Borrower struct {
 	BankAccountNumber string    `json:"bank_account_number"`
 	BankName          string    `json:"bank_name"`
 	AccountHolderName string    `json:"account_holder_name"`
+	AddressLatitude   float64   `json:"address_latitude"`
+	AddressLongitude  float64   `json:"address_longitude"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 	PasswordHash      string    `json:"password_hash"`
@@ -235,6 +237,8 @@ func seedBorrowers(ctx context.Context, db *sql.DB) error {
 			BankAccountNumber: "1122334455",
 			BankName:          "Bank BRI",
 			AccountHolderName: "Siti Peminjam",
+			AddressLatitude:   -6.288896,
+			AddressLongitude:  106.717952,
 			CreatedAt:         time.Now(),
 			UpdatedAt:         time.Now(),
 			PasswordHash:      "$2a$10$WYmkE5HjRSYrJcUS9OGL/u9biq0iYc6GoUPiYLVd1UvO8hZPo98fO", // password123
@@ -252,6 +256,8 @@ func seedBorrowers(ctx context.Context, db *sql.DB) error {
 			BankAccountNumber: "5566778899",
 			BankName:          "Bank BNI",
 			AccountHolderName: "Joko Wirausaha",
+			AddressLatitude:   -6.238270,
+			AddressLongitude:  106.975573,
 			CreatedAt:         time.Now(),
 			UpdatedAt:         time.Now(),
 			PasswordHash:      "$2a$10$WYmkE5HjRSYrJcUS9OGL/u9biq0iYc6GoUPiYLVd1UvO8hZPo98fO", // password123
@@ -262,8 +268,9 @@ func seedBorrowers(ctx context.Context, db *sql.DB) error {
 		INSERT INTO borrowers (
 			id, full_name, identity_number, phone_number, email, address,
 			date_of_birth, occupation, monthly_income, bank_account_number,
-			bank_name, account_holder_name, created_at, updated_at, password_hash
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			bank_name, account_holder_name, created_at, updated_at, password_hash,
+			address_latitude, address_longitude
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		ON CONFLICT (identity_number) DO NOTHING`
 
 	for _, borrower := range borrowers {
@@ -273,6 +280,7 @@ func seedBorrowers(ctx context.Context, db *sql.DB) error {
 			borrower.DateOfBirth, borrower.Occupation, borrower.MonthlyIncome,
 			borrower.BankAccountNumber, borrower.BankName, borrower.AccountHolderName,
 			borrower.CreatedAt, borrower.UpdatedAt, borrower.PasswordHash,
+			borrower.AddressLatitude, borrower.AddressLongitude,
 		)
 		if err != nil {
 			return err
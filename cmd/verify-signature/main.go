@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdfsign"
+)
+
+func main() {
+	// Parse command line flags
+	var (
+		file = flag.String("file", "", "Signed PDF to verify")
+		ca   = flag.String("ca", "", "PEM bundle of trusted root certificates (optional)")
+	)
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("File flag is required")
+	}
+
+	document, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("Failed to read document: %v", err)
+	}
+
+	var roots *x509.CertPool
+	if *ca != "" {
+		bundle, err := os.ReadFile(*ca)
+		if err != nil {
+			log.Fatalf("Failed to read CA bundle: %v", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(bundle) {
+			log.Fatal("CA bundle contains no certificates")
+		}
+	}
+
+	result, err := pdfsign.Verify(document, roots)
+	if err != nil {
+		log.Fatalf("Signature verification failed: %v", err)
+	}
+
+	fmt.Printf("Signer:       %s\n", result.SignerName)
+	fmt.Printf("Fingerprint:  %s\n", result.Fingerprint)
+	fmt.Printf("Signing time: %s\n", result.SigningTime.Format("2006-01-02 15:04:05 MST"))
+	fmt.Printf("Format:       %s\n", result.SubFilter)
+	fmt.Printf("Whole file:   %t\n", result.CoversWholeDocument)
+	if roots != nil {
+		fmt.Printf("Trusted:      %t\n", result.Trusted)
+	}
+
+	// Content appended after signing is not covered by the signature
+	if !result.CoversWholeDocument {
+		log.Fatal("Document was modified after signing")
+	}
+}
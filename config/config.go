@@ -31,6 +31,81 @@ func LoadConfig() error {
 	viper.SetDefault("http.port", defaultPort)
 	viper.SetDefault("log.level", 0)
 	viper.Set("http.timeout", "120s")
+	viper.SetDefault("mfa.issuer", "Loan Engine")
+	viper.SetDefault("mfa.required_roles", []string{"FIELD_OFFICER", "ADMIN"})
+	viper.SetDefault("mfa.challenge_ttl", "5m")
+	viper.SetDefault("mfa.skew", 1)
+	viper.SetDefault("mfa.max_attempts", 5)
+	viper.SetDefault("mfa.lockout", "15m")
+	viper.SetDefault("permissions.source", "database")
+	viper.SetDefault("permissions.refresh_interval", "5m")
+	viper.SetDefault("api_keys.default_ttl", "2160h")
+	viper.SetDefault("api_keys.max_ttl", "8760h")
+	viper.SetDefault("api_keys.rotation_grace", "24h")
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.local.root", "uploads")
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.use_ssl", true)
+	viper.SetDefault("files.signed_url_ttl", "15m")
+	viper.SetDefault("files.max_signed_url_ttl", "24h")
+	viper.SetDefault("scanner.driver", "none")
+	viper.SetDefault("scanner.clamd.network", "tcp")
+	viper.SetDefault("scanner.clamd.address", "localhost:3310")
+	viper.SetDefault("scanner.clamd.timeout", "30s")
+	viper.SetDefault("scanner.rescan_interval", "5m")
+	viper.SetDefault("scanner.rescan_batch", 50)
+	viper.SetDefault("survey.max_distance_meters", 500)
+	viper.SetDefault("survey.capture_time_tolerance", "12h")
+	viper.SetDefault("survey.timezone", "Asia/Jakarta")
+	viper.SetDefault("agreement.company_name", "Loan Engine")
+	viper.SetDefault("agreement.default_locale", "id")
+	viper.SetDefault("agreement.verify_base_url", "http://localhost:8080/api/v1/verify")
+	viper.SetDefault("investments.acceptance_window", "72h")
+	viper.SetDefault("investments.expiry_interval", "5m")
+	viper.SetDefault("signing.certificate_path", "")
+	viper.SetDefault("signing.reason", "Perjanjian ditandatangani secara elektronik oleh Loan Engine")
+	viper.SetDefault("signing.location", "Jakarta, Indonesia")
+	viper.SetDefault("e_signing.otp_ttl", "5m")
+	viper.SetDefault("e_signing.max_attempts", 5)
+	viper.SetDefault("e_signing.resend_interval", "60s")
+	viper.SetDefault("sms.driver", "file")
+	viper.SetDefault("sms.file.path", "tmp/sms.jsonl")
+	viper.SetDefault("email.driver", "file")
+	viper.SetDefault("email.from", "Loan Engine <no-reply@loan-engine.local>")
+	viper.SetDefault("email.file.dir", "tmp/mail")
+	viper.SetDefault("email.smtp.host", "localhost")
+	viper.SetDefault("email.smtp.port", 1025)
+	viper.SetDefault("outbox.relay_interval", "1s")
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.lease", "1m")
+	viper.SetDefault("outbox.retry_base", "5s")
+	viper.SetDefault("outbox.retry_max", "10m")
+	viper.SetDefault("webhooks.delivery_interval", "5s")
+	viper.SetDefault("webhooks.batch_size", 20)
+	viper.SetDefault("webhooks.lease", "5m")
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.max_attempts", 8)
+	viper.SetDefault("webhooks.retry_base", "30s")
+	viper.SetDefault("webhooks.retry_max", "1h")
+	viper.SetDefault("payout.driver", "simulated")
+	viper.SetDefault("payout.simulated.delay", "5s")
+	viper.SetDefault("payout.simulated.fail_accounts", []string{})
+	viper.SetDefault("bank_verification.driver", "fixture")
+	viper.SetDefault("bank_verification.fixture.path", "config/bank_accounts.json")
+	viper.SetDefault("bank_verification.match_threshold", 0.85)
+	viper.SetDefault("idempotency.ttl", "24h")
+	viper.SetDefault("idempotency.lock_timeout", "1m")
+	viper.SetDefault("idempotency.purge_interval", "1h")
+	viper.SetDefault("payments.gateway", "fake")
+	viper.SetDefault("payments.webhook_secret", "")
+	viper.SetDefault("payments.webhook_tolerance", "5m")
+	viper.SetDefault("payments.virtual_account_prefix", "88810")
+	viper.SetDefault("payments.virtual_account_bank", "Bank BCA")
+	viper.SetDefault("approval.tiers", []map[string]interface{}{
+		{"max_amount": 50000000, "required_approvals": 1, "roles": []string{"FIELD_OFFICER"}},
+		{"max_amount": 250000000, "required_approvals": 2, "roles": []string{"FIELD_OFFICER"}},
+		{"max_amount": 0, "required_approvals": 3, "roles": []string{"FIELD_OFFICER"}},
+	})
 
 	lvl, _ := zerolog.ParseLevel(viper.GetString("log.level"))
 
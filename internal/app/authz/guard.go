@@ -0,0 +1,133 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+
+	"github.com/google/uuid"
+)
+
+// Guard performs resource level checks that a route permission alone cannot express.
+type Guard interface {
+	CheckLoanAccess(ctx context.Context, employeeID, loanID uuid.UUID, permission string) error
+	CheckAssignee(ctx context.Context, employeeID, loanID uuid.UUID, permission string) error
+	CheckPermission(ctx context.Context, employeeID uuid.UUID, permission string) (*models.EmployeeScope, error)
+	Authorize(ctx context.Context, claims *models.JWTClaims, permissions ...string) error
+}
+
+type guard struct {
+	accessRepo repositories.AccessRepository
+	policy     Policy
+}
+
+func NewGuard(accessRepo repositories.AccessRepository, policy Policy) Guard {
+	return &guard{
+		accessRepo: accessRepo,
+		policy:     policy,
+	}
+}
+
+// CheckLoanAccess verifies the employee currently holds the permission, that the
+// loan belongs to the employee's branch and, for surveys, that the loan was
+// assigned to that validator.
+func (g *guard) CheckLoanAccess(ctx context.Context, employeeID, loanID uuid.UUID, permission string) error {
+	employee, err := g.CheckPermission(ctx, employeeID, permission)
+	if err != nil {
+		return err
+	}
+
+	loan, err := g.accessRepo.GetLoanScope(ctx, loanID)
+	if err != nil {
+		return err
+	}
+
+	if !employee.AllBranches && employee.Branch != loan.BorrowerBranch {
+		return fmt.Errorf("loan outside employee branch")
+	}
+
+	if permission == constants.PERM_SURVEY_UPLOAD {
+		if loan.AssignedValidatorEmployeeID == nil || *loan.AssignedValidatorEmployeeID != employeeID {
+			return fmt.Errorf("loan not assigned to validator")
+		}
+	}
+
+	return nil
+}
+
+// CheckPermission verifies the employee is active and currently holds the
+// permission, and returns their scope for list queries that filter by branch.
+func (g *guard) CheckPermission(ctx context.Context, employeeID uuid.UUID, permission string) (*models.EmployeeScope, error) {
+	employee, err := g.accessRepo.GetEmployeeScope(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !employee.IsActive || !g.policy.HasPermission(employee.Role, permission) {
+		return nil, fmt.Errorf("permission denied: %s", permission)
+	}
+
+	employee.AllBranches = g.policy.HasPermission(employee.Role, constants.PERM_LOAN_ALL_BRANCHES)
+	return employee, nil
+}
+
+// Authorize verifies the principal behind a token holds any of the
+// permissions. Employees are checked against their current role and status,
+// not the ones in their token, so demoting or deactivating an employee takes
+// effect on their next request.
+func (g *guard) Authorize(ctx context.Context, claims *models.JWTClaims, permissions ...string) error {
+	if claims.UserType != constants.USER_EMPLOYEE {
+		for _, permission := range permissions {
+			if Allows(g.policy, claims, permission) {
+				return nil
+			}
+		}
+		return fmt.Errorf("permission denied: %v", permissions)
+	}
+
+	employeeID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid employee ID")
+	}
+
+	employee, err := g.accessRepo.GetEmployeeScope(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+
+	if employee.IsActive {
+		for _, permission := range permissions {
+			if g.policy.HasPermission(employee.Role, permission) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("permission denied: %v", permissions)
+}
+
+// CheckAssignee verifies that work on the loan can be handed to the employee:
+// they must be active, hold the permission and work in the loan's branch.
+func (g *guard) CheckAssignee(ctx context.Context, employeeID, loanID uuid.UUID, permission string) error {
+	employee, err := g.accessRepo.GetEmployeeScope(ctx, employeeID)
+	if err != nil {
+		return err
+	}
+
+	if !employee.IsActive || !g.policy.HasPermission(employee.Role, permission) {
+		return fmt.Errorf("assignee lacks permission: %s", permission)
+	}
+
+	loan, err := g.accessRepo.GetLoanScope(ctx, loanID)
+	if err != nil {
+		return err
+	}
+
+	if employee.Branch != loan.BorrowerBranch {
+		return fmt.Errorf("loan outside employee branch")
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package authz
+
+import (
+	"context"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckLoanAccess_ValidatorOnlySurveysAssignedLoans(t *testing.T) {
+	accessRepo := mocksRepo.NewAccessRepository(t)
+	guard := NewGuard(accessRepo, NewPolicy(DefaultRolePermissions))
+
+	validatorID := uuid.New()
+	otherValidatorID := uuid.New()
+	loanID := uuid.New()
+
+	accessRepo.On("GetEmployeeScope", mock.Anything, validatorID).Return(&models.EmployeeScope{
+		ID: validatorID, Role: "FIELD_VALIDATOR", Branch: "Bekasi", IsActive: true,
+	}, nil)
+	accessRepo.On("GetLoanScope", mock.Anything, loanID).Return(&models.LoanScope{
+		ID: loanID, BorrowerBranch: "Bekasi", AssignedValidatorEmployeeID: &otherValidatorID,
+	}, nil)
+
+	err := guard.CheckLoanAccess(context.Background(), validatorID, loanID, "survey:upload")
+
+	assert.EqualError(t, err, "loan not assigned to validator")
+}
+
+func TestCheckLoanAccess_BranchScope(t *testing.T) {
+	accessRepo := mocksRepo.NewAccessRepository(t)
+	guard := NewGuard(accessRepo, NewPolicy(DefaultRolePermissions))
+
+	officerID := uuid.New()
+	adminID := uuid.New()
+	loanID := uuid.New()
+
+	accessRepo.On("GetEmployeeScope", mock.Anything, officerID).Return(&models.EmployeeScope{
+		ID: officerID, Role: "FIELD_OFFICER", Branch: "Tangerang", IsActive: true,
+	}, nil)
+	accessRepo.On("GetEmployeeScope", mock.Anything, adminID).Return(&models.EmployeeScope{
+		ID: adminID, Role: "ADMIN", Branch: "Jakarta", IsActive: true,
+	}, nil)
+	accessRepo.On("GetLoanScope", mock.Anything, loanID).Return(&models.LoanScope{
+		ID: loanID, BorrowerBranch: "Bekasi",
+	}, nil)
+
+	err := guard.CheckLoanAccess(context.Background(), officerID, loanID, "loan:approve")
+	assert.EqualError(t, err, "loan outside employee branch")
+
+	// ADMIN holds loan:all_branches
+	err = guard.CheckLoanAccess(context.Background(), adminID, loanID, "loan:assign")
+	assert.NoError(t, err)
+}
+
+func TestCheckLoanAccess_InactiveEmployeeDenied(t *testing.T) {
+	accessRepo := mocksRepo.NewAccessRepository(t)
+	guard := NewGuard(accessRepo, NewPolicy(DefaultRolePermissions))
+
+	officerID := uuid.New()
+	accessRepo.On("GetEmployeeScope", mock.Anything, officerID).Return(&models.EmployeeScope{
+		ID: officerID, Role: "FIELD_OFFICER", IsActive: false,
+	}, nil)
+
+	err := guard.CheckLoanAccess(context.Background(), officerID, uuid.New(), "loan:approve")
+
+	assert.EqualError(t, err, "permission denied: loan:approve")
+}
+
+func TestPolicy_RoleNamesAreCaseInsensitive(t *testing.T) {
+	policy := NewPolicy(map[string][]string{"field_officer": {"loan:approve"}})
+
+	assert.True(t, policy.HasPermission("FIELD_OFFICER", "loan:approve"))
+	assert.False(t, policy.HasPermission("FIELD_OFFICER", "loan:disburse"))
+}
+
+func TestAllows_ServicePrincipalUsesKeyScopes(t *testing.T) {
+	policy := NewPolicy(DefaultRolePermissions)
+	service := &models.JWTClaims{UserType: "service", Role: "collections", Scopes: []string{"loan:approve"}}
+
+	assert.True(t, Allows(policy, service, "loan:approve"))
+	assert.False(t, Allows(policy, service, "loan:disburse"))
+	// A principal named after a role gets nothing from the role mapping
+	assert.False(t, Allows(policy, &models.JWTClaims{UserType: "service", Role: "ADMIN"}, "loan:assign"))
+}
+
+func TestAuthorize_EmployeeUsesCurrentRoleAndStatus(t *testing.T) {
+	accessRepo := mocksRepo.NewAccessRepository(t)
+	guard := NewGuard(accessRepo, NewPolicy(DefaultRolePermissions))
+
+	demotedID := uuid.New()
+	inactiveID := uuid.New()
+	accessRepo.On("GetEmployeeScope", mock.Anything, demotedID).Return(&models.EmployeeScope{
+		ID: demotedID, Role: "FIELD_OFFICER", IsActive: true,
+	}, nil)
+	accessRepo.On("GetEmployeeScope", mock.Anything, inactiveID).Return(&models.EmployeeScope{
+		ID: inactiveID, Role: "ADMIN", IsActive: false,
+	}, nil)
+
+	// Both tokens were issued while the employee was an active ADMIN
+	demoted := &models.JWTClaims{UserID: demotedID.String(), UserType: "employee", Role: "ADMIN"}
+	inactive := &models.JWTClaims{UserID: inactiveID.String(), UserType: "employee", Role: "ADMIN"}
+
+	assert.EqualError(t, guard.Authorize(context.Background(), demoted, "employee:manage"), "permission denied: [employee:manage]")
+	assert.NoError(t, guard.Authorize(context.Background(), demoted, "employee:manage", "loan:approve"))
+	assert.EqualError(t, guard.Authorize(context.Background(), inactive, "loan:assign"), "permission denied: [loan:assign]")
+}
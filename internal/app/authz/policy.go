@@ -0,0 +1,131 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultRolePermissions mirrors the role_permissions seed and is used when no
+// mapping can be loaded.
+var DefaultRolePermissions = map[string][]string{
+	constants.ROLE_FIELD_VALIDATOR: {constants.PERM_SURVEY_UPLOAD, constants.PERM_DOCUMENT_READ, constants.PERM_DOCUMENT_DELETE},
+	constants.ROLE_FIELD_OFFICER:   {constants.PERM_LOAN_APPROVE, constants.PERM_LOAN_DISBURSE, constants.PERM_LOAN_ASSIGN, constants.PERM_DOCUMENT_READ, constants.PERM_DOCUMENT_DELETE},
+	constants.ROLE_ADMIN:           {constants.PERM_LOAN_ASSIGN, constants.PERM_LOAN_ALL_BRANCHES, constants.PERM_EMPLOYEE_MANAGE, constants.PERM_SERVICE_MANAGE, constants.PERM_DOCUMENT_READ, constants.PERM_DOCUMENT_DELETE, constants.PERM_TEMPLATE_MANAGE, constants.PERM_WEBHOOK_MANAGE, constants.PERM_BANK_OVERRIDE, constants.PERM_PAYMENT_RECONCILE},
+	constants.USER_BORROWER:        {constants.PERM_LOAN_CREATE, constants.PERM_AGREEMENT_SIGN},
+	constants.USER_INVESTOR:        {constants.PERM_INVESTMENT_CREATE},
+}
+
+type Policy interface {
+	HasPermission(role, permission string) bool
+	Permissions(role string) []string
+}
+
+type RolePolicy struct {
+	mu    sync.RWMutex
+	roles map[string]map[string]struct{}
+}
+
+func NewPolicy(rolePermissions map[string][]string) *RolePolicy {
+	p := &RolePolicy{}
+	p.Replace(rolePermissions)
+	return p
+}
+
+// LoadPolicy reads the role to permission mapping from the database.
+func LoadPolicy(ctx context.Context, repo repositories.AccessRepository) (*RolePolicy, error) {
+	rolePermissions, err := repo.GetRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(rolePermissions) == 0 {
+		return nil, fmt.Errorf("no role permissions configured")
+	}
+	return NewPolicy(rolePermissions), nil
+}
+
+func (p *RolePolicy) HasPermission(role, permission string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, ok := p.roles[strings.ToLower(role)][permission]
+	return ok
+}
+
+func (p *RolePolicy) Permissions(role string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	set := p.roles[strings.ToLower(role)]
+	permissions := make([]string, 0, len(set))
+	for permission := range set {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// Replace swaps the whole mapping, used when reloading from the database.
+// Role names are matched case-insensitively because viper lowercases map keys.
+func (p *RolePolicy) Replace(rolePermissions map[string][]string) {
+	roles := make(map[string]map[string]struct{}, len(rolePermissions))
+	for role, permissions := range rolePermissions {
+		set := make(map[string]struct{}, len(permissions))
+		for _, permission := range permissions {
+			set[permission] = struct{}{}
+		}
+		roles[strings.ToLower(role)] = set
+	}
+
+	p.mu.Lock()
+	p.roles = roles
+	p.mu.Unlock()
+}
+
+// RoleKey maps a principal to the role name used in the permission mapping.
+// Employees use their employee role, everyone else their user type.
+func RoleKey(claims *models.JWTClaims) string {
+	if claims.UserType == constants.USER_EMPLOYEE {
+		return claims.Role
+	}
+	return claims.UserType
+}
+
+// ServiceScopes lists the permissions that may be granted to API keys, those
+// whose handlers accept a service principal. Administrative permissions stay
+// with human employees. Permissions that change loans or investments act as
+// the borrower, investor or employee behind the request, so keys cannot hold
+// them.
+var ServiceScopes = []string{
+	constants.PERM_LOAN_READ,
+	constants.PERM_DOCUMENT_READ,
+}
+
+func IsServiceScope(scope string) bool {
+	for _, s := range ServiceScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the principal holds the permission. Service principals
+// are limited to the scopes on their API key, everyone else goes through the
+// role mapping.
+func Allows(policy Policy, claims *models.JWTClaims, permission string) bool {
+	if claims.UserType == constants.USER_SERVICE {
+		for _, scope := range claims.Scopes {
+			if scope == permission {
+				return true
+			}
+		}
+		return false
+	}
+	return policy.HasPermission(RoleKey(claims), permission)
+}
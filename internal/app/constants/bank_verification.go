@@ -0,0 +1,10 @@
+package constants
+
+// Outcomes of a bank account inquiry. Only MATCHED and OVERRIDDEN accounts can
+// be disbursed to.
+const (
+	BANK_VERIFICATION_MATCHED    = "MATCHED"
+	BANK_VERIFICATION_MISMATCHED = "MISMATCHED"
+	BANK_VERIFICATION_NOT_FOUND  = "NOT_FOUND"
+	BANK_VERIFICATION_OVERRIDDEN = "OVERRIDDEN"
+)
@@ -0,0 +1,15 @@
+package constants
+
+// Domain events written to the outbox together with the state change
+const (
+	EVENT_LOAN_PROPOSED        = "LoanProposed"
+	EVENT_LOAN_APPROVED        = "LoanApproved"
+	EVENT_INVESTMENT_CREATED   = "InvestmentCreated"
+	EVENT_INVESTMENT_CANCELLED = "InvestmentCancelled"
+	EVENT_LOAN_FULLY_FUNDED    = "LoanFullyFunded"
+	EVENT_LOAN_DISBURSED       = "LoanDisbursed"
+	EVENT_REPAYMENT_RECEIVED   = "RepaymentReceived"
+)
+
+// Every event belongs to a loan, events of one loan are published in order
+const AGGREGATE_LOAN = "loan"
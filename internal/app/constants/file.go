@@ -0,0 +1,61 @@
+package constants
+
+const (
+	DOCUMENT_SURVEY_PHOTO         = "SURVEY_PHOTO"
+	DOCUMENT_SURVEY_DOCUMENT      = "SURVEY_DOCUMENT"
+	DOCUMENT_SUPPORTING_DOCUMENT  = "SUPPORTING_DOCUMENT"
+	DOCUMENT_LOAN_AGREEMENT       = "LOAN_AGREEMENT"
+	DOCUMENT_SIGNED_AGREEMENT     = "SIGNED_AGREEMENT"
+	DOCUMENT_INVESTMENT_AGREEMENT = "INVESTMENT_AGREEMENT"
+)
+
+const (
+	SCAN_CLEAN    = "CLEAN"
+	SCAN_PENDING  = "PENDING"
+	SCAN_INFECTED = "INFECTED"
+)
+
+// Verification status of a stamped document
+const (
+	VERIFICATION_VALID   = "VALID"
+	VERIFICATION_REVOKED = "REVOKED"
+)
+
+// Status of a borrower e-signing session
+const (
+	SIGNING_PENDING    = "PENDING"
+	SIGNING_SIGNED     = "SIGNED"
+	SIGNING_FAILED     = "FAILED"
+	SIGNING_SUPERSEDED = "SUPERSEDED"
+)
+
+// UPLOADER_SYSTEM marks documents the platform generated itself.
+const UPLOADER_SYSTEM = "system"
+
+const (
+	FILE_DOWNLOAD_PATH = "/api/v1/files/"
+	FILE_SHARED_PATH   = "/api/v1/shared-files/"
+	// DOCUMENT_KEY_PREFIX holds every stored document as <prefix>/<loan_id>/<document_id><ext>
+	DOCUMENT_KEY_PREFIX = "documents"
+	// QUARANTINE_KEY_PREFIX holds infected files, they are never served
+	QUARANTINE_KEY_PREFIX = "quarantine"
+	// PENDING_SCAN_KEY_PREFIX holds the untouched upload of a document stored
+	// while the scanner was down, until it is rescanned
+	PENDING_SCAN_KEY_PREFIX = "pending-scan"
+)
+
+const (
+	// MAX_UPLOAD_SIZE bounds a single uploaded file
+	MAX_UPLOAD_SIZE = 10 << 20
+	// MAX_UPLOAD_REQUEST_SIZE leaves room for the other form fields
+	MAX_UPLOAD_REQUEST_SIZE = MAX_UPLOAD_SIZE + 1<<20
+)
+
+// Survey flags raised when a survey photo does not back up the survey
+const (
+	SURVEY_FLAG_GPS_MISSING                 = "GPS_MISSING"
+	SURVEY_FLAG_LOCATION_MISMATCH           = "LOCATION_MISMATCH"
+	SURVEY_FLAG_ADDRESS_COORDINATES_MISSING = "ADDRESS_COORDINATES_MISSING"
+	SURVEY_FLAG_CAPTURE_TIME_MISSING        = "CAPTURE_TIME_MISSING"
+	SURVEY_FLAG_CAPTURE_TIME_MISMATCH       = "CAPTURE_TIME_MISMATCH"
+)
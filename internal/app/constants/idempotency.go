@@ -0,0 +1,8 @@
+package constants
+
+// States of an idempotency key. A key is PROCESSING while its first request
+// runs and COMPLETED once the response is stored for replay.
+const (
+	IDEMPOTENCY_PROCESSING = "PROCESSING"
+	IDEMPOTENCY_COMPLETED  = "COMPLETED"
+)
@@ -0,0 +1,13 @@
+package constants
+
+// Investment status, investors have until the acceptance deadline to accept
+// their agreement
+const (
+	INVESTMENT_PENDING_ACCEPTANCE = "PENDING_ACCEPTANCE"
+	INVESTMENT_ACCEPTED           = "ACCEPTED"
+	INVESTMENT_CANCELLED          = "CANCELLED"
+)
+
+// INVESTMENT_EXPIRED_REASON is recorded on investments cancelled because the
+// agreement was not accepted in time.
+const INVESTMENT_EXPIRED_REASON = "agreement not accepted before the deadline"
@@ -7,3 +7,7 @@ const (
 	INVESTED  = "INVESTED"
 	DISBURSED = "DISBURSED"
 )
+
+// DISBURSING is between INVESTED and DISBURSED while the payout to the
+// borrower settles
+const DISBURSING = "DISBURSING"
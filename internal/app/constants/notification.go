@@ -0,0 +1,12 @@
+package constants
+
+// Notifications users can choose channels for
+const (
+	NOTIFICATION_INVESTMENT_AGREEMENT = "INVESTMENT_AGREEMENT"
+	NOTIFICATION_LOAN_FULLY_INVESTED  = "LOAN_FULLY_INVESTED"
+)
+
+const (
+	CHANNEL_EMAIL = "email"
+	CHANNEL_SMS   = "sms"
+)
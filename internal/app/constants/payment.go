@@ -0,0 +1,18 @@
+package constants
+
+// Incoming payments. A payment that cannot be matched to one disbursed loan
+// waits in SUSPENSE until an employee assigns it to a loan (RESOLVED) or
+// sends it back (RETURNED).
+const (
+	PAYMENT_MATCHED  = "MATCHED"
+	PAYMENT_SUSPENSE = "SUSPENSE"
+	PAYMENT_RESOLVED = "RESOLVED"
+	PAYMENT_RETURNED = "RETURNED"
+)
+
+// Why a payment went to suspense
+const (
+	SUSPENSE_UNKNOWN_ACCOUNT   = "unknown virtual account"
+	SUSPENSE_NO_DISBURSED_LOAN = "borrower has no disbursed loan"
+	SUSPENSE_SEVERAL_LOANS     = "borrower has several disbursed loans"
+)
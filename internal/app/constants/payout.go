@@ -0,0 +1,8 @@
+package constants
+
+// Payouts of a disbursement, following the transfer at the payout provider
+const (
+	PAYOUT_PENDING = "PENDING"
+	PAYOUT_SUCCESS = "SUCCESS"
+	PAYOUT_FAILED  = "FAILED"
+)
@@ -0,0 +1,21 @@
+package constants
+
+const (
+	PERM_LOAN_CREATE       = "loan:create"
+	PERM_LOAN_APPROVE      = "loan:approve"
+	PERM_LOAN_DISBURSE     = "loan:disburse"
+	PERM_LOAN_ASSIGN       = "loan:assign"
+	PERM_LOAN_READ         = "loan:read"
+	PERM_LOAN_ALL_BRANCHES = "loan:all_branches"
+	PERM_SURVEY_UPLOAD     = "survey:upload"
+	PERM_INVESTMENT_CREATE = "investment:create"
+	PERM_EMPLOYEE_MANAGE   = "employee:manage"
+	PERM_SERVICE_MANAGE    = "service:manage"
+	PERM_DOCUMENT_READ     = "document:read"
+	PERM_DOCUMENT_DELETE   = "document:delete"
+	PERM_TEMPLATE_MANAGE   = "template:manage"
+	PERM_AGREEMENT_SIGN    = "agreement:sign"
+	PERM_WEBHOOK_MANAGE    = "webhook:manage"
+	PERM_BANK_OVERRIDE     = "bank:override"
+	PERM_PAYMENT_RECONCILE = "payment:reconcile"
+)
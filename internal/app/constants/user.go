@@ -4,6 +4,27 @@ const (
 	USER_EMPLOYEE        = "employee"
 	USER_BORROWER        = "borrower"
 	USER_INVESTOR        = "investor"
+	USER_SERVICE         = "service"
 	ROLE_FIELD_VALIDATOR = "FIELD_VALIDATOR"
 	ROLE_FIELD_OFFICER   = "FIELD_OFFICER"
+	ROLE_ADMIN           = "ADMIN"
+)
+
+const (
+	TOKEN_USE_ACCESS        = "access"
+	TOKEN_USE_MFA_CHALLENGE = "mfa_challenge"
+	TOKEN_USE_API_KEY       = "api_key"
+)
+
+const (
+	AUDIT_EMPLOYEE_CREATE         = "CREATE"
+	AUDIT_EMPLOYEE_UPDATE         = "UPDATE"
+	AUDIT_EMPLOYEE_DEACTIVATE     = "DEACTIVATE"
+	AUDIT_EMPLOYEE_ACTIVATE       = "ACTIVATE"
+	AUDIT_EMPLOYEE_RESET_PASSWORD = "RESET_PASSWORD"
+)
+
+const (
+	LOCALE_INDONESIAN = "id"
+	LOCALE_ENGLISH    = "en"
 )
@@ -0,0 +1,13 @@
+package constants
+
+// Webhook delivery states. Deliveries are retried until they go through or
+// run out of attempts and end up DEAD.
+const (
+	WEBHOOK_PENDING   = "PENDING"
+	WEBHOOK_RETRYING  = "RETRYING"
+	WEBHOOK_DELIVERED = "DELIVERED"
+	WEBHOOK_DEAD      = "DEAD"
+)
+
+// WEBHOOK_ALL_EVENTS subscribes to every event type
+const WEBHOOK_ALL_EVENTS = "*"
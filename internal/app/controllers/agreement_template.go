@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+type AgreementTemplateController struct {
+	templateUsecase usecase.AgreementTemplateUsecase
+	validator       *validator.Validate
+}
+
+func NewAgreementTemplateController(templateUsecase usecase.AgreementTemplateUsecase) *AgreementTemplateController {
+	return &AgreementTemplateController{
+		templateUsecase: templateUsecase,
+		validator:       validator.New(),
+	}
+}
+
+func (c *AgreementTemplateController) PublishTemplate(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.PublishTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	agreementTemplate, err := c.templateUsecase.PublishTemplate(r.Context(), user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("actor_id", user.UserID).Str("template_type", req.TemplateType).Msg("Failed to publish agreement template")
+		c.handleTemplateError(w, err, "Failed to publish agreement template")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusCreated, "Agreement template published successfully", agreementTemplate)
+}
+
+func (c *AgreementTemplateController) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := c.templateUsecase.ListTemplates(r.Context(), r.URL.Query().Get("template_type"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list agreement templates")
+		c.handleTemplateError(w, err, "Failed to list agreement templates")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Agreement templates retrieved successfully", templates)
+}
+
+func (c *AgreementTemplateController) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version <= 0 {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid template version", nil)
+		return
+	}
+
+	agreementTemplate, err := c.templateUsecase.GetTemplate(r.Context(), chi.URLParam(r, "type"), version)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get agreement template")
+		c.handleTemplateError(w, err, "Failed to get agreement template")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Agreement template retrieved successfully", agreementTemplate)
+}
+
+func (c *AgreementTemplateController) handleTemplateError(w http.ResponseWriter, err error, fallback string) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "agreement template not found":
+		c.sendErrorResponse(w, http.StatusNotFound, "Agreement template not found", map[string]string{
+			"error_code": "TEMPLATE_NOT_FOUND",
+		})
+	case strings.HasPrefix(errMsg, "unknown template type"):
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	case strings.HasPrefix(errMsg, "invalid template"):
+		c.sendErrorResponse(w, http.StatusUnprocessableEntity, errMsg, map[string]string{
+			"error_code": "INVALID_TEMPLATE",
+		})
+	case errMsg == "invalid employee ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, fallback, nil)
+	}
+}
+
+func (c *AgreementTemplateController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *AgreementTemplateController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *AgreementTemplateController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+type APIKeyController struct {
+	apiKeyUsecase usecase.APIKeyUsecase
+	validator     *validator.Validate
+}
+
+func NewAPIKeyController(apiKeyUsecase usecase.APIKeyUsecase) *APIKeyController {
+	return &APIKeyController{
+		apiKeyUsecase: apiKeyUsecase,
+		validator:     validator.New(),
+	}
+}
+
+func (c *APIKeyController) CreateServicePrincipal(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.CreateServicePrincipalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	principal, err := c.apiKeyUsecase.CreateServicePrincipal(r.Context(), user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("actor_id", user.UserID).Msg("Failed to create service principal")
+		c.handleAPIKeyError(w, err, "Failed to create service principal")
+		return
+	}
+
+	log.Info().Str("actor_id", user.UserID).Str("service_principal", principal.Name).Msg("Service principal created")
+
+	c.sendSuccessResponse(w, http.StatusCreated, "Service principal created successfully", principal)
+}
+
+func (c *APIKeyController) ListServicePrincipals(w http.ResponseWriter, r *http.Request) {
+	principals, err := c.apiKeyUsecase.ListServicePrincipals(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list service principals")
+		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to list service principals", nil)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Service principals retrieved successfully", principals)
+}
+
+func (c *APIKeyController) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	principalID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	response, err := c.apiKeyUsecase.CreateAPIKey(r.Context(), user.UserID, principalID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("service_principal_id", principalID).Msg("Failed to create api key")
+		c.handleAPIKeyError(w, err, "Failed to create API key")
+		return
+	}
+
+	log.Info().
+		Str("actor_id", user.UserID).
+		Str("service_principal_id", principalID).
+		Str("api_key_id", response.APIKey.ID.String()).
+		Strs("scopes", response.APIKey.Scopes).
+		Msg("API key created")
+
+	c.sendSuccessResponse(w, http.StatusCreated, "API key created, store it now as it will not be shown again", response)
+}
+
+func (c *APIKeyController) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	principalID := chi.URLParam(r, "id")
+
+	keys, err := c.apiKeyUsecase.ListAPIKeys(r.Context(), principalID)
+	if err != nil {
+		log.Error().Err(err).Str("service_principal_id", principalID).Msg("Failed to list api keys")
+		c.handleAPIKeyError(w, err, "Failed to list API keys")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "API keys retrieved successfully", keys)
+}
+
+func (c *APIKeyController) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.RotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("Failed to decode request body")
+			c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+			return
+		}
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	response, err := c.apiKeyUsecase.RotateAPIKey(r.Context(), user.UserID, keyID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("api_key_id", keyID).Msg("Failed to rotate api key")
+		c.handleAPIKeyError(w, err, "Failed to rotate API key")
+		return
+	}
+
+	log.Info().
+		Str("actor_id", user.UserID).
+		Str("old_api_key_id", keyID).
+		Str("api_key_id", response.APIKey.ID.String()).
+		Msg("API key rotated")
+
+	c.sendSuccessResponse(w, http.StatusCreated, "API key rotated, store it now as it will not be shown again", response)
+}
+
+func (c *APIKeyController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	if err := c.apiKeyUsecase.RevokeAPIKey(r.Context(), keyID); err != nil {
+		log.Error().Err(err).Str("api_key_id", keyID).Msg("Failed to revoke api key")
+		c.handleAPIKeyError(w, err, "Failed to revoke API key")
+		return
+	}
+
+	log.Info().Str("actor_id", user.UserID).Str("api_key_id", keyID).Msg("API key revoked")
+
+	c.sendSuccessResponse(w, http.StatusOK, "API key revoked successfully", nil)
+}
+
+func (c *APIKeyController) handleAPIKeyError(w http.ResponseWriter, err error, fallback string) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "service principal not found" || errMsg == "api key not found":
+		c.sendErrorResponse(w, http.StatusNotFound, errMsg, nil)
+	case errMsg == "service principal already exists":
+		c.sendErrorResponse(w, http.StatusConflict, "Service principal name already in use", map[string]string{
+			"error_code": "SERVICE_PRINCIPAL_EXISTS",
+		})
+	case errMsg == "service principal inactive" || errMsg == "api key revoked" || errMsg == "api key expired":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, nil)
+	case strings.HasPrefix(errMsg, "invalid scope"):
+		c.sendErrorResponse(w, http.StatusUnprocessableEntity, errMsg, map[string]string{
+			"error_code": "INVALID_SCOPE",
+		})
+	case errMsg == "api key lifetime exceeds maximum":
+		c.sendErrorResponse(w, http.StatusUnprocessableEntity, errMsg, nil)
+	case strings.HasPrefix(errMsg, "invalid") && strings.HasSuffix(errMsg, "ID"):
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, fallback, nil)
+	}
+}
+
+func (c *APIKeyController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *APIKeyController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *APIKeyController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
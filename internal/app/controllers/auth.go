@@ -3,6 +3,7 @@ package controller
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
 	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
 	"net/http"
@@ -58,6 +59,150 @@ func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 	c.sendSuccessResponse(w, http.StatusOK, "Login successful", resp)
 }
 
+func (c *AuthController) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	challenge, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	resp, err := c.authUsecase.VerifyMFAChallenge(r.Context(), challenge, &req)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", challenge.UserID).Str("user_type", challenge.UserType).Msg("MFA verification failed")
+		c.handleMFAError(w, err)
+		return
+	}
+
+	log.Info().Str("user_id", challenge.UserID).Str("user_type", challenge.UserType).Msg("Login successful")
+	c.sendSuccessResponse(w, http.StatusOK, "Login successful", resp)
+}
+
+func (c *AuthController) SetupMFA(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	resp, err := c.authUsecase.SetupMFA(r.Context(), user.UserID, user.UserType)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.UserID).Msg("Failed to set up MFA")
+		c.handleMFAError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Scan the provisioning URI with an authenticator app", resp)
+}
+
+func (c *AuthController) ActivateMFA(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	resp, err := c.authUsecase.ActivateMFA(r.Context(), user.UserID, user.UserType, &req)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.UserID).Msg("Failed to activate MFA")
+		c.handleMFAError(w, err)
+		return
+	}
+
+	log.Info().Str("user_id", user.UserID).Str("user_type", user.UserType).Msg("MFA enabled")
+	c.sendSuccessResponse(w, http.StatusOK, "MFA enabled, store the recovery codes safely", resp)
+}
+
+func (c *AuthController) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	if err := c.authUsecase.DisableMFA(r.Context(), user.UserID, user.UserType, user.Role, &req); err != nil {
+		log.Error().Err(err).Str("user_id", user.UserID).Msg("Failed to disable MFA")
+		c.handleMFAError(w, err)
+		return
+	}
+
+	log.Info().Str("user_id", user.UserID).Str("user_type", user.UserType).Msg("MFA disabled")
+	c.sendSuccessResponse(w, http.StatusOK, "MFA disabled", nil)
+}
+
+func (c *AuthController) handleMFAError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch errMsg {
+	case "invalid mfa code", "mfa code already used", "invalid mfa challenge":
+		c.sendErrorResponse(w, http.StatusUnauthorized, errMsg, map[string]string{
+			"error_code": "INVALID_MFA_CODE",
+		})
+	case "too many mfa attempts":
+		c.sendErrorResponse(w, http.StatusTooManyRequests, errMsg, map[string]string{
+			"error_code": "MFA_LOCKED",
+		})
+	case "mfa code is required", "mfa not supported for user type":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
+			"error_code": "MFA_BAD_REQUEST",
+		})
+	case "mfa not set up", "mfa not enabled", "mfa already enabled", "mfa setup not found":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "MFA_INVALID_STATE",
+		})
+	case "mfa is mandatory for this role":
+		c.sendErrorResponse(w, http.StatusForbidden, errMsg, map[string]string{
+			"error_code": "MFA_MANDATORY",
+		})
+	case "employee not found", "investor not found":
+		c.sendErrorResponse(w, http.StatusUnauthorized, "Invalid credentials", map[string]string{
+			"error_code": "INVALID_CREDENTIALS",
+		})
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, "Internal server error", map[string]string{
+			"error_code": "INTERNAL_ERROR",
+		})
+	}
+}
+
 func (c *AuthController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -127,6 +272,12 @@ func getValidationMessage(err validator.FieldError) string {
 		return fmt.Sprintf("%s must be at least %s characters", err.Field(), err.Param())
 	case "oneof":
 		return fmt.Sprintf("%s must be one of: %s", err.Field(), err.Param())
+	case "len":
+		return fmt.Sprintf("%s must be %s characters", err.Field(), err.Param())
+	case "numeric":
+		return fmt.Sprintf("%s must be numeric", err.Field())
+	case "required_without":
+		return fmt.Sprintf("%s is required when %s is empty", err.Field(), err.Param())
 	default:
 		return fmt.Sprintf("%s is invalid", err.Field())
 	}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+type BankVerificationController struct {
+	bankVerificationUsecase usecase.BankVerificationUsecase
+	validator               *validator.Validate
+}
+
+func NewBankVerificationController(bankVerificationUsecase usecase.BankVerificationUsecase) *BankVerificationController {
+	return &BankVerificationController{
+		bankVerificationUsecase: bankVerificationUsecase,
+		validator:               validator.New(),
+	}
+}
+
+func (c *BankVerificationController) Verify(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	verification, err := c.bankVerificationUsecase.Verify(r.Context(), loanID, user.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("loan_id", loanID).Msg("Failed to verify bank account")
+		c.handleBankVerificationError(w, err, "Failed to verify bank account")
+		return
+	}
+
+	log.Info().
+		Str("loan_id", loanID).
+		Str("employee_id", user.UserID).
+		Str("status", verification.Status).
+		Float64("match_score", verification.MatchScore).
+		Msg("Bank account verified")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Bank account verified", verification)
+}
+
+func (c *BankVerificationController) ListVerifications(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	verifications, err := c.bankVerificationUsecase.ListVerifications(r.Context(), loanID, user.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("loan_id", loanID).Msg("Failed to list bank verifications")
+		c.handleBankVerificationError(w, err, "Failed to list bank verifications")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Bank verifications retrieved successfully", verifications)
+}
+
+func (c *BankVerificationController) Override(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.OverrideBankVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	verification, err := c.bankVerificationUsecase.Override(r.Context(), loanID, user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("loan_id", loanID).Msg("Failed to override bank verification")
+		c.handleBankVerificationError(w, err, "Failed to override bank verification")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Bank account mismatch overridden", verification)
+}
+
+func (c *BankVerificationController) handleBankVerificationError(w http.ResponseWriter, err error, fallback string) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "loan not found" || errMsg == "bank verification not found":
+		c.sendErrorResponse(w, http.StatusNotFound, errMsg, map[string]string{
+			"error_code": "NOT_FOUND",
+		})
+	case errMsg == "borrower has no bank account":
+		c.sendErrorResponse(w, http.StatusUnprocessableEntity, "Borrower has no bank account to verify", map[string]string{
+			"error_code": "BANK_ACCOUNT_REQUIRED",
+		})
+	case errMsg == "bank verification is outdated":
+		c.sendErrorResponse(w, http.StatusConflict, "The borrower's bank account changed, verify it again", map[string]string{
+			"error_code": "BANK_VERIFICATION_OUTDATED",
+		})
+	case errMsg == "bank verification cannot be overridden":
+		c.sendErrorResponse(w, http.StatusConflict, "Only a name mismatch can be overridden", map[string]string{
+			"error_code": "BANK_VERIFICATION_NOT_OVERRIDABLE",
+		})
+	case strings.HasPrefix(errMsg, "bank account inquiry failed"):
+		c.sendErrorResponse(w, http.StatusServiceUnavailable, "Bank account inquiry is unavailable, try again later", map[string]string{
+			"error_code": "BANK_INQUIRY_UNAVAILABLE",
+		})
+	case errMsg == "override reason is required" || errMsg == "invalid loan ID" || errMsg == "invalid employee ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	case isAccessError(errMsg):
+		c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, fallback, nil)
+	}
+}
+
+func (c *BankVerificationController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *BankVerificationController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *BankVerificationController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+type EmployeeController struct {
+	employeeUsecase usecase.EmployeeUsecase
+	validator       *validator.Validate
+}
+
+func NewEmployeeController(employeeUsecase usecase.EmployeeUsecase) *EmployeeController {
+	return &EmployeeController{
+		employeeUsecase: employeeUsecase,
+		validator:       validator.New(),
+	}
+}
+
+func (c *EmployeeController) ListEmployees(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models2.EmployeeListFilter{
+		EmployeeRole: query.Get("role"),
+	}
+
+	if active := query.Get("is_active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			c.sendErrorResponse(w, http.StatusBadRequest, "Invalid is_active value", nil)
+			return
+		}
+		filter.IsActive = &isActive
+	}
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, _ = strconv.Atoi(limit)
+	}
+	if offset := query.Get("offset"); offset != "" {
+		filter.Offset, _ = strconv.Atoi(offset)
+	}
+
+	employees, err := c.employeeUsecase.ListEmployees(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list employees")
+		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to list employees", nil)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Employees retrieved successfully", employees)
+}
+
+func (c *EmployeeController) GetEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+
+	employee, err := c.employeeUsecase.GetEmployee(r.Context(), employeeID)
+	if err != nil {
+		log.Error().Err(err).Str("employee_id", employeeID).Msg("Failed to get employee")
+		c.handleEmployeeError(w, err, "Failed to get employee")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Employee retrieved successfully", employee)
+}
+
+func (c *EmployeeController) CreateEmployee(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.CreateEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	employee, err := c.employeeUsecase.CreateEmployee(r.Context(), user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("actor_id", user.UserID).Msg("Failed to create employee")
+		c.handleEmployeeError(w, err, "Failed to create employee")
+		return
+	}
+
+	log.Info().
+		Str("actor_id", user.UserID).
+		Str("employee_id", employee.ID.String()).
+		Str("employee_role", employee.EmployeeRole).
+		Msg("Employee created")
+
+	c.sendSuccessResponse(w, http.StatusCreated, "Employee created successfully", employee)
+}
+
+func (c *EmployeeController) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.UpdateEmployeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	employee, err := c.employeeUsecase.UpdateEmployee(r.Context(), user.UserID, employeeID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("employee_id", employeeID).Str("actor_id", user.UserID).Msg("Failed to update employee")
+		c.handleEmployeeError(w, err, "Failed to update employee")
+		return
+	}
+
+	log.Info().Str("employee_id", employeeID).Str("actor_id", user.UserID).Msg("Employee updated")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Employee updated successfully", employee)
+}
+
+func (c *EmployeeController) DeactivateEmployee(w http.ResponseWriter, r *http.Request) {
+	c.setEmployeeActive(w, r, false)
+}
+
+func (c *EmployeeController) ActivateEmployee(w http.ResponseWriter, r *http.Request) {
+	c.setEmployeeActive(w, r, true)
+}
+
+func (c *EmployeeController) setEmployeeActive(w http.ResponseWriter, r *http.Request, active bool) {
+	employeeID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	employee, err := c.employeeUsecase.SetEmployeeActive(r.Context(), user.UserID, employeeID, active)
+	if err != nil {
+		log.Error().Err(err).Str("employee_id", employeeID).Bool("active", active).Msg("Failed to change employee status")
+		c.handleEmployeeError(w, err, "Failed to change employee status")
+		return
+	}
+
+	log.Info().Str("employee_id", employeeID).Str("actor_id", user.UserID).Bool("active", active).Msg("Employee status changed")
+
+	message := "Employee deactivated successfully"
+	if active {
+		message = "Employee activated successfully"
+	}
+	c.sendSuccessResponse(w, http.StatusOK, message, employee)
+}
+
+func (c *EmployeeController) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.ResetPasswordRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("Failed to decode request body")
+			c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+			return
+		}
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	response, err := c.employeeUsecase.ResetPassword(r.Context(), user.UserID, employeeID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("employee_id", employeeID).Msg("Failed to reset password")
+		c.handleEmployeeError(w, err, "Failed to reset password")
+		return
+	}
+
+	log.Info().Str("employee_id", employeeID).Str("actor_id", user.UserID).Bool("mfa_reset", req.ResetMFA).Msg("Employee password reset")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Password reset successfully", response)
+}
+
+func (c *EmployeeController) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	employeeID := chi.URLParam(r, "id")
+
+	logs, err := c.employeeUsecase.ListAuditLogs(r.Context(), employeeID)
+	if err != nil {
+		log.Error().Err(err).Str("employee_id", employeeID).Msg("Failed to list audit logs")
+		c.handleEmployeeError(w, err, "Failed to list audit logs")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Audit logs retrieved successfully", logs)
+}
+
+func (c *EmployeeController) handleEmployeeError(w http.ResponseWriter, err error, fallback string) {
+	errMsg := err.Error()
+	switch errMsg {
+	case "employee not found":
+		c.sendErrorResponse(w, http.StatusNotFound, "Employee not found", map[string]string{
+			"error_code": "EMPLOYEE_NOT_FOUND",
+		})
+	case "employee already exists":
+		c.sendErrorResponse(w, http.StatusConflict, "Username or email already in use", map[string]string{
+			"error_code": "EMPLOYEE_EXISTS",
+		})
+	case "cannot remove the last active admin":
+		c.sendErrorResponse(w, http.StatusConflict, "Cannot remove the last active admin", map[string]string{
+			"error_code": "LAST_ADMIN",
+		})
+	case "cannot reset own password":
+		c.sendErrorResponse(w, http.StatusForbidden, "Use the profile flow to change your own password", nil)
+	case "cannot change own role or status":
+		c.sendErrorResponse(w, http.StatusForbidden, "Another admin must change your role or status", nil)
+	case "invalid employee ID", "invalid actor ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, fallback, nil)
+	}
+}
+
+func (c *EmployeeController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *EmployeeController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *EmployeeController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
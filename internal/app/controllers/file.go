@@ -2,13 +2,21 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
 	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/scanner"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/upload"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
+	"io"
 	"net/http"
+	"strconv"
 )
 
 type FileController struct {
@@ -31,9 +39,17 @@ func (c *FileController) UploadSurveyDocument(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	r.Body = http.MaxBytesReader(w, r.Body, constants.MAX_UPLOAD_REQUEST_SIZE)
+
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get file from form")
+		if isRequestTooLarge(err) {
+			c.sendErrorResponse(w, http.StatusRequestEntityTooLarge, "File size exceeds maximum limit of 10MB", map[string]string{
+				"error_code": "FILE_TOO_LARGE",
+			})
+			return
+		}
 		c.sendErrorResponse(w, http.StatusBadRequest, "File is required", map[string]string{
 			"error_code": "FILE_REQUIRED",
 		})
@@ -41,19 +57,12 @@ func (c *FileController) UploadSurveyDocument(w http.ResponseWriter, r *http.Req
 	}
 	defer file.Close()
 
-	// Check file size in header
-	if header.Size > 10*1024*1024 {
-		c.sendErrorResponse(w, http.StatusBadRequest, "File size exceeds maximum limit of 10MB", map[string]string{
-			"error_code": "FILE_TOO_LARGE",
-		})
-		return
-	}
-
 	// Parse form data
 	req := &models2.UploadDocumentRequest{
-		LoanID:      r.FormValue("loan_id"),
-		SurveyDate:  r.FormValue("survey_date"),
-		SurveyNotes: r.FormValue("survey_notes"),
+		LoanID:       r.FormValue("loan_id"),
+		SurveyDate:   r.FormValue("survey_date"),
+		SurveyNotes:  r.FormValue("survey_notes"),
+		DocumentType: r.FormValue("document_type"),
 	}
 
 	// Validate request
@@ -69,13 +78,24 @@ func (c *FileController) UploadSurveyDocument(w http.ResponseWriter, r *http.Req
 		log.Error().Err(err).Str("loan_id", req.LoanID).Msg("Failed to upload survey document")
 
 		// Handle specific errors
+		if status, code, ok := uploadError(err); ok {
+			c.sendErrorResponse(w, status, err.Error(), map[string]string{
+				"error_code": code,
+			})
+			return
+		}
+
 		errMsg := err.Error()
 		switch {
 		case contains(errMsg, "loan not found"):
 			c.sendErrorResponse(w, http.StatusNotFound, "Loan not found", map[string]string{
 				"error_code": "LOAN_NOT_FOUND",
 			})
-		case contains(errMsg, "not in PROPOSED state"):
+		case isAccessError(errMsg):
+			c.sendErrorResponse(w, http.StatusForbidden, errMsg, map[string]string{
+				"error_code": "FORBIDDEN",
+			})
+		case contains(errMsg, "PROPOSED state"):
 			c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
 				"error_code": "INVALID_LOAN_STATE",
 			})
@@ -83,14 +103,14 @@ func (c *FileController) UploadSurveyDocument(w http.ResponseWriter, r *http.Req
 			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
 				"error_code": "INVALID_FILE_TYPE",
 			})
-		case contains(errMsg, "file size exceeds"):
-			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
-				"error_code": "FILE_TOO_LARGE",
-			})
 		case contains(errMsg, "invalid survey date"):
 			c.sendErrorResponse(w, http.StatusBadRequest, "Invalid survey date format, expected YYYY-MM-DD", map[string]string{
 				"error_code": "INVALID_DATE_FORMAT",
 			})
+		case errMsg == "survey date cannot be in the future":
+			c.sendErrorResponse(w, http.StatusBadRequest, "Survey date cannot be in the future", map[string]string{
+				"error_code": "SURVEY_DATE_IN_FUTURE",
+			})
 		default:
 			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to upload document", map[string]string{
 				"error_code": "UPLOAD_FAILED",
@@ -102,12 +122,304 @@ func (c *FileController) UploadSurveyDocument(w http.ResponseWriter, r *http.Req
 	log.Info().
 		Str("loan_id", req.LoanID).
 		Str("validator_id", user.UserID).
-		Str("file_name", response.FileName).
+		Str("document_id", response.DocumentID.String()).
+		Int("version", response.Version).
+		Int("survey_flags", len(response.SurveyFlags)).
 		Msg("Survey document uploaded successfully")
 
 	c.sendSuccessResponse(w, http.StatusCreated, "Survey document uploaded successfully", response)
 }
 
+func (c *FileController) GetFile(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, "file_id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	download, err := c.fileUsecase.GetFile(r.Context(), fileID, user)
+	if err != nil {
+		log.Error().Err(err).Str("file_id", fileID).Str("user_id", user.UserID).Msg("Failed to get file")
+		c.handleFileError(w, err)
+		return
+	}
+	defer download.Content.Close()
+
+	c.streamFile(w, r, download)
+}
+
+func (c *FileController) ShareFile(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, "file_id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.ShareFileRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error().Err(err).Msg("Failed to decode request body")
+			c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+			return
+		}
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	response, err := c.fileUsecase.ShareFile(r.Context(), fileID, user, &req)
+	if err != nil {
+		log.Error().Err(err).Str("file_id", fileID).Str("user_id", user.UserID).Msg("Failed to share file")
+		c.handleFileError(w, err)
+		return
+	}
+
+	log.Info().
+		Str("file_id", fileID).
+		Str("user_id", user.UserID).
+		Time("expires_at", response.ExpiresAt).
+		Msg("Signed file link issued")
+
+	c.sendSuccessResponse(w, http.StatusCreated, "Signed file link created", response)
+}
+
+// GetSharedFile serves signed links. It sits outside the auth middleware, the
+// signature is the credential.
+func (c *FileController) GetSharedFile(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, "file_id")
+
+	download, err := c.fileUsecase.GetSharedFile(r.Context(), fileID, r.URL.Query().Get("expires"), r.URL.Query().Get("signature"))
+	if err != nil {
+		log.Error().Err(err).Str("file_id", fileID).Msg("Failed to get shared file")
+		c.handleFileError(w, err)
+		return
+	}
+	defer download.Content.Close()
+
+	c.streamFile(w, r, download)
+}
+
+// VerifyDocument is public, anyone holding an agreement can check it against
+// the registry. A copy can be presented by its hash in the sha256 parameter.
+func (c *FileController) VerifyDocument(w http.ResponseWriter, r *http.Request) {
+	verificationID := chi.URLParam(r, "id")
+
+	verification, err := c.fileUsecase.VerifyDocument(r.Context(), verificationID, r.URL.Query().Get("sha256"))
+	if err != nil {
+		log.Error().Err(err).Str("verification_id", verificationID).Msg("Failed to verify document")
+		c.handleFileError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Document verified", verification)
+}
+
+// VerifyUploadedDocument checks a copy uploaded in the file field.
+func (c *FileController) VerifyUploadedDocument(w http.ResponseWriter, r *http.Request) {
+	verificationID := chi.URLParam(r, "id")
+
+	r.Body = http.MaxBytesReader(w, r.Body, constants.MAX_UPLOAD_REQUEST_SIZE)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get file from form")
+		if isRequestTooLarge(err) {
+			c.sendErrorResponse(w, http.StatusRequestEntityTooLarge, "File size exceeds maximum limit of 10MB", map[string]string{
+				"error_code": "FILE_TOO_LARGE",
+			})
+			return
+		}
+		c.sendErrorResponse(w, http.StatusBadRequest, "File is required", nil)
+		return
+	}
+	defer file.Close()
+
+	verification, err := c.fileUsecase.VerifyDocumentContent(r.Context(), verificationID, file)
+	if err != nil {
+		log.Error().Err(err).Str("verification_id", verificationID).Msg("Failed to verify document")
+		c.handleFileError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Document verified", verification)
+}
+
+func (c *FileController) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+
+	documents, err := c.fileUsecase.ListDocuments(r.Context(), loanID, user, includeDeleted)
+	if err != nil {
+		log.Error().Err(err).Str("loan_id", loanID).Str("user_id", user.UserID).Msg("Failed to list documents")
+		c.handleFileError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Documents retrieved successfully", documents)
+}
+
+func (c *FileController) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	documentID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.DeleteDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	if err := c.fileUsecase.DeleteDocument(r.Context(), documentID, user.UserID, &req); err != nil {
+		log.Error().Err(err).Str("document_id", documentID).Str("employee_id", user.UserID).Msg("Failed to delete document")
+		c.handleFileError(w, err)
+		return
+	}
+
+	log.Info().
+		Str("document_id", documentID).
+		Str("employee_id", user.UserID).
+		Str("reason", req.Reason).
+		Msg("Document deleted")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Document deleted successfully", nil)
+}
+
+func (c *FileController) streamFile(w http.ResponseWriter, r *http.Request, download *models2.FileDownload) {
+	if download.ContentType != "" {
+		w.Header().Set("Content-Type", download.ContentType)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", download.FileName))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if readSeeker, ok := download.Content.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, download.FileName, download.LastModified, readSeeker)
+		return
+	}
+
+	if download.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(download.Size, 10))
+	}
+	if _, err := io.Copy(w, download.Content); err != nil {
+		log.Error().Err(err).Str("file_name", download.FileName).Msg("Failed to stream file")
+	}
+}
+
+func (c *FileController) handleFileError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "file not found":
+		c.sendErrorResponse(w, http.StatusNotFound, "File not found", map[string]string{
+			"error_code": "FILE_NOT_FOUND",
+		})
+	case errMsg == "document not found":
+		c.sendErrorResponse(w, http.StatusNotFound, "Document not found", map[string]string{
+			"error_code": "DOCUMENT_NOT_FOUND",
+		})
+	case errMsg == "invalid loan ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	case errMsg == "invalid sha256":
+		c.sendErrorResponse(w, http.StatusBadRequest, "sha256 must be 64 hex characters", map[string]string{
+			"error_code": "INVALID_SHA256",
+		})
+	case contains(errMsg, "loan not found"):
+		c.sendErrorResponse(w, http.StatusNotFound, "Loan not found", map[string]string{
+			"error_code": "LOAN_NOT_FOUND",
+		})
+	case isAccessError(errMsg):
+		c.sendErrorResponse(w, http.StatusForbidden, errMsg, map[string]string{
+			"error_code": "FORBIDDEN",
+		})
+	case contains(errMsg, "document type cannot be deleted"):
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "DOCUMENT_NOT_DELETABLE",
+		})
+	case contains(errMsg, "PROPOSED state"):
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "INVALID_LOAN_STATE",
+		})
+	case errMsg == "file pending malware scan":
+		c.sendErrorResponse(w, http.StatusConflict, "File is waiting for a malware scan", map[string]string{
+			"error_code": "FILE_PENDING_SCAN",
+		})
+	case errMsg == "invalid file signature":
+		c.sendErrorResponse(w, http.StatusForbidden, "Invalid file signature", map[string]string{
+			"error_code": "INVALID_SIGNATURE",
+		})
+	case errMsg == "file link expired":
+		c.sendErrorResponse(w, http.StatusGone, "File link expired", map[string]string{
+			"error_code": "LINK_EXPIRED",
+		})
+	case errMsg == "file sharing is not configured":
+		c.sendErrorResponse(w, http.StatusServiceUnavailable, "File sharing is not configured", map[string]string{
+			"error_code": "SHARING_DISABLED",
+		})
+	case contains(errMsg, "expiry exceeds maximum"):
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
+			"error_code": "INVALID_EXPIRY",
+		})
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to get file", map[string]string{
+			"error_code": "FILE_UNAVAILABLE",
+		})
+	}
+}
+
+// uploadError maps content inspection failures to their status and error code.
+func uploadError(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, upload.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", true
+	case errors.Is(err, upload.ErrInvalidExtension):
+		return http.StatusBadRequest, "INVALID_FILE_TYPE", true
+	case errors.Is(err, upload.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType, "UNSUPPORTED_FILE_CONTENT", true
+	case errors.Is(err, upload.ErrTypeMismatch):
+		return http.StatusBadRequest, "FILE_TYPE_MISMATCH", true
+	case errors.Is(err, upload.ErrInvalidImage):
+		return http.StatusBadRequest, "INVALID_IMAGE", true
+	case errors.Is(err, upload.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge, "IMAGE_TOO_LARGE", true
+	case errors.Is(err, upload.ErrPDFJavaScript):
+		return http.StatusBadRequest, "PDF_JAVASCRIPT_NOT_ALLOWED", true
+	case errors.Is(err, upload.ErrPDFEncrypted):
+		return http.StatusBadRequest, "PDF_ENCRYPTED", true
+	case errors.Is(err, scanner.ErrInfected):
+		return http.StatusUnprocessableEntity, "MALWARE_DETECTED", true
+	}
+	return 0, "", false
+}
+
+func isRequestTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
 func (c *FileController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -2,11 +2,14 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
 	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"io"
+	"net"
 	"net/http"
 	"strings"
 
@@ -78,6 +81,138 @@ func (c *InvestmentController) CreateInvestment(w http.ResponseWriter, r *http.R
 	c.sendSuccessResponse(w, http.StatusCreated, message, response)
 }
 
+// AcceptAgreement is the investor's click-to-accept of their investment
+// agreement. The body is optional, it can pin the hash of the copy that was
+// read.
+func (c *InvestmentController) AcceptAgreement(w http.ResponseWriter, r *http.Request) {
+	investmentID := chi.URLParam(r, "id")
+	if investmentID == "" {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Investment ID is required", map[string]string{
+			"error_code": "MISSING_INVESTMENT_ID",
+		})
+		return
+	}
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user from context")
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.AcceptAgreementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	evidence := usecase.ClientInfo{
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	response, err := c.investmentUsecase.AcceptAgreement(r.Context(), investmentID, user.UserID, &req, evidence)
+	if err != nil {
+		log.Error().Err(err).
+			Str("investment_id", investmentID).
+			Str("investor_id", user.UserID).
+			Msg("Failed to accept investment agreement")
+
+		c.handleAcceptanceError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Agreement accepted successfully", response)
+}
+
+func (c *InvestmentController) handleAcceptanceError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch {
+	case errMsg == "investment not found" || errMsg == "investment agreement not found":
+		c.sendErrorResponse(w, http.StatusNotFound, errMsg, map[string]string{
+			"error_code": "INVESTMENT_NOT_FOUND",
+		})
+	case errMsg == "invalid investment ID" || errMsg == "invalid investor ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
+			"error_code": "INVALID_ID",
+		})
+	case errMsg == "agreement already accepted":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "AGREEMENT_ALREADY_ACCEPTED",
+		})
+	case errMsg == "investment has been cancelled" || errMsg == "acceptance deadline has passed" ||
+		errMsg == "investment is not pending acceptance":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "ACCEPTANCE_CLOSED",
+		})
+	case errMsg == "agreement has changed":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "AGREEMENT_CHANGED",
+		})
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to accept agreement", map[string]string{
+			"error_code": "INTERNAL_ERROR",
+		})
+	}
+}
+
+// GetPortfolio returns the investor's loans, each with their tranches in it
+// added up.
+func (c *InvestmentController) GetPortfolio(w http.ResponseWriter, r *http.Request) {
+	investorID := chi.URLParam(r, "investor_id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user from context")
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	portfolio, err := c.investmentUsecase.GetPortfolio(r.Context(), investorID, user.UserID)
+	if err != nil {
+		log.Error().Err(err).
+			Str("investor_id", investorID).
+			Msg("Failed to get investor portfolio")
+
+		errMsg := err.Error()
+		switch {
+		case errMsg == "invalid investor ID":
+			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
+				"error_code": "INVALID_ID",
+			})
+		case isAccessError(errMsg):
+			c.sendErrorResponse(w, http.StatusForbidden, "You can only view your own portfolio", map[string]string{
+				"error_code": "FORBIDDEN",
+			})
+		default:
+			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to get portfolio", map[string]string{
+				"error_code": "INTERNAL_ERROR",
+			})
+		}
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Portfolio retrieved successfully", portfolio)
+}
+
+// clientIP is the caller's address without the port. The router's RealIP
+// middleware has already applied forwarding headers.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func (c *InvestmentController) handleInvestmentError(w http.ResponseWriter, err error) {
 	errMsg := err.Error()
 
@@ -94,14 +229,14 @@ func (c *InvestmentController) handleInvestmentError(w http.ResponseWriter, err
 		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
 			"error_code": "INVALID_LOAN_STATE",
 		})
-	case errMsg == "investor has already invested in this loan":
-		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
-			"error_code": "DUPLICATE_INVESTMENT",
-		})
 	case strings.Contains(errMsg, "investment amount exceeds remaining"):
 		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
 			"error_code": "INVESTMENT_EXCEEDS_REMAINING",
 		})
+	case errMsg == "investment tranche already taken":
+		c.sendErrorResponse(w, http.StatusConflict, "Another investment in this loan was placed at the same time, please retry", map[string]string{
+			"error_code": "CONCURRENT_INVESTMENT",
+		})
 	default:
 		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to create investment", map[string]string{
 			"error_code": "INTERNAL_ERROR",
@@ -1,19 +1,21 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
 	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
 	"github.com/go-chi/chi/v5"
-	"io"
+	"github.com/google/uuid"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
@@ -21,12 +23,14 @@ import (
 
 type LoanController struct {
 	loanUsecase usecase.LoanUsecase
+	intake      usecase.UploadIntake
 	validator   *validator.Validate
 }
 
-func NewLoanController(loanUsecase usecase.LoanUsecase) *LoanController {
+func NewLoanController(loanUsecase usecase.LoanUsecase, intake usecase.UploadIntake) *LoanController {
 	return &LoanController{
 		loanUsecase: loanUsecase,
+		intake:      intake,
 		validator:   validator.New(),
 	}
 }
@@ -85,6 +89,29 @@ func (c *LoanController) CreateLoanProposal(w http.ResponseWriter, r *http.Reque
 	c.sendSuccessResponse(w, http.StatusCreated, "Loan proposal created successfully", response)
 }
 
+func (c *LoanController) GetLoan(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+
+	response, err := c.loanUsecase.GetLoan(r.Context(), loanID)
+	if err != nil {
+		log.Error().Err(err).Str("loan_id", loanID).Msg("Failed to get loan")
+
+		switch err.Error() {
+		case "invalid loan ID":
+			c.sendErrorResponse(w, http.StatusBadRequest, "Invalid loan ID", nil)
+		case "loan not found":
+			c.sendErrorResponse(w, http.StatusNotFound, "Loan not found", nil)
+		default:
+			c.sendErrorResponse(w, http.StatusInternalServerError, "Internal server error", map[string]string{
+				"error_code": "INTERNAL_ERROR",
+			})
+		}
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Loan retrieved successfully", response)
+}
+
 func (c *LoanController) ApproveLoan(w http.ResponseWriter, r *http.Request) {
 	loanID := chi.URLParam(r, "id")
 	if loanID == "" {
@@ -121,6 +148,26 @@ func (c *LoanController) ApproveLoan(w http.ResponseWriter, r *http.Request) {
 			c.sendErrorResponse(w, http.StatusConflict, "Loan must be in proposed state", nil)
 		case errMsg == "invalid loan ID" || errMsg == "invalid employee ID":
 			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+		case errMsg == "approver cannot be the field validator":
+			c.sendErrorResponse(w, http.StatusForbidden, "Approver cannot be the field validator of this loan", map[string]string{
+				"error_code": "APPROVER_IS_VALIDATOR",
+			})
+		case errMsg == "approver role not allowed for this tier":
+			c.sendErrorResponse(w, http.StatusForbidden, "Approver role not allowed for this loan amount", map[string]string{
+				"error_code": "APPROVER_ROLE_NOT_ALLOWED",
+			})
+		case errMsg == "employee already approved this loan":
+			c.sendErrorResponse(w, http.StatusConflict, "Employee already approved this loan", map[string]string{
+				"error_code": "DUPLICATE_APPROVAL",
+			})
+		case errMsg == "loan already has required approvals":
+			c.sendErrorResponse(w, http.StatusConflict, "Loan already has the required approvals", nil)
+		case errMsg == "survey flags must be acknowledged":
+			c.sendErrorResponse(w, http.StatusConflict, "Survey photos were flagged, review them and set acknowledge_survey_flags", map[string]string{
+				"error_code": "SURVEY_FLAGS_UNACKNOWLEDGED",
+			})
+		case isAccessError(errMsg):
+			c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
 		default:
 			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to approve loan", nil)
 		}
@@ -131,11 +178,101 @@ func (c *LoanController) ApproveLoan(w http.ResponseWriter, r *http.Request) {
 		Str("loan_id", loanID).
 		Str("employee_id", user.UserID).
 		Str("new_state", response.CurrentState).
-		Msg("Loan approved successfully")
+		Int("received_approvals", response.ReceivedApprovals).
+		Int("required_approvals", response.RequiredApprovals).
+		Msg("Loan approval recorded")
+
+	if response.CurrentState != constants.APPROVED {
+		c.sendSuccessResponse(w, http.StatusAccepted, "Approval recorded, waiting for further approvers", response)
+		return
+	}
 
 	c.sendSuccessResponse(w, http.StatusOK, "Loan approved successfully", response)
 }
 
+func (c *LoanController) ListPendingApprovals(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	queue, err := c.loanUsecase.ListPendingApprovals(r.Context(), user.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("employee_id", user.UserID).Msg("Failed to list pending approvals")
+
+		errMsg := err.Error()
+		switch {
+		case errMsg == "invalid employee ID":
+			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+		case isAccessError(errMsg):
+			c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
+		default:
+			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to list pending approvals", nil)
+		}
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Pending approvals retrieved successfully", queue)
+}
+
+func (c *LoanController) AssignValidator(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+	if loanID == "" {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Loan ID is required", nil)
+		return
+	}
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.AssignValidatorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	response, err := c.loanUsecase.AssignValidator(r.Context(), loanID, user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("loan_id", loanID).Str("employee_id", user.UserID).Msg("Failed to assign validator")
+
+		errMsg := err.Error()
+		switch {
+		case errMsg == "loan not found" || errMsg == "employee not found":
+			c.sendErrorResponse(w, http.StatusNotFound, errMsg, nil)
+		case errMsg == "loan must be in proposed state":
+			c.sendErrorResponse(w, http.StatusConflict, "Loan must be in proposed state", nil)
+		case errMsg == "invalid loan ID" || errMsg == "invalid employee ID" || errMsg == "invalid validator ID":
+			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+		case strings.HasPrefix(errMsg, "assignee lacks permission"):
+			c.sendErrorResponse(w, http.StatusUnprocessableEntity, errMsg, nil)
+		case isAccessError(errMsg):
+			c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
+		default:
+			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to assign validator", nil)
+		}
+		return
+	}
+
+	log.Info().
+		Str("loan_id", loanID).
+		Str("employee_id", user.UserID).
+		Str("validator_id", req.ValidatorEmployeeID).
+		Msg("Field validator assigned")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Field validator assigned successfully", response)
+}
+
 func (c *LoanController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -209,30 +346,34 @@ func (c *LoanController) DisburseLoan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse multipart form
+	r.Body = http.MaxBytesReader(w, r.Body, constants.MAX_UPLOAD_REQUEST_SIZE)
 	err = r.ParseMultipartForm(10 << 20) // 10MB
 	if err != nil {
+		if isRequestTooLarge(err) {
+			c.sendErrorResponse(w, http.StatusRequestEntityTooLarge, "File size exceeds 10MB limit", map[string]string{
+				"error_code": "FILE_TOO_LARGE",
+			})
+			return
+		}
 		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid form data", nil)
 		return
 	}
 
-	// Get signed agreement file
+	// Get signed agreement file, optional when the borrower signed electronically
 	file, header, err := r.FormFile("signed_agreement")
-	if err != nil {
-		c.sendErrorResponse(w, http.StatusBadRequest, "Signed agreement file is required", nil)
+	if err != nil && !errors.Is(err, http.ErrMissingFile) {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid signed agreement file", nil)
 		return
 	}
-	defer file.Close()
-
-	// Validate file type
-	if !isValidSignedAgreementFile(header.Filename) {
-		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid file type, allowed: .pdf, .jpg, .jpeg", nil)
-		return
-	}
-
-	// Validate file size
-	if header.Size > 10*1024*1024 {
-		c.sendErrorResponse(w, http.StatusBadRequest, "File size exceeds 10MB limit", nil)
-		return
+	hasUpload := err == nil
+	if hasUpload {
+		defer file.Close()
+
+		// Validate file type
+		if !isValidSignedAgreementFile(header.Filename) {
+			c.sendErrorResponse(w, http.StatusBadRequest, "Invalid file type, allowed: .pdf, .jpg, .jpeg", nil)
+			return
+		}
 	}
 
 	// Parse form data
@@ -241,16 +382,35 @@ func (c *LoanController) DisburseLoan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save signed agreement file
-	signedAgreementURL, err := saveSignedAgreement(file, header, loanID)
+	loanUUID, err := uuid.Parse(loanID)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to save signed agreement")
-		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to save signed agreement", nil)
+		c.sendErrorResponse(w, http.StatusBadRequest, "invalid loan ID", nil)
 		return
 	}
 
+	var signedAgreement *models2.Document
+	if hasUpload {
+		signedAgreement, err = c.saveSignedAgreement(r.Context(), file, header, loanUUID, user.UserID)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to save signed agreement")
+			if status, code, ok := uploadError(err); ok {
+				c.sendErrorResponse(w, status, err.Error(), map[string]string{
+					"error_code": code,
+				})
+				return
+			}
+			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to save signed agreement", nil)
+			return
+		}
+	}
+
 	// Disburse loan
-	response, err := c.loanUsecase.DisburseLoan(r.Context(), loanID, user.UserID, req, signedAgreementURL)
+	response, err := c.loanUsecase.DisburseLoan(r.Context(), loanID, user.UserID, req, signedAgreement)
 	if err != nil {
+		if signedAgreement != nil {
+			c.intake.Discard(r.Context(), signedAgreement)
+		}
+
 		log.Error().Err(err).Str("loan_id", loanID).Str("officer_id", user.UserID).Msg("Failed to disburse loan")
 
 		// Handle specific errors
@@ -260,8 +420,38 @@ func (c *LoanController) DisburseLoan(w http.ResponseWriter, r *http.Request) {
 			c.sendErrorResponse(w, http.StatusNotFound, "Loan not found", nil)
 		case errMsg == "loan must be in invested state":
 			c.sendErrorResponse(w, http.StatusConflict, "Loan must be in invested state", nil)
+		case errMsg == "loan disbursement already in progress":
+			c.sendErrorResponse(w, http.StatusConflict, "Loan disbursement already in progress", map[string]string{
+				"error_code": "DISBURSEMENT_IN_PROGRESS",
+			})
+		case errMsg == "borrower has no bank account":
+			c.sendErrorResponse(w, http.StatusUnprocessableEntity, "Borrower has no bank account to disburse to", map[string]string{
+				"error_code": "BANK_ACCOUNT_REQUIRED",
+			})
+		case errMsg == "bank account name mismatch":
+			c.sendErrorResponse(w, http.StatusConflict, "Bank account holder does not match the borrower, an override is required", map[string]string{
+				"error_code": "BANK_ACCOUNT_MISMATCH",
+			})
+		case errMsg == "bank account not found":
+			c.sendErrorResponse(w, http.StatusUnprocessableEntity, "The bank does not know the borrower's account", map[string]string{
+				"error_code": "BANK_ACCOUNT_NOT_FOUND",
+			})
+		case strings.HasPrefix(errMsg, "bank account inquiry failed"):
+			c.sendErrorResponse(w, http.StatusServiceUnavailable, "Bank account inquiry is unavailable, try again later", map[string]string{
+				"error_code": "BANK_INQUIRY_UNAVAILABLE",
+			})
+		case errMsg == "signed agreement required":
+			c.sendErrorResponse(w, http.StatusBadRequest, "Signed agreement file is required unless the borrower signed electronically", map[string]string{
+				"error_code": "SIGNED_AGREEMENT_REQUIRED",
+			})
+		case strings.HasPrefix(errMsg, "investor agreements not accepted"):
+			c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+				"error_code": "INVESTOR_AGREEMENTS_NOT_ACCEPTED",
+			})
 		case errMsg == "invalid loan ID" || errMsg == "invalid officer ID":
 			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+		case isAccessError(errMsg):
+			c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
 		default:
 			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to disburse loan", nil)
 		}
@@ -272,9 +462,60 @@ func (c *LoanController) DisburseLoan(w http.ResponseWriter, r *http.Request) {
 		Str("loan_id", loanID).
 		Str("officer_id", user.UserID).
 		Str("new_state", response.CurrentState).
-		Msg("Loan disbursed successfully")
+		Str("payout_status", response.Payout.Status).
+		Msg("Loan disbursement started")
+
+	switch response.Payout.Status {
+	case constants.PAYOUT_FAILED:
+		// The signed agreement is kept, only the transfer has to be retried
+		c.sendErrorResponse(w, http.StatusBadGateway, "Payout failed: "+response.Payout.FailureReason, map[string]string{
+			"error_code": "PAYOUT_FAILED",
+		})
+	case constants.PAYOUT_SUCCESS:
+		c.sendSuccessResponse(w, http.StatusOK, "Loan disbursed successfully", response)
+	default:
+		c.sendSuccessResponse(w, http.StatusAccepted, "Loan disbursement started, waiting for the payout to settle", response)
+	}
+}
+
+func (c *LoanController) ListPayouts(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+	if loanID == "" {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Loan ID is required", nil)
+		return
+	}
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	payouts, err := c.loanUsecase.ListPayouts(r.Context(), loanID, user.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("loan_id", loanID).Msg("Failed to list payouts")
 
-	c.sendSuccessResponse(w, http.StatusOK, "Loan disbursed successfully", response)
+		errMsg := err.Error()
+		switch {
+		case errMsg == "loan not found":
+			c.sendErrorResponse(w, http.StatusNotFound, "Loan not found", nil)
+		case errMsg == "invalid loan ID" || errMsg == "invalid employee ID":
+			c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+		case isAccessError(errMsg):
+			c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
+		default:
+			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to list payouts", nil)
+		}
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Payouts retrieved successfully", payouts)
+}
+
+func isAccessError(errMsg string) bool {
+	return strings.HasPrefix(errMsg, "permission denied") ||
+		errMsg == "loan outside employee branch" ||
+		errMsg == "loan not assigned to validator"
 }
 
 func isValidSignedAgreementFile(filename string) bool {
@@ -289,30 +530,24 @@ func isValidSignedAgreementFile(filename string) bool {
 	return false
 }
 
-func saveSignedAgreement(file multipart.File, header *multipart.FileHeader, loanID string) (string, error) {
-	// Create directory
-	agreementDir := "uploads/agreements"
-	if err := os.MkdirAll(agreementDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+// saveSignedAgreement stores the upload through the intake, the document is
+// recorded when the disbursement commits.
+func (c *LoanController) saveSignedAgreement(ctx context.Context, file multipart.File, header *multipart.FileHeader, loanID uuid.UUID, officerID string) (*models2.Document, error) {
+	document := &models2.Document{
+		ID:             uuid.New(),
+		LoanID:         loanID,
+		DocumentType:   constants.DOCUMENT_SIGNED_AGREEMENT,
+		FileName:       filepath.Base(header.Filename),
+		UploadedByType: constants.USER_EMPLOYEE,
+		CreatedAt:      time.Now(),
 	}
-
-	// Generate filename
-	fileExt := filepath.Ext(header.Filename)
-	fileName := fmt.Sprintf("signed_agreement_%s%s", loanID, fileExt)
-	filePath := filepath.Join(agreementDir, fileName)
-
-	// Save file
-	dst, err := os.Create(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+	if officerUUID, err := uuid.Parse(officerID); err == nil {
+		document.UploadedByID = &officerUUID
 	}
-	defer dst.Close()
 
-	_, err = io.Copy(dst, file)
-	if err != nil {
-		return "", fmt.Errorf("failed to save file: %w", err)
+	if err := c.intake.Accept(ctx, document, file); err != nil {
+		return nil, err
 	}
 
-	// Return URL
-	return fmt.Sprintf("/uploads/agreements/%s", fileName), nil
+	return document, nil
 }
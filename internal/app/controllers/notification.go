@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+type NotificationController struct {
+	notificationUsecase usecase.NotificationUsecase
+	validator           *validator.Validate
+}
+
+func NewNotificationController(notificationUsecase usecase.NotificationUsecase) *NotificationController {
+	return &NotificationController{
+		notificationUsecase: notificationUsecase,
+		validator:           validator.New(),
+	}
+}
+
+// GetPreferences returns the channels the user receives each notification on.
+func (c *NotificationController) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user from context")
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	preferences, err := c.notificationUsecase.GetPreferences(r.Context(), user.UserID, user.UserType)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.UserID).Msg("Failed to get notification preferences")
+		c.handleNotificationError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Notification preferences retrieved successfully", preferences)
+}
+
+func (c *NotificationController) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user from context")
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	preferences, err := c.notificationUsecase.UpdatePreferences(r.Context(), user.UserID, user.UserType, &req)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.UserID).Msg("Failed to update notification preferences")
+		c.handleNotificationError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Notification preferences updated successfully", preferences)
+}
+
+func (c *NotificationController) handleNotificationError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch {
+	case errMsg == "invalid user ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
+			"error_code": "INVALID_ID",
+		})
+	case strings.HasPrefix(errMsg, "duplicate notification type"):
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
+			"error_code": "DUPLICATE_NOTIFICATION_TYPE",
+		})
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to process notification preferences", map[string]string{
+			"error_code": "INTERNAL_ERROR",
+		})
+	}
+}
+
+func (c *NotificationController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *NotificationController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *NotificationController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
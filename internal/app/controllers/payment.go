@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/paygate"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+// maxPaymentNotificationSize bounds the body of a gateway notification
+const maxPaymentNotificationSize = 64 << 10
+
+type PaymentController struct {
+	paymentUsecase usecase.PaymentUsecase
+	validator      *validator.Validate
+}
+
+func NewPaymentController(paymentUsecase usecase.PaymentUsecase) *PaymentController {
+	return &PaymentController{
+		paymentUsecase: paymentUsecase,
+		validator:      validator.New(),
+	}
+}
+
+// GetVirtualAccount returns the account number the borrower repays into.
+func (c *PaymentController) GetVirtualAccount(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	account, err := c.paymentUsecase.GetVirtualAccount(r.Context(), user.UserID, user.UserType)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.UserID).Msg("Failed to get virtual account")
+		c.handlePaymentError(w, err, "Failed to get virtual account")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Virtual account retrieved successfully", account)
+}
+
+// PaymentCallback receives the gateway's payment notifications. It is public,
+// the signature authenticates the gateway. Any 2xx tells the gateway to stop
+// retrying, so only payments that are recorded are acknowledged.
+func (c *PaymentController) PaymentCallback(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPaymentNotificationSize+1))
+	if err != nil || len(body) > maxPaymentNotificationSize {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	response, err := c.paymentUsecase.ReceivePayment(r.Context(), r.Header.Get(paygate.HeaderTimestamp), r.Header.Get(paygate.HeaderSignature), body)
+	if err != nil {
+		log.Error().Err(err).Str("remote_addr", r.RemoteAddr).Msg("Failed to receive payment notification")
+		switch {
+		case errors.Is(err, paygate.ErrInvalidSignature) || errors.Is(err, paygate.ErrStaleTimestamp):
+			c.sendErrorResponse(w, http.StatusUnauthorized, err.Error(), map[string]string{
+				"error_code": "INVALID_SIGNATURE",
+			})
+		case err.Error() == "payment notifications are disabled":
+			c.sendErrorResponse(w, http.StatusServiceUnavailable, err.Error(), nil)
+		case errors.Is(err, paygate.ErrInvalidPayload):
+			c.sendErrorResponse(w, http.StatusBadRequest, err.Error(), map[string]string{
+				"error_code": "INVALID_PAYLOAD",
+			})
+		default:
+			c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to record payment", nil)
+		}
+		return
+	}
+
+	log.Info().
+		Str("payment_id", response.PaymentID.String()).
+		Str("status", response.Status).
+		Bool("duplicate", response.Duplicate).
+		Msg("Payment notification received")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Payment received", response)
+}
+
+// ListPayments lists incoming payments, those in suspense unless ?status= asks
+// for another status.
+func (c *PaymentController) ListPayments(w http.ResponseWriter, r *http.Request) {
+	payments, err := c.paymentUsecase.ListPayments(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list payments")
+		c.handlePaymentError(w, err, "Failed to list payments")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Payments retrieved successfully", payments)
+}
+
+func (c *PaymentController) ResolvePayment(w http.ResponseWriter, r *http.Request) {
+	paymentID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.ResolvePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	payment, err := c.paymentUsecase.ResolvePayment(r.Context(), paymentID, user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("payment_id", paymentID).Msg("Failed to resolve payment")
+		c.handlePaymentError(w, err, "Failed to resolve payment")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Payment resolved", payment)
+}
+
+func (c *PaymentController) handlePaymentError(w http.ResponseWriter, err error, fallback string) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "payment not found" || errMsg == "loan not found" || errMsg == "borrower not found":
+		c.sendErrorResponse(w, http.StatusNotFound, errMsg, map[string]string{
+			"error_code": "NOT_FOUND",
+		})
+	case errMsg == "payment is not in suspense":
+		c.sendErrorResponse(w, http.StatusConflict, "Only a payment in suspense can be resolved", map[string]string{
+			"error_code": "PAYMENT_NOT_IN_SUSPENSE",
+		})
+	case errMsg == "loan is not disbursed":
+		c.sendErrorResponse(w, http.StatusUnprocessableEntity, "Payments can only be assigned to a disbursed loan", map[string]string{
+			"error_code": "LOAN_NOT_DISBURSED",
+		})
+	case errMsg == "only borrowers have a virtual account":
+		c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
+	case errMsg == "invalid payment status" || errMsg == "resolution note is required" ||
+		errMsg == "invalid payment ID" || errMsg == "invalid loan ID" || errMsg == "invalid employee ID" || errMsg == "invalid user ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	case isAccessError(errMsg):
+		c.sendErrorResponse(w, http.StatusForbidden, errMsg, nil)
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, fallback, nil)
+	}
+}
+
+func (c *PaymentController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *PaymentController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *PaymentController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
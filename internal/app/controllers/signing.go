@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+type SigningController struct {
+	signingUsecase usecase.SigningUsecase
+	validator      *validator.Validate
+}
+
+func NewSigningController(signingUsecase usecase.SigningUsecase) *SigningController {
+	return &SigningController{
+		signingUsecase: signingUsecase,
+		validator:      validator.New(),
+	}
+}
+
+// RequestSignature sends the borrower an OTP for signing the loan agreement
+// they have reviewed in the app.
+func (c *SigningController) RequestSignature(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+	if loanID == "" {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Loan ID is required", map[string]string{
+			"error_code": "MISSING_LOAN_ID",
+		})
+		return
+	}
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user from context")
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	client := usecase.ClientInfo{
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	response, err := c.signingUsecase.RequestSignature(r.Context(), loanID, user.UserID, client)
+	if err != nil {
+		log.Error().Err(err).
+			Str("loan_id", loanID).
+			Str("borrower_id", user.UserID).
+			Msg("Failed to request agreement signature")
+
+		c.handleSigningError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "OTP sent successfully", response)
+}
+
+// ConfirmSignature signs the agreement with the OTP the borrower received.
+func (c *SigningController) ConfirmSignature(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+	if loanID == "" {
+		c.sendErrorResponse(w, http.StatusBadRequest, "Loan ID is required", map[string]string{
+			"error_code": "MISSING_LOAN_ID",
+		})
+		return
+	}
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get user from context")
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.ConfirmSignatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	client := usecase.ClientInfo{
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	response, err := c.signingUsecase.ConfirmSignature(r.Context(), loanID, user.UserID, &req, client)
+	if err != nil {
+		log.Error().Err(err).
+			Str("loan_id", loanID).
+			Str("borrower_id", user.UserID).
+			Msg("Failed to confirm agreement signature")
+
+		c.handleSigningError(w, err)
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Agreement signed successfully", response)
+}
+
+func (c *SigningController) handleSigningError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch {
+	case errMsg == "loan not found" || errMsg == "signing session not found":
+		c.sendErrorResponse(w, http.StatusNotFound, errMsg, map[string]string{
+			"error_code": "NOT_FOUND",
+		})
+	case errMsg == "loan agreement not found":
+		c.sendErrorResponse(w, http.StatusNotFound, errMsg, map[string]string{
+			"error_code": "AGREEMENT_NOT_FOUND",
+		})
+	case errMsg == "borrower not found" || errMsg == "invalid borrower ID":
+		c.sendErrorResponse(w, http.StatusForbidden, errMsg, map[string]string{
+			"error_code": "BORROWER_NOT_FOUND",
+		})
+	case errMsg == "invalid loan ID" || errMsg == "invalid session ID":
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, map[string]string{
+			"error_code": "INVALID_ID",
+		})
+	case errMsg == "loan agreement cannot be signed in current state":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "INVALID_LOAN_STATE",
+		})
+	case errMsg == "agreement already signed":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "AGREEMENT_ALREADY_SIGNED",
+		})
+	case errMsg == "agreement has changed":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "AGREEMENT_CHANGED",
+		})
+	case errMsg == "otp recently sent, try again later":
+		c.sendErrorResponse(w, http.StatusTooManyRequests, errMsg, map[string]string{
+			"error_code": "OTP_RECENTLY_SENT",
+		})
+	case errMsg == "invalid otp":
+		c.sendErrorResponse(w, http.StatusUnauthorized, errMsg, map[string]string{
+			"error_code": "INVALID_OTP",
+		})
+	case errMsg == "otp expired":
+		c.sendErrorResponse(w, http.StatusGone, errMsg, map[string]string{
+			"error_code": "OTP_EXPIRED",
+		})
+	case errMsg == "too many failed attempts" || errMsg == "signing session is no longer active":
+		c.sendErrorResponse(w, http.StatusGone, errMsg, map[string]string{
+			"error_code": "SIGNING_SESSION_CLOSED",
+		})
+	case strings.HasPrefix(errMsg, "failed to send otp"):
+		c.sendErrorResponse(w, http.StatusBadGateway, "Failed to send OTP", map[string]string{
+			"error_code": "OTP_DELIVERY_FAILED",
+		})
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, "Failed to sign agreement", map[string]string{
+			"error_code": "INTERNAL_ERROR",
+		})
+	}
+}
+
+func (c *SigningController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *SigningController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *SigningController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/commons"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog/log"
+)
+
+type WebhookController struct {
+	webhookUsecase usecase.WebhookUsecase
+	validator      *validator.Validate
+}
+
+func NewWebhookController(webhookUsecase usecase.WebhookUsecase) *WebhookController {
+	return &WebhookController{
+		webhookUsecase: webhookUsecase,
+		validator:      validator.New(),
+	}
+}
+
+func (c *WebhookController) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	response, err := c.webhookUsecase.CreateSubscription(r.Context(), user.UserID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("actor_id", user.UserID).Msg("Failed to create webhook subscription")
+		c.handleWebhookError(w, err, "Failed to create webhook subscription")
+		return
+	}
+
+	log.Info().Str("actor_id", user.UserID).Str("subscription_id", response.Subscription.ID.String()).Msg("Webhook subscription created")
+
+	c.sendSuccessResponse(w, http.StatusCreated, "Webhook subscription created successfully", response)
+}
+
+func (c *WebhookController) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := c.webhookUsecase.ListSubscriptions(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhook subscriptions")
+		c.handleWebhookError(w, err, "Failed to list webhook subscriptions")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Webhook subscriptions retrieved successfully", subscriptions)
+}
+
+func (c *WebhookController) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	subscription, err := c.webhookUsecase.GetSubscription(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get webhook subscription")
+		c.handleWebhookError(w, err, "Failed to get webhook subscription")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Webhook subscription retrieved successfully", subscription)
+}
+
+func (c *WebhookController) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	var req models2.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		c.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("Validation failed")
+		c.sendValidationErrorResponse(w, err)
+		return
+	}
+
+	subscription, err := c.webhookUsecase.UpdateSubscription(r.Context(), subscriptionID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("subscription_id", subscriptionID).Msg("Failed to update webhook subscription")
+		c.handleWebhookError(w, err, "Failed to update webhook subscription")
+		return
+	}
+
+	log.Info().Str("actor_id", user.UserID).Str("subscription_id", subscriptionID).Msg("Webhook subscription updated")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Webhook subscription updated successfully", subscription)
+}
+
+func (c *WebhookController) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionID := chi.URLParam(r, "id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	if err := c.webhookUsecase.DeleteSubscription(r.Context(), subscriptionID); err != nil {
+		log.Error().Err(err).Str("subscription_id", subscriptionID).Msg("Failed to delete webhook subscription")
+		c.handleWebhookError(w, err, "Failed to delete webhook subscription")
+		return
+	}
+
+	log.Info().Str("actor_id", user.UserID).Str("subscription_id", subscriptionID).Msg("Webhook subscription deleted")
+
+	c.sendSuccessResponse(w, http.StatusOK, "Webhook subscription deleted successfully", nil)
+}
+
+func (c *WebhookController) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := c.webhookUsecase.ListDeliveries(r.Context(), chi.URLParam(r, "id"), strings.ToUpper(r.URL.Query().Get("status")))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhook deliveries")
+		c.handleWebhookError(w, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Webhook deliveries retrieved successfully", deliveries)
+}
+
+func (c *WebhookController) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryLog, err := c.webhookUsecase.GetDelivery(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "delivery_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get webhook delivery")
+		c.handleWebhookError(w, err, "Failed to get webhook delivery")
+		return
+	}
+
+	c.sendSuccessResponse(w, http.StatusOK, "Webhook delivery retrieved successfully", deliveryLog)
+}
+
+func (c *WebhookController) Redeliver(w http.ResponseWriter, r *http.Request) {
+	deliveryID := chi.URLParam(r, "delivery_id")
+
+	user, err := middleware.GetUserFromCtx(r.Context())
+	if err != nil {
+		c.sendErrorResponse(w, http.StatusUnauthorized, "User context not found", nil)
+		return
+	}
+
+	delivery, err := c.webhookUsecase.Redeliver(r.Context(), chi.URLParam(r, "id"), deliveryID)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryID).Msg("Failed to redeliver webhook")
+		c.handleWebhookError(w, err, "Failed to redeliver webhook")
+		return
+	}
+
+	log.Info().Str("actor_id", user.UserID).Str("delivery_id", deliveryID).Msg("Webhook redelivery queued")
+
+	c.sendSuccessResponse(w, http.StatusAccepted, "Webhook redelivery queued", delivery)
+}
+
+func (c *WebhookController) handleWebhookError(w http.ResponseWriter, err error, fallback string) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "webhook subscription not found" || errMsg == "webhook delivery not found":
+		c.sendErrorResponse(w, http.StatusNotFound, errMsg, map[string]string{
+			"error_code": "NOT_FOUND",
+		})
+	case errMsg == "webhook subscription is inactive":
+		c.sendErrorResponse(w, http.StatusConflict, errMsg, map[string]string{
+			"error_code": "WEBHOOK_INACTIVE",
+		})
+	case strings.HasPrefix(errMsg, "invalid webhook URL"):
+		c.sendErrorResponse(w, http.StatusUnprocessableEntity, errMsg, map[string]string{
+			"error_code": "INVALID_WEBHOOK_URL",
+		})
+	case strings.HasPrefix(errMsg, "invalid delivery status"):
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	case strings.HasPrefix(errMsg, "invalid") && strings.HasSuffix(errMsg, "ID"):
+		c.sendErrorResponse(w, http.StatusBadRequest, errMsg, nil)
+	default:
+		c.sendErrorResponse(w, http.StatusInternalServerError, fallback, nil)
+	}
+}
+
+func (c *WebhookController) sendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": true,
+			"message": message,
+			"data":    data,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *WebhookController) sendErrorResponse(w http.ResponseWriter, statusCode int, message string, extra map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorData := map[string]interface{}{
+		"success": false,
+		"message": message,
+	}
+
+	for k, v := range extra {
+		errorData[k] = v
+	}
+
+	response := models2.Response[interface{}]{
+		Data: errorData,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (c *WebhookController) sendValidationErrorResponse(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	var errors []map[string]string
+	for _, err := range err.(validator.ValidationErrors) {
+		fieldError := map[string]string{
+			"field":   err.Field(),
+			"message": commons.GetValidationMessage(err),
+		}
+		errors = append(errors, fieldError)
+	}
+
+	response := models2.Response[interface{}]{
+		Data: map[string]interface{}{
+			"success": false,
+			"message": "Validation error",
+			"errors":  errors,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -18,41 +18,59 @@ type contextKey string
 
 const UserContextKey contextKey = "user"
 
-func JWTAuthMiddleware() func(http.Handler) http.Handler {
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves a service API key to the principal's claims.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*models2.JWTClaims, error)
+}
+
+// JWTAuthMiddleware authenticates the Bearer token, or the X-API-Key header for
+// service principals when apiKeys is set. Either way the principal is available
+// through GetUserFromCtx.
+func JWTAuthMiddleware(apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rawKey := r.Header.Get(APIKeyHeader); rawKey != "" && apiKeys != nil {
+				claims, err := apiKeys.AuthenticateAPIKey(r.Context(), rawKey)
+				if err != nil {
+					log.Error().Err(err).Msg("Failed to authenticate API key")
+					sendUnauthorizedResponse(w, "Invalid API key")
+					return
+				}
 
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				sendUnauthorizedResponse(w, "Missing authorization header")
+				ctx := context.WithValue(r.Context(), UserContextKey, claims)
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
-			tokenParts := strings.Split(authHeader, " ")
-			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-				sendUnauthorizedResponse(w, "Invalid authorization header format")
+			claims, ok := authenticateBearer(w, r)
+			if !ok {
 				return
 			}
 
-			tokenString := tokenParts[1]
-
-			claims := &models2.JWTClaims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			// MFA challenge tokens are only good for completing the login
+			if claims.TokenUse == constants.TOKEN_USE_MFA_CHALLENGE {
+				sendUnauthorizedResponse(w, "MFA verification required")
+				return
+			}
 
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return []byte(viper.GetString("jwt.secret")), nil
-			})
+			ctx := context.WithValue(r.Context(), UserContextKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-			if err != nil {
-				log.Error().Err(err).Msg("Failed to parse JWT token")
-				sendUnauthorizedResponse(w, "Invalid token")
+func MFAChallengeMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := authenticateBearer(w, r)
+			if !ok {
 				return
 			}
 
-			if !token.Valid {
-				sendUnauthorizedResponse(w, "Invalid token")
+			if claims.TokenUse != constants.TOKEN_USE_MFA_CHALLENGE {
+				sendUnauthorizedResponse(w, "MFA challenge token required")
 				return
 			}
 
@@ -62,6 +80,44 @@ func JWTAuthMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
+func authenticateBearer(w http.ResponseWriter, r *http.Request) (*models2.JWTClaims, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		sendUnauthorizedResponse(w, "Missing authorization header")
+		return nil, false
+	}
+
+	tokenParts := strings.Split(authHeader, " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		sendUnauthorizedResponse(w, "Invalid authorization header format")
+		return nil, false
+	}
+
+	tokenString := tokenParts[1]
+
+	claims := &models2.JWTClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(viper.GetString("jwt.secret")), nil
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse JWT token")
+		sendUnauthorizedResponse(w, "Invalid token")
+		return nil, false
+	}
+
+	if !token.Valid {
+		sendUnauthorizedResponse(w, "Invalid token")
+		return nil, false
+	}
+
+	return claims, true
+}
+
 func RequireRole(roles ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed from an earlier request
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// IdempotencyKeys stores the responses of requests by their Idempotency-Key.
+type IdempotencyKeys interface {
+	Begin(ctx context.Context, principal, key, fingerprint string) (*models2.IdempotencyKey, error)
+	Complete(ctx context.Context, principal, key, fingerprint string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, principal, key string) error
+}
+
+// Idempotency makes mutating requests sent with an Idempotency-Key header safe
+// to retry. The first request with a key runs and its response is stored for
+// the principal; retries with the same key and request get that response
+// back. Server errors are not stored, a retry runs the request again. It must
+// run after authentication. Responses are stored as they are, routes that
+// return secrets must not use it.
+func Idempotency(keys IdempotencyKeys) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" || !isMutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, ok := r.Context().Value(UserContextKey).(*models2.JWTClaims)
+			if !ok {
+				sendForbiddenResponse(w, "User context not found")
+				return
+			}
+			principal := user.UserType + ":" + user.UserID
+
+			// The body is held in memory for the fingerprint, uploads are the
+			// largest requests accepted
+			r.Body = http.MaxBytesReader(w, r.Body, constants.MAX_UPLOAD_REQUEST_SIZE)
+			body, err := io.ReadAll(r.Body)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				sendIdempotencyErrorResponse(w, http.StatusRequestEntityTooLarge, "Request body is too large", "REQUEST_TOO_LARGE")
+				return
+			}
+			if err != nil {
+				sendIdempotencyErrorResponse(w, http.StatusBadRequest, "Invalid request body", "INVALID_REQUEST_BODY")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := requestFingerprint(r, body)
+
+			stored, err := keys.Begin(r.Context(), principal, key, fingerprint)
+			if err != nil {
+				log.Warn().Err(err).Str("principal", principal).Str("idempotency_key", key).Msg("Idempotency key rejected")
+				switch err.Error() {
+				case "invalid idempotency key":
+					sendIdempotencyErrorResponse(w, http.StatusBadRequest, "Idempotency-Key must be 1 to 255 characters", "INVALID_IDEMPOTENCY_KEY")
+				case "idempotency key reused with a different request":
+					sendIdempotencyErrorResponse(w, http.StatusConflict, "Idempotency-Key was already used for a different request", "IDEMPOTENCY_KEY_MISMATCH")
+				case "request with this idempotency key is in progress":
+					sendIdempotencyErrorResponse(w, http.StatusConflict, "A request with this Idempotency-Key is still in progress", "IDEMPOTENCY_KEY_IN_USE")
+				default:
+					sendIdempotencyErrorResponse(w, http.StatusInternalServerError, "Failed to check Idempotency-Key", "IDEMPOTENCY_UNAVAILABLE")
+				}
+				return
+			}
+
+			if stored != nil {
+				if stored.ResponseContentType != "" {
+					w.Header().Set("Content-Type", stored.ResponseContentType)
+				}
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(stored.ResponseStatus)
+				w.Write(stored.ResponseBody)
+				return
+			}
+
+			// The outcome is saved even when the client went away
+			ctx := context.WithoutCancel(r.Context())
+			recorder := &responseRecorder{ResponseWriter: w}
+			completed := false
+			defer func() {
+				if !completed {
+					if err := keys.Release(ctx, principal, key); err != nil {
+						log.Error().Err(err).Str("idempotency_key", key).Msg("Failed to release idempotency key")
+					}
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status() >= http.StatusInternalServerError {
+				return
+			}
+
+			if err := keys.Complete(ctx, principal, key, fingerprint, recorder.status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+				log.Error().Err(err).Str("idempotency_key", key).Msg("Failed to store idempotent response")
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint identifies a request by its method, path and body, so a
+// key reused on another endpoint is a mismatch too.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response through and keeps a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if rec.statusCode == 0 {
+		rec.statusCode = statusCode
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.statusCode == 0 {
+		rec.statusCode = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *responseRecorder) status() int {
+	if rec.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rec.statusCode
+}
+
+func sendIdempotencyErrorResponse(w http.ResponseWriter, statusCode int, message, errorCode string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := map[string]interface{}{
+		"success":    false,
+		"message":    message,
+		"error_code": errorCode,
+	}
+
+	json.NewEncoder(w).Encode(models2.Response[interface{}]{Data: response})
+}
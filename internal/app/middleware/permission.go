@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/authz"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RequirePermission allows the request when the principal's role, or the
+// API key scopes for service principals, hold any of the permissions.
+// Employees are checked against their current role and status.
+func RequirePermission(guard authz.Guard, permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(UserContextKey).(*models2.JWTClaims)
+			if !ok {
+				sendForbiddenResponse(w, "User context not found")
+				return
+			}
+
+			if err := guard.Authorize(r.Context(), user, permissions...); err != nil {
+				if !strings.HasPrefix(err.Error(), "permission denied") {
+					log.Warn().Err(err).Str("user_id", user.UserID).Msg("Failed to check permission")
+				}
+				sendForbiddenResponse(w, fmt.Sprintf("Access denied: required permission %v", permissions))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// Guard is an autogenerated mock type for the Guard type
+type Guard struct {
+	mock.Mock
+}
+
+// Authorize provides a mock function with given fields: ctx, claims, permissions
+func (_m *Guard) Authorize(ctx context.Context, claims *models.JWTClaims, permissions ...string) error {
+	_va := make([]interface{}, len(permissions))
+	for _i := range permissions {
+		_va[_i] = permissions[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, claims)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authorize")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.JWTClaims, ...string) error); ok {
+		r0 = rf(ctx, claims, permissions...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckAssignee provides a mock function with given fields: ctx, employeeID, loanID, permission
+func (_m *Guard) CheckAssignee(ctx context.Context, employeeID uuid.UUID, loanID uuid.UUID, permission string) error {
+	ret := _m.Called(ctx, employeeID, loanID, permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckAssignee")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, employeeID, loanID, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckLoanAccess provides a mock function with given fields: ctx, employeeID, loanID, permission
+func (_m *Guard) CheckLoanAccess(ctx context.Context, employeeID uuid.UUID, loanID uuid.UUID, permission string) error {
+	ret := _m.Called(ctx, employeeID, loanID, permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckLoanAccess")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, employeeID, loanID, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckPermission provides a mock function with given fields: ctx, employeeID, permission
+func (_m *Guard) CheckPermission(ctx context.Context, employeeID uuid.UUID, permission string) (*models.EmployeeScope, error) {
+	ret := _m.Called(ctx, employeeID, permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckPermission")
+	}
+
+	var r0 *models.EmployeeScope
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (*models.EmployeeScope, error)); ok {
+		return rf(ctx, employeeID, permission)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) *models.EmployeeScope); ok {
+		r0 = rf(ctx, employeeID, permission)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EmployeeScope)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, employeeID, permission)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewGuard creates a new instance of Guard. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGuard(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Guard {
+	mock := &Guard{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
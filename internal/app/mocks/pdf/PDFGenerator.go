@@ -3,7 +3,9 @@
 package mocks
 
 import (
-	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -12,27 +14,34 @@ type PDFGenerator struct {
 	mock.Mock
 }
 
-// GenerateInvestmentAgreement provides a mock function with given fields: investment, loan, investorName
-func (_m *PDFGenerator) GenerateInvestmentAgreement(investment *models2.Investment, loan *models2.LoanInvestmentInfo, investorName string) (string, error) {
-	ret := _m.Called(investment, loan, investorName)
+// Discard provides a mock function with given fields: ctx, document
+func (_m *PDFGenerator) Discard(ctx context.Context, document *models.Document) {
+	_m.Called(ctx, document)
+}
+
+// GenerateInvestmentAgreement provides a mock function with given fields: investment, loan, investorName, agreementTemplate
+func (_m *PDFGenerator) GenerateInvestmentAgreement(investment *models.Investment, loan *models.LoanInvestmentInfo, investorName string, agreementTemplate *models.AgreementTemplate) (*models.Document, error) {
+	ret := _m.Called(investment, loan, investorName, agreementTemplate)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateInvestmentAgreement")
 	}
 
-	var r0 string
+	var r0 *models.Document
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models2.Investment, *models2.LoanInvestmentInfo, string) (string, error)); ok {
-		return rf(investment, loan, investorName)
+	if rf, ok := ret.Get(0).(func(*models.Investment, *models.LoanInvestmentInfo, string, *models.AgreementTemplate) (*models.Document, error)); ok {
+		return rf(investment, loan, investorName, agreementTemplate)
 	}
-	if rf, ok := ret.Get(0).(func(*models2.Investment, *models2.LoanInvestmentInfo, string) string); ok {
-		r0 = rf(investment, loan, investorName)
+	if rf, ok := ret.Get(0).(func(*models.Investment, *models.LoanInvestmentInfo, string, *models.AgreementTemplate) *models.Document); ok {
+		r0 = rf(investment, loan, investorName, agreementTemplate)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models2.Investment, *models2.LoanInvestmentInfo, string) error); ok {
-		r1 = rf(investment, loan, investorName)
+	if rf, ok := ret.Get(1).(func(*models.Investment, *models.LoanInvestmentInfo, string, *models.AgreementTemplate) error); ok {
+		r1 = rf(investment, loan, investorName, agreementTemplate)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -40,27 +49,59 @@ func (_m *PDFGenerator) GenerateInvestmentAgreement(investment *models2.Investme
 	return r0, r1
 }
 
-// GenerateLoanAgreement provides a mock function with given fields: loan
-func (_m *PDFGenerator) GenerateLoanAgreement(loan *models2.LoanForApproval) (string, error) {
-	ret := _m.Called(loan)
+// GenerateLoanAgreement provides a mock function with given fields: loan, agreementTemplate
+func (_m *PDFGenerator) GenerateLoanAgreement(loan *models.LoanForApproval, agreementTemplate *models.AgreementTemplate) (*models.Document, error) {
+	ret := _m.Called(loan, agreementTemplate)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateLoanAgreement")
 	}
 
-	var r0 string
+	var r0 *models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*models.LoanForApproval, *models.AgreementTemplate) (*models.Document, error)); ok {
+		return rf(loan, agreementTemplate)
+	}
+	if rf, ok := ret.Get(0).(func(*models.LoanForApproval, *models.AgreementTemplate) *models.Document); ok {
+		r0 = rf(loan, agreementTemplate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*models.LoanForApproval, *models.AgreementTemplate) error); ok {
+		r1 = rf(loan, agreementTemplate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GenerateSignedLoanAgreement provides a mock function with given fields: loan, agreementTemplate, audit
+func (_m *PDFGenerator) GenerateSignedLoanAgreement(loan *models.LoanForApproval, agreementTemplate *models.AgreementTemplate, audit *models.SignatureAudit) (*models.Document, error) {
+	ret := _m.Called(loan, agreementTemplate, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateSignedLoanAgreement")
+	}
+
+	var r0 *models.Document
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*models2.LoanForApproval) (string, error)); ok {
-		return rf(loan)
+	if rf, ok := ret.Get(0).(func(*models.LoanForApproval, *models.AgreementTemplate, *models.SignatureAudit) (*models.Document, error)); ok {
+		return rf(loan, agreementTemplate, audit)
 	}
-	if rf, ok := ret.Get(0).(func(*models2.LoanForApproval) string); ok {
-		r0 = rf(loan)
+	if rf, ok := ret.Get(0).(func(*models.LoanForApproval, *models.AgreementTemplate, *models.SignatureAudit) *models.Document); ok {
+		r0 = rf(loan, agreementTemplate, audit)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*models2.LoanForApproval) error); ok {
-		r1 = rf(loan)
+	if rf, ok := ret.Get(1).(func(*models.LoanForApproval, *models.AgreementTemplate, *models.SignatureAudit) error); ok {
+		r1 = rf(loan, agreementTemplate, audit)
 	} else {
 		r1 = ret.Error(1)
 	}
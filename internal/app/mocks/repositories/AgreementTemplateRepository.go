@@ -0,0 +1,167 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// AgreementTemplateRepository is an autogenerated mock type for the AgreementTemplateRepository type
+type AgreementTemplateRepository struct {
+	mock.Mock
+}
+
+// GetLatestTemplate provides a mock function with given fields: ctx, templateType
+func (_m *AgreementTemplateRepository) GetLatestTemplate(ctx context.Context, templateType string) (*models.AgreementTemplate, error) {
+	ret := _m.Called(ctx, templateType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestTemplate")
+	}
+
+	var r0 *models.AgreementTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.AgreementTemplate, error)); ok {
+		return rf(ctx, templateType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.AgreementTemplate); ok {
+		r0 = rf(ctx, templateType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgreementTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, templateType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoanTemplateVersion provides a mock function with given fields: ctx, loanID, documentType
+func (_m *AgreementTemplateRepository) GetLoanTemplateVersion(ctx context.Context, loanID uuid.UUID, documentType string) (int, error) {
+	ret := _m.Called(ctx, loanID, documentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanTemplateVersion")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (int, error)); ok {
+		return rf(ctx, loanID, documentType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) int); ok {
+		r0 = rf(ctx, loanID, documentType)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, loanID, documentType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTemplate provides a mock function with given fields: ctx, templateType, version
+func (_m *AgreementTemplateRepository) GetTemplate(ctx context.Context, templateType string, version int) (*models.AgreementTemplate, error) {
+	ret := _m.Called(ctx, templateType, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTemplate")
+	}
+
+	var r0 *models.AgreementTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*models.AgreementTemplate, error)); ok {
+		return rf(ctx, templateType, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *models.AgreementTemplate); ok {
+		r0 = rf(ctx, templateType, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AgreementTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, templateType, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTemplates provides a mock function with given fields: ctx, templateType
+func (_m *AgreementTemplateRepository) ListTemplates(ctx context.Context, templateType string) ([]models.AgreementTemplate, error) {
+	ret := _m.Called(ctx, templateType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTemplates")
+	}
+
+	var r0 []models.AgreementTemplate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.AgreementTemplate, error)); ok {
+		return rf(ctx, templateType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.AgreementTemplate); ok {
+		r0 = rf(ctx, templateType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AgreementTemplate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, templateType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublishTemplate provides a mock function with given fields: ctx, agreementTemplate
+func (_m *AgreementTemplateRepository) PublishTemplate(ctx context.Context, agreementTemplate *models.AgreementTemplate) error {
+	ret := _m.Called(ctx, agreementTemplate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishTemplate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AgreementTemplate) error); ok {
+		r0 = rf(ctx, agreementTemplate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAgreementTemplateRepository creates a new instance of AgreementTemplateRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAgreementTemplateRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AgreementTemplateRepository {
+	mock := &AgreementTemplateRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,229 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// AuthRepository is an autogenerated mock type for the AuthRepository type
+type AuthRepository struct {
+	mock.Mock
+}
+
+// GetBorrowerByEmail provides a mock function with given fields: ctx, email
+func (_m *AuthRepository) GetBorrowerByEmail(ctx context.Context, email string) (uuid.UUID, *models.BorrowerProfile, string, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBorrowerByEmail")
+	}
+
+	var r0 uuid.UUID
+	var r1 *models.BorrowerProfile
+	var r2 string
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (uuid.UUID, *models.BorrowerProfile, string, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) uuid.UUID); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) *models.BorrowerProfile); ok {
+		r1 = rf(ctx, email)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.BorrowerProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) string); ok {
+		r2 = rf(ctx, email)
+	} else {
+		r2 = ret.Get(2).(string)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, string) error); ok {
+		r3 = rf(ctx, email)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// GetEmployeeByEmail provides a mock function with given fields: ctx, email
+func (_m *AuthRepository) GetEmployeeByEmail(ctx context.Context, email string) (uuid.UUID, *models.EmployeeProfile, string, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEmployeeByEmail")
+	}
+
+	var r0 uuid.UUID
+	var r1 *models.EmployeeProfile
+	var r2 string
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (uuid.UUID, *models.EmployeeProfile, string, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) uuid.UUID); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) *models.EmployeeProfile); ok {
+		r1 = rf(ctx, email)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.EmployeeProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) string); ok {
+		r2 = rf(ctx, email)
+	} else {
+		r2 = ret.Get(2).(string)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, string) error); ok {
+		r3 = rf(ctx, email)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// GetEmployeeByID provides a mock function with given fields: ctx, id
+func (_m *AuthRepository) GetEmployeeByID(ctx context.Context, id uuid.UUID) (*models.EmployeeProfile, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEmployeeByID")
+	}
+
+	var r0 *models.EmployeeProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.EmployeeProfile, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.EmployeeProfile); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EmployeeProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvestorByEmail provides a mock function with given fields: ctx, email
+func (_m *AuthRepository) GetInvestorByEmail(ctx context.Context, email string) (uuid.UUID, *models.InvestorProfile, string, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvestorByEmail")
+	}
+
+	var r0 uuid.UUID
+	var r1 *models.InvestorProfile
+	var r2 string
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (uuid.UUID, *models.InvestorProfile, string, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) uuid.UUID); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) *models.InvestorProfile); ok {
+		r1 = rf(ctx, email)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.InvestorProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) string); ok {
+		r2 = rf(ctx, email)
+	} else {
+		r2 = ret.Get(2).(string)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, string) error); ok {
+		r3 = rf(ctx, email)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// GetInvestorByID provides a mock function with given fields: ctx, id
+func (_m *AuthRepository) GetInvestorByID(ctx context.Context, id uuid.UUID) (*models.InvestorProfile, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvestorByID")
+	}
+
+	var r0 *models.InvestorProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.InvestorProfile, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.InvestorProfile); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InvestorProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAuthRepository creates a new instance of AuthRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuthRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuthRepository {
+	mock := &AuthRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
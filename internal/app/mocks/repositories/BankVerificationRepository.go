@@ -0,0 +1,159 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// BankVerificationRepository is an autogenerated mock type for the BankVerificationRepository type
+type BankVerificationRepository struct {
+	mock.Mock
+}
+
+// CreateVerification provides a mock function with given fields: ctx, verification
+func (_m *BankVerificationRepository) CreateVerification(ctx context.Context, verification *models.BankAccountVerification) error {
+	ret := _m.Called(ctx, verification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.BankAccountVerification) error); ok {
+		r0 = rf(ctx, verification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetLatestVerification provides a mock function with given fields: ctx, loanID
+func (_m *BankVerificationRepository) GetLatestVerification(ctx context.Context, loanID uuid.UUID) (*models.BankAccountVerification, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestVerification")
+	}
+
+	var r0 *models.BankAccountVerification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.BankAccountVerification, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.BankAccountVerification); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BankAccountVerification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoanBankAccount provides a mock function with given fields: ctx, loanID
+func (_m *BankVerificationRepository) GetLoanBankAccount(ctx context.Context, loanID uuid.UUID) (*models.LoanBankAccount, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanBankAccount")
+	}
+
+	var r0 *models.LoanBankAccount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.LoanBankAccount, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.LoanBankAccount); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.LoanBankAccount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListVerifications provides a mock function with given fields: ctx, loanID
+func (_m *BankVerificationRepository) ListVerifications(ctx context.Context, loanID uuid.UUID) ([]models.BankAccountVerification, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVerifications")
+	}
+
+	var r0 []models.BankAccountVerification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]models.BankAccountVerification, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.BankAccountVerification); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BankAccountVerification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OverrideVerification provides a mock function with given fields: ctx, verificationID, employeeID, reason, overriddenAt
+func (_m *BankVerificationRepository) OverrideVerification(ctx context.Context, verificationID uuid.UUID, employeeID uuid.UUID, reason string, overriddenAt time.Time) error {
+	ret := _m.Called(ctx, verificationID, employeeID, reason, overriddenAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OverrideVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string, time.Time) error); ok {
+		r0 = rf(ctx, verificationID, employeeID, reason, overriddenAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewBankVerificationRepository creates a new instance of BankVerificationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBankVerificationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *BankVerificationRepository {
+	mock := &BankVerificationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
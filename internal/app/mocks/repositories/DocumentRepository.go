@@ -0,0 +1,235 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// DocumentRepository is an autogenerated mock type for the DocumentRepository type
+type DocumentRepository struct {
+	mock.Mock
+}
+
+// CreateDocument provides a mock function with given fields: ctx, document
+func (_m *DocumentRepository) CreateDocument(ctx context.Context, document *models.Document) error {
+	ret := _m.Called(ctx, document)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Document) error); ok {
+		r0 = rf(ctx, document)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDocument provides a mock function with given fields: ctx, documentID
+func (_m *DocumentRepository) GetDocument(ctx context.Context, documentID uuid.UUID) (*models.Document, error) {
+	ret := _m.Called(ctx, documentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocument")
+	}
+
+	var r0 *models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Document, error)); ok {
+		return rf(ctx, documentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Document); ok {
+		r0 = rf(ctx, documentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, documentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDocumentByFileName provides a mock function with given fields: ctx, fileName
+func (_m *DocumentRepository) GetDocumentByFileName(ctx context.Context, fileName string) (*models.Document, error) {
+	ret := _m.Called(ctx, fileName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocumentByFileName")
+	}
+
+	var r0 *models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Document, error)); ok {
+		return rf(ctx, fileName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Document); ok {
+		r0 = rf(ctx, fileName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, fileName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDocumentByVerificationID provides a mock function with given fields: ctx, verificationID
+func (_m *DocumentRepository) GetDocumentByVerificationID(ctx context.Context, verificationID string) (*models.Document, error) {
+	ret := _m.Called(ctx, verificationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocumentByVerificationID")
+	}
+
+	var r0 *models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Document, error)); ok {
+		return rf(ctx, verificationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Document); ok {
+		r0 = rf(ctx, verificationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, verificationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDocuments provides a mock function with given fields: ctx, loanID, includeDeleted
+func (_m *DocumentRepository) ListDocuments(ctx context.Context, loanID uuid.UUID, includeDeleted bool) ([]models.Document, error) {
+	ret := _m.Called(ctx, loanID, includeDeleted)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDocuments")
+	}
+
+	var r0 []models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) ([]models.Document, error)); ok {
+		return rf(ctx, loanID, includeDeleted)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, bool) []models.Document); ok {
+		r0 = rf(ctx, loanID, includeDeleted)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, bool) error); ok {
+		r1 = rf(ctx, loanID, includeDeleted)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPendingDocuments provides a mock function with given fields: ctx, limit
+func (_m *DocumentRepository) ListPendingDocuments(ctx context.Context, limit int) ([]models.Document, error) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPendingDocuments")
+	}
+
+	var r0 []models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]models.Document, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.Document); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SoftDeleteDocument provides a mock function with given fields: ctx, documentID, deletedByID, reason
+func (_m *DocumentRepository) SoftDeleteDocument(ctx context.Context, documentID uuid.UUID, deletedByID uuid.UUID, reason string) error {
+	ret := _m.Called(ctx, documentID, deletedByID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SoftDeleteDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, documentID, deletedByID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateDocumentScan provides a mock function with given fields: ctx, documentID, status, signature, storageKey
+func (_m *DocumentRepository) UpdateDocumentScan(ctx context.Context, documentID uuid.UUID, status string, signature string, storageKey string) error {
+	ret := _m.Called(ctx, documentID, status, signature, storageKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDocumentScan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, string) error); ok {
+		r0 = rf(ctx, documentID, status, signature, storageKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDocumentRepository creates a new instance of DocumentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDocumentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DocumentRepository {
+	mock := &DocumentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
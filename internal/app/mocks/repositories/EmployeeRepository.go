@@ -0,0 +1,175 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// EmployeeRepository is an autogenerated mock type for the EmployeeRepository type
+type EmployeeRepository struct {
+	mock.Mock
+}
+
+// CreateEmployee provides a mock function with given fields: ctx, employee, passwordHash, audit
+func (_m *EmployeeRepository) CreateEmployee(ctx context.Context, employee *models.Employee, passwordHash string, audit *models.EmployeeAuditLog) error {
+	ret := _m.Called(ctx, employee, passwordHash, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEmployee")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Employee, string, *models.EmployeeAuditLog) error); ok {
+		r0 = rf(ctx, employee, passwordHash, audit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetEmployee provides a mock function with given fields: ctx, id
+func (_m *EmployeeRepository) GetEmployee(ctx context.Context, id uuid.UUID) (*models.Employee, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEmployee")
+	}
+
+	var r0 *models.Employee
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Employee, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Employee); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Employee)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAuditLogs provides a mock function with given fields: ctx, employeeID
+func (_m *EmployeeRepository) ListAuditLogs(ctx context.Context, employeeID uuid.UUID) ([]models.EmployeeAuditLog, error) {
+	ret := _m.Called(ctx, employeeID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAuditLogs")
+	}
+
+	var r0 []models.EmployeeAuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]models.EmployeeAuditLog, error)); ok {
+		return rf(ctx, employeeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.EmployeeAuditLog); ok {
+		r0 = rf(ctx, employeeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EmployeeAuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, employeeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListEmployees provides a mock function with given fields: ctx, filter
+func (_m *EmployeeRepository) ListEmployees(ctx context.Context, filter models.EmployeeListFilter) ([]models.Employee, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEmployees")
+	}
+
+	var r0 []models.Employee
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.EmployeeListFilter) ([]models.Employee, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.EmployeeListFilter) []models.Employee); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Employee)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.EmployeeListFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateEmployee provides a mock function with given fields: ctx, employee, audit
+func (_m *EmployeeRepository) UpdateEmployee(ctx context.Context, employee *models.Employee, audit *models.EmployeeAuditLog) error {
+	ret := _m.Called(ctx, employee, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEmployee")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Employee, *models.EmployeeAuditLog) error); ok {
+		r0 = rf(ctx, employee, audit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, id, passwordHash, resetMFA, audit
+func (_m *EmployeeRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, resetMFA bool, audit *models.EmployeeAuditLog) error {
+	ret := _m.Called(ctx, id, passwordHash, resetMFA, audit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, bool, *models.EmployeeAuditLog) error); ok {
+		r0 = rf(ctx, id, passwordHash, resetMFA, audit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewEmployeeRepository creates a new instance of EmployeeRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEmployeeRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EmployeeRepository {
+	mock := &EmployeeRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,109 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// FileRepository is an autogenerated mock type for the FileRepository type
+type FileRepository struct {
+	mock.Mock
+}
+
+// GetBorrowerCoordinates provides a mock function with given fields: ctx, loanID
+func (_m *FileRepository) GetBorrowerCoordinates(ctx context.Context, loanID uuid.UUID) (*models.Coordinates, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBorrowerCoordinates")
+	}
+
+	var r0 *models.Coordinates
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Coordinates, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Coordinates); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Coordinates)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoanCurrentState provides a mock function with given fields: ctx, loanID
+func (_m *FileRepository) GetLoanCurrentState(ctx context.Context, loanID uuid.UUID) (string, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanCurrentState")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (string, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) string); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateLoanSurveyInfo provides a mock function with given fields: ctx, loanID, validatorID, surveyDate, surveyNotes, document, flags
+func (_m *FileRepository) UpdateLoanSurveyInfo(ctx context.Context, loanID uuid.UUID, validatorID uuid.UUID, surveyDate time.Time, surveyNotes string, document *models.Document, flags []models.SurveyFlag) error {
+	ret := _m.Called(ctx, loanID, validatorID, surveyDate, surveyNotes, document, flags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLoanSurveyInfo")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, time.Time, string, *models.Document, []models.SurveyFlag) error); ok {
+		r0 = rf(ctx, loanID, validatorID, surveyDate, surveyNotes, document, flags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewFileRepository creates a new instance of FileRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFileRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *FileRepository {
+	mock := &FileRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,153 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IdempotencyRepository is an autogenerated mock type for the IdempotencyRepository type
+type IdempotencyRepository struct {
+	mock.Mock
+}
+
+// ClaimKey provides a mock function with given fields: ctx, key, staleBefore
+func (_m *IdempotencyRepository) ClaimKey(ctx context.Context, key *models.IdempotencyKey, staleBefore time.Time) (bool, error) {
+	ret := _m.Called(ctx, key, staleBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimKey")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey, time.Time) (bool, error)); ok {
+		return rf(ctx, key, staleBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey, time.Time) bool); ok {
+		r0 = rf(ctx, key, staleBefore)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.IdempotencyKey, time.Time) error); ok {
+		r1 = rf(ctx, key, staleBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteKey provides a mock function with given fields: ctx, key
+func (_m *IdempotencyRepository) CompleteKey(ctx context.Context, key *models.IdempotencyKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IdempotencyKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredKeys provides a mock function with given fields: ctx, now
+func (_m *IdempotencyRepository) DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredKeys")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetKey provides a mock function with given fields: ctx, principal, key
+func (_m *IdempotencyRepository) GetKey(ctx context.Context, principal string, key string) (*models.IdempotencyKey, error) {
+	ret := _m.Called(ctx, principal, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetKey")
+	}
+
+	var r0 *models.IdempotencyKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.IdempotencyKey, error)); ok {
+		return rf(ctx, principal, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.IdempotencyKey); ok {
+		r0 = rf(ctx, principal, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.IdempotencyKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, principal, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseKey provides a mock function with given fields: ctx, principal, key
+func (_m *IdempotencyRepository) ReleaseKey(ctx context.Context, principal string, key string) error {
+	ret := _m.Called(ctx, principal, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, principal, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyRepository {
+	mock := &IdempotencyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
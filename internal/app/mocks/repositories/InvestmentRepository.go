@@ -4,10 +4,14 @@ package mocks
 
 import (
 	context "context"
-	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
 
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	mock "github.com/stretchr/testify/mock"
 
+	repositories "github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -16,27 +20,47 @@ type InvestmentRepository struct {
 	mock.Mock
 }
 
-// CheckExistingInvestment provides a mock function with given fields: ctx, loanID, investorID
-func (_m *InvestmentRepository) CheckExistingInvestment(ctx context.Context, loanID uuid.UUID, investorID uuid.UUID) (bool, error) {
-	ret := _m.Called(ctx, loanID, investorID)
+// AcceptAgreement provides a mock function with given fields: ctx, acceptance
+func (_m *InvestmentRepository) AcceptAgreement(ctx context.Context, acceptance *models.AgreementAcceptance) error {
+	ret := _m.Called(ctx, acceptance)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CheckExistingInvestment")
+		panic("no return value specified for AcceptAgreement")
 	}
 
-	var r0 bool
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AgreementAcceptance) error); ok {
+		r0 = rf(ctx, acceptance)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CancelExpiredInvestments provides a mock function with given fields: ctx, now, newEvent
+func (_m *InvestmentRepository) CancelExpiredInvestments(ctx context.Context, now time.Time, newEvent repositories.CancelledEventFunc) ([]models.Investment, error) {
+	ret := _m.Called(ctx, now, newEvent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelExpiredInvestments")
+	}
+
+	var r0 []models.Investment
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (bool, error)); ok {
-		return rf(ctx, loanID, investorID)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, repositories.CancelledEventFunc) ([]models.Investment, error)); ok {
+		return rf(ctx, now, newEvent)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) bool); ok {
-		r0 = rf(ctx, loanID, investorID)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, repositories.CancelledEventFunc) []models.Investment); ok {
+		r0 = rf(ctx, now, newEvent)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Investment)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
-		r1 = rf(ctx, loanID, investorID)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, repositories.CancelledEventFunc) error); ok {
+		r1 = rf(ctx, now, newEvent)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -44,22 +68,94 @@ func (_m *InvestmentRepository) CheckExistingInvestment(ctx context.Context, loa
 	return r0, r1
 }
 
-// CreateInvestment provides a mock function with given fields: ctx, investment
-func (_m *InvestmentRepository) CreateInvestment(ctx context.Context, investment *models.Investment) error {
-	ret := _m.Called(ctx, investment)
+// CreateInvestment provides a mock function with given fields: ctx, investment, agreement, newEvents
+func (_m *InvestmentRepository) CreateInvestment(ctx context.Context, investment *models.Investment, agreement *models.Document, newEvents repositories.InvestmentEventsFunc) (*models.InvestmentPlacement, error) {
+	ret := _m.Called(ctx, investment, agreement, newEvents)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateInvestment")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Investment) error); ok {
-		r0 = rf(ctx, investment)
+	var r0 *models.InvestmentPlacement
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Investment, *models.Document, repositories.InvestmentEventsFunc) (*models.InvestmentPlacement, error)); ok {
+		return rf(ctx, investment, agreement, newEvents)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Investment, *models.Document, repositories.InvestmentEventsFunc) *models.InvestmentPlacement); ok {
+		r0 = rf(ctx, investment, agreement, newEvents)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InvestmentPlacement)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Investment, *models.Document, repositories.InvestmentEventsFunc) error); ok {
+		r1 = rf(ctx, investment, agreement, newEvents)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvestment provides a mock function with given fields: ctx, investmentID
+func (_m *InvestmentRepository) GetInvestment(ctx context.Context, investmentID uuid.UUID) (*models.Investment, error) {
+	ret := _m.Called(ctx, investmentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvestment")
+	}
+
+	var r0 *models.Investment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Investment, error)); ok {
+		return rf(ctx, investmentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Investment); ok {
+		r0 = rf(ctx, investmentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Investment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, investmentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvestmentAgreement provides a mock function with given fields: ctx, investmentID
+func (_m *InvestmentRepository) GetInvestmentAgreement(ctx context.Context, investmentID uuid.UUID) (*models.Document, error) {
+	ret := _m.Called(ctx, investmentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvestmentAgreement")
+	}
+
+	var r0 *models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Document, error)); ok {
+		return rf(ctx, investmentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Document); ok {
+		r0 = rf(ctx, investmentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, investmentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // GetInvestorName provides a mock function with given fields: ctx, investorID
@@ -90,6 +186,36 @@ func (_m *InvestmentRepository) GetInvestorName(ctx context.Context, investorID
 	return r0, r1
 }
 
+// GetInvestorPortfolio provides a mock function with given fields: ctx, investorID
+func (_m *InvestmentRepository) GetInvestorPortfolio(ctx context.Context, investorID uuid.UUID) ([]models.PortfolioLoan, error) {
+	ret := _m.Called(ctx, investorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvestorPortfolio")
+	}
+
+	var r0 []models.PortfolioLoan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]models.PortfolioLoan, error)); ok {
+		return rf(ctx, investorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.PortfolioLoan); ok {
+		r0 = rf(ctx, investorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PortfolioLoan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, investorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLoanForInvestment provides a mock function with given fields: ctx, loanID
 func (_m *InvestmentRepository) GetLoanForInvestment(ctx context.Context, loanID uuid.UUID) (*models.LoanInvestmentInfo, error) {
 	ret := _m.Called(ctx, loanID)
@@ -120,6 +246,34 @@ func (_m *InvestmentRepository) GetLoanForInvestment(ctx context.Context, loanID
 	return r0, r1
 }
 
+// GetNextTranche provides a mock function with given fields: ctx, loanID, investorID
+func (_m *InvestmentRepository) GetNextTranche(ctx context.Context, loanID uuid.UUID, investorID uuid.UUID) (int, error) {
+	ret := _m.Called(ctx, loanID, investorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNextTranche")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (int, error)); ok {
+		return rf(ctx, loanID, investorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) int); ok {
+		r0 = rf(ctx, loanID, investorID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID, investorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTotalInvestedAmount provides a mock function with given fields: ctx, loanID
 func (_m *InvestmentRepository) GetTotalInvestedAmount(ctx context.Context, loanID uuid.UUID) (float64, error) {
 	ret := _m.Called(ctx, loanID)
@@ -148,22 +302,34 @@ func (_m *InvestmentRepository) GetTotalInvestedAmount(ctx context.Context, loan
 	return r0, r1
 }
 
-// UpdateLoanState provides a mock function with given fields: ctx, loanID, newState
-func (_m *InvestmentRepository) UpdateLoanState(ctx context.Context, loanID uuid.UUID, newState string) error {
-	ret := _m.Called(ctx, loanID, newState)
+// ListLoanInvestments provides a mock function with given fields: ctx, loanID
+func (_m *InvestmentRepository) ListLoanInvestments(ctx context.Context, loanID uuid.UUID) ([]models.Investment, error) {
+	ret := _m.Called(ctx, loanID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateLoanState")
+		panic("no return value specified for ListLoanInvestments")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
-		r0 = rf(ctx, loanID, newState)
+	var r0 []models.Investment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]models.Investment, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.Investment); ok {
+		r0 = rf(ctx, loanID)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Investment)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewInvestmentRepository creates a new instance of InvestmentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -4,10 +4,12 @@ package mocks
 
 import (
 	context "context"
-	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
 
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -16,17 +18,52 @@ type LoanRepository struct {
 	mock.Mock
 }
 
-// ApproveLoan provides a mock function with given fields: ctx, loanID, approvingEmployeeID, approvalNotes, agreementURL
-func (_m *LoanRepository) ApproveLoan(ctx context.Context, loanID uuid.UUID, approvingEmployeeID uuid.UUID, approvalNotes string, agreementURL string) error {
-	ret := _m.Called(ctx, loanID, approvingEmployeeID, approvalNotes, agreementURL)
+// AddLoanApproval provides a mock function with given fields: ctx, approval, requiredApprovals, allowedRoles
+func (_m *LoanRepository) AddLoanApproval(ctx context.Context, approval *models.LoanApproval, requiredApprovals int, allowedRoles []string) (int, int, error) {
+	ret := _m.Called(ctx, approval, requiredApprovals, allowedRoles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddLoanApproval")
+	}
+
+	var r0 int
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanApproval, int, []string) (int, int, error)); ok {
+		return rf(ctx, approval, requiredApprovals, allowedRoles)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.LoanApproval, int, []string) int); ok {
+		r0 = rf(ctx, approval, requiredApprovals, allowedRoles)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.LoanApproval, int, []string) int); ok {
+		r1 = rf(ctx, approval, requiredApprovals, allowedRoles)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.LoanApproval, int, []string) error); ok {
+		r2 = rf(ctx, approval, requiredApprovals, allowedRoles)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ApproveLoan provides a mock function with given fields: ctx, loanID, approvingEmployeeID, approvalNotes, agreement, event
+func (_m *LoanRepository) ApproveLoan(ctx context.Context, loanID uuid.UUID, approvingEmployeeID uuid.UUID, approvalNotes string, agreement *models.Document, event *models.OutboxEvent) error {
+	ret := _m.Called(ctx, loanID, approvingEmployeeID, approvalNotes, agreement, event)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ApproveLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string, string) error); ok {
-		r0 = rf(ctx, loanID, approvingEmployeeID, approvalNotes, agreementURL)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string, *models.Document, *models.OutboxEvent) error); ok {
+		r0 = rf(ctx, loanID, approvingEmployeeID, approvalNotes, agreement, event)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -34,17 +71,81 @@ func (_m *LoanRepository) ApproveLoan(ctx context.Context, loanID uuid.UUID, app
 	return r0
 }
 
-// CreateLoan provides a mock function with given fields: ctx, loan
-func (_m *LoanRepository) CreateLoan(ctx context.Context, loan *models.Loan) error {
-	ret := _m.Called(ctx, loan)
+// AssignValidator provides a mock function with given fields: ctx, loanID, validatorID
+func (_m *LoanRepository) AssignValidator(ctx context.Context, loanID uuid.UUID, validatorID uuid.UUID) error {
+	ret := _m.Called(ctx, loanID, validatorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssignValidator")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, loanID, validatorID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CompleteDisbursement provides a mock function with given fields: ctx, payoutID, providerReference, completedAt, event
+func (_m *LoanRepository) CompleteDisbursement(ctx context.Context, payoutID uuid.UUID, providerReference string, completedAt time.Time, event *models.OutboxEvent) error {
+	ret := _m.Called(ctx, payoutID, providerReference, completedAt, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteDisbursement")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, time.Time, *models.OutboxEvent) error); ok {
+		r0 = rf(ctx, payoutID, providerReference, completedAt, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountUnacceptedInvestments provides a mock function with given fields: ctx, loanID
+func (_m *LoanRepository) CountUnacceptedInvestments(ctx context.Context, loanID uuid.UUID) (int, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUnacceptedInvestments")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (int, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) int); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateLoan provides a mock function with given fields: ctx, loan, event
+func (_m *LoanRepository) CreateLoan(ctx context.Context, loan *models.Loan, event *models.OutboxEvent) error {
+	ret := _m.Called(ctx, loan, event)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Loan) error); ok {
-		r0 = rf(ctx, loan)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Loan, *models.OutboxEvent) error); ok {
+		r0 = rf(ctx, loan, event)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -52,17 +153,17 @@ func (_m *LoanRepository) CreateLoan(ctx context.Context, loan *models.Loan) err
 	return r0
 }
 
-// DisburseLoan provides a mock function with given fields: ctx, loanID, fieldOfficerID, signedAgreementURL, disbursementNotes
-func (_m *LoanRepository) DisburseLoan(ctx context.Context, loanID uuid.UUID, fieldOfficerID uuid.UUID, signedAgreementURL string, disbursementNotes string) error {
-	ret := _m.Called(ctx, loanID, fieldOfficerID, signedAgreementURL, disbursementNotes)
+// FailDisbursement provides a mock function with given fields: ctx, payoutID, providerReference, reason, failedAt
+func (_m *LoanRepository) FailDisbursement(ctx context.Context, payoutID uuid.UUID, providerReference string, reason string, failedAt time.Time) error {
+	ret := _m.Called(ctx, payoutID, providerReference, reason, failedAt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DisburseLoan")
+		panic("no return value specified for FailDisbursement")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string, string) error); ok {
-		r0 = rf(ctx, loanID, fieldOfficerID, signedAgreementURL, disbursementNotes)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, time.Time) error); ok {
+		r0 = rf(ctx, payoutID, providerReference, reason, failedAt)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -130,6 +231,66 @@ func (_m *LoanRepository) GetDisbursedLoan(ctx context.Context, loanID uuid.UUID
 	return r0, r1
 }
 
+// GetLoan provides a mock function with given fields: ctx, loanID
+func (_m *LoanRepository) GetLoan(ctx context.Context, loanID uuid.UUID) (*models.Loan, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoan")
+	}
+
+	var r0 *models.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Loan, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Loan); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoanApprovals provides a mock function with given fields: ctx, loanID
+func (_m *LoanRepository) GetLoanApprovals(ctx context.Context, loanID uuid.UUID) ([]models.LoanApproval, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanApprovals")
+	}
+
+	var r0 []models.LoanApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]models.LoanApproval, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.LoanApproval); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LoanApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLoanForApproval provides a mock function with given fields: ctx, loanID
 func (_m *LoanRepository) GetLoanForApproval(ctx context.Context, loanID uuid.UUID) (*models.LoanForApproval, error) {
 	ret := _m.Called(ctx, loanID)
@@ -190,6 +351,192 @@ func (_m *LoanRepository) GetLoanForDisbursement(ctx context.Context, loanID uui
 	return r0, r1
 }
 
+// GetPayout provides a mock function with given fields: ctx, payoutID
+func (_m *LoanRepository) GetPayout(ctx context.Context, payoutID uuid.UUID) (*models.Payout, error) {
+	ret := _m.Called(ctx, payoutID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPayout")
+	}
+
+	var r0 *models.Payout
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Payout, error)); ok {
+		return rf(ctx, payoutID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Payout); ok {
+		r0 = rf(ctx, payoutID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Payout)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, payoutID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSignedAgreement provides a mock function with given fields: ctx, loanID
+func (_m *LoanRepository) GetSignedAgreement(ctx context.Context, loanID uuid.UUID) (*models.Document, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSignedAgreement")
+	}
+
+	var r0 *models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Document, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Document); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSurveyFlags provides a mock function with given fields: ctx, loanID
+func (_m *LoanRepository) GetSurveyFlags(ctx context.Context, loanID uuid.UUID) ([]models.SurveyFlag, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSurveyFlags")
+	}
+
+	var r0 []models.SurveyFlag
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]models.SurveyFlag, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.SurveyFlag); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SurveyFlag)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPayouts provides a mock function with given fields: ctx, loanID
+func (_m *LoanRepository) ListPayouts(ctx context.Context, loanID uuid.UUID) ([]models.Payout, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPayouts")
+	}
+
+	var r0 []models.Payout
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]models.Payout, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []models.Payout); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Payout)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPendingApprovals provides a mock function with given fields: ctx, branch, allBranches
+func (_m *LoanRepository) ListPendingApprovals(ctx context.Context, branch string, allBranches bool) ([]models.PendingApproval, error) {
+	ret := _m.Called(ctx, branch, allBranches)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPendingApprovals")
+	}
+
+	var r0 []models.PendingApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) ([]models.PendingApproval, error)); ok {
+		return rf(ctx, branch, allBranches)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) []models.PendingApproval); ok {
+		r0 = rf(ctx, branch, allBranches)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PendingApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, branch, allBranches)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetPayoutReference provides a mock function with given fields: ctx, payoutID, providerReference
+func (_m *LoanRepository) SetPayoutReference(ctx context.Context, payoutID uuid.UUID, providerReference string) error {
+	ret := _m.Called(ctx, payoutID, providerReference)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPayoutReference")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, payoutID, providerReference)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StartDisbursement provides a mock function with given fields: ctx, loanID, fieldOfficerID, signedAgreement, disbursementNotes, payout
+func (_m *LoanRepository) StartDisbursement(ctx context.Context, loanID uuid.UUID, fieldOfficerID uuid.UUID, signedAgreement *models.Document, disbursementNotes string, payout *models.Payout) error {
+	ret := _m.Called(ctx, loanID, fieldOfficerID, signedAgreement, disbursementNotes, payout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartDisbursement")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, *models.Document, string, *models.Payout) error); ok {
+		r0 = rf(ctx, loanID, fieldOfficerID, signedAgreement, disbursementNotes, payout)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewLoanRepository creates a new instance of LoanRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewLoanRepository(t interface {
@@ -0,0 +1,191 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// MFARepository is an autogenerated mock type for the MFARepository type
+type MFARepository struct {
+	mock.Mock
+}
+
+// ConsumeRecoveryCode provides a mock function with given fields: ctx, userID, userType, codeHash
+func (_m *MFARepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, userType string, codeHash string) (bool, error) {
+	ret := _m.Called(ctx, userID, userType, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeRecoveryCode")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) (bool, error)); ok {
+		return rf(ctx, userID, userType, codeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) bool); ok {
+		r0 = rf(ctx, userID, userType, codeHash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, string) error); ok {
+		r1 = rf(ctx, userID, userType, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DisableMFA provides a mock function with given fields: ctx, userID, userType
+func (_m *MFARepository) DisableMFA(ctx context.Context, userID uuid.UUID, userType string) error {
+	ret := _m.Called(ctx, userID, userType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableMFA")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, userID, userType)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableMFA provides a mock function with given fields: ctx, userID, userType, step, recoveryCodeHashes
+func (_m *MFARepository) EnableMFA(ctx context.Context, userID uuid.UUID, userType string, step int64, recoveryCodeHashes []string) error {
+	ret := _m.Called(ctx, userID, userType, step, recoveryCodeHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnableMFA")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, int64, []string) error); ok {
+		r0 = rf(ctx, userID, userType, step, recoveryCodeHashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUserMFA provides a mock function with given fields: ctx, userID, userType
+func (_m *MFARepository) GetUserMFA(ctx context.Context, userID uuid.UUID, userType string) (*models.UserMFA, error) {
+	ret := _m.Called(ctx, userID, userType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserMFA")
+	}
+
+	var r0 *models.UserMFA
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (*models.UserMFA, error)); ok {
+		return rf(ctx, userID, userType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) *models.UserMFA); ok {
+		r0 = rf(ctx, userID, userType)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserMFA)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, userID, userType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkStepUsed provides a mock function with given fields: ctx, userID, userType, step
+func (_m *MFARepository) MarkStepUsed(ctx context.Context, userID uuid.UUID, userType string, step int64) (bool, error) {
+	ret := _m.Called(ctx, userID, userType, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkStepUsed")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, int64) (bool, error)); ok {
+		return rf(ctx, userID, userType, step)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, int64) bool); ok {
+		r0 = rf(ctx, userID, userType, step)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, int64) error); ok {
+		r1 = rf(ctx, userID, userType, step)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordFailedAttempt provides a mock function with given fields: ctx, userID, userType, maxAttempts, lockout
+func (_m *MFARepository) RecordFailedAttempt(ctx context.Context, userID uuid.UUID, userType string, maxAttempts int, lockout time.Duration) error {
+	ret := _m.Called(ctx, userID, userType, maxAttempts, lockout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordFailedAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, int, time.Duration) error); ok {
+		r0 = rf(ctx, userID, userType, maxAttempts, lockout)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePendingSecret provides a mock function with given fields: ctx, userID, userType, secret
+func (_m *MFARepository) SavePendingSecret(ctx context.Context, userID uuid.UUID, userType string, secret string) error {
+	ret := _m.Called(ctx, userID, userType, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePendingSecret")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string) error); ok {
+		r0 = rf(ctx, userID, userType, secret)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMFARepository creates a new instance of MFARepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMFARepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MFARepository {
+	mock := &MFARepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,109 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// NotificationRepository is an autogenerated mock type for the NotificationRepository type
+type NotificationRepository struct {
+	mock.Mock
+}
+
+// GetPreferences provides a mock function with given fields: ctx, userType, userID
+func (_m *NotificationRepository) GetPreferences(ctx context.Context, userType string, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	ret := _m.Called(ctx, userType, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPreferences")
+	}
+
+	var r0 []models.NotificationPreference
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uuid.UUID) ([]models.NotificationPreference, error)); ok {
+		return rf(ctx, userType, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uuid.UUID) []models.NotificationPreference); ok {
+		r0 = rf(ctx, userType, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.NotificationPreference)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uuid.UUID) error); ok {
+		r1 = rf(ctx, userType, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRecipient provides a mock function with given fields: ctx, userType, userID
+func (_m *NotificationRepository) GetRecipient(ctx context.Context, userType string, userID uuid.UUID) (*models.NotificationRecipient, error) {
+	ret := _m.Called(ctx, userType, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecipient")
+	}
+
+	var r0 *models.NotificationRecipient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uuid.UUID) (*models.NotificationRecipient, error)); ok {
+		return rf(ctx, userType, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uuid.UUID) *models.NotificationRecipient); ok {
+		r0 = rf(ctx, userType, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.NotificationRecipient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uuid.UUID) error); ok {
+		r1 = rf(ctx, userType, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SavePreferences provides a mock function with given fields: ctx, userType, userID, preferences
+func (_m *NotificationRepository) SavePreferences(ctx context.Context, userType string, userID uuid.UUID, preferences []models.NotificationPreference) error {
+	ret := _m.Called(ctx, userType, userID, preferences)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePreferences")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uuid.UUID, []models.NotificationPreference) error); ok {
+		r0 = rf(ctx, userType, userID, preferences)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewNotificationRepository creates a new instance of NotificationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewNotificationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *NotificationRepository {
+	mock := &NotificationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,257 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// PaymentRepository is an autogenerated mock type for the PaymentRepository type
+type PaymentRepository struct {
+	mock.Mock
+}
+
+// CreatePayment provides a mock function with given fields: ctx, payment, event
+func (_m *PaymentRepository) CreatePayment(ctx context.Context, payment *models.IncomingPayment, event *models.OutboxEvent) (bool, error) {
+	ret := _m.Called(ctx, payment, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePayment")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IncomingPayment, *models.OutboxEvent) (bool, error)); ok {
+		return rf(ctx, payment, event)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IncomingPayment, *models.OutboxEvent) bool); ok {
+		r0 = rf(ctx, payment, event)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.IncomingPayment, *models.OutboxEvent) error); ok {
+		r1 = rf(ctx, payment, event)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnsureVirtualAccount provides a mock function with given fields: ctx, borrowerID, prefix
+func (_m *PaymentRepository) EnsureVirtualAccount(ctx context.Context, borrowerID uuid.UUID, prefix string) (*models.VirtualAccount, error) {
+	ret := _m.Called(ctx, borrowerID, prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnsureVirtualAccount")
+	}
+
+	var r0 *models.VirtualAccount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (*models.VirtualAccount, error)); ok {
+		return rf(ctx, borrowerID, prefix)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) *models.VirtualAccount); ok {
+		r0 = rf(ctx, borrowerID, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.VirtualAccount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, borrowerID, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindBorrowerByVirtualAccount provides a mock function with given fields: ctx, virtualAccountNumber
+func (_m *PaymentRepository) FindBorrowerByVirtualAccount(ctx context.Context, virtualAccountNumber string) (uuid.UUID, error) {
+	ret := _m.Called(ctx, virtualAccountNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBorrowerByVirtualAccount")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (uuid.UUID, error)); ok {
+		return rf(ctx, virtualAccountNumber)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) uuid.UUID); ok {
+		r0 = rf(ctx, virtualAccountNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, virtualAccountNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPayment provides a mock function with given fields: ctx, paymentID
+func (_m *PaymentRepository) GetPayment(ctx context.Context, paymentID uuid.UUID) (*models.IncomingPayment, error) {
+	ret := _m.Called(ctx, paymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPayment")
+	}
+
+	var r0 *models.IncomingPayment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.IncomingPayment, error)); ok {
+		return rf(ctx, paymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.IncomingPayment); ok {
+		r0 = rf(ctx, paymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.IncomingPayment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, paymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentByGatewayID provides a mock function with given fields: ctx, gateway, gatewayPaymentID
+func (_m *PaymentRepository) GetPaymentByGatewayID(ctx context.Context, gateway string, gatewayPaymentID string) (*models.IncomingPayment, error) {
+	ret := _m.Called(ctx, gateway, gatewayPaymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPaymentByGatewayID")
+	}
+
+	var r0 *models.IncomingPayment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.IncomingPayment, error)); ok {
+		return rf(ctx, gateway, gatewayPaymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.IncomingPayment); ok {
+		r0 = rf(ctx, gateway, gatewayPaymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.IncomingPayment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, gateway, gatewayPaymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDisbursedLoanIDs provides a mock function with given fields: ctx, borrowerID
+func (_m *PaymentRepository) ListDisbursedLoanIDs(ctx context.Context, borrowerID uuid.UUID) ([]uuid.UUID, error) {
+	ret := _m.Called(ctx, borrowerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDisbursedLoanIDs")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]uuid.UUID, error)); ok {
+		return rf(ctx, borrowerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []uuid.UUID); ok {
+		r0 = rf(ctx, borrowerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, borrowerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPayments provides a mock function with given fields: ctx, status, limit
+func (_m *PaymentRepository) ListPayments(ctx context.Context, status string, limit int) ([]models.IncomingPayment, error) {
+	ret := _m.Called(ctx, status, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPayments")
+	}
+
+	var r0 []models.IncomingPayment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]models.IncomingPayment, error)); ok {
+		return rf(ctx, status, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []models.IncomingPayment); ok {
+		r0 = rf(ctx, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.IncomingPayment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolvePayment provides a mock function with given fields: ctx, payment, event
+func (_m *PaymentRepository) ResolvePayment(ctx context.Context, payment *models.IncomingPayment, event *models.OutboxEvent) error {
+	ret := _m.Called(ctx, payment, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolvePayment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IncomingPayment, *models.OutboxEvent) error); ok {
+		r0 = rf(ctx, payment, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPaymentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PaymentRepository {
+	mock := &PaymentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,233 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	models "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// SigningRepository is an autogenerated mock type for the SigningRepository type
+type SigningRepository struct {
+	mock.Mock
+}
+
+// CompleteSigning provides a mock function with given fields: ctx, session, signedAgreement
+func (_m *SigningRepository) CompleteSigning(ctx context.Context, session *models.SigningSession, signedAgreement *models.Document) error {
+	ret := _m.Called(ctx, session, signedAgreement)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteSigning")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SigningSession, *models.Document) error); ok {
+		r0 = rf(ctx, session, signedAgreement)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *SigningRepository) CreateSession(ctx context.Context, session *models.SigningSession) error {
+	ret := _m.Called(ctx, session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SigningSession) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FailSession provides a mock function with given fields: ctx, sessionID
+func (_m *SigningRepository) FailSession(ctx context.Context, sessionID uuid.UUID) error {
+	ret := _m.Called(ctx, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FailSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetBorrowerContact provides a mock function with given fields: ctx, borrowerID
+func (_m *SigningRepository) GetBorrowerContact(ctx context.Context, borrowerID uuid.UUID) (*models.BorrowerContact, error) {
+	ret := _m.Called(ctx, borrowerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBorrowerContact")
+	}
+
+	var r0 *models.BorrowerContact
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.BorrowerContact, error)); ok {
+		return rf(ctx, borrowerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.BorrowerContact); ok {
+		r0 = rf(ctx, borrowerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BorrowerContact)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, borrowerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLatestSession provides a mock function with given fields: ctx, loanID
+func (_m *SigningRepository) GetLatestSession(ctx context.Context, loanID uuid.UUID) (*models.SigningSession, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestSession")
+	}
+
+	var r0 *models.SigningSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.SigningSession, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.SigningSession); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SigningSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoanAgreement provides a mock function with given fields: ctx, loanID
+func (_m *SigningRepository) GetLoanAgreement(ctx context.Context, loanID uuid.UUID) (*models.Document, error) {
+	ret := _m.Called(ctx, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoanAgreement")
+	}
+
+	var r0 *models.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.Document, error)); ok {
+		return rf(ctx, loanID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.Document); ok {
+		r0 = rf(ctx, loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSession provides a mock function with given fields: ctx, sessionID
+func (_m *SigningRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.SigningSession, error) {
+	ret := _m.Called(ctx, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSession")
+	}
+
+	var r0 *models.SigningSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*models.SigningSession, error)); ok {
+		return rf(ctx, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *models.SigningSession); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SigningSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordFailedAttempt provides a mock function with given fields: ctx, sessionID, maxAttempts
+func (_m *SigningRepository) RecordFailedAttempt(ctx context.Context, sessionID uuid.UUID, maxAttempts int) (int, error) {
+	ret := _m.Called(ctx, sessionID, maxAttempts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordFailedAttempt")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) (int, error)); ok {
+		return rf(ctx, sessionID, maxAttempts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) int); ok {
+		r0 = rf(ctx, sessionID, maxAttempts)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, int) error); ok {
+		r1 = rf(ctx, sessionID, maxAttempts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSigningRepository creates a new instance of SigningRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSigningRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SigningRepository {
+	mock := &SigningRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,19 @@
+package models
+
+import "github.com/google/uuid"
+
+type EmployeeScope struct {
+	ID       uuid.UUID `json:"id"`
+	Role     string    `json:"role"`
+	Branch   string    `json:"branch"`
+	IsActive bool      `json:"is_active"`
+	// AllBranches is resolved from the policy by the guard, not stored
+	AllBranches bool `json:"all_branches"`
+}
+
+type LoanScope struct {
+	ID                          uuid.UUID  `json:"id"`
+	BorrowerBranch              string     `json:"borrower_branch"`
+	CurrentState                string     `json:"current_state"`
+	AssignedValidatorEmployeeID *uuid.UUID `json:"assigned_validator_employee_id,omitempty"`
+}
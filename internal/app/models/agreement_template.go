@@ -0,0 +1,24 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// AgreementTemplate is one published version of an agreement's text. Versions
+// are never edited, publishing always adds the next version.
+type AgreementTemplate struct {
+	ID           uuid.UUID  `json:"id"`
+	TemplateType string     `json:"template_type"`
+	Version      int        `json:"version"`
+	Body         string     `json:"body"`
+	ChangeNotes  string     `json:"change_notes,omitempty"`
+	CreatedByID  *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type PublishTemplateRequest struct {
+	TemplateType string `json:"template_type" validate:"required,oneof=LOAN_AGREEMENT INVESTMENT_AGREEMENT"`
+	Body         string `json:"body" validate:"required,max=65536"`
+	ChangeNotes  string `json:"change_notes" validate:"max=500"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ServicePrincipal struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type APIKey struct {
+	ID                 uuid.UUID  `json:"id"`
+	ServicePrincipalID uuid.UUID  `json:"service_principal_id"`
+	KeyPrefix          string     `json:"key_prefix"`
+	KeyHash            string     `json:"-"`
+	Scopes             []string   `json:"scopes"`
+	ExpiresAt          time.Time  `json:"expires_at"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
+	RotatedFromID      *uuid.UUID `json:"rotated_from_id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// APIKeyCredential is an API key joined with its principal, used to authenticate requests.
+type APIKeyCredential struct {
+	APIKey
+	PrincipalName     string
+	PrincipalIsActive bool
+}
+
+type CreateServicePrincipalRequest struct {
+	Name        string `json:"name" validate:"required,min=3,max=50"`
+	Description string `json:"description" validate:"omitempty,max=255"`
+}
+
+type CreateAPIKeyRequest struct {
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required"`
+	// ExpiresInDays falls back to api_keys.default_ttl when zero
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1"`
+}
+
+type RotateAPIKeyRequest struct {
+	// GracePeriodHours keeps the old key valid while callers switch over
+	GracePeriodHours *int `json:"grace_period_hours" validate:"omitempty,min=0"`
+}
+
+// APIKeyResponse carries the plaintext key, which is only ever returned once.
+type APIKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
@@ -12,10 +12,14 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken string   `json:"access_token"`
-	TokenType   string   `json:"token_type"`
-	ExpiresIn   int      `json:"expires_in"`
-	User        UserInfo `json:"user"`
+	AccessToken           string   `json:"access_token,omitempty"`
+	TokenType             string   `json:"token_type,omitempty"`
+	ExpiresIn             int      `json:"expires_in,omitempty"`
+	MFARequired           bool     `json:"mfa_required"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
+	User                  UserInfo `json:"user"`
 }
 
 type UserInfo struct {
@@ -53,5 +57,8 @@ type JWTClaims struct {
 	UserID   string `json:"user_id"`
 	UserType string `json:"user_type"`
 	Role     string `json:"role,omitempty"`
+	TokenUse string `json:"token_use,omitempty"`
+	// Scopes is only set for service principals authenticated by API key
+	Scopes []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BankAccountVerification is one inquiry of the borrower's bank account for a
+// loan, comparing the holder name the bank reports with the borrower's name.
+type BankAccountVerification struct {
+	ID                uuid.UUID  `json:"id"`
+	LoanID            uuid.UUID  `json:"loan_id"`
+	BorrowerID        uuid.UUID  `json:"borrower_id"`
+	BankName          string     `json:"bank_name"`
+	BankAccountNumber string     `json:"bank_account_number"`
+	BorrowerName      string     `json:"borrower_name"`
+	AccountHolderName string     `json:"account_holder_name,omitempty"`
+	MatchScore        float64    `json:"match_score"`
+	MatchThreshold    float64    `json:"match_threshold"`
+	Status            string     `json:"status"`
+	Provider          string     `json:"provider"`
+	CheckedByID       *uuid.UUID `json:"checked_by_id,omitempty"`
+	OverrideReason    string     `json:"override_reason,omitempty"`
+	OverriddenByID    *uuid.UUID `json:"overridden_by_id,omitempty"`
+	OverriddenAt      *time.Time `json:"overridden_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// LoanBankAccount is the account a loan would be disbursed to.
+type LoanBankAccount struct {
+	LoanID            uuid.UUID
+	BorrowerID        uuid.UUID
+	BorrowerName      string
+	BankName          string
+	BankAccountNumber string
+}
+
+type OverrideBankVerificationRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Document is one stored file attached to a loan. Each upload or generated
+// agreement is a new row, numbered per loan, type and investment.
+type Document struct {
+	ID             uuid.UUID  `json:"id"`
+	LoanID         uuid.UUID  `json:"loan_id"`
+	InvestmentID   *uuid.UUID `json:"investment_id,omitempty"`
+	DocumentType   string     `json:"document_type"`
+	FileName       string     `json:"file_name"`
+	StorageKey     string     `json:"-"`
+	ContentType    string     `json:"content_type"`
+	SizeBytes      *int64     `json:"size_bytes,omitempty"`
+	SHA256         string     `json:"sha256,omitempty"`
+	Version        int        `json:"version"`
+	UploadedByID   *uuid.UUID `json:"uploaded_by_id,omitempty"`
+	UploadedByType string     `json:"uploaded_by_type"`
+	DownloadURL    string     `json:"download_url"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	DeletedByID    *uuid.UUID `json:"deleted_by_id,omitempty"`
+	DeletedReason  string     `json:"deleted_reason,omitempty"`
+	ScanStatus     string     `json:"scan_status"`
+	ScanSignature  string     `json:"scan_signature,omitempty"`
+	ScannedAt      *time.Time `json:"scanned_at,omitempty"`
+	// Geotag and capture time read from photos before metadata is stripped
+	Latitude   *float64   `json:"gps_latitude,omitempty"`
+	Longitude  *float64   `json:"gps_longitude,omitempty"`
+	CapturedAt *time.Time `json:"captured_at,omitempty"`
+	// TemplateVersion is the agreement template a generated document used
+	TemplateVersion *int `json:"template_version,omitempty"`
+	// VerificationID is stamped on every page of generated agreements
+	VerificationID string `json:"verification_id,omitempty"`
+	// SignerFingerprint is the SHA-256 of the certificate the platform signed
+	// the file with, empty for unsigned files
+	SignerFingerprint string `json:"signer_fingerprint,omitempty"`
+
+	// Owners resolved for access checks
+	BorrowerID uuid.UUID  `json:"-"`
+	InvestorID *uuid.UUID `json:"-"`
+}
+
+type DeleteDocumentRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// DocumentVerification is what the public verification endpoint reveals about
+// a stamped document. It leaves out the parties and amounts on purpose.
+type DocumentVerification struct {
+	VerificationID string    `json:"verification_id"`
+	DocumentType   string    `json:"document_type"`
+	Status         string    `json:"status"`
+	SHA256         string    `json:"sha256"`
+	IssuedAt       time.Time `json:"issued_at"`
+	// Matches is set when a copy was presented, by hash or by upload
+	PresentedSHA256 string `json:"presented_sha256,omitempty"`
+	Matches         *bool  `json:"matches,omitempty"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Employee struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	FullName     string    `json:"full_name"`
+	PhoneNumber  string    `json:"phone_number,omitempty"`
+	EmployeeRole string    `json:"employee_role"`
+	Department   string    `json:"department,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type EmployeeListFilter struct {
+	EmployeeRole string
+	IsActive     *bool
+	Limit        int
+	Offset       int
+}
+
+type CreateEmployeeRequest struct {
+	Username     string `json:"username" validate:"required,min=3,max=50"`
+	Email        string `json:"email" validate:"required,email,max=100"`
+	Password     string `json:"password" validate:"required,min=8"`
+	FullName     string `json:"full_name" validate:"required,max=100"`
+	PhoneNumber  string `json:"phone_number" validate:"omitempty,max=15"`
+	EmployeeRole string `json:"employee_role" validate:"required,oneof=FIELD_VALIDATOR FIELD_OFFICER ADMIN"`
+	Department   string `json:"department" validate:"omitempty,max=50"`
+	Branch       string `json:"branch" validate:"omitempty,max=50"`
+}
+
+type UpdateEmployeeRequest struct {
+	FullName     *string `json:"full_name" validate:"omitempty,min=1,max=100"`
+	PhoneNumber  *string `json:"phone_number" validate:"omitempty,max=15"`
+	EmployeeRole *string `json:"employee_role" validate:"omitempty,oneof=FIELD_VALIDATOR FIELD_OFFICER ADMIN"`
+	Department   *string `json:"department" validate:"omitempty,max=50"`
+	Branch       *string `json:"branch" validate:"omitempty,max=50"`
+	IsActive     *bool   `json:"is_active"`
+}
+
+type ResetPasswordRequest struct {
+	// NewPassword is generated when empty
+	NewPassword string `json:"new_password" validate:"omitempty,min=8"`
+	ResetMFA    bool   `json:"reset_mfa"`
+}
+
+type ResetPasswordResponse struct {
+	EmployeeID        uuid.UUID `json:"employee_id"`
+	TemporaryPassword string    `json:"temporary_password,omitempty"`
+	MFAReset          bool      `json:"mfa_reset"`
+}
+
+type EmployeeAuditLog struct {
+	ID              uuid.UUID              `json:"id"`
+	EmployeeID      uuid.UUID              `json:"employee_id"`
+	ActorEmployeeID uuid.UUID              `json:"actor_employee_id"`
+	Action          string                 `json:"action"`
+	Changes         map[string]interface{} `json:"changes,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event saved in the same transaction as the state
+// change it describes and published afterwards by the relay.
+type OutboxEvent struct {
+	ID            int64           `json:"-"`
+	EventID       uuid.UUID       `json:"event_id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Attempts      int             `json:"-"`
+}
+
+type LoanProposedEvent struct {
+	LoanID          uuid.UUID `json:"loan_id"`
+	BorrowerID      uuid.UUID `json:"borrower_id"`
+	PrincipalAmount float64   `json:"principal_amount"`
+	InterestRate    float64   `json:"interest_rate"`
+	ROIRate         float64   `json:"roi_rate"`
+	LoanTermMonth   int       `json:"loan_term_month"`
+}
+
+type LoanApprovedEvent struct {
+	LoanID              uuid.UUID `json:"loan_id"`
+	ApprovingEmployeeID uuid.UUID `json:"approving_employee_id"`
+	AgreementID         uuid.UUID `json:"agreement_id"`
+}
+
+type InvestmentCreatedEvent struct {
+	InvestmentID       uuid.UUID  `json:"investment_id"`
+	LoanID             uuid.UUID  `json:"loan_id"`
+	InvestorID         uuid.UUID  `json:"investor_id"`
+	Tranche            int        `json:"tranche"`
+	InvestmentAmount   float64    `json:"investment_amount"`
+	ExpectedReturn     float64    `json:"expected_return"`
+	AcceptanceDeadline *time.Time `json:"acceptance_deadline,omitempty"`
+}
+
+// InvestmentCancelledEvent carries the loan's state after the cancellation,
+// a loan left without enough invested is back in FUNDING or APPROVED.
+type InvestmentCancelledEvent struct {
+	InvestmentID     uuid.UUID `json:"investment_id"`
+	LoanID           uuid.UUID `json:"loan_id"`
+	InvestorID       uuid.UUID `json:"investor_id"`
+	Tranche          int       `json:"tranche"`
+	InvestmentAmount float64   `json:"investment_amount"`
+	Reason           string    `json:"reason"`
+	LoanState        string    `json:"loan_state"`
+}
+
+type LoanFullyFundedEvent struct {
+	LoanID          uuid.UUID `json:"loan_id"`
+	PrincipalAmount float64   `json:"principal_amount"`
+	TotalInvested   float64   `json:"total_invested"`
+}
+
+type LoanDisbursedEvent struct {
+	LoanID                 uuid.UUID  `json:"loan_id"`
+	FieldOfficerEmployeeID *uuid.UUID `json:"field_officer_employee_id,omitempty"`
+	PayoutID               uuid.UUID  `json:"payout_id"`
+	Amount                 float64    `json:"amount"`
+	ProviderReference      string     `json:"provider_reference"`
+}
+
+type RepaymentReceivedEvent struct {
+	PaymentID  uuid.UUID `json:"payment_id"`
+	LoanID     uuid.UUID `json:"loan_id"`
+	BorrowerID uuid.UUID `json:"borrower_id"`
+	Amount     float64   `json:"amount"`
+	PaidAt     time.Time `json:"paid_at"`
+	// Matched is false when an employee assigned the payment from suspense
+	Matched bool `json:"matched"`
+}
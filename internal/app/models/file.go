@@ -2,6 +2,7 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -9,15 +10,60 @@ type UploadDocumentRequest struct {
 	LoanID      string `form:"loan_id" validate:"required,uuid"`
 	SurveyDate  string `form:"survey_date" validate:"required"`
 	SurveyNotes string `form:"survey_notes"`
+	// DocumentType defaults to SURVEY_PHOTO for images and SURVEY_DOCUMENT for PDFs
+	DocumentType string `form:"document_type" validate:"omitempty,oneof=SURVEY_PHOTO SURVEY_DOCUMENT SUPPORTING_DOCUMENT"`
 }
 
 type UploadDocumentResponse struct {
 	LoanID                   uuid.UUID `json:"loan_id"`
+	DocumentID               uuid.UUID `json:"document_id"`
+	DocumentType             string    `json:"document_type"`
+	Version                  int       `json:"version"`
 	FileName                 string    `json:"file_name"`
 	FileURL                  string    `json:"file_url"`
 	FileType                 string    `json:"file_type"`
+	SizeBytes                int64     `json:"size_bytes"`
+	SHA256                   string    `json:"sha256"`
+	ScanStatus               string    `json:"scan_status"`
 	FieldValidatorEmployeeID uuid.UUID `json:"field_validator_employee_id"`
 	SurveyDate               string    `json:"survey_date"`
 	SurveyNotes              string    `json:"survey_notes,omitempty"`
 	UploadedAt               time.Time `json:"uploaded_at"`
+	// SurveyFlags lists what did not match the borrower's address or the survey date
+	SurveyFlags []SurveyFlag `json:"survey_flags"`
+}
+
+// SurveyFlag records a survey photo whose geotag or capture time does not
+// match the loan, for the approving officer to review.
+type SurveyFlag struct {
+	ID         uuid.UUID `json:"id"`
+	LoanID     uuid.UUID `json:"loan_id"`
+	DocumentID uuid.UUID `json:"document_id"`
+	FlagType   string    `json:"flag_type"`
+	Detail     string    `json:"detail"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Coordinates are decimal degrees, south and west negative.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type FileDownload struct {
+	FileName     string
+	ContentType  string
+	Size         int64
+	LastModified time.Time
+	Content      io.ReadCloser
+}
+
+type ShareFileRequest struct {
+	ExpiresInMinutes int `json:"expires_in_minutes" validate:"omitempty,min=1"`
+}
+
+type ShareFileResponse struct {
+	FileID    string    `json:"file_id"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyKey is a request a principal sent with an Idempotency-Key header
+// and, once answered, the response replayed to retries.
+type IdempotencyKey struct {
+	Principal string
+	Key       string
+	// Fingerprint is the SHA-256 of the method, path and body of the request
+	Fingerprint         string
+	Status              string
+	ResponseStatus      int
+	ResponseContentType string
+	ResponseBody        []byte
+	LockedAt            time.Time
+	ExpiresAt           time.Time
+	CreatedAt           time.Time
+}
@@ -14,6 +14,7 @@ type InvestmentResponse struct {
 	ID                  uuid.UUID `json:"id"`
 	LoanID              uuid.UUID `json:"loan_id"`
 	InvestorID          uuid.UUID `json:"investor_id"`
+	Tranche             int       `json:"tranche"`
 	InvestmentAmount    float64   `json:"investment_amount"`
 	ExpectedReturn      float64   `json:"expected_return"`
 	InvestmentDate      string    `json:"investment_date"`
@@ -21,17 +22,53 @@ type InvestmentResponse struct {
 	TotalInvestedAmount float64   `json:"total_invested_amount"`
 	RemainingAmount     float64   `json:"remaining_amount"`
 	AgreementURL        string    `json:"agreement_url,omitempty"`
+	Status              string    `json:"status"`
+	AcceptanceDeadline  time.Time `json:"acceptance_deadline"`
 	CreatedAt           time.Time `json:"created_at"`
+	// The investor's position in the loan over all their tranches
+	InvestorTotalAmount    float64 `json:"investor_total_amount"`
+	InvestorExpectedReturn float64 `json:"investor_expected_return"`
 }
 
 type Investment struct {
 	ID               uuid.UUID `json:"id"`
 	LoanID           uuid.UUID `json:"loan_id"`
 	InvestorID       uuid.UUID `json:"investor_id"`
+	Tranche          int       `json:"tranche"`
 	InvestmentAmount float64   `json:"investment_amount"`
 	ExpectedReturn   float64   `json:"expected_return"`
 	InvestmentDate   time.Time `json:"investment_date"`
-	CreatedAt        time.Time `json:"created_at"`
+	Status           string    `json:"status"`
+	// AcceptanceDeadline is when the investment is cancelled unless the
+	// investor has accepted the agreement
+	AcceptanceDeadline *time.Time `json:"acceptance_deadline,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+type AcceptAgreementRequest struct {
+	// AgreementSHA256 is the hash of the copy the investor read. When given it
+	// must match the current agreement.
+	AgreementSHA256 string `json:"agreement_sha256" validate:"omitempty,len=64,hexadecimal"`
+}
+
+// AgreementAcceptance is the evidence recorded when an investor accepts their
+// agreement.
+type AgreementAcceptance struct {
+	InvestmentID    uuid.UUID `json:"investment_id"`
+	DocumentID      uuid.UUID `json:"document_id"`
+	AgreementSHA256 string    `json:"agreement_sha256"`
+	AcceptedAt      time.Time `json:"accepted_at"`
+	IPAddress       string    `json:"ip_address"`
+	UserAgent       string    `json:"user_agent"`
+}
+
+type AcceptAgreementResponse struct {
+	InvestmentID    uuid.UUID `json:"investment_id"`
+	LoanID          uuid.UUID `json:"loan_id"`
+	Status          string    `json:"status"`
+	AgreementURL    string    `json:"agreement_url"`
+	AgreementSHA256 string    `json:"agreement_sha256"`
+	AcceptedAt      time.Time `json:"accepted_at"`
 }
 
 type LoanInvestmentInfo struct {
@@ -41,3 +78,46 @@ type LoanInvestmentInfo struct {
 	CurrentState    string    `json:"current_state"`
 	TotalInvested   float64   `json:"total_invested"`
 }
+
+// InvestorPosition is what an investor holds in a loan over all their
+// tranches that were not cancelled.
+type InvestorPosition struct {
+	LoanID         uuid.UUID `json:"loan_id"`
+	InvestorID     uuid.UUID `json:"investor_id"`
+	InvestedAmount float64   `json:"invested_amount"`
+	ExpectedReturn float64   `json:"expected_return"`
+	Tranches       int       `json:"tranches"`
+}
+
+// InvestmentPlacement is where a new investment lands, worked out while its
+// loan is locked: the loan and the investor's position with it included.
+type InvestmentPlacement struct {
+	Loan     LoanInvestmentInfo
+	Position InvestorPosition
+}
+
+// PortfolioLoan is one loan of an investor's portfolio, their tranches in it
+// added up.
+type PortfolioLoan struct {
+	LoanID          uuid.UUID `json:"loan_id"`
+	LoanState       string    `json:"loan_state"`
+	PrincipalAmount float64   `json:"principal_amount"`
+	ROIRate         float64   `json:"roi_rate"`
+	InvestedAmount  float64   `json:"invested_amount"`
+	ExpectedReturn  float64   `json:"expected_return"`
+	// AcceptedAmount is invested through accepted agreements, PendingAmount
+	// still waits for the investor's acceptance
+	AcceptedAmount      float64   `json:"accepted_amount"`
+	PendingAmount       float64   `json:"pending_amount"`
+	Tranches            int       `json:"tranches"`
+	FirstInvestmentDate time.Time `json:"first_investment_date"`
+	LastInvestmentDate  time.Time `json:"last_investment_date"`
+}
+
+type InvestorPortfolio struct {
+	InvestorID          uuid.UUID       `json:"investor_id"`
+	TotalInvested       float64         `json:"total_invested"`
+	TotalExpectedReturn float64         `json:"total_expected_return"`
+	LoanCount           int             `json:"loan_count"`
+	Loans               []PortfolioLoan `json:"loans"`
+}
@@ -40,6 +40,7 @@ type LoanForApproval struct {
 	ID                       uuid.UUID `json:"id"`
 	BorrowerID               uuid.UUID `json:"borrower_id"`
 	BorrowerName             string    `json:"borrower_name"`
+	BorrowerLocale           string    `json:"borrower_locale"`
 	PrincipalAmount          float64   `json:"principal_amount"`
 	InterestRate             float64   `json:"interest_rate"`
 	ROIRate                  float64   `json:"roi_rate"`
@@ -47,10 +48,14 @@ type LoanForApproval struct {
 	CurrentState             string    `json:"current_state"`
 	FieldValidatorEmployeeID uuid.UUID `json:"field_validator_employee_id"`
 	SurveyDate               time.Time `json:"survey_date"`
+	// RequiredApprovals is fixed from the approval tier when the first approval lands
+	RequiredApprovals int `json:"required_approvals"`
 }
 
 type ApproveLoanRequest struct {
 	ApprovalNotes string `json:"approval_notes"`
+	// AcknowledgeSurveyFlags confirms the approver reviewed the loan's survey flags
+	AcknowledgeSurveyFlags bool `json:"acknowledge_survey_flags"`
 }
 
 type ApproveLoanResponse struct {
@@ -62,12 +67,46 @@ type ApproveLoanResponse struct {
 	LoanTermMonth            int       `json:"loan_term_month"`
 	CurrentState             string    `json:"current_state"`
 	ApprovalDate             string    `json:"approval_date"`
-	ApprovingEmployeeID      uuid.UUID `json:"approving_employee_id"`
+	ApprovingEmployeeID      uuid.UUID `json:"approving_employee_id,omitzero"`
 	ApprovalNotes            string    `json:"approval_notes,omitempty"`
-	LoanAgreementPDFURL      string    `json:"loan_agreement_pdf_url"`
+	LoanAgreementPDFURL      string    `json:"loan_agreement_pdf_url,omitempty"`
 	FieldValidatorEmployeeID uuid.UUID `json:"field_validator_employee_id"`
 	SurveyDate               string    `json:"survey_date"`
 	UpdatedAt                time.Time `json:"updated_at"`
+
+	RequiredApprovals int            `json:"required_approvals"`
+	ReceivedApprovals int            `json:"received_approvals"`
+	Approvals         []LoanApproval `json:"approvals,omitempty"`
+	SurveyFlags       []SurveyFlag   `json:"survey_flags,omitempty"`
+}
+
+type LoanApproval struct {
+	ID            uuid.UUID `json:"id"`
+	LoanID        uuid.UUID `json:"loan_id"`
+	EmployeeID    uuid.UUID `json:"employee_id"`
+	EmployeeRole  string    `json:"employee_role"`
+	ApprovalNotes string    `json:"approval_notes,omitempty"`
+	// SurveyFlagsAcknowledged is set when the loan had survey flags at the time
+	SurveyFlagsAcknowledged bool      `json:"survey_flags_acknowledged"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
+// PendingApproval is a surveyed PROPOSED loan still waiting for approvals.
+type PendingApproval struct {
+	LoanID                   uuid.UUID   `json:"loan_id"`
+	BorrowerID               uuid.UUID   `json:"borrower_id"`
+	BorrowerName             string      `json:"borrower_name"`
+	BorrowerBranch           string      `json:"borrower_branch,omitempty"`
+	PrincipalAmount          float64     `json:"principal_amount"`
+	LoanTermMonth            int         `json:"loan_term_month"`
+	FieldValidatorEmployeeID uuid.UUID   `json:"field_validator_employee_id"`
+	SurveyDate               string      `json:"survey_date"`
+	RequiredApprovals        int         `json:"required_approvals"`
+	ReceivedApprovals        int         `json:"received_approvals"`
+	ApproverEmployeeIDs      []uuid.UUID `json:"approver_employee_ids"`
+	// SurveyFlagTypes lists the distinct flags raised on the loan's survey photos
+	SurveyFlagTypes []string  `json:"survey_flag_types"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type DisburseLoanRequest struct {
@@ -87,4 +126,16 @@ type DisburseLoanResponse struct {
 	SignedAgreementURL     string    `json:"signed_agreement_url"`
 	DisbursementNotes      string    `json:"disbursement_notes,omitempty"`
 	UpdatedAt              time.Time `json:"updated_at"`
+	// Payout is the transfer the disbursement waits for
+	Payout *Payout `json:"payout,omitempty"`
+}
+
+type AssignValidatorRequest struct {
+	ValidatorEmployeeID string `json:"validator_employee_id" validate:"required,uuid"`
+}
+
+type AssignValidatorResponse struct {
+	LoanID                      uuid.UUID `json:"loan_id"`
+	AssignedValidatorEmployeeID uuid.UUID `json:"assigned_validator_employee_id"`
+	AssignedByEmployeeID        uuid.UUID `json:"assigned_by_employee_id"`
 }
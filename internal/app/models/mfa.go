@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserMFA struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	UserType       string     `json:"user_type"`
+	TOTPSecret     string     `json:"-"`
+	IsEnabled      bool       `json:"is_enabled"`
+	EnabledAt      *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep   int64      `json:"-"`
+	FailedAttempts int        `json:"-"`
+	LockedUntil    *time.Time `json:"-"`
+}
+
+type MFAVerifyRequest struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAActivateResponse struct {
+	RecoveryCodes []string  `json:"recovery_codes"`
+	EnabledAt     time.Time `json:"enabled_at"`
+}
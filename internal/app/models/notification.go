@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// NotificationRecipient is where a user's notifications go.
+type NotificationRecipient struct {
+	UserID      uuid.UUID
+	UserType    string
+	FullName    string
+	Email       string
+	PhoneNumber string
+}
+
+// NotificationPreference is the channels a user receives one notification
+// type on.
+type NotificationPreference struct {
+	NotificationType string `json:"notification_type" validate:"required,oneof=INVESTMENT_AGREEMENT LOAN_FULLY_INVESTED"`
+	Email            bool   `json:"email"`
+	SMS              bool   `json:"sms"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreference `json:"preferences" validate:"required,min=1,dive"`
+}
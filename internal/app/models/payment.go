@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VirtualAccount is the account number a borrower repays into by bank
+// transfer.
+type VirtualAccount struct {
+	BorrowerID           uuid.UUID `json:"borrower_id"`
+	BorrowerName         string    `json:"borrower_name"`
+	BankName             string    `json:"bank_name"`
+	VirtualAccountNumber string    `json:"virtual_account_number"`
+}
+
+// IncomingPayment is money the payment gateway reports paid into a virtual
+// account.
+type IncomingPayment struct {
+	ID                   uuid.UUID       `json:"id"`
+	Gateway              string          `json:"gateway"`
+	GatewayPaymentID     string          `json:"gateway_payment_id"`
+	VirtualAccountNumber string          `json:"virtual_account_number"`
+	Amount               float64         `json:"amount"`
+	PayerName            string          `json:"payer_name,omitempty"`
+	PaidAt               time.Time       `json:"paid_at"`
+	Payload              json.RawMessage `json:"-"`
+	Status               string          `json:"status"`
+	BorrowerID           *uuid.UUID      `json:"borrower_id,omitempty"`
+	LoanID               *uuid.UUID      `json:"loan_id,omitempty"`
+	SuspenseReason       string          `json:"suspense_reason,omitempty"`
+	ResolvedByID         *uuid.UUID      `json:"resolved_by_id,omitempty"`
+	ResolvedAt           *time.Time      `json:"resolved_at,omitempty"`
+	ResolutionNote       string          `json:"resolution_note,omitempty"`
+	CreatedAt            time.Time       `json:"created_at"`
+}
+
+// ResolvePaymentRequest assigns a payment in suspense to a loan, or records
+// that it was sent back when loan_id is empty.
+type ResolvePaymentRequest struct {
+	LoanID string `json:"loan_id" validate:"omitempty,uuid"`
+	Note   string `json:"note" validate:"required,max=500"`
+}
+
+// PaymentCallbackResponse acknowledges a gateway notification.
+type PaymentCallbackResponse struct {
+	PaymentID uuid.UUID `json:"payment_id"`
+	Status    string    `json:"status"`
+	Duplicate bool      `json:"duplicate"`
+}
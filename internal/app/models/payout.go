@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payout is one transfer of a loan's principal to the borrower's bank
+// account. The bank details are copied from the borrower when it is made.
+type Payout struct {
+	ID                uuid.UUID  `json:"id"`
+	LoanID            uuid.UUID  `json:"loan_id"`
+	Amount            float64    `json:"amount"`
+	BankName          string     `json:"bank_name"`
+	BankAccountNumber string     `json:"bank_account_number"`
+	AccountHolderName string     `json:"account_holder_name"`
+	Provider          string     `json:"provider"`
+	ProviderReference string     `json:"provider_reference,omitempty"`
+	Status            string     `json:"status"`
+	FailureReason     string     `json:"failure_reason,omitempty"`
+	RequestedByID     *uuid.UUID `json:"requested_by_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+}
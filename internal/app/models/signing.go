@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SigningSession is one OTP sent to a borrower to sign their loan agreement.
+type SigningSession struct {
+	ID                  uuid.UUID  `json:"id"`
+	LoanID              uuid.UUID  `json:"loan_id"`
+	BorrowerID          uuid.UUID  `json:"borrower_id"`
+	AgreementDocumentID uuid.UUID  `json:"agreement_document_id"`
+	AgreementSHA256     string     `json:"agreement_sha256"`
+	PhoneNumber         string     `json:"-"`
+	OTPHash             string     `json:"-"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	FailedAttempts      int        `json:"failed_attempts"`
+	Status              string     `json:"status"`
+	RequestedIP         string     `json:"-"`
+	RequestedUserAgent  string     `json:"-"`
+	SignedAt            *time.Time `json:"signed_at,omitempty"`
+	SignedIP            string     `json:"-"`
+	SignedUserAgent     string     `json:"-"`
+	SignedDocumentID    *uuid.UUID `json:"signed_document_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// BorrowerContact is where signing codes are sent.
+type BorrowerContact struct {
+	BorrowerID  uuid.UUID
+	FullName    string
+	PhoneNumber string
+}
+
+type RequestSignatureResponse struct {
+	SessionID       uuid.UUID `json:"session_id"`
+	LoanID          uuid.UUID `json:"loan_id"`
+	AgreementURL    string    `json:"agreement_url"`
+	AgreementSHA256 string    `json:"agreement_sha256"`
+	// PhoneNumber is masked, only the last digits are shown
+	PhoneNumber string    `json:"phone_number"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type ConfirmSignatureRequest struct {
+	SessionID string `json:"session_id" validate:"required,uuid"`
+	OTP       string `json:"otp" validate:"required,len=6,numeric"`
+}
+
+type ConfirmSignatureResponse struct {
+	LoanID             uuid.UUID `json:"loan_id"`
+	SignedAgreementURL string    `json:"signed_agreement_url"`
+	SignedSHA256       string    `json:"signed_sha256"`
+	VerificationID     string    `json:"verification_id,omitempty"`
+	SignedAt           time.Time `json:"signed_at"`
+}
+
+// SignatureAudit is printed on the audit page of an electronically signed
+// agreement.
+type SignatureAudit struct {
+	SessionID    uuid.UUID
+	BorrowerName string
+	// PhoneNumber is masked
+	PhoneNumber string
+	// Agreement is the generated agreement the borrower reviewed
+	Agreement *Document
+	OTPSentAt time.Time
+	SignedAt  time.Time
+	IPAddress string
+	UserAgent string
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookSubscription sends the events it is subscribed to to URL, signed
+// with Secret.
+type WebhookSubscription struct {
+	ID          uuid.UUID  `json:"id"`
+	URL         string     `json:"url"`
+	EventTypes  []string   `json:"event_types"`
+	Secret      string     `json:"-"`
+	Description string     `json:"description,omitempty"`
+	IsActive    bool       `json:"is_active"`
+	CreatedByID *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=* LoanProposed LoanApproved InvestmentCreated InvestmentCancelled LoanFullyFunded LoanDisbursed"`
+	// Secret is generated when left empty
+	Secret      string `json:"secret" validate:"omitempty,min=16,max=128"`
+	Description string `json:"description" validate:"omitempty,max=255"`
+}
+
+// UpdateWebhookRequest changes the fields that are set.
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" validate:"omitempty,url,max=2048"`
+	EventTypes  []string `json:"event_types" validate:"omitempty,min=1,dive,oneof=* LoanProposed LoanApproved InvestmentCreated InvestmentCancelled LoanFullyFunded LoanDisbursed"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// WebhookResponse carries the secret, which is only returned when the
+// subscription is created.
+type WebhookResponse struct {
+	Secret       string              `json:"secret"`
+	Subscription WebhookSubscription `json:"subscription"`
+}
+
+// WebhookDelivery is one event sent to one subscription, with its retries.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Body           json.RawMessage `json:"body"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	// URL and Secret of the subscription, loaded when the delivery is sent
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookAttempt is one HTTP request made for a delivery.
+type WebhookAttempt struct {
+	DeliveryID  uuid.UUID `json:"-"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  *int      `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int       `json:"duration_ms"`
+	// Status is what the delivery moves to after this attempt, NextAttemptAt
+	// is set when it is retried
+	Status        string     `json:"-"`
+	NextAttemptAt *time.Time `json:"-"`
+}
+
+// WebhookDeliveryLog is a delivery with every attempt made for it.
+type WebhookDeliveryLog struct {
+	Delivery WebhookDelivery  `json:"delivery"`
+	Attempts []WebhookAttempt `json:"attempts"`
+}
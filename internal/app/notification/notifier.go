@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/email"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/sms"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"io"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is one message to one user. It goes out on the channels the
+// user chose for its type.
+type Notification struct {
+	Type     string
+	UserID   uuid.UUID
+	UserType string
+	// Data is handed to the templates as .Data
+	Data interface{}
+	// Documents are attached to the email
+	Documents []*models.Document
+}
+
+// Notifier sends notifications to borrowers and investors.
+type Notifier interface {
+	Notify(ctx context.Context, notification *Notification) error
+}
+
+type Config struct {
+	// CompanyName signs every message
+	CompanyName string
+	// Location times are printed in
+	Location *time.Location
+}
+
+type notifier struct {
+	repo      repositories.NotificationRepository
+	store     storage.Store
+	mailer    email.Sender
+	texter    sms.Sender
+	config    Config
+	templates *template.Template
+}
+
+func NewNotifier(repo repositories.NotificationRepository, store storage.Store, mailer email.Sender, texter sms.Sender, config Config) Notifier {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+
+	return &notifier{
+		repo:      repo,
+		store:     store,
+		mailer:    mailer,
+		texter:    texter,
+		config:    config,
+		templates: parseTemplates(config.Location),
+	}
+}
+
+// Notify sends on every channel the user enabled and has an address for.
+// Channels are independent, one failing does not hold back the others.
+func (n *notifier) Notify(ctx context.Context, notification *Notification) error {
+	saved, err := n.repo.GetPreferences(ctx, notification.UserType, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	preference := PreferenceFor(saved, notification.Type)
+	if !preference.Email && !preference.SMS {
+		return nil
+	}
+
+	recipient, err := n.repo.GetRecipient(ctx, notification.UserType, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	data := templateData{
+		Company:   n.config.CompanyName,
+		Recipient: recipient,
+		Data:      notification.Data,
+	}
+
+	var errs []error
+	if preference.Email && recipient.Email != "" {
+		errs = append(errs, n.sendEmail(ctx, notification, recipient, data))
+	}
+	if preference.SMS && recipient.PhoneNumber != "" {
+		errs = append(errs, n.sendSMS(ctx, notification, recipient, data))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *notifier) sendEmail(ctx context.Context, notification *Notification, recipient *models.NotificationRecipient, data templateData) error {
+	subject, body, err := renderEmail(n.templates, notification.Type, data)
+	if err != nil {
+		return err
+	}
+
+	message := email.Message{
+		To:      recipient.Email,
+		Subject: subject,
+		Body:    body,
+	}
+	for _, document := range notification.Documents {
+		attachment, err := n.attachment(ctx, document)
+		if err != nil {
+			return err
+		}
+		message.Attachments = append(message.Attachments, attachment)
+	}
+
+	if err := n.mailer.Send(ctx, message); err != nil {
+		return fmt.Errorf("failed to send %s email: %w", notification.Type, err)
+	}
+	return nil
+}
+
+func (n *notifier) sendSMS(ctx context.Context, notification *Notification, recipient *models.NotificationRecipient, data templateData) error {
+	body, err := render(n.templates, notification.Type, constants.CHANNEL_SMS, data)
+	if err != nil {
+		return err
+	}
+
+	if err := n.texter.Send(ctx, sms.Message{To: recipient.PhoneNumber, Body: body}); err != nil {
+		return fmt.Errorf("failed to send %s sms: %w", notification.Type, err)
+	}
+	return nil
+}
+
+func (n *notifier) attachment(ctx context.Context, document *models.Document) (email.Attachment, error) {
+	reader, _, err := n.store.Get(ctx, document.StorageKey)
+	if err != nil {
+		return email.Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return email.Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	return email.Attachment{
+		FileName:    document.FileName,
+		ContentType: document.ContentType,
+		Content:     content,
+	}, nil
+}
@@ -0,0 +1,222 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/email"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/sms"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMailer struct {
+	messages []email.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, message email.Message) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+type recordingTexter struct {
+	messages []sms.Message
+	err      error
+}
+
+func (s *recordingTexter) Send(ctx context.Context, message sms.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+var testInvestor = &models.NotificationRecipient{
+	UserID:      uuid.New(),
+	UserType:    "investor",
+	FullName:    "Rina Investor",
+	Email:       "rina.investor@gmail.com",
+	PhoneNumber: "081298765432",
+}
+
+func agreementNotification(documents ...*models.Document) *Notification {
+	return &Notification{
+		Type:     "INVESTMENT_AGREEMENT",
+		UserID:   testInvestor.UserID,
+		UserType: "investor",
+		Data: InvestmentAgreementData{
+			InvestmentID:       uuid.New(),
+			LoanID:             uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"),
+			Tranche:            1,
+			InvestmentAmount:   2000000,
+			ExpectedReturn:     160000,
+			ROIRate:            8,
+			AcceptanceDeadline: time.Date(2025, 7, 8, 3, 0, 0, 0, time.UTC),
+			VerificationID:     "MFRGGZDFMZTWQ2LK",
+		},
+		Documents: documents,
+	}
+}
+
+func TestNotify_EmailsAgreementByDefault(t *testing.T) {
+	mockRepo := mocksRepo.NewNotificationRepository(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	mailer := &recordingMailer{}
+	texter := &recordingTexter{}
+	notifier := NewNotifier(mockRepo, store, mailer, texter, Config{CompanyName: "PT Loan Engine", Location: jakarta})
+
+	pdf := []byte("%PDF-1.4 agreement")
+	agreement := &models.Document{FileName: "investment_agreement.pdf", ContentType: "application/pdf", StorageKey: "documents/loan/agreement.pdf"}
+	require.NoError(t, store.Put(context.Background(), agreement.StorageKey, bytes.NewReader(pdf), int64(len(pdf)), agreement.ContentType))
+
+	mockRepo.On("GetPreferences", mock.Anything, "investor", testInvestor.UserID).Return(nil, nil)
+	mockRepo.On("GetRecipient", mock.Anything, "investor", testInvestor.UserID).Return(testInvestor, nil)
+
+	err = notifier.Notify(context.Background(), agreementNotification(agreement))
+
+	require.NoError(t, err)
+	assert.Empty(t, texter.messages)
+	require.Len(t, mailer.messages, 1)
+
+	message := mailer.messages[0]
+	assert.Equal(t, "rina.investor@gmail.com", message.To)
+	assert.Equal(t, "Perjanjian investasi Anda untuk pinjaman 7C9E6679", message.Subject)
+	assert.Contains(t, message.Body, "Yth. Rina Investor,")
+	assert.Contains(t, message.Body, "Rp 2.000.000,00")
+	assert.Contains(t, message.Body, "8,00%")
+	assert.Contains(t, message.Body, "MFRGGZDFMZTWQ2LK")
+	// Deadlines are printed in the configured zone
+	assert.Contains(t, message.Body, "8 Juli 2025 10:00 WIB")
+	assert.Contains(t, message.Body, "PT Loan Engine")
+	require.Len(t, message.Attachments, 1)
+	assert.Equal(t, "investment_agreement.pdf", message.Attachments[0].FileName)
+	assert.Equal(t, pdf, message.Attachments[0].Content)
+}
+
+func TestNotify_FollowsPreferences(t *testing.T) {
+	mockRepo := mocksRepo.NewNotificationRepository(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	mailer := &recordingMailer{}
+	texter := &recordingTexter{}
+	notifier := NewNotifier(mockRepo, store, mailer, texter, Config{CompanyName: "PT Loan Engine", Location: jakarta})
+
+	mockRepo.On("GetPreferences", mock.Anything, "investor", testInvestor.UserID).Return([]models.NotificationPreference{
+		{NotificationType: "INVESTMENT_AGREEMENT", Email: false, SMS: true},
+	}, nil)
+	mockRepo.On("GetRecipient", mock.Anything, "investor", testInvestor.UserID).Return(testInvestor, nil)
+
+	err = notifier.Notify(context.Background(), agreementNotification())
+
+	require.NoError(t, err)
+	assert.Empty(t, mailer.messages)
+	require.Len(t, texter.messages, 1)
+	assert.Equal(t, "081298765432", texter.messages[0].To)
+	assert.Equal(t, "PT Loan Engine: investasi Rp 2.000.000,00 pada pinjaman 7C9E6679 tercatat. Setujui perjanjian di aplikasi sebelum 8 Juli 2025 10:00 WIB.", texter.messages[0].Body)
+}
+
+func TestNotify_AllChannelsOff(t *testing.T) {
+	mockRepo := mocksRepo.NewNotificationRepository(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	mailer := &recordingMailer{}
+	texter := &recordingTexter{}
+	notifier := NewNotifier(mockRepo, store, mailer, texter, Config{CompanyName: "PT Loan Engine", Location: jakarta})
+
+	mockRepo.On("GetPreferences", mock.Anything, "investor", testInvestor.UserID).Return([]models.NotificationPreference{
+		{NotificationType: "INVESTMENT_AGREEMENT"},
+	}, nil)
+
+	err = notifier.Notify(context.Background(), agreementNotification())
+
+	require.NoError(t, err)
+	assert.Empty(t, mailer.messages)
+	mockRepo.AssertNotCalled(t, "GetRecipient", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotify_ChannelsFailIndependently(t *testing.T) {
+	mockRepo := mocksRepo.NewNotificationRepository(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	mailer := &recordingMailer{}
+	texter := &recordingTexter{err: errors.New("gateway unavailable")}
+	notifier := NewNotifier(mockRepo, store, mailer, texter, Config{CompanyName: "PT Loan Engine", Location: jakarta})
+
+	mockRepo.On("GetPreferences", mock.Anything, "investor", testInvestor.UserID).Return([]models.NotificationPreference{
+		{NotificationType: "LOAN_FULLY_INVESTED", Email: true, SMS: true},
+	}, nil)
+	mockRepo.On("GetRecipient", mock.Anything, "investor", testInvestor.UserID).Return(testInvestor, nil)
+
+	err = notifier.Notify(context.Background(), &Notification{
+		Type:     "LOAN_FULLY_INVESTED",
+		UserID:   testInvestor.UserID,
+		UserType: "investor",
+		Data: LoanFullyInvestedData{
+			LoanID:           uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"),
+			PrincipalAmount:  5000000,
+			InvestorCount:    2,
+			InvestmentAmount: 3000000,
+			ExpectedReturn:   240000,
+		},
+	})
+
+	assert.ErrorContains(t, err, "failed to send LOAN_FULLY_INVESTED sms: gateway unavailable")
+	require.Len(t, mailer.messages, 1)
+	assert.Equal(t, "Pinjaman 7C9E6679 telah terdanai penuh", mailer.messages[0].Subject)
+	assert.Contains(t, mailer.messages[0].Body, "oleh 2 investor")
+}
+
+func TestTemplates_EveryTypeHasEveryChannel(t *testing.T) {
+	templates := parseTemplates(time.UTC)
+	data := templateData{
+		Company:   "PT Loan Engine",
+		Recipient: &models.NotificationRecipient{FullName: "Rina"},
+	}
+
+	samples := map[string]interface{}{
+		"INVESTMENT_AGREEMENT": InvestmentAgreementData{LoanID: uuid.New()},
+		"LOAN_FULLY_INVESTED":  LoanFullyInvestedData{LoanID: uuid.New()},
+	}
+	for _, preference := range DefaultPreferences {
+		data.Data = samples[preference.NotificationType]
+		require.NotNil(t, data.Data, preference.NotificationType)
+
+		subject, body, err := renderEmail(templates, preference.NotificationType, data)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, subject)
+		assert.NotEmpty(t, body)
+
+		text, err := render(templates, preference.NotificationType, "sms", data)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(text), 320, "%s sms should fit two segments", preference.NotificationType)
+	}
+}
+
+func TestPreferences_SavedOverDefaults(t *testing.T) {
+	preferences := Preferences([]models.NotificationPreference{
+		{NotificationType: "LOAN_FULLY_INVESTED", Email: false, SMS: true},
+	})
+
+	assert.Equal(t, []models.NotificationPreference{
+		{NotificationType: "INVESTMENT_AGREEMENT", Email: true},
+		{NotificationType: "LOAN_FULLY_INVESTED", SMS: true},
+	}, preferences)
+}
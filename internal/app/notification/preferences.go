@@ -0,0 +1,38 @@
+package notification
+
+import (
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+)
+
+// DefaultPreferences apply to notification types a user never changed. Every
+// notification goes by email, SMS is opt-in.
+var DefaultPreferences = []models.NotificationPreference{
+	{NotificationType: constants.NOTIFICATION_INVESTMENT_AGREEMENT, Email: true},
+	{NotificationType: constants.NOTIFICATION_LOAN_FULLY_INVESTED, Email: true},
+}
+
+// Preferences lays what a user saved over the defaults, one entry per
+// notification type.
+func Preferences(saved []models.NotificationPreference) []models.NotificationPreference {
+	preferences := make([]models.NotificationPreference, 0, len(DefaultPreferences))
+	for _, preference := range DefaultPreferences {
+		preferences = append(preferences, PreferenceFor(saved, preference.NotificationType))
+	}
+	return preferences
+}
+
+// PreferenceFor is the user's preference for one notification type.
+func PreferenceFor(saved []models.NotificationPreference, notificationType string) models.NotificationPreference {
+	for _, preference := range saved {
+		if preference.NotificationType == notificationType {
+			return preference
+		}
+	}
+	for _, preference := range DefaultPreferences {
+		if preference.NotificationType == notificationType {
+			return preference
+		}
+	}
+	return models.NotificationPreference{NotificationType: notificationType}
+}
@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdf"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Templates are named <notification type>.<channel>.tmpl in lower case.
+// Email templates start with a "Subject: " line and a blank line.
+//
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+const subjectPrefix = "Subject: "
+
+// templateData is what every template sees.
+type templateData struct {
+	Company   string
+	Recipient *models.NotificationRecipient
+	Data      interface{}
+}
+
+// InvestmentAgreementData is sent with every new investment.
+type InvestmentAgreementData struct {
+	InvestmentID       uuid.UUID
+	LoanID             uuid.UUID
+	Tranche            int
+	InvestmentAmount   float64
+	ExpectedReturn     float64
+	ROIRate            float64
+	AcceptanceDeadline time.Time
+	AgreementURL       string
+	VerificationID     string
+}
+
+// LoanFullyInvestedData is sent to each investor once a loan is funded.
+type LoanFullyInvestedData struct {
+	LoanID           uuid.UUID
+	PrincipalAmount  float64
+	InvestorCount    int
+	InvestmentAmount float64
+	ExpectedReturn   float64
+}
+
+func parseTemplates(location *time.Location) *template.Template {
+	locale := constants.LOCALE_INDONESIAN
+	funcs := template.FuncMap{
+		"rupiah":  pdf.FormatRupiah,
+		"percent": func(rate float64) string { return pdf.FormatPercent(rate, locale) },
+		"date":    func(t time.Time) string { return pdf.FormatDate(t.In(location), locale) },
+		"datetime": func(t time.Time) string {
+			return pdf.FormatDate(t.In(location), locale) + t.In(location).Format(" 15:04 MST")
+		},
+		"short": func(id uuid.UUID) string { return strings.ToUpper(id.String()[:8]) },
+	}
+
+	return template.Must(template.New("").Option("missingkey=error").Funcs(funcs).ParseFS(templateFiles, "templates/*.tmpl"))
+}
+
+func templateName(notificationType, channel string) string {
+	return fmt.Sprintf("%s.%s.tmpl", strings.ToLower(notificationType), channel)
+}
+
+func render(templates *template.Template, notificationType, channel string, data templateData) (string, error) {
+	name := templateName(notificationType, channel)
+	tmpl := templates.Lookup(name)
+	if tmpl == nil {
+		return "", fmt.Errorf("notification template not found: %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func renderEmail(templates *template.Template, notificationType string, data templateData) (string, string, error) {
+	rendered, err := render(templates, notificationType, constants.CHANNEL_EMAIL, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject, body, _ := strings.Cut(rendered, "\n")
+	if !strings.HasPrefix(subject, subjectPrefix) {
+		return "", "", fmt.Errorf("email template %s has no subject line", templateName(notificationType, constants.CHANNEL_EMAIL))
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(subject, subjectPrefix)), strings.TrimSpace(body) + "\n", nil
+}
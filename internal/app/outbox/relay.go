@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"context"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Publisher hands an event to whoever reacts to it. Events are delivered at
+// least once, a publisher may see the same event_id again after a crash or a
+// failed attempt.
+type Publisher interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// PublisherFunc lets a function publish events.
+type PublisherFunc func(ctx context.Context, event *models.OutboxEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+type publishers []Publisher
+
+// Publishers hands each event to every publisher in turn. The first failure
+// stops it and the event is retried with all of them, so publishers that come
+// first see it again.
+func Publishers(list ...Publisher) Publisher {
+	return publishers(list)
+}
+
+func (p publishers) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Relay publishes what the outbox holds.
+type Relay interface {
+	// PublishPending publishes due events until none are left and returns how
+	// many went out. Events of one loan go out in the order they were saved,
+	// a failing event holds back the ones after it until it is retried.
+	PublishPending(ctx context.Context) (int, error)
+}
+
+type Config struct {
+	// BatchSize is how many events are claimed at once
+	BatchSize int
+	// Lease is how long a claimed event is kept from other relays
+	Lease time.Duration
+	// RetryBase is the wait after the first failure, doubled on each one after
+	RetryBase time.Duration
+	// RetryMax caps the wait between attempts
+	RetryMax time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.Lease <= 0 {
+		c.Lease = time.Minute
+	}
+	if c.RetryBase <= 0 {
+		c.RetryBase = 5 * time.Second
+	}
+	if c.RetryMax <= 0 {
+		c.RetryMax = 10 * time.Minute
+	}
+	return c
+}
+
+type relay struct {
+	repo      repositories.OutboxRepository
+	publisher Publisher
+	config    Config
+	now       func() time.Time
+}
+
+func NewRelay(repo repositories.OutboxRepository, publisher Publisher, config Config) Relay {
+	return &relay{
+		repo:      repo,
+		publisher: publisher,
+		config:    config.withDefaults(),
+		now:       time.Now,
+	}
+}
+
+func (r *relay) PublishPending(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		now := r.now()
+		events, err := r.repo.ClaimEvents(ctx, now, now.Add(r.config.Lease), r.config.BatchSize)
+		if err != nil {
+			return published, err
+		}
+		if len(events) == 0 {
+			return published, nil
+		}
+
+		for i := range events {
+			event := &events[i]
+			if err := r.publisher.Publish(ctx, event); err != nil {
+				r.retryLater(ctx, event, err)
+				continue
+			}
+
+			if err := r.repo.MarkPublished(ctx, event.ID, r.now()); err != nil {
+				// The lease runs out and the event goes out again
+				return published, err
+			}
+			published++
+		}
+	}
+}
+
+func (r *relay) retryLater(ctx context.Context, event *models.OutboxEvent, cause error) {
+	attempts := event.Attempts + 1
+	nextAttemptAt := r.now().Add(RetryDelay(attempts, r.config.RetryBase, r.config.RetryMax))
+
+	log.Warn().Err(cause).
+		Str("event_id", event.EventID.String()).
+		Str("event_type", event.EventType).
+		Int("attempts", attempts).
+		Time("next_attempt_at", nextAttemptAt).
+		Msg("Failed to publish outbox event")
+
+	if err := r.repo.MarkFailed(ctx, event.ID, nextAttemptAt, cause.Error()); err != nil {
+		log.Warn().Err(err).Str("event_id", event.EventID.String()).Msg("Failed to reschedule outbox event")
+	}
+}
+
+// RetryDelay is the wait before the next attempt after attempts failures:
+// base, doubled each time, never more than maxDelay.
+func RetryDelay(attempts int, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
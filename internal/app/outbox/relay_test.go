@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type recordingPublisher struct {
+	published []string
+	failures  map[string]error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	if err := p.failures[event.EventType]; err != nil {
+		return err
+	}
+	p.published = append(p.published, event.EventType)
+	return nil
+}
+
+func newTestRelay(repo *mocksRepo.OutboxRepository, publisher Publisher, now time.Time) *relay {
+	r := NewRelay(repo, publisher, Config{BatchSize: 10, Lease: time.Minute, RetryBase: 5 * time.Second, RetryMax: time.Minute}).(*relay)
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func TestPublishPending_PublishesInClaimOrder(t *testing.T) {
+	repo := mocksRepo.NewOutboxRepository(t)
+	publisher := &recordingPublisher{}
+	now := time.Date(2025, 7, 6, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, publisher, now)
+
+	loanID := uuid.New()
+	first := []models.OutboxEvent{{ID: 1, EventID: uuid.New(), AggregateID: loanID, EventType: "LoanProposed"}}
+	second := []models.OutboxEvent{{ID: 2, EventID: uuid.New(), AggregateID: loanID, EventType: "LoanApproved"}}
+
+	// The next event of a loan is only claimed once the one before is out
+	repo.On("ClaimEvents", mock.Anything, now, now.Add(time.Minute), 10).Return(first, nil).Once()
+	repo.On("MarkPublished", mock.Anything, int64(1), now).Return(nil).Once()
+	repo.On("ClaimEvents", mock.Anything, now, now.Add(time.Minute), 10).Return(second, nil).Once()
+	repo.On("MarkPublished", mock.Anything, int64(2), now).Return(nil).Once()
+	repo.On("ClaimEvents", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.OutboxEvent{}, nil).Once()
+
+	published, err := relay.PublishPending(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []string{"LoanProposed", "LoanApproved"}, publisher.published)
+}
+
+func TestPublishPending_FailedEventIsRetriedLater(t *testing.T) {
+	repo := mocksRepo.NewOutboxRepository(t)
+	publisher := &recordingPublisher{failures: map[string]error{"LoanDisbursed": errors.New("broker unavailable")}}
+	now := time.Date(2025, 7, 6, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, publisher, now)
+
+	events := []models.OutboxEvent{
+		{ID: 7, EventID: uuid.New(), AggregateID: uuid.New(), EventType: "LoanDisbursed", Attempts: 2},
+		{ID: 9, EventID: uuid.New(), AggregateID: uuid.New(), EventType: "InvestmentCreated"},
+	}
+
+	repo.On("ClaimEvents", mock.Anything, now, now.Add(time.Minute), 10).Return(events, nil).Once()
+	// Third failure waits 5s doubled twice
+	repo.On("MarkFailed", mock.Anything, int64(7), now.Add(20*time.Second), "broker unavailable").Return(nil).Once()
+	repo.On("MarkPublished", mock.Anything, int64(9), now).Return(nil).Once()
+	repo.On("ClaimEvents", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.OutboxEvent{}, nil).Once()
+
+	published, err := relay.PublishPending(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []string{"InvestmentCreated"}, publisher.published)
+}
+
+func TestPublishPending_StopsWhenEventCannotBeMarked(t *testing.T) {
+	repo := mocksRepo.NewOutboxRepository(t)
+	publisher := &recordingPublisher{}
+	now := time.Date(2025, 7, 6, 10, 0, 0, 0, time.UTC)
+	relay := newTestRelay(repo, publisher, now)
+
+	events := []models.OutboxEvent{{ID: 3, EventID: uuid.New(), AggregateID: uuid.New(), EventType: "LoanFullyFunded"}}
+	repo.On("ClaimEvents", mock.Anything, now, now.Add(time.Minute), 10).Return(events, nil).Once()
+	repo.On("MarkPublished", mock.Anything, int64(3), now).Return(errors.New("connection reset")).Once()
+
+	published, err := relay.PublishPending(context.Background())
+
+	// The lease runs out and the event is published again
+	assert.Error(t, err)
+	assert.Equal(t, 0, published)
+}
+
+func TestPublishers_StopAtFirstFailure(t *testing.T) {
+	first := &recordingPublisher{}
+	second := &recordingPublisher{failures: map[string]error{"LoanFullyFunded": errors.New("mail server down")}}
+	var third []string
+	publisher := Publishers(first, second, PublisherFunc(func(ctx context.Context, event *models.OutboxEvent) error {
+		third = append(third, event.EventType)
+		return nil
+	}))
+
+	err := publisher.Publish(context.Background(), &models.OutboxEvent{EventType: "InvestmentCreated"})
+	assert.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), &models.OutboxEvent{EventType: "LoanFullyFunded"})
+	assert.EqualError(t, err, "mail server down")
+
+	assert.Equal(t, []string{"InvestmentCreated", "LoanFullyFunded"}, first.published)
+	assert.Equal(t, []string{"InvestmentCreated"}, second.published)
+	assert.Equal(t, []string{"InvestmentCreated"}, third)
+}
+
+func TestRetryDelay(t *testing.T) {
+	base := 5 * time.Second
+	maxDelay := time.Minute
+
+	assert.Equal(t, 5*time.Second, RetryDelay(1, base, maxDelay))
+	assert.Equal(t, 10*time.Second, RetryDelay(2, base, maxDelay))
+	assert.Equal(t, 40*time.Second, RetryDelay(4, base, maxDelay))
+	assert.Equal(t, time.Minute, RetryDelay(5, base, maxDelay))
+	assert.Equal(t, time.Minute, RetryDelay(100, base, maxDelay))
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type AccessRepository interface {
+	GetRolePermissions(ctx context.Context) (map[string][]string, error)
+	GetEmployeeScope(ctx context.Context, employeeID uuid.UUID) (*models.EmployeeScope, error)
+	GetLoanScope(ctx context.Context, loanID uuid.UUID) (*models.LoanScope, error)
+}
+
+type accessRepository struct {
+	db database.Querier
+}
+
+func NewAccessRepository(db database.Querier) AccessRepository {
+	return &accessRepository{
+		db: db,
+	}
+}
+
+func (r *accessRepository) GetRolePermissions(ctx context.Context) (map[string][]string, error) {
+	query := `SELECT role, permission FROM role_permissions ORDER BY role, permission`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := make(map[string][]string)
+	for rows.Next() {
+		var role, permission string
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, fmt.Errorf("failed to scan role permission: %w", err)
+		}
+		permissions[role] = append(permissions[role], permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read role permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+func (r *accessRepository) GetEmployeeScope(ctx context.Context, employeeID uuid.UUID) (*models.EmployeeScope, error) {
+	query := `
+		SELECT id, employee_role, COALESCE(branch, ''), is_active
+		FROM employees
+		WHERE id = $1
+	`
+
+	var scope models.EmployeeScope
+	err := r.db.QueryRow(ctx, query, employeeID).Scan(
+		&scope.ID,
+		&scope.Role,
+		&scope.Branch,
+		&scope.IsActive,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("employee not found")
+		}
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	return &scope, nil
+}
+
+func (r *accessRepository) GetLoanScope(ctx context.Context, loanID uuid.UUID) (*models.LoanScope, error) {
+	query := `
+		SELECT l.id, COALESCE(b.branch, ''), l.current_state, l.assigned_validator_employee_id
+		FROM loans l
+		JOIN borrowers b ON l.borrower_id = b.id
+		WHERE l.id = $1
+	`
+
+	var scope models.LoanScope
+	err := r.db.QueryRow(ctx, query, loanID).Scan(
+		&scope.ID,
+		&scope.BorrowerBranch,
+		&scope.CurrentState,
+		&scope.AssignedValidatorEmployeeID,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan not found")
+		}
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	return &scope, nil
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type AgreementTemplateRepository interface {
+	PublishTemplate(ctx context.Context, agreementTemplate *models.AgreementTemplate) error
+	GetLatestTemplate(ctx context.Context, templateType string) (*models.AgreementTemplate, error)
+	GetTemplate(ctx context.Context, templateType string, version int) (*models.AgreementTemplate, error)
+	ListTemplates(ctx context.Context, templateType string) ([]models.AgreementTemplate, error)
+	GetLoanTemplateVersion(ctx context.Context, loanID uuid.UUID, documentType string) (int, error)
+}
+
+type agreementTemplateRepository struct {
+	db database.Querier
+}
+
+func NewAgreementTemplateRepository(db database.Querier) AgreementTemplateRepository {
+	return &agreementTemplateRepository{
+		db: db,
+	}
+}
+
+const templateSelect = `
+	SELECT id, template_type, version, body, change_notes, created_by_id, created_at
+	FROM agreement_templates
+`
+
+// PublishTemplate stores the template as the next version of its type.
+func (r *agreementTemplateRepository) PublishTemplate(ctx context.Context, agreementTemplate *models.AgreementTemplate) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialise version numbering per template type
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('agreement_templates:' || $1))`, agreementTemplate.TemplateType); err != nil {
+		return fmt.Errorf("failed to lock agreement templates: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO agreement_templates (id, template_type, version, body, change_notes, created_by_id, created_at)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, NULLIF($4, ''), $5, $6
+		FROM agreement_templates
+		WHERE template_type = $2
+		RETURNING version
+	`,
+		agreementTemplate.ID,
+		agreementTemplate.TemplateType,
+		agreementTemplate.Body,
+		agreementTemplate.ChangeNotes,
+		agreementTemplate.CreatedByID,
+		agreementTemplate.CreatedAt,
+	).Scan(&agreementTemplate.Version)
+	if err != nil {
+		return fmt.Errorf("failed to publish agreement template: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *agreementTemplateRepository) GetLatestTemplate(ctx context.Context, templateType string) (*models.AgreementTemplate, error) {
+	query := templateSelect + `
+		WHERE template_type = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	return r.getTemplate(ctx, query, templateType)
+}
+
+func (r *agreementTemplateRepository) GetTemplate(ctx context.Context, templateType string, version int) (*models.AgreementTemplate, error) {
+	query := templateSelect + ` WHERE template_type = $1 AND version = $2`
+
+	return r.getTemplate(ctx, query, templateType, version)
+}
+
+func (r *agreementTemplateRepository) getTemplate(ctx context.Context, query string, args ...interface{}) (*models.AgreementTemplate, error) {
+	agreementTemplate, err := scanTemplate(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("agreement template not found")
+		}
+		return nil, fmt.Errorf("failed to get agreement template: %w", err)
+	}
+
+	return agreementTemplate, nil
+}
+
+// ListTemplates returns every version, newest first, of one type or of all
+// types when templateType is empty.
+func (r *agreementTemplateRepository) ListTemplates(ctx context.Context, templateType string) ([]models.AgreementTemplate, error) {
+	query := templateSelect + `
+		WHERE ($1 = '' OR template_type = $1)
+		ORDER BY template_type, version DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, templateType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agreement templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []models.AgreementTemplate{}
+	for rows.Next() {
+		agreementTemplate, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan agreement template: %w", err)
+		}
+		templates = append(templates, *agreementTemplate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list agreement templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// GetLoanTemplateVersion returns the template version of the loan's first
+// document of the type, or 0 when there is none yet. Agreements generated
+// before templates existed count as version 1, which carries the same text.
+func (r *agreementTemplateRepository) GetLoanTemplateVersion(ctx context.Context, loanID uuid.UUID, documentType string) (int, error) {
+	query := `
+		SELECT COALESCE(template_version, 1)
+		FROM documents
+		WHERE loan_id = $1 AND document_type = $2
+		ORDER BY created_at, version
+		LIMIT 1
+	`
+
+	var version int
+	err := r.db.QueryRow(ctx, query, loanID, documentType).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get loan template version: %w", err)
+	}
+
+	return version, nil
+}
+
+func scanTemplate(row pgx.Row) (*models.AgreementTemplate, error) {
+	var agreementTemplate models.AgreementTemplate
+	var changeNotes sql.NullString
+
+	err := row.Scan(
+		&agreementTemplate.ID,
+		&agreementTemplate.TemplateType,
+		&agreementTemplate.Version,
+		&agreementTemplate.Body,
+		&changeNotes,
+		&agreementTemplate.CreatedByID,
+		&agreementTemplate.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	agreementTemplate.ChangeNotes = changeNotes.String
+	return &agreementTemplate, nil
+}
@@ -0,0 +1,297 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type APIKeyRepository interface {
+	CreateServicePrincipal(ctx context.Context, principal *models.ServicePrincipal, createdBy uuid.UUID) error
+	ListServicePrincipals(ctx context.Context) ([]models.ServicePrincipal, error)
+	GetServicePrincipal(ctx context.Context, id uuid.UUID) (*models.ServicePrincipal, error)
+	CreateAPIKey(ctx context.Context, key *models.APIKey, createdBy uuid.UUID) error
+	ListAPIKeys(ctx context.Context, principalID uuid.UUID) ([]models.APIKey, error)
+	GetAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error)
+	GetCredentialByPrefix(ctx context.Context, prefix string) (*models.APIKeyCredential, error)
+	RotateAPIKey(ctx context.Context, oldID uuid.UUID, oldExpiresAt time.Time, newKey *models.APIKey, createdBy uuid.UUID) error
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db database.Querier
+}
+
+func NewAPIKeyRepository(db database.Querier) APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = `
+	id, service_principal_id, key_prefix, key_hash, scopes,
+	expires_at, revoked_at, last_used_at, rotated_from_id, created_at
+`
+
+func scanAPIKey(row pgx.Row, key *models.APIKey, extra ...interface{}) error {
+	dest := []interface{}{
+		&key.ID,
+		&key.ServicePrincipalID,
+		&key.KeyPrefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.LastUsedAt,
+		&key.RotatedFromID,
+		&key.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func (r *apiKeyRepository) CreateServicePrincipal(ctx context.Context, principal *models.ServicePrincipal, createdBy uuid.UUID) error {
+	executor, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support exec")
+	}
+
+	_, err := executor.Exec(ctx, `
+		INSERT INTO service_principals (id, name, description, is_active, created_by_employee_id, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	`,
+		principal.ID,
+		principal.Name,
+		principal.Description,
+		principal.IsActive,
+		createdBy,
+		principal.CreatedAt,
+		principal.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("service principal already exists")
+		}
+		return fmt.Errorf("failed to create service principal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) ListServicePrincipals(ctx context.Context) ([]models.ServicePrincipal, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, COALESCE(description, ''), is_active, created_at, updated_at
+		FROM service_principals
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service principals: %w", err)
+	}
+	defer rows.Close()
+
+	principals := []models.ServicePrincipal{}
+	for rows.Next() {
+		var principal models.ServicePrincipal
+		if err := rows.Scan(&principal.ID, &principal.Name, &principal.Description, &principal.IsActive, &principal.CreatedAt, &principal.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan service principal: %w", err)
+		}
+		principals = append(principals, principal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list service principals: %w", err)
+	}
+
+	return principals, nil
+}
+
+func (r *apiKeyRepository) GetServicePrincipal(ctx context.Context, id uuid.UUID) (*models.ServicePrincipal, error) {
+	var principal models.ServicePrincipal
+	err := r.db.QueryRow(ctx, `
+		SELECT id, name, COALESCE(description, ''), is_active, created_at, updated_at
+		FROM service_principals
+		WHERE id = $1
+	`, id).Scan(&principal.ID, &principal.Name, &principal.Description, &principal.IsActive, &principal.CreatedAt, &principal.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("service principal not found")
+		}
+		return nil, fmt.Errorf("failed to get service principal: %w", err)
+	}
+
+	return &principal, nil
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey, createdBy uuid.UUID) error {
+	executor, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support exec")
+	}
+
+	return insertAPIKey(ctx, executor, key, createdBy)
+}
+
+func (r *apiKeyRepository) ListAPIKeys(ctx context.Context, principalID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE service_principal_id = $1
+		ORDER BY created_at DESC
+	`, principalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	err := scanAPIKey(r.db.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id), &key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetCredentialByPrefix(ctx context.Context, prefix string) (*models.APIKeyCredential, error) {
+	query := `
+		SELECT k.id, k.service_principal_id, k.key_prefix, k.key_hash, k.scopes,
+		       k.expires_at, k.revoked_at, k.last_used_at, k.rotated_from_id, k.created_at,
+		       sp.name, sp.is_active
+		FROM api_keys k
+		JOIN service_principals sp ON sp.id = k.service_principal_id
+		WHERE k.key_prefix = $1
+	`
+
+	var credential models.APIKeyCredential
+	err := scanAPIKey(r.db.QueryRow(ctx, query, prefix), &credential.APIKey, &credential.PrincipalName, &credential.PrincipalIsActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return &credential, nil
+}
+
+// RotateAPIKey issues the replacement key and shortens the old key's expiry to
+// the grace period in one transaction.
+func (r *apiKeyRepository) RotateAPIKey(ctx context.Context, oldID uuid.UUID, oldExpiresAt time.Time, newKey *models.APIKey, createdBy uuid.UUID) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE api_keys
+		SET expires_at = LEAST(expires_at, $2)
+		WHERE id = $1 AND revoked_at IS NULL
+	`, oldID, oldExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to expire api key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("api key not found")
+	}
+
+	if err := insertAPIKey(ctx, tx, newKey, createdBy); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	executor, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support exec")
+	}
+
+	result, err := executor.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("api key not found")
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	executor, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support exec")
+	}
+
+	if _, err := executor.Exec(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt); err != nil {
+		return fmt.Errorf("failed to update api key usage: %w", err)
+	}
+
+	return nil
+}
+
+func insertAPIKey(ctx context.Context, executor database.Executor, key *models.APIKey, createdBy uuid.UUID) error {
+	_, err := executor.Exec(ctx, `
+		INSERT INTO api_keys (
+			id, service_principal_id, key_prefix, key_hash, scopes,
+			expires_at, rotated_from_id, created_by_employee_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		key.ID,
+		key.ServicePrincipalID,
+		key.KeyPrefix,
+		key.KeyHash,
+		key.Scopes,
+		key.ExpiresAt,
+		key.RotatedFromID,
+		createdBy,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
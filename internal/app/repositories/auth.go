@@ -3,17 +3,21 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type AuthRepository interface {
 	GetEmployeeByEmail(ctx context.Context, email string) (uuid.UUID, *models.EmployeeProfile, string, error)
 	GetBorrowerByEmail(ctx context.Context, email string) (uuid.UUID, *models.BorrowerProfile, string, error)
 	GetInvestorByEmail(ctx context.Context, email string) (uuid.UUID, *models.InvestorProfile, string, error)
+	GetEmployeeByID(ctx context.Context, id uuid.UUID) (*models.EmployeeProfile, error)
+	GetInvestorByID(ctx context.Context, id uuid.UUID) (*models.InvestorProfile, error)
 }
 
 type authRepository struct {
@@ -119,3 +123,56 @@ func (r *authRepository) GetInvestorByEmail(ctx context.Context, email string) (
 
 	return id, &profile, passwordHash, nil
 }
+
+func (r *authRepository) GetEmployeeByID(ctx context.Context, id uuid.UUID) (*models.EmployeeProfile, error) {
+	query := `
+		SELECT username, email, full_name, employee_role, department, is_active
+		FROM employees
+		WHERE id = $1 AND is_active = true
+	`
+
+	var profile models.EmployeeProfile
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&profile.Username,
+		&profile.Email,
+		&profile.FullName,
+		&profile.EmployeeRole,
+		&profile.Department,
+		&profile.IsActive,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("employee not found")
+		}
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	return &profile, nil
+}
+
+func (r *authRepository) GetInvestorByID(ctx context.Context, id uuid.UUID) (*models.InvestorProfile, error) {
+	query := `
+		SELECT full_name, email, phone_number, identity_number, is_active
+		FROM investors
+		WHERE id = $1 AND is_active = true
+	`
+
+	var profile models.InvestorProfile
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&profile.FullName,
+		&profile.Email,
+		&profile.PhoneNumber,
+		&profile.IdentityNumber,
+		&profile.IsActive,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("investor not found")
+		}
+		return nil, fmt.Errorf("failed to get investor: %w", err)
+	}
+
+	return &profile, nil
+}
@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type BankVerificationRepository interface {
+	// GetLoanBankAccount returns the borrower's current bank account, the
+	// bank fields are empty when the borrower has none.
+	GetLoanBankAccount(ctx context.Context, loanID uuid.UUID) (*models.LoanBankAccount, error)
+	CreateVerification(ctx context.Context, verification *models.BankAccountVerification) error
+	GetLatestVerification(ctx context.Context, loanID uuid.UUID) (*models.BankAccountVerification, error)
+	ListVerifications(ctx context.Context, loanID uuid.UUID) ([]models.BankAccountVerification, error)
+	// OverrideVerification accepts a MISMATCHED verification.
+	OverrideVerification(ctx context.Context, verificationID, employeeID uuid.UUID, reason string, overriddenAt time.Time) error
+}
+
+type bankVerificationRepository struct {
+	db database.Querier
+}
+
+func NewBankVerificationRepository(db database.Querier) BankVerificationRepository {
+	return &bankVerificationRepository{
+		db: db,
+	}
+}
+
+func (r *bankVerificationRepository) GetLoanBankAccount(ctx context.Context, loanID uuid.UUID) (*models.LoanBankAccount, error) {
+	query := `
+		SELECT l.id, b.id, b.full_name, COALESCE(b.bank_name, ''), COALESCE(b.bank_account_number, '')
+		FROM loans l
+		JOIN borrowers b ON b.id = l.borrower_id
+		WHERE l.id = $1
+	`
+
+	var account models.LoanBankAccount
+	err := r.db.QueryRow(ctx, query, loanID).Scan(
+		&account.LoanID,
+		&account.BorrowerID,
+		&account.BorrowerName,
+		&account.BankName,
+		&account.BankAccountNumber,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan not found")
+		}
+		return nil, fmt.Errorf("failed to get loan bank account: %w", err)
+	}
+
+	return &account, nil
+}
+
+func (r *bankVerificationRepository) CreateVerification(ctx context.Context, verification *models.BankAccountVerification) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	query := `
+		INSERT INTO bank_account_verifications (
+			id, loan_id, borrower_id, bank_name, bank_account_number, borrower_name,
+			account_holder_name, match_score, match_threshold, status, provider,
+			checked_by_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := db.Exec(ctx, query,
+		verification.ID,
+		verification.LoanID,
+		verification.BorrowerID,
+		verification.BankName,
+		verification.BankAccountNumber,
+		verification.BorrowerName,
+		verification.AccountHolderName,
+		verification.MatchScore,
+		verification.MatchThreshold,
+		verification.Status,
+		verification.Provider,
+		verification.CheckedByID,
+		verification.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bank verification: %w", err)
+	}
+
+	return nil
+}
+
+const bankVerificationColumns = `
+	id, loan_id, borrower_id, bank_name, bank_account_number, borrower_name,
+	COALESCE(account_holder_name, ''), match_score, match_threshold, status, provider,
+	checked_by_id, COALESCE(override_reason, ''), overridden_by_id, overridden_at, created_at`
+
+func scanBankVerification(row pgx.Row, verification *models.BankAccountVerification) error {
+	return row.Scan(
+		&verification.ID,
+		&verification.LoanID,
+		&verification.BorrowerID,
+		&verification.BankName,
+		&verification.BankAccountNumber,
+		&verification.BorrowerName,
+		&verification.AccountHolderName,
+		&verification.MatchScore,
+		&verification.MatchThreshold,
+		&verification.Status,
+		&verification.Provider,
+		&verification.CheckedByID,
+		&verification.OverrideReason,
+		&verification.OverriddenByID,
+		&verification.OverriddenAt,
+		&verification.CreatedAt,
+	)
+}
+
+func (r *bankVerificationRepository) GetLatestVerification(ctx context.Context, loanID uuid.UUID) (*models.BankAccountVerification, error) {
+	query := `
+		SELECT ` + bankVerificationColumns + `
+		FROM bank_account_verifications
+		WHERE loan_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var verification models.BankAccountVerification
+	if err := scanBankVerification(r.db.QueryRow(ctx, query, loanID), &verification); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("bank verification not found")
+		}
+		return nil, fmt.Errorf("failed to get bank verification: %w", err)
+	}
+
+	return &verification, nil
+}
+
+func (r *bankVerificationRepository) ListVerifications(ctx context.Context, loanID uuid.UUID) ([]models.BankAccountVerification, error) {
+	query := `
+		SELECT ` + bankVerificationColumns + `
+		FROM bank_account_verifications
+		WHERE loan_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bank verifications: %w", err)
+	}
+	defer rows.Close()
+
+	verifications := []models.BankAccountVerification{}
+	for rows.Next() {
+		var verification models.BankAccountVerification
+		if err := scanBankVerification(rows, &verification); err != nil {
+			return nil, fmt.Errorf("failed to scan bank verification: %w", err)
+		}
+		verifications = append(verifications, verification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate bank verifications: %w", err)
+	}
+
+	return verifications, nil
+}
+
+func (r *bankVerificationRepository) OverrideVerification(ctx context.Context, verificationID, employeeID uuid.UUID, reason string, overriddenAt time.Time) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	query := `
+		UPDATE bank_account_verifications
+		SET status = $2, override_reason = $3, overridden_by_id = $4, overridden_at = $5
+		WHERE id = $1 AND status = $6
+	`
+
+	result, err := db.Exec(ctx, query, verificationID, constants.BANK_VERIFICATION_OVERRIDDEN, reason, employeeID, overriddenAt,
+		constants.BANK_VERIFICATION_MISMATCHED)
+	if err != nil {
+		return fmt.Errorf("failed to override bank verification: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("bank verification cannot be overridden")
+	}
+
+	return nil
+}
@@ -0,0 +1,404 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type DocumentRepository interface {
+	GetDocument(ctx context.Context, documentID uuid.UUID) (*models.Document, error)
+	GetDocumentByFileName(ctx context.Context, fileName string) (*models.Document, error)
+	ListDocuments(ctx context.Context, loanID uuid.UUID, includeDeleted bool) ([]models.Document, error)
+	SoftDeleteDocument(ctx context.Context, documentID, deletedByID uuid.UUID, reason string) error
+	CreateDocument(ctx context.Context, document *models.Document) error
+	ListPendingDocuments(ctx context.Context, limit int) ([]models.Document, error)
+	UpdateDocumentScan(ctx context.Context, documentID uuid.UUID, status, signature, storageKey string) error
+	GetDocumentByVerificationID(ctx context.Context, verificationID string) (*models.Document, error)
+}
+
+type documentRepository struct {
+	db database.Querier
+}
+
+func NewDocumentRepository(db database.Querier) DocumentRepository {
+	return &documentRepository{
+		db: db,
+	}
+}
+
+const documentSelect = `
+	SELECT d.id, d.loan_id, d.investment_id, d.document_type, d.file_name, d.storage_key,
+	       d.content_type, d.size_bytes, d.sha256, d.version, d.uploaded_by_id, d.uploaded_by_type,
+	       d.created_at, d.deleted_at, d.deleted_by_id, d.deleted_reason,
+	       d.scan_status, d.scan_signature, d.scanned_at,
+	       d.gps_latitude, d.gps_longitude, d.captured_at, d.template_version,
+	       d.verification_id, d.signer_fingerprint, l.borrower_id, i.investor_id
+	FROM documents d
+	JOIN loans l ON l.id = d.loan_id
+	LEFT JOIN investments i ON i.id = d.investment_id
+`
+
+func (r *documentRepository) GetDocument(ctx context.Context, documentID uuid.UUID) (*models.Document, error) {
+	query := documentSelect + ` WHERE d.id = $1`
+
+	document, err := scanDocument(r.db.QueryRow(ctx, query, documentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("document not found")
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	return document, nil
+}
+
+// GetDocumentByVerificationID finds a stamped document, deleted ones included
+// so revoked agreements can be reported as such.
+func (r *documentRepository) GetDocumentByVerificationID(ctx context.Context, verificationID string) (*models.Document, error) {
+	query := documentSelect + ` WHERE d.verification_id = $1`
+
+	document, err := scanDocument(r.db.QueryRow(ctx, query, verificationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("document not found")
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	return document, nil
+}
+
+// GetDocumentByFileName resolves links handed out before documents had IDs,
+// which end in the stored file name.
+func (r *documentRepository) GetDocumentByFileName(ctx context.Context, fileName string) (*models.Document, error) {
+	query := documentSelect + `
+		WHERE right(d.storage_key, length($1) + 1) = '/' || $1
+		ORDER BY d.deleted_at IS NULL DESC, d.created_at DESC
+		LIMIT 1
+	`
+
+	document, err := scanDocument(r.db.QueryRow(ctx, query, fileName))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("document not found")
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	return document, nil
+}
+
+func (r *documentRepository) ListDocuments(ctx context.Context, loanID uuid.UUID, includeDeleted bool) ([]models.Document, error) {
+	query := documentSelect + `
+		WHERE d.loan_id = $1 AND ($2 OR d.deleted_at IS NULL)
+		ORDER BY d.created_at, d.document_type, d.version
+	`
+
+	return r.queryDocuments(ctx, query, loanID, includeDeleted)
+}
+
+// ListPendingDocuments returns the oldest documents still waiting for a malware
+// scan.
+func (r *documentRepository) ListPendingDocuments(ctx context.Context, limit int) ([]models.Document, error) {
+	query := documentSelect + `
+		WHERE d.scan_status = 'PENDING' AND d.deleted_at IS NULL
+		ORDER BY d.created_at
+		LIMIT $1
+	`
+
+	return r.queryDocuments(ctx, query, limit)
+}
+
+func (r *documentRepository) queryDocuments(ctx context.Context, query string, args ...interface{}) ([]models.Document, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []models.Document{}
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, *document)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	return documents, nil
+}
+
+// SoftDeleteDocument hides the document and moves the loan's pointer columns
+// back to the previous version. The stored object is kept.
+func (r *documentRepository) SoftDeleteDocument(ctx context.Context, documentID, deletedByID uuid.UUID, reason string) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var loanID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE documents
+		SET deleted_at = CURRENT_TIMESTAMP,
+		    deleted_by_id = $2,
+		    deleted_reason = $3
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING loan_id
+	`, documentID, deletedByID, reason).Scan(&loanID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("document not found")
+		}
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	if err := refreshDocumentPointers(ctx, tx, loanID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDocument records a document without changing the loan, used for
+// quarantined uploads that were rejected.
+func (r *documentRepository) CreateDocument(ctx context.Context, document *models.Document) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertDocument(ctx, tx, document); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateDocumentScan records the outcome of a rescan. Infected documents are
+// soft-deleted and point at their quarantined object.
+func (r *documentRepository) UpdateDocumentScan(ctx context.Context, documentID uuid.UUID, status, signature, storageKey string) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var loanID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE documents
+		SET scan_status = $2,
+		    scan_signature = NULLIF($3, ''),
+		    scanned_at = CURRENT_TIMESTAMP,
+		    storage_key = $4,
+		    deleted_at = CASE WHEN $2 = 'INFECTED' THEN CURRENT_TIMESTAMP ELSE deleted_at END,
+		    deleted_reason = CASE WHEN $2 = 'INFECTED' THEN 'malware detected: ' || $3 ELSE deleted_reason END
+		WHERE id = $1 AND scan_status = 'PENDING'
+		RETURNING loan_id
+	`, documentID, status, signature, storageKey).Scan(&loanID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("document not found")
+		}
+		return fmt.Errorf("failed to update document scan: %w", err)
+	}
+
+	if err := refreshDocumentPointers(ctx, tx, loanID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertDocument records a stored file inside the caller's transaction,
+// numbering it after earlier versions of the same document and refreshing the
+// loan's pointer columns.
+func insertDocument(ctx context.Context, tx pgx.Tx, document *models.Document) error {
+	// Serialise version numbering per loan
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM loans WHERE id = $1 FOR UPDATE`, document.LoanID); err != nil {
+		return fmt.Errorf("failed to lock loan: %w", err)
+	}
+
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(version), 0) + 1
+		FROM documents
+		WHERE loan_id = $1 AND document_type = $2 AND investment_id IS NOT DISTINCT FROM $3
+	`, document.LoanID, document.DocumentType, document.InvestmentID).Scan(&document.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get document version: %w", err)
+	}
+
+	// Generated files are not scanned
+	if document.ScanStatus == "" {
+		document.ScanStatus = constants.SCAN_CLEAN
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO documents (
+			id, loan_id, investment_id, document_type, file_name, storage_key,
+			content_type, size_bytes, sha256, version, uploaded_by_id, uploaded_by_type, created_at,
+			scan_status, scan_signature, scanned_at, deleted_at, deleted_reason,
+			gps_latitude, gps_longitude, captured_at, template_version, verification_id,
+			signer_fingerprint
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, $13, $14, NULLIF($15, ''), $16, $17, NULLIF($18, ''), $19, $20, $21, $22, NULLIF($23, ''), NULLIF($24, ''))
+	`,
+		document.ID,
+		document.LoanID,
+		document.InvestmentID,
+		document.DocumentType,
+		document.FileName,
+		document.StorageKey,
+		document.ContentType,
+		document.SizeBytes,
+		document.SHA256,
+		document.Version,
+		document.UploadedByID,
+		document.UploadedByType,
+		document.CreatedAt,
+		document.ScanStatus,
+		document.ScanSignature,
+		document.ScannedAt,
+		document.DeletedAt,
+		document.DeletedReason,
+		document.Latitude,
+		document.Longitude,
+		document.CapturedAt,
+		document.TemplateVersion,
+		document.VerificationID,
+		document.SignerFingerprint,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record document: %w", err)
+	}
+
+	return refreshDocumentPointers(ctx, tx, document.LoanID)
+}
+
+// refreshDocumentPointers derives the legacy single-file columns from the
+// latest live, clean document of each type.
+func refreshDocumentPointers(ctx context.Context, tx pgx.Tx, loanID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE loans l
+		SET field_visit_proof_url = (
+		        SELECT $2 || d.storage_key FROM documents d
+		        WHERE d.loan_id = l.id AND d.deleted_at IS NULL AND d.scan_status = 'CLEAN'
+		          AND d.document_type IN ('SURVEY_PHOTO', 'SURVEY_DOCUMENT')
+		        ORDER BY d.created_at DESC, d.version DESC LIMIT 1),
+		    loan_agreement_pdf_url = (
+		        SELECT $2 || d.storage_key FROM documents d
+		        WHERE d.loan_id = l.id AND d.deleted_at IS NULL AND d.scan_status = 'CLEAN' AND d.document_type = 'LOAN_AGREEMENT'
+		        ORDER BY d.version DESC LIMIT 1),
+		    signed_agreement_url = (
+		        SELECT $2 || d.storage_key FROM documents d
+		        WHERE d.loan_id = l.id AND d.deleted_at IS NULL AND d.scan_status = 'CLEAN' AND d.document_type = 'SIGNED_AGREEMENT'
+		        ORDER BY d.version DESC LIMIT 1)
+		WHERE l.id = $1
+	`, loanID, storage.URL_PREFIX)
+	if err != nil {
+		return fmt.Errorf("failed to update loan document pointers: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE investments i
+		SET agreement_url = (
+		        SELECT $2 || d.storage_key FROM documents d
+		        WHERE d.investment_id = i.id AND d.deleted_at IS NULL AND d.scan_status = 'CLEAN' AND d.document_type = 'INVESTMENT_AGREEMENT'
+		        ORDER BY d.version DESC LIMIT 1)
+		WHERE i.loan_id = $1
+	`, loanID, storage.URL_PREFIX)
+	if err != nil {
+		return fmt.Errorf("failed to update investment document pointers: %w", err)
+	}
+
+	return nil
+}
+
+func scanDocument(row pgx.Row) (*models.Document, error) {
+	var document models.Document
+	var sha256 sql.NullString
+	var deletedReason sql.NullString
+	var scanSignature sql.NullString
+	var verificationID sql.NullString
+	var signerFingerprint sql.NullString
+
+	err := row.Scan(
+		&document.ID,
+		&document.LoanID,
+		&document.InvestmentID,
+		&document.DocumentType,
+		&document.FileName,
+		&document.StorageKey,
+		&document.ContentType,
+		&document.SizeBytes,
+		&sha256,
+		&document.Version,
+		&document.UploadedByID,
+		&document.UploadedByType,
+		&document.CreatedAt,
+		&document.DeletedAt,
+		&document.DeletedByID,
+		&deletedReason,
+		&document.ScanStatus,
+		&scanSignature,
+		&document.ScannedAt,
+		&document.Latitude,
+		&document.Longitude,
+		&document.CapturedAt,
+		&document.TemplateVersion,
+		&verificationID,
+		&signerFingerprint,
+		&document.BorrowerID,
+		&document.InvestorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	document.SHA256 = sha256.String
+	document.DeletedReason = deletedReason.String
+	document.ScanSignature = scanSignature.String
+	document.VerificationID = verificationID.String
+	document.SignerFingerprint = signerFingerprint.String
+	return &document, nil
+}
@@ -0,0 +1,312 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type EmployeeRepository interface {
+	ListEmployees(ctx context.Context, filter models.EmployeeListFilter) ([]models.Employee, error)
+	GetEmployee(ctx context.Context, id uuid.UUID) (*models.Employee, error)
+	CreateEmployee(ctx context.Context, employee *models.Employee, passwordHash string, audit *models.EmployeeAuditLog) error
+	UpdateEmployee(ctx context.Context, employee *models.Employee, audit *models.EmployeeAuditLog) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, resetMFA bool, audit *models.EmployeeAuditLog) error
+	ListAuditLogs(ctx context.Context, employeeID uuid.UUID) ([]models.EmployeeAuditLog, error)
+}
+
+type employeeRepository struct {
+	db database.Querier
+}
+
+func NewEmployeeRepository(db database.Querier) EmployeeRepository {
+	return &employeeRepository{
+		db: db,
+	}
+}
+
+const employeeColumns = `
+	id, username, email, full_name, COALESCE(phone_number, ''), employee_role,
+	COALESCE(department, ''), COALESCE(branch, ''), is_active, created_at, updated_at
+`
+
+func scanEmployee(row pgx.Row, employee *models.Employee) error {
+	return row.Scan(
+		&employee.ID,
+		&employee.Username,
+		&employee.Email,
+		&employee.FullName,
+		&employee.PhoneNumber,
+		&employee.EmployeeRole,
+		&employee.Department,
+		&employee.Branch,
+		&employee.IsActive,
+		&employee.CreatedAt,
+		&employee.UpdatedAt,
+	)
+}
+
+func (r *employeeRepository) ListEmployees(ctx context.Context, filter models.EmployeeListFilter) ([]models.Employee, error) {
+	query := `
+		SELECT ` + employeeColumns + `
+		FROM employees
+		WHERE ($1 = '' OR employee_role::text = $1)
+		  AND ($2::boolean IS NULL OR is_active = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(ctx, query, filter.EmployeeRole, filter.IsActive, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list employees: %w", err)
+	}
+	defer rows.Close()
+
+	employees := []models.Employee{}
+	for rows.Next() {
+		var employee models.Employee
+		if err := scanEmployee(rows, &employee); err != nil {
+			return nil, fmt.Errorf("failed to scan employee: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list employees: %w", err)
+	}
+
+	return employees, nil
+}
+
+func (r *employeeRepository) GetEmployee(ctx context.Context, id uuid.UUID) (*models.Employee, error) {
+	query := `SELECT ` + employeeColumns + ` FROM employees WHERE id = $1`
+
+	var employee models.Employee
+	if err := scanEmployee(r.db.QueryRow(ctx, query, id), &employee); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("employee not found")
+		}
+		return nil, fmt.Errorf("failed to get employee: %w", err)
+	}
+
+	return &employee, nil
+}
+
+func (r *employeeRepository) CreateEmployee(ctx context.Context, employee *models.Employee, passwordHash string, audit *models.EmployeeAuditLog) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO employees (
+				id, username, email, password_hash, full_name, phone_number,
+				employee_role, department, branch, is_active, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12)
+		`,
+			employee.ID,
+			employee.Username,
+			employee.Email,
+			passwordHash,
+			employee.FullName,
+			employee.PhoneNumber,
+			employee.EmployeeRole,
+			employee.Department,
+			employee.Branch,
+			employee.IsActive,
+			employee.CreatedAt,
+			employee.UpdatedAt,
+		)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return fmt.Errorf("employee already exists")
+			}
+			return fmt.Errorf("failed to create employee: %w", err)
+		}
+
+		return insertEmployeeAudit(ctx, tx, audit)
+	})
+}
+
+// UpdateEmployee saves the employee and refuses any change that would leave the
+// system without an active ADMIN. Active admins are locked for the duration of
+// the check so two concurrent demotions cannot both pass.
+func (r *employeeRepository) UpdateEmployee(ctx context.Context, employee *models.Employee, audit *models.EmployeeAuditLog) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		stillAdmin := employee.IsActive && employee.EmployeeRole == constants.ROLE_ADMIN
+		if !stillAdmin {
+			rows, err := tx.Query(ctx, `
+				SELECT id FROM employees
+				WHERE employee_role = $1 AND is_active = true
+				FOR UPDATE
+			`, constants.ROLE_ADMIN)
+			if err != nil {
+				return fmt.Errorf("failed to lock admins: %w", err)
+			}
+
+			remaining := 0
+			wasAdmin := false
+			for rows.Next() {
+				var id uuid.UUID
+				if err := rows.Scan(&id); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to scan admin: %w", err)
+				}
+				if id == employee.ID {
+					wasAdmin = true
+				} else {
+					remaining++
+				}
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("failed to lock admins: %w", err)
+			}
+
+			if wasAdmin && remaining == 0 {
+				return fmt.Errorf("cannot remove the last active admin")
+			}
+		}
+
+		result, err := tx.Exec(ctx, `
+			UPDATE employees
+			SET full_name = $2,
+			    phone_number = NULLIF($3, ''),
+			    employee_role = $4,
+			    department = NULLIF($5, ''),
+			    branch = NULLIF($6, ''),
+			    is_active = $7,
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`,
+			employee.ID,
+			employee.FullName,
+			employee.PhoneNumber,
+			employee.EmployeeRole,
+			employee.Department,
+			employee.Branch,
+			employee.IsActive,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update employee: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("employee not found")
+		}
+
+		return insertEmployeeAudit(ctx, tx, audit)
+	})
+}
+
+func (r *employeeRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, resetMFA bool, audit *models.EmployeeAuditLog) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			UPDATE employees
+			SET password_hash = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, id, passwordHash)
+		if err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("employee not found")
+		}
+
+		if resetMFA {
+			if _, err := tx.Exec(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1 AND user_type = $2`, id, constants.USER_EMPLOYEE); err != nil {
+				return fmt.Errorf("failed to reset mfa: %w", err)
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1 AND user_type = $2`, id, constants.USER_EMPLOYEE); err != nil {
+				return fmt.Errorf("failed to reset mfa: %w", err)
+			}
+		}
+
+		return insertEmployeeAudit(ctx, tx, audit)
+	})
+}
+
+func (r *employeeRepository) ListAuditLogs(ctx context.Context, employeeID uuid.UUID) ([]models.EmployeeAuditLog, error) {
+	query := `
+		SELECT id, employee_id, actor_employee_id, action, changes, created_at
+		FROM employee_audit_logs
+		WHERE employee_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []models.EmployeeAuditLog{}
+	for rows.Next() {
+		var entry models.EmployeeAuditLog
+		var actorID *uuid.UUID
+		var changes []byte
+
+		if err := rows.Scan(&entry.ID, &entry.EmployeeID, &actorID, &entry.Action, &changes, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if actorID != nil {
+			entry.ActorEmployeeID = *actorID
+		}
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+				return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+			}
+		}
+		logs = append(logs, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+func (r *employeeRepository) withTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func insertEmployeeAudit(ctx context.Context, tx pgx.Tx, audit *models.EmployeeAuditLog) error {
+	changes, err := json.Marshal(audit.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO employee_audit_logs (id, employee_id, actor_employee_id, action, changes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, audit.ID, audit.EmployeeID, audit.ActorEmployeeID, audit.Action, changes, audit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
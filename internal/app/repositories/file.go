@@ -2,15 +2,19 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"time"
 )
 
 type FileRepository interface {
-	UpdateLoanSurveyInfo(ctx context.Context, loanID uuid.UUID, validatorID uuid.UUID, surveyDate time.Time, fileURL, surveyNotes string) error
+	UpdateLoanSurveyInfo(ctx context.Context, loanID uuid.UUID, validatorID uuid.UUID, surveyDate time.Time, surveyNotes string, document *models.Document, flags []models.SurveyFlag) error
 	GetLoanCurrentState(ctx context.Context, loanID uuid.UUID) (string, error)
+	GetBorrowerCoordinates(ctx context.Context, loanID uuid.UUID) (*models.Coordinates, error)
 }
 
 type fileRepository struct {
@@ -35,28 +39,80 @@ func (r *fileRepository) GetLoanCurrentState(ctx context.Context, loanID uuid.UU
 	return currentState, nil
 }
 
-func (r *fileRepository) UpdateLoanSurveyInfo(ctx context.Context, loanID uuid.UUID, validatorID uuid.UUID, surveyDate time.Time, fileURL, surveyNotes string) error {
+// GetBorrowerCoordinates returns the coordinates of the borrower's registered
+// address, or nil when they were never recorded.
+func (r *fileRepository) GetBorrowerCoordinates(ctx context.Context, loanID uuid.UUID) (*models.Coordinates, error) {
+	query := `
+		SELECT b.address_latitude, b.address_longitude
+		FROM loans l
+		JOIN borrowers b ON b.id = l.borrower_id
+		WHERE l.id = $1
+	`
+
+	var latitude, longitude *float64
+	err := r.db.QueryRow(ctx, query, loanID).Scan(&latitude, &longitude)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan not found")
+		}
+		return nil, fmt.Errorf("failed to get borrower coordinates: %w", err)
+	}
+
+	if latitude == nil || longitude == nil {
+		return nil, nil
+	}
+
+	return &models.Coordinates{Latitude: *latitude, Longitude: *longitude}, nil
+}
+
+// UpdateLoanSurveyInfo records the survey, the uploaded document and any flags
+// raised on it together.
+func (r *fileRepository) UpdateLoanSurveyInfo(ctx context.Context, loanID uuid.UUID, validatorID uuid.UUID, surveyDate time.Time, surveyNotes string, document *models.Document, flags []models.SurveyFlag) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		UPDATE loans 
 		SET field_validator_employee_id = $2,
 		    survey_date = $3,
-		    field_visit_proof_url = $4,
-		    survey_notes = $5,
+		    survey_notes = $4,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND current_state = 'PROPOSED'
 	`
 
-	if db, ok := r.db.(database.Executor); ok {
-		result, err := db.Exec(ctx, query, loanID, validatorID, surveyDate, fileURL, surveyNotes)
+	result, err := tx.Exec(ctx, query, loanID, validatorID, surveyDate, surveyNotes)
+	if err != nil {
+		return fmt.Errorf("failed to update loan survey info: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("loan not found or not in PROPOSED state")
+	}
+
+	if err := insertDocument(ctx, tx, document); err != nil {
+		return err
+	}
+
+	for _, flag := range flags {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO survey_flags (id, loan_id, document_id, flag_type, detail, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, flag.ID, flag.LoanID, flag.DocumentID, flag.FlagType, flag.Detail, flag.CreatedAt)
 		if err != nil {
-			return fmt.Errorf("failed to update loan survey info: %w", err)
+			return fmt.Errorf("failed to record survey flag: %w", err)
 		}
+	}
 
-		if result.RowsAffected() == 0 {
-			return fmt.Errorf("loan not found or not in PROPOSED state")
-		}
-	} else {
-		return fmt.Errorf("database does not support Exec operation")
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
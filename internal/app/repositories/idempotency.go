@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type IdempotencyRepository interface {
+	// ClaimKey saves the key as PROCESSING. A key already saved is only taken
+	// over when it expired, or when its request has been processing since
+	// before staleBefore. It returns false when the key is held.
+	ClaimKey(ctx context.Context, key *models.IdempotencyKey, staleBefore time.Time) (bool, error)
+	GetKey(ctx context.Context, principal, key string) (*models.IdempotencyKey, error)
+	// CompleteKey stores the response of the request holding the key.
+	CompleteKey(ctx context.Context, key *models.IdempotencyKey) error
+	// ReleaseKey deletes a key still processing, so the request can be retried.
+	ReleaseKey(ctx context.Context, principal, key string) error
+	DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db database.Querier
+}
+
+func NewIdempotencyRepository(db database.Querier) IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+func (r *idempotencyRepository) ClaimKey(ctx context.Context, key *models.IdempotencyKey, staleBefore time.Time) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (principal, idempotency_key, fingerprint, status, locked_at, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (principal, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    status = EXCLUDED.status,
+		    response_status = NULL,
+		    response_content_type = NULL,
+		    response_body = NULL,
+		    locked_at = EXCLUDED.locked_at,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.locked_at
+		   OR (idempotency_keys.status = $4 AND idempotency_keys.locked_at <= $8)
+		RETURNING true
+	`
+
+	var claimed bool
+	err := r.db.QueryRow(ctx, query,
+		key.Principal,
+		key.Key,
+		key.Fingerprint,
+		constants.IDEMPOTENCY_PROCESSING,
+		key.LockedAt,
+		key.ExpiresAt,
+		key.CreatedAt,
+		staleBefore,
+	).Scan(&claimed)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	return claimed, nil
+}
+
+func (r *idempotencyRepository) GetKey(ctx context.Context, principal, key string) (*models.IdempotencyKey, error) {
+	query := `
+		SELECT principal, idempotency_key, fingerprint, status, COALESCE(response_status, 0),
+		       COALESCE(response_content_type, ''), response_body, locked_at, expires_at, created_at
+		FROM idempotency_keys
+		WHERE principal = $1 AND idempotency_key = $2
+	`
+
+	var record models.IdempotencyKey
+	err := r.db.QueryRow(ctx, query, principal, key).Scan(
+		&record.Principal,
+		&record.Key,
+		&record.Fingerprint,
+		&record.Status,
+		&record.ResponseStatus,
+		&record.ResponseContentType,
+		&record.ResponseBody,
+		&record.LockedAt,
+		&record.ExpiresAt,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("idempotency key not found")
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+func (r *idempotencyRepository) CompleteKey(ctx context.Context, key *models.IdempotencyKey) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	result, err := db.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status = $4, response_status = $5, response_content_type = $6, response_body = $7, expires_at = $8
+		WHERE principal = $1 AND idempotency_key = $2 AND fingerprint = $3 AND status = $9
+	`,
+		key.Principal,
+		key.Key,
+		key.Fingerprint,
+		constants.IDEMPOTENCY_COMPLETED,
+		key.ResponseStatus,
+		key.ResponseContentType,
+		key.ResponseBody,
+		key.ExpiresAt,
+		constants.IDEMPOTENCY_PROCESSING,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("idempotency key not found")
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) ReleaseKey(ctx context.Context, principal, key string) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	_, err := db.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE principal = $1 AND idempotency_key = $2 AND status = $3
+	`, principal, key, constants.IDEMPOTENCY_PROCESSING)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return 0, fmt.Errorf("database does not support Exec operation")
+	}
+
+	result, err := db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -3,20 +3,49 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+// InvestmentEventsFunc builds the events saved with a new investment. It is
+// called inside the investment's transaction, once the loan's new state is
+// known, so it must not wait on anything outside the database.
+type InvestmentEventsFunc func(investment *models.Investment, placement *models.InvestmentPlacement) ([]*models.OutboxEvent, error)
+
+// CancelledEventFunc builds the event saved for a cancelled investment, given
+// the state its loan is left in.
+type CancelledEventFunc func(investment *models.Investment, loanState string) (*models.OutboxEvent, error)
+
 type InvestmentRepository interface {
 	GetLoanForInvestment(ctx context.Context, loanID uuid.UUID) (*models.LoanInvestmentInfo, error)
-	CheckExistingInvestment(ctx context.Context, loanID, investorID uuid.UUID) (bool, error)
-	CreateInvestment(ctx context.Context, investment *models.Investment) error
-	UpdateLoanState(ctx context.Context, loanID uuid.UUID, newState string) error
+	// GetNextTranche numbers the investor's next tranche in the loan, so its
+	// agreement can be generated before the loan is locked.
+	GetNextTranche(ctx context.Context, loanID, investorID uuid.UUID) (int, error)
+	// CreateInvestment saves the investment and its agreement if it still fits
+	// in what remains of the loan principal. With the loan locked it checks
+	// the investor's tranche is still the next one, moves the loan to FUNDING
+	// or INVESTED and saves what newEvents returns, all in one transaction.
+	CreateInvestment(ctx context.Context, investment *models.Investment, agreement *models.Document, newEvents InvestmentEventsFunc) (*models.InvestmentPlacement, error)
 	GetTotalInvestedAmount(ctx context.Context, loanID uuid.UUID) (float64, error)
 	GetInvestorName(ctx context.Context, investorID uuid.UUID) (string, error)
+	GetInvestment(ctx context.Context, investmentID uuid.UUID) (*models.Investment, error)
+	GetInvestmentAgreement(ctx context.Context, investmentID uuid.UUID) (*models.Document, error)
+	AcceptAgreement(ctx context.Context, acceptance *models.AgreementAcceptance) error
+	// CancelExpiredInvestments cancels the investments past their acceptance
+	// deadline and saves the event newEvent builds for each of them in the
+	// same transaction.
+	CancelExpiredInvestments(ctx context.Context, now time.Time, newEvent CancelledEventFunc) ([]models.Investment, error)
+	ListLoanInvestments(ctx context.Context, loanID uuid.UUID) ([]models.Investment, error)
+	// GetInvestorPortfolio returns the investor's positions, one per loan,
+	// latest investment first.
+	GetInvestorPortfolio(ctx context.Context, investorID uuid.UUID) ([]models.PortfolioLoan, error)
 }
 
 type investmentRepository struct {
@@ -35,7 +64,7 @@ func (r *investmentRepository) GetLoanForInvestment(ctx context.Context, loanID
 			l.id, l.principal_amount, l.roi_rate, l.current_state,
 			COALESCE(SUM(i.investment_amount), 0) as total_invested
 		FROM loans l
-		LEFT JOIN investments i ON l.id = i.loan_id
+		LEFT JOIN investments i ON l.id = i.loan_id AND i.status <> 'CANCELLED'
 		WHERE l.id = $1
 		GROUP BY l.id, l.principal_amount, l.roi_rate, l.current_state
 	`
@@ -59,72 +88,156 @@ func (r *investmentRepository) GetLoanForInvestment(ctx context.Context, loanID
 	return &loan, nil
 }
 
-func (r *investmentRepository) CheckExistingInvestment(ctx context.Context, loanID, investorID uuid.UUID) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM investments WHERE loan_id = $1 AND investor_id = $2)`
+func (r *investmentRepository) GetNextTranche(ctx context.Context, loanID, investorID uuid.UUID) (int, error) {
+	query := `
+		SELECT COALESCE(MAX(tranche), 0) + 1
+		FROM investments
+		WHERE loan_id = $1 AND investor_id = $2 AND status <> $3
+	`
 
-	var exists bool
-	err := r.db.QueryRow(ctx, query, loanID, investorID).Scan(&exists)
+	var tranche int
+	err := r.db.QueryRow(ctx, query, loanID, investorID, constants.INVESTMENT_CANCELLED).Scan(&tranche)
 	if err != nil {
-		return false, fmt.Errorf("failed to check existing investment: %w", err)
+		return 0, fmt.Errorf("failed to get next tranche: %w", err)
 	}
 
-	return exists, nil
+	return tranche, nil
 }
 
-func (r *investmentRepository) CreateInvestment(ctx context.Context, investment *models.Investment) error {
+func (r *investmentRepository) CreateInvestment(ctx context.Context, investment *models.Investment, agreement *models.Document, newEvents InvestmentEventsFunc) (*models.InvestmentPlacement, error) {
 	query := `
 		INSERT INTO investments (
-			id, loan_id, investor_id, investment_amount, expected_return,
-			investment_date, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			id, loan_id, investor_id, tranche, investment_amount, expected_return,
+			investment_date, status, acceptance_deadline, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
-	if db, ok := r.db.(database.Executor); ok {
-		_, err := db.Exec(ctx, query,
-			investment.ID,
-			investment.LoanID,
-			investment.InvestorID,
-			investment.InvestmentAmount,
-			investment.ExpectedReturn,
-			investment.InvestmentDate,
-			investment.CreatedAt,
-		)
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return nil, fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-		if err != nil {
-			return fmt.Errorf("failed to create investment: %w", err)
+	// Locking the loan serializes investments in it, so two of them cannot
+	// both fit in the same remaining amount or take the same tranche
+	placement := models.InvestmentPlacement{
+		Loan:     models.LoanInvestmentInfo{ID: investment.LoanID},
+		Position: models.InvestorPosition{LoanID: investment.LoanID, InvestorID: investment.InvestorID},
+	}
+	loan := &placement.Loan
+	err = tx.QueryRow(ctx, `
+		SELECT principal_amount, roi_rate, current_state FROM loans WHERE id = $1 FOR UPDATE
+	`, investment.LoanID).Scan(&loan.PrincipalAmount, &loan.ROIRate, &loan.CurrentState)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan not found")
 		}
+		return nil, fmt.Errorf("failed to lock loan: %w", err)
+	}
+
+	if loan.CurrentState != constants.APPROVED && loan.CurrentState != constants.FUNDING {
+		return nil, fmt.Errorf("loan must be in APPROVED or FUNDING state")
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(investment_amount), 0) FROM investments WHERE loan_id = $1 AND status <> $2
+	`, investment.LoanID, constants.INVESTMENT_CANCELLED).Scan(&loan.TotalInvested)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total invested amount: %w", err)
+	}
+
+	if investment.InvestmentAmount > loan.PrincipalAmount-loan.TotalInvested {
+		return nil, fmt.Errorf("investment amount exceeds remaining loan amount")
+	}
+
+	position := &placement.Position
+	var lastTranche int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(investment_amount), 0), COALESCE(SUM(expected_return), 0),
+		       COUNT(*), COALESCE(MAX(tranche), 0)
+		FROM investments
+		WHERE loan_id = $1 AND investor_id = $2 AND status <> $3
+	`, investment.LoanID, investment.InvestorID, constants.INVESTMENT_CANCELLED).Scan(
+		&position.InvestedAmount,
+		&position.ExpectedReturn,
+		&position.Tranches,
+		&lastTranche,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get investor position: %w", err)
+	}
+
+	// The agreement already prints the tranche, another investment by the
+	// same investor may have taken it since
+	if investment.Tranche != lastTranche+1 {
+		return nil, fmt.Errorf("investment tranche already taken")
+	}
+	position.InvestedAmount += investment.InvestmentAmount
+	position.ExpectedReturn += investment.ExpectedReturn
+	position.Tranches++
+
+	previousState := loan.CurrentState
+	loan.TotalInvested += investment.InvestmentAmount
+	if loan.TotalInvested >= loan.PrincipalAmount {
+		loan.CurrentState = constants.INVESTED
 	} else {
-		return fmt.Errorf("database does not support Exec operation")
+		loan.CurrentState = constants.FUNDING
 	}
 
-	return nil
-}
+	events, err := newEvents(investment, &placement)
+	if err != nil {
+		return nil, err
+	}
 
-func (r *investmentRepository) UpdateLoanState(ctx context.Context, loanID uuid.UUID, newState string) error {
-	query := `
-		UPDATE loans 
-		SET current_state = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1
-	`
+	_, err = tx.Exec(ctx, query,
+		investment.ID,
+		investment.LoanID,
+		investment.InvestorID,
+		investment.Tranche,
+		investment.InvestmentAmount,
+		investment.ExpectedReturn,
+		investment.InvestmentDate,
+		investment.Status,
+		investment.AcceptanceDeadline,
+		investment.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create investment: %w", err)
+	}
 
-	if db, ok := r.db.(database.Executor); ok {
-		result, err := db.Exec(ctx, query, loanID, newState)
+	if loan.CurrentState != previousState {
+		_, err = tx.Exec(ctx, `
+			UPDATE loans SET current_state = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+		`, investment.LoanID, loan.CurrentState)
 		if err != nil {
-			return fmt.Errorf("failed to update loan state: %w", err)
+			return nil, fmt.Errorf("failed to update loan state: %w", err)
 		}
+	}
 
-		if result.RowsAffected() == 0 {
-			return fmt.Errorf("loan not found")
+	if err := insertDocument(ctx, tx, agreement); err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if err := insertOutboxEvent(ctx, tx, event); err != nil {
+			return nil, err
 		}
-	} else {
-		return fmt.Errorf("database does not support Exec operation")
 	}
 
-	return nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &placement, nil
 }
 
 func (r *investmentRepository) GetTotalInvestedAmount(ctx context.Context, loanID uuid.UUID) (float64, error) {
-	query := `SELECT COALESCE(SUM(investment_amount), 0) FROM investments WHERE loan_id = $1`
+	query := `SELECT COALESCE(SUM(investment_amount), 0) FROM investments WHERE loan_id = $1 AND status <> 'CANCELLED'`
 
 	var total float64
 	err := r.db.QueryRow(ctx, query, loanID).Scan(&total)
@@ -149,3 +262,321 @@ func (r *investmentRepository) GetInvestorName(ctx context.Context, investorID u
 
 	return name, nil
 }
+
+func (r *investmentRepository) GetInvestment(ctx context.Context, investmentID uuid.UUID) (*models.Investment, error) {
+	query := `
+		SELECT id, loan_id, investor_id, tranche, investment_amount, expected_return,
+		       investment_date, status, acceptance_deadline, created_at
+		FROM investments
+		WHERE id = $1
+	`
+
+	var investment models.Investment
+	err := r.db.QueryRow(ctx, query, investmentID).Scan(
+		&investment.ID,
+		&investment.LoanID,
+		&investment.InvestorID,
+		&investment.Tranche,
+		&investment.InvestmentAmount,
+		&investment.ExpectedReturn,
+		&investment.InvestmentDate,
+		&investment.Status,
+		&investment.AcceptanceDeadline,
+		&investment.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("investment not found")
+		}
+		return nil, fmt.Errorf("failed to get investment: %w", err)
+	}
+
+	return &investment, nil
+}
+
+// ListLoanInvestments returns the loan's investments that were not
+// cancelled, oldest first.
+func (r *investmentRepository) ListLoanInvestments(ctx context.Context, loanID uuid.UUID) ([]models.Investment, error) {
+	query := `
+		SELECT id, loan_id, investor_id, tranche, investment_amount, expected_return,
+		       investment_date, status, acceptance_deadline, created_at
+		FROM investments
+		WHERE loan_id = $1 AND status <> $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, loanID, constants.INVESTMENT_CANCELLED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list investments: %w", err)
+	}
+	defer rows.Close()
+
+	investments := []models.Investment{}
+	for rows.Next() {
+		var investment models.Investment
+		if err := rows.Scan(
+			&investment.ID,
+			&investment.LoanID,
+			&investment.InvestorID,
+			&investment.Tranche,
+			&investment.InvestmentAmount,
+			&investment.ExpectedReturn,
+			&investment.InvestmentDate,
+			&investment.Status,
+			&investment.AcceptanceDeadline,
+			&investment.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan investment: %w", err)
+		}
+		investments = append(investments, investment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate investments: %w", err)
+	}
+
+	return investments, nil
+}
+
+func (r *investmentRepository) GetInvestorPortfolio(ctx context.Context, investorID uuid.UUID) ([]models.PortfolioLoan, error) {
+	query := `
+		SELECT l.id, l.current_state, l.principal_amount, l.roi_rate,
+		       SUM(i.investment_amount), SUM(i.expected_return),
+		       COALESCE(SUM(i.investment_amount) FILTER (WHERE i.status = $2), 0),
+		       COALESCE(SUM(i.investment_amount) FILTER (WHERE i.status = $3), 0),
+		       COUNT(*), MIN(i.created_at), MAX(i.created_at)
+		FROM investments i
+		JOIN loans l ON l.id = i.loan_id
+		WHERE i.investor_id = $1 AND i.status <> $4
+		GROUP BY l.id, l.current_state, l.principal_amount, l.roi_rate
+		ORDER BY MAX(i.created_at) DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, investorID,
+		constants.INVESTMENT_ACCEPTED, constants.INVESTMENT_PENDING_ACCEPTANCE, constants.INVESTMENT_CANCELLED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get investor portfolio: %w", err)
+	}
+	defer rows.Close()
+
+	loans := []models.PortfolioLoan{}
+	for rows.Next() {
+		var loan models.PortfolioLoan
+		if err := rows.Scan(
+			&loan.LoanID,
+			&loan.LoanState,
+			&loan.PrincipalAmount,
+			&loan.ROIRate,
+			&loan.InvestedAmount,
+			&loan.ExpectedReturn,
+			&loan.AcceptedAmount,
+			&loan.PendingAmount,
+			&loan.Tranches,
+			&loan.FirstInvestmentDate,
+			&loan.LastInvestmentDate,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio loan: %w", err)
+		}
+		loans = append(loans, loan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate portfolio: %w", err)
+	}
+
+	return loans, nil
+}
+
+// GetInvestmentAgreement returns the latest live version of the investor's
+// agreement, the one they accept.
+func (r *investmentRepository) GetInvestmentAgreement(ctx context.Context, investmentID uuid.UUID) (*models.Document, error) {
+	query := documentSelect + `
+		WHERE d.investment_id = $1 AND d.document_type = $2
+		  AND d.deleted_at IS NULL AND d.scan_status = 'CLEAN'
+		ORDER BY d.version DESC
+		LIMIT 1
+	`
+
+	document, err := scanDocument(r.db.QueryRow(ctx, query, investmentID, constants.DOCUMENT_INVESTMENT_AGREEMENT))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("investment agreement not found")
+		}
+		return nil, fmt.Errorf("failed to get investment agreement: %w", err)
+	}
+
+	return document, nil
+}
+
+// AcceptAgreement records the investor's acceptance. It only applies to
+// investments still pending within their deadline, so an acceptance cannot
+// race the expiry.
+func (r *investmentRepository) AcceptAgreement(ctx context.Context, acceptance *models.AgreementAcceptance) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	result, err := db.Exec(ctx, `
+		UPDATE investments
+		SET status = $2,
+		    agreement_signed = true,
+		    agreement_signed_date = $3::date,
+		    agreement_signed_at = $3,
+		    agreement_signed_ip = $4,
+		    agreement_signed_user_agent = $5,
+		    agreement_document_id = $6,
+		    agreement_sha256 = $7
+		WHERE id = $1 AND status = $8 AND acceptance_deadline > $3
+	`,
+		acceptance.InvestmentID,
+		constants.INVESTMENT_ACCEPTED,
+		acceptance.AcceptedAt,
+		acceptance.IPAddress,
+		acceptance.UserAgent,
+		acceptance.DocumentID,
+		acceptance.AgreementSHA256,
+		constants.INVESTMENT_PENDING_ACCEPTANCE,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to accept agreement: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("investment is not pending acceptance")
+	}
+
+	return nil
+}
+
+// CancelExpiredInvestments cancels investments whose agreement was not
+// accepted in time and moves their loans back to the state matching what is
+// still invested. Loans that were already disbursed are left alone.
+func (r *investmentRepository) CancelExpiredInvestments(ctx context.Context, now time.Time, newEvent CancelledEventFunc) ([]models.Investment, error) {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return nil, fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE investments i
+		SET status = $2, cancelled_at = $1, cancellation_reason = $3
+		FROM loans l
+		WHERE l.id = i.loan_id AND l.current_state IN ($4, $5)
+		  AND i.status = $6 AND i.acceptance_deadline <= $1
+		RETURNING i.id, i.loan_id, i.investor_id, i.tranche, i.investment_amount, i.expected_return,
+		          i.investment_date, i.status, i.acceptance_deadline, i.created_at
+	`, now, constants.INVESTMENT_CANCELLED, constants.INVESTMENT_EXPIRED_REASON,
+		constants.FUNDING, constants.INVESTED, constants.INVESTMENT_PENDING_ACCEPTANCE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel expired investments: %w", err)
+	}
+
+	cancelled := []models.Investment{}
+	loanIDs := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for rows.Next() {
+		var investment models.Investment
+		if err := rows.Scan(
+			&investment.ID,
+			&investment.LoanID,
+			&investment.InvestorID,
+			&investment.Tranche,
+			&investment.InvestmentAmount,
+			&investment.ExpectedReturn,
+			&investment.InvestmentDate,
+			&investment.Status,
+			&investment.AcceptanceDeadline,
+			&investment.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan investment: %w", err)
+		}
+		cancelled = append(cancelled, investment)
+		if !seen[investment.LoanID] {
+			seen[investment.LoanID] = true
+			loanIDs = append(loanIDs, investment.LoanID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to cancel expired investments: %w", err)
+	}
+
+	if len(loanIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE loans l
+			SET current_state = CASE
+			        WHEN t.total = 0 THEN $2
+			        WHEN t.total < l.principal_amount THEN $3
+			        ELSE l.current_state END,
+			    updated_at = CURRENT_TIMESTAMP
+			FROM (
+			    SELECT l2.id, COALESCE(SUM(i.investment_amount), 0) AS total
+			    FROM loans l2
+			    LEFT JOIN investments i ON i.loan_id = l2.id AND i.status <> $4
+			    WHERE l2.id = ANY($1)
+			    GROUP BY l2.id
+			) t
+			WHERE l.id = t.id AND l.current_state IN ($3, $5)
+		`, loanIDs, constants.APPROVED, constants.FUNDING, constants.INVESTMENT_CANCELLED, constants.INVESTED)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update loan state: %w", err)
+		}
+	}
+
+	loanStates, err := loanStates(ctx, tx, loanIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cancelled {
+		event, err := newEvent(&cancelled[i], loanStates[cancelled[i].LoanID])
+		if err != nil {
+			return nil, err
+		}
+		if err := insertOutboxEvent(ctx, tx, event); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+func loanStates(ctx context.Context, tx pgx.Tx, loanIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	states := make(map[uuid.UUID]string, len(loanIDs))
+	if len(loanIDs) == 0 {
+		return states, nil
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, current_state FROM loans WHERE id = ANY($1)`, loanIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan states: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var state string
+		if err := rows.Scan(&id, &state); err != nil {
+			return nil, fmt.Errorf("failed to scan loan state: %w", err)
+		}
+		states[id] = state
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get loan states: %w", err)
+	}
+
+	return states, nil
+}
@@ -3,21 +3,39 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type LoanRepository interface {
-	CreateLoan(ctx context.Context, loan *models.Loan) error
+	CreateLoan(ctx context.Context, loan *models.Loan, event *models.OutboxEvent) error
+	GetLoan(ctx context.Context, loanID uuid.UUID) (*models.Loan, error)
 	GetLoanForApproval(ctx context.Context, loanID uuid.UUID) (*models.LoanForApproval, error)
-	ApproveLoan(ctx context.Context, loanID, approvingEmployeeID uuid.UUID, approvalNotes, agreementURL string) error
+	ApproveLoan(ctx context.Context, loanID, approvingEmployeeID uuid.UUID, approvalNotes string, agreement *models.Document, event *models.OutboxEvent) error
 	GetApprovedLoan(ctx context.Context, loanID uuid.UUID) (*models.ApproveLoanResponse, error)
 	GetLoanForDisbursement(ctx context.Context, loanID uuid.UUID) (*models.Loan, error)
-	DisburseLoan(ctx context.Context, loanID, fieldOfficerID uuid.UUID, signedAgreementURL, disbursementNotes string) error
+	StartDisbursement(ctx context.Context, loanID, fieldOfficerID uuid.UUID, signedAgreement *models.Document, disbursementNotes string, payout *models.Payout) error
+	SetPayoutReference(ctx context.Context, payoutID uuid.UUID, providerReference string) error
+	CompleteDisbursement(ctx context.Context, payoutID uuid.UUID, providerReference string, completedAt time.Time, event *models.OutboxEvent) error
+	FailDisbursement(ctx context.Context, payoutID uuid.UUID, providerReference, reason string, failedAt time.Time) error
+	GetPayout(ctx context.Context, payoutID uuid.UUID) (*models.Payout, error)
+	ListPayouts(ctx context.Context, loanID uuid.UUID) ([]models.Payout, error)
 	GetDisbursedLoan(ctx context.Context, loanID uuid.UUID) (*models.DisburseLoanResponse, error)
+	AssignValidator(ctx context.Context, loanID, validatorID uuid.UUID) error
+	GetLoanApprovals(ctx context.Context, loanID uuid.UUID) ([]models.LoanApproval, error)
+	AddLoanApproval(ctx context.Context, approval *models.LoanApproval, requiredApprovals int, allowedRoles []string) (received int, required int, err error)
+	ListPendingApprovals(ctx context.Context, branch string, allBranches bool) ([]models.PendingApproval, error)
+	GetSurveyFlags(ctx context.Context, loanID uuid.UUID) ([]models.SurveyFlag, error)
+	CountUnacceptedInvestments(ctx context.Context, loanID uuid.UUID) (int, error)
+	GetSignedAgreement(ctx context.Context, loanID uuid.UUID) (*models.Document, error)
 }
 
 type loanRepository struct {
@@ -30,7 +48,7 @@ func NewLoanRepository(db database.Querier) LoanRepository {
 	}
 }
 
-func (r *loanRepository) CreateLoan(ctx context.Context, loan *models.Loan) error {
+func (r *loanRepository) CreateLoan(ctx context.Context, loan *models.Loan, event *models.OutboxEvent) error {
 	query := `
 		INSERT INTO loans (
 			id, borrower_id, principal_amount, interest_rate, roi_rate,
@@ -38,33 +56,79 @@ func (r *loanRepository) CreateLoan(ctx context.Context, loan *models.Loan) erro
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	if db, ok := r.db.(database.Executor); ok {
-		_, err := db.Exec(ctx, query,
-			loan.ID,
-			loan.BorrowerID,
-			loan.PrincipalAmount,
-			loan.InterestRate,
-			loan.ROIRate,
-			loan.LoanTermMonth,
-			loan.CurrentState,
-			loan.CreatedAt,
-			loan.UpdatedAt,
-		)
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to create loan: %w", err)
-		}
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query,
+		loan.ID,
+		loan.BorrowerID,
+		loan.PrincipalAmount,
+		loan.InterestRate,
+		loan.ROIRate,
+		loan.LoanTermMonth,
+		loan.CurrentState,
+		loan.CreatedAt,
+		loan.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create loan: %w", err)
+	}
+
+	if err := insertOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
+func (r *loanRepository) GetLoan(ctx context.Context, loanID uuid.UUID) (*models.Loan, error) {
+	query := `
+		SELECT id, borrower_id, principal_amount, interest_rate, roi_rate,
+		       loan_term_month, current_state, created_at, updated_at
+		FROM loans
+		WHERE id = $1
+	`
+
+	var loan models.Loan
+	err := r.db.QueryRow(ctx, query, loanID).Scan(
+		&loan.ID,
+		&loan.BorrowerID,
+		&loan.PrincipalAmount,
+		&loan.InterestRate,
+		&loan.ROIRate,
+		&loan.LoanTermMonth,
+		&loan.CurrentState,
+		&loan.CreatedAt,
+		&loan.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan not found")
+		}
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	return &loan, nil
+}
+
 func (r *loanRepository) GetLoanForApproval(ctx context.Context, loanID uuid.UUID) (*models.LoanForApproval, error) {
 	query := `
 		SELECT
 			l.id, l.borrower_id, l.principal_amount, l.interest_rate, l.roi_rate,
 			l.loan_term_month, l.current_state, l.field_validator_employee_id,
-			l.survey_date, b.full_name as borrower_name
+			l.survey_date, b.full_name as borrower_name, b.preferred_locale, l.required_approvals
 		FROM loans l
 		JOIN borrowers b ON l.borrower_id = b.id
 		WHERE l.id = $1
@@ -85,6 +149,8 @@ func (r *loanRepository) GetLoanForApproval(ctx context.Context, loanID uuid.UUI
 		&validatorID,
 		&surveyDate,
 		&loan.BorrowerName,
+		&loan.BorrowerLocale,
+		&loan.RequiredApprovals,
 	)
 
 	if err != nil {
@@ -104,27 +170,47 @@ func (r *loanRepository) GetLoanForApproval(ctx context.Context, loanID uuid.UUI
 	return &loan, nil
 }
 
-func (r *loanRepository) ApproveLoan(ctx context.Context, loanID, approvingEmployeeID uuid.UUID, approvalNotes, agreementURL string) error {
+func (r *loanRepository) ApproveLoan(ctx context.Context, loanID, approvingEmployeeID uuid.UUID, approvalNotes string, agreement *models.Document, event *models.OutboxEvent) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		UPDATE loans 
-		SET current_state = $5,
+		SET current_state = $4,
 		    approving_employee_id = $2,
 		    approval_date = CURRENT_DATE,
 		    approval_notes = $3,
-		    loan_agreement_pdf_url = $4,
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND current_state = $6
+		WHERE id = $1 AND current_state = $5
 	`
 
-	if db, ok := r.db.(database.Executor); ok {
-		result, err := db.Exec(ctx, query, loanID, approvingEmployeeID, approvalNotes, agreementURL, constants.APPROVED, constants.PROPOSED)
-		if err != nil {
-			return fmt.Errorf("failed to approve loan: %w", err)
-		}
+	result, err := tx.Exec(ctx, query, loanID, approvingEmployeeID, approvalNotes, constants.APPROVED, constants.PROPOSED)
+	if err != nil {
+		return fmt.Errorf("failed to approve loan: %w", err)
+	}
 
-		if result.RowsAffected() == 0 {
-			return fmt.Errorf("loan not found")
-		}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("loan not found")
+	}
+
+	if err := insertDocument(ctx, tx, agreement); err != nil {
+		return err
+	}
+
+	if err := insertOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -211,36 +297,293 @@ func (r *loanRepository) GetLoanForDisbursement(ctx context.Context, loanID uuid
 	return &loan, nil
 }
 
-func (r *loanRepository) DisburseLoan(ctx context.Context, loanID, fieldOfficerID uuid.UUID, signedAgreementURL, disbursementNotes string) error {
+// StartDisbursement moves the loan to DISBURSING and records the payout to the
+// borrower's bank account, copying the account into payout.
+func (r *loanRepository) StartDisbursement(ctx context.Context, loanID, fieldOfficerID uuid.UUID, signedAgreement *models.Document, disbursementNotes string, payout *models.Payout) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		UPDATE loans 
-		SET current_state = $5,
+		SET current_state = $4,
 		    field_officer_employee_id = $2,
-		    disbursement_date = CURRENT_DATE,
-		    signed_agreement_url = $3,
-		    disbursement_notes = $4,
+		    disbursement_notes = $3,
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND current_state = $6
+		WHERE id = $1 AND current_state = $5
+		  AND NOT EXISTS (
+		      SELECT 1 FROM investments
+		      WHERE loan_id = $1 AND status = $6)
+		  AND ($7 OR EXISTS (
+		      SELECT 1 FROM documents
+		      WHERE loan_id = $1 AND document_type = $8 AND deleted_at IS NULL AND scan_status = 'CLEAN'))
 	`
 
-	if db, ok := r.db.(database.Executor); ok {
-		result, err := db.Exec(ctx, query, loanID, fieldOfficerID, signedAgreementURL, disbursementNotes, constants.DISBURSED, constants.INVESTED)
+	// Without an uploaded copy the borrower must have signed electronically
+	uploaded := signedAgreement != nil
+	result, err := tx.Exec(ctx, query, loanID, fieldOfficerID, disbursementNotes, constants.DISBURSING, constants.INVESTED,
+		constants.INVESTMENT_PENDING_ACCEPTANCE, uploaded, constants.DOCUMENT_SIGNED_AGREEMENT)
+	if err != nil {
+		return fmt.Errorf("failed to disburse loan: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("loan not found")
+	}
+
+	if uploaded {
+		if err := insertDocument(ctx, tx, signedAgreement); err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payouts (
+			id, loan_id, amount, bank_name, bank_account_number, account_holder_name,
+			provider, status, requested_by_id, created_at, updated_at
+		)
+		SELECT $1, l.id, l.principal_amount, b.bank_name, b.bank_account_number, b.account_holder_name,
+		       $3, $4, $5, $6, $6
+		FROM loans l
+		JOIN borrowers b ON b.id = l.borrower_id
+		WHERE l.id = $2
+		  AND COALESCE(b.bank_name, '') <> '' AND COALESCE(b.bank_account_number, '') <> ''
+		  AND COALESCE(b.account_holder_name, '') <> ''
+		RETURNING amount, bank_name, bank_account_number, account_holder_name
+	`, payout.ID, loanID, payout.Provider, payout.Status, payout.RequestedByID, payout.CreatedAt).Scan(
+		&payout.Amount,
+		&payout.BankName,
+		&payout.BankAccountNumber,
+		&payout.AccountHolderName,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("borrower has no bank account")
+		}
+		return fmt.Errorf("failed to create payout: %w", err)
+	}
+	payout.LoanID = loanID
+	payout.UpdatedAt = payout.CreatedAt
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetPayoutReference remembers the provider's reference of a transfer it
+// accepted.
+func (r *loanRepository) SetPayoutReference(ctx context.Context, payoutID uuid.UUID, providerReference string) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	_, err := db.Exec(ctx, `
+		UPDATE payouts
+		SET provider_reference = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND provider_reference IS NULL
+	`, payoutID, providerReference)
+	if err != nil {
+		return fmt.Errorf("failed to save payout reference: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteDisbursement settles a successful payout and disburses its loan.
+func (r *loanRepository) CompleteDisbursement(ctx context.Context, payoutID uuid.UUID, providerReference string, completedAt time.Time, event *models.OutboxEvent) error {
+	return r.settlePayout(ctx, payoutID, constants.PAYOUT_SUCCESS, providerReference, "", completedAt, func(tx pgx.Tx, loanID uuid.UUID) error {
+		result, err := tx.Exec(ctx, `
+			UPDATE loans
+			SET current_state = $2, disbursement_date = $4, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND current_state = $3
+		`, loanID, constants.DISBURSED, constants.DISBURSING, completedAt)
 		if err != nil {
 			return fmt.Errorf("failed to disburse loan: %w", err)
 		}
 
 		if result.RowsAffected() == 0 {
-			return fmt.Errorf("loan not found")
+			return fmt.Errorf("loan is not being disbursed")
+		}
+
+		return insertOutboxEvent(ctx, tx, event)
+	})
+}
+
+// FailDisbursement settles a failed payout and returns its loan to INVESTED,
+// where it can be disbursed again.
+func (r *loanRepository) FailDisbursement(ctx context.Context, payoutID uuid.UUID, providerReference, reason string, failedAt time.Time) error {
+	return r.settlePayout(ctx, payoutID, constants.PAYOUT_FAILED, providerReference, reason, failedAt, func(tx pgx.Tx, loanID uuid.UUID) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE loans
+			SET current_state = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND current_state = $3
+		`, loanID, constants.INVESTED, constants.DISBURSING)
+		if err != nil {
+			return fmt.Errorf("failed to return loan to invested: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// settlePayout moves a pending payout to status and applies the matching
+// change to its loan in the same transaction.
+func (r *loanRepository) settlePayout(ctx context.Context, payoutID uuid.UUID, status, providerReference, reason string, settledAt time.Time, updateLoan func(tx pgx.Tx, loanID uuid.UUID) error) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var loanID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE payouts
+		SET status = $2,
+		    provider_reference = COALESCE(NULLIF($3, ''), provider_reference),
+		    failure_reason = NULLIF($4, ''),
+		    completed_at = $5,
+		    updated_at = $5
+		WHERE id = $1 AND status = $6
+		RETURNING loan_id
+	`, payoutID, status, providerReference, reason, settledAt, constants.PAYOUT_PENDING).Scan(&loanID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("payout already settled")
 		}
+		return fmt.Errorf("failed to settle payout: %w", err)
+	}
+
+	if err := updateLoan(tx, loanID); err != nil {
+		return err
 	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
+const payoutColumns = `
+	id, loan_id, amount, bank_name, bank_account_number, account_holder_name, provider,
+	COALESCE(provider_reference, ''), status, COALESCE(failure_reason, ''), requested_by_id,
+	created_at, updated_at, completed_at`
+
+func scanPayout(row pgx.Row, payout *models.Payout) error {
+	return row.Scan(
+		&payout.ID,
+		&payout.LoanID,
+		&payout.Amount,
+		&payout.BankName,
+		&payout.BankAccountNumber,
+		&payout.AccountHolderName,
+		&payout.Provider,
+		&payout.ProviderReference,
+		&payout.Status,
+		&payout.FailureReason,
+		&payout.RequestedByID,
+		&payout.CreatedAt,
+		&payout.UpdatedAt,
+		&payout.CompletedAt,
+	)
+}
+
+func (r *loanRepository) GetPayout(ctx context.Context, payoutID uuid.UUID) (*models.Payout, error) {
+	var payout models.Payout
+	err := scanPayout(r.db.QueryRow(ctx, `SELECT `+payoutColumns+` FROM payouts WHERE id = $1`, payoutID), &payout)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("payout not found")
+		}
+		return nil, fmt.Errorf("failed to get payout: %w", err)
+	}
+
+	return &payout, nil
+}
+
+func (r *loanRepository) ListPayouts(ctx context.Context, loanID uuid.UUID) ([]models.Payout, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+payoutColumns+`
+		FROM payouts
+		WHERE loan_id = $1
+		ORDER BY created_at
+	`, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payouts: %w", err)
+	}
+	defer rows.Close()
+
+	payouts := []models.Payout{}
+	for rows.Next() {
+		var payout models.Payout
+		if err := scanPayout(rows, &payout); err != nil {
+			return nil, fmt.Errorf("failed to scan payout: %w", err)
+		}
+		payouts = append(payouts, payout)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate payouts: %w", err)
+	}
+
+	return payouts, nil
+}
+
+// GetSignedAgreement returns the latest live signed agreement, either an
+// uploaded copy or one the borrower signed electronically.
+func (r *loanRepository) GetSignedAgreement(ctx context.Context, loanID uuid.UUID) (*models.Document, error) {
+	query := documentSelect + `
+		WHERE d.loan_id = $1 AND d.document_type = $2
+		  AND d.deleted_at IS NULL AND d.scan_status = 'CLEAN'
+		ORDER BY d.version DESC
+		LIMIT 1
+	`
+
+	document, err := scanDocument(r.db.QueryRow(ctx, query, loanID, constants.DOCUMENT_SIGNED_AGREEMENT))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("signed agreement required")
+		}
+		return nil, fmt.Errorf("failed to get signed agreement: %w", err)
+	}
+
+	return document, nil
+}
+
+// CountUnacceptedInvestments counts the live investments on a loan whose
+// investor has not accepted the agreement yet.
+func (r *loanRepository) CountUnacceptedInvestments(ctx context.Context, loanID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM investments WHERE loan_id = $1 AND status = $2`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, loanID, constants.INVESTMENT_PENDING_ACCEPTANCE).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unaccepted investments: %w", err)
+	}
+
+	return count, nil
+}
+
 func (r *loanRepository) GetDisbursedLoan(ctx context.Context, loanID uuid.UUID) (*models.DisburseLoanResponse, error) {
 	query := `
 		SELECT id, borrower_id, principal_amount, interest_rate, roi_rate,
 		       loan_term_month, current_state, disbursement_date, 
-		       field_officer_employee_id, signed_agreement_url, 
+		       field_officer_employee_id, COALESCE(signed_agreement_url, ''), 
 		       disbursement_notes, updated_at
 		FROM loans 
 		WHERE id = $1
@@ -278,3 +621,253 @@ func (r *loanRepository) GetDisbursedLoan(ctx context.Context, loanID uuid.UUID)
 
 	return &response, nil
 }
+
+func (r *loanRepository) AssignValidator(ctx context.Context, loanID, validatorID uuid.UUID) error {
+	query := `
+		UPDATE loans
+		SET assigned_validator_employee_id = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND current_state = $3
+	`
+
+	if db, ok := r.db.(database.Executor); ok {
+		result, err := db.Exec(ctx, query, loanID, validatorID, constants.PROPOSED)
+		if err != nil {
+			return fmt.Errorf("failed to assign validator: %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("loan must be in proposed state")
+		}
+	} else {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	return nil
+}
+
+func (r *loanRepository) GetLoanApprovals(ctx context.Context, loanID uuid.UUID) ([]models.LoanApproval, error) {
+	query := `
+		SELECT id, loan_id, employee_id, employee_role, COALESCE(approval_notes, ''),
+		       survey_flags_acknowledged, created_at
+		FROM loan_approvals
+		WHERE loan_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []models.LoanApproval{}
+	for rows.Next() {
+		var approval models.LoanApproval
+		if err := rows.Scan(
+			&approval.ID,
+			&approval.LoanID,
+			&approval.EmployeeID,
+			&approval.EmployeeRole,
+			&approval.ApprovalNotes,
+			&approval.SurveyFlagsAcknowledged,
+			&approval.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan loan approval: %w", err)
+		}
+		approvals = append(approvals, approval)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get loan approvals: %w", err)
+	}
+
+	return approvals, nil
+}
+
+// AddLoanApproval records one approval under a lock on the loan row. The tier's
+// required approvals are fixed on the loan with the first approval, so later
+// config changes do not move the goalposts for loans already in review.
+func (r *loanRepository) AddLoanApproval(ctx context.Context, approval *models.LoanApproval, requiredApprovals int, allowedRoles []string) (int, int, error) {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return 0, 0, fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var currentState string
+	var required, received int
+	err = tx.QueryRow(ctx, `
+		SELECT current_state, required_approvals,
+		       (SELECT COUNT(*) FROM loan_approvals WHERE loan_id = loans.id)
+		FROM loans
+		WHERE id = $1
+		FOR UPDATE
+	`, approval.LoanID).Scan(&currentState, &required, &received)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, fmt.Errorf("loan not found")
+		}
+		return 0, 0, fmt.Errorf("failed to lock loan: %w", err)
+	}
+
+	if currentState != constants.PROPOSED {
+		return 0, 0, fmt.Errorf("loan must be in proposed state")
+	}
+
+	if received == 0 {
+		required = requiredApprovals
+		if _, err := tx.Exec(ctx, `UPDATE loans SET required_approvals = $2 WHERE id = $1`, approval.LoanID, required); err != nil {
+			return 0, 0, fmt.Errorf("failed to set required approvals: %w", err)
+		}
+	}
+
+	if received >= required {
+		return 0, 0, fmt.Errorf("loan already has required approvals")
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO loan_approvals (id, loan_id, employee_id, employee_role, approval_notes, survey_flags_acknowledged, created_at)
+		SELECT $1, $2, e.id, e.employee_role, NULLIF($4, ''), $7, $5
+		FROM employees e
+		WHERE e.id = $3 AND e.employee_role::text = ANY($6)
+		RETURNING employee_role
+	`,
+		approval.ID,
+		approval.LoanID,
+		approval.EmployeeID,
+		approval.ApprovalNotes,
+		approval.CreatedAt,
+		allowedRoles,
+		approval.SurveyFlagsAcknowledged,
+	).Scan(&approval.EmployeeRole)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return 0, 0, fmt.Errorf("approver role not allowed for this tier")
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			return 0, 0, fmt.Errorf("employee already approved this loan")
+		}
+		return 0, 0, fmt.Errorf("failed to record approval: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return received + 1, required, nil
+}
+
+// ListPendingApprovals returns surveyed PROPOSED loans, oldest first, limited
+// to the branch unless allBranches is set.
+func (r *loanRepository) ListPendingApprovals(ctx context.Context, branch string, allBranches bool) ([]models.PendingApproval, error) {
+	query := `
+		SELECT
+			l.id, l.borrower_id, b.full_name, COALESCE(b.branch, ''), l.principal_amount,
+			l.loan_term_month, l.field_validator_employee_id, l.survey_date, l.created_at,
+			l.required_approvals,
+			ARRAY(SELECT la.employee_id::text FROM loan_approvals la WHERE la.loan_id = l.id ORDER BY la.created_at),
+			ARRAY(SELECT DISTINCT sf.flag_type FROM survey_flags sf
+			      JOIN documents d ON d.id = sf.document_id
+			      WHERE sf.loan_id = l.id AND d.deleted_at IS NULL
+			      ORDER BY sf.flag_type)
+		FROM loans l
+		JOIN borrowers b ON l.borrower_id = b.id
+		WHERE l.current_state = $1
+		  AND l.field_validator_employee_id IS NOT NULL
+		  AND l.survey_date IS NOT NULL
+		  AND ($3 OR COALESCE(b.branch, '') = $2)
+		ORDER BY l.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, constants.PROPOSED, branch, allBranches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending approvals: %w", err)
+	}
+	defer rows.Close()
+
+	pending := []models.PendingApproval{}
+	for rows.Next() {
+		var item models.PendingApproval
+		var surveyDate time.Time
+		var approverIDs []string
+
+		if err := rows.Scan(
+			&item.LoanID,
+			&item.BorrowerID,
+			&item.BorrowerName,
+			&item.BorrowerBranch,
+			&item.PrincipalAmount,
+			&item.LoanTermMonth,
+			&item.FieldValidatorEmployeeID,
+			&surveyDate,
+			&item.CreatedAt,
+			&item.RequiredApprovals,
+			&approverIDs,
+			&item.SurveyFlagTypes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pending approval: %w", err)
+		}
+
+		item.SurveyDate = surveyDate.Format("2006-01-02")
+		item.ApproverEmployeeIDs = make([]uuid.UUID, 0, len(approverIDs))
+		for _, id := range approverIDs {
+			item.ApproverEmployeeIDs = append(item.ApproverEmployeeIDs, uuid.MustParse(id))
+		}
+		item.ReceivedApprovals = len(item.ApproverEmployeeIDs)
+
+		pending = append(pending, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list pending approvals: %w", err)
+	}
+
+	return pending, nil
+}
+
+// GetSurveyFlags returns the flags raised on the loan's survey documents that
+// have not been deleted.
+func (r *loanRepository) GetSurveyFlags(ctx context.Context, loanID uuid.UUID) ([]models.SurveyFlag, error) {
+	query := `
+		SELECT sf.id, sf.loan_id, sf.document_id, sf.flag_type, sf.detail, sf.created_at
+		FROM survey_flags sf
+		JOIN documents d ON d.id = sf.document_id
+		WHERE sf.loan_id = $1 AND d.deleted_at IS NULL
+		ORDER BY sf.created_at, sf.flag_type
+	`
+
+	rows, err := r.db.Query(ctx, query, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get survey flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []models.SurveyFlag{}
+	for rows.Next() {
+		var flag models.SurveyFlag
+		if err := rows.Scan(
+			&flag.ID,
+			&flag.LoanID,
+			&flag.DocumentID,
+			&flag.FlagType,
+			&flag.Detail,
+			&flag.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan survey flag: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get survey flags: %w", err)
+	}
+
+	return flags, nil
+}
@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type MFARepository interface {
+	GetUserMFA(ctx context.Context, userID uuid.UUID, userType string) (*models.UserMFA, error)
+	SavePendingSecret(ctx context.Context, userID uuid.UUID, userType, secret string) error
+	EnableMFA(ctx context.Context, userID uuid.UUID, userType string, step int64, recoveryCodeHashes []string) error
+	DisableMFA(ctx context.Context, userID uuid.UUID, userType string) error
+	MarkStepUsed(ctx context.Context, userID uuid.UUID, userType string, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, userType, codeHash string) (bool, error)
+	RecordFailedAttempt(ctx context.Context, userID uuid.UUID, userType string, maxAttempts int, lockout time.Duration) error
+}
+
+type mfaRepository struct {
+	db database.Querier
+}
+
+func NewMFARepository(db database.Querier) MFARepository {
+	return &mfaRepository{
+		db: db,
+	}
+}
+
+// GetUserMFA returns nil without error when the user never started enrolment.
+func (r *mfaRepository) GetUserMFA(ctx context.Context, userID uuid.UUID, userType string) (*models.UserMFA, error) {
+	query := `
+		SELECT user_id, user_type, totp_secret, is_enabled, enabled_at,
+		       last_used_step, failed_attempts, locked_until
+		FROM user_mfa
+		WHERE user_id = $1 AND user_type = $2
+	`
+
+	var mfa models.UserMFA
+	err := r.db.QueryRow(ctx, query, userID, userType).Scan(
+		&mfa.UserID,
+		&mfa.UserType,
+		&mfa.TOTPSecret,
+		&mfa.IsEnabled,
+		&mfa.EnabledAt,
+		&mfa.LastUsedStep,
+		&mfa.FailedAttempts,
+		&mfa.LockedUntil,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user mfa: %w", err)
+	}
+
+	return &mfa, nil
+}
+
+func (r *mfaRepository) SavePendingSecret(ctx context.Context, userID uuid.UUID, userType, secret string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, user_type, totp_secret, is_enabled)
+		VALUES ($1, $2, $3, false)
+		ON CONFLICT (user_id, user_type) DO UPDATE
+		SET totp_secret = EXCLUDED.totp_secret,
+		    last_used_step = 0,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE user_mfa.is_enabled = false
+	`
+
+	if db, ok := r.db.(database.Executor); ok {
+		result, err := db.Exec(ctx, query, userID, userType, secret)
+		if err != nil {
+			return fmt.Errorf("failed to save mfa secret: %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("mfa already enabled")
+		}
+	} else {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	return nil
+}
+
+func (r *mfaRepository) EnableMFA(ctx context.Context, userID uuid.UUID, userType string, step int64, recoveryCodeHashes []string) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE user_mfa
+		SET is_enabled = true,
+		    enabled_at = CURRENT_TIMESTAMP,
+		    last_used_step = $3,
+		    failed_attempts = 0,
+		    locked_until = NULL,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND user_type = $2 AND is_enabled = false
+	`, userID, userType, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("mfa setup not found")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1 AND user_type = $2`, userID, userType); err != nil {
+		return fmt.Errorf("failed to clear recovery codes: %w", err)
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO user_mfa_recovery_codes (user_id, user_type, code_hash)
+			VALUES ($1, $2, $3)
+		`, userID, userType, hash)
+		if err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mfaRepository) DisableMFA(ctx context.Context, userID uuid.UUID, userType string) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1 AND user_type = $2`, userID, userType); err != nil {
+		return fmt.Errorf("failed to clear recovery codes: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1 AND user_type = $2`, userID, userType); err != nil {
+		return fmt.Errorf("failed to disable mfa: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// MarkStepUsed moves last_used_step forward. It reports false when the step was
+// already used, which means the code is being replayed.
+func (r *mfaRepository) MarkStepUsed(ctx context.Context, userID uuid.UUID, userType string, step int64) (bool, error) {
+	query := `
+		UPDATE user_mfa
+		SET last_used_step = $3,
+		    failed_attempts = 0,
+		    locked_until = NULL,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND user_type = $2 AND last_used_step < $3
+	`
+
+	if db, ok := r.db.(database.Executor); ok {
+		result, err := db.Exec(ctx, query, userID, userType, step)
+		if err != nil {
+			return false, fmt.Errorf("failed to update mfa step: %w", err)
+		}
+		return result.RowsAffected() > 0, nil
+	}
+
+	return false, fmt.Errorf("database does not support Exec operation")
+}
+
+func (r *mfaRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, userType, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_mfa_recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM user_mfa_recovery_codes
+			WHERE user_id = $1 AND user_type = $2 AND code_hash = $3 AND used_at IS NULL
+			LIMIT 1
+		)
+	`
+
+	if db, ok := r.db.(database.Executor); ok {
+		result, err := db.Exec(ctx, query, userID, userType, codeHash)
+		if err != nil {
+			return false, fmt.Errorf("failed to consume recovery code: %w", err)
+		}
+		return result.RowsAffected() > 0, nil
+	}
+
+	return false, fmt.Errorf("database does not support Exec operation")
+}
+
+func (r *mfaRepository) RecordFailedAttempt(ctx context.Context, userID uuid.UUID, userType string, maxAttempts int, lockout time.Duration) error {
+	query := `
+		UPDATE user_mfa
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $3 THEN 0 ELSE failed_attempts + 1 END,
+		    locked_until = CASE WHEN failed_attempts + 1 >= $3 THEN $4 ELSE locked_until END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND user_type = $2
+	`
+
+	if db, ok := r.db.(database.Executor); ok {
+		_, err := db.Exec(ctx, query, userID, userType, maxAttempts, time.Now().Add(lockout))
+		if err != nil {
+			return fmt.Errorf("failed to record mfa attempt: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("database does not support Exec operation")
+}
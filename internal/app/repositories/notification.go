@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type NotificationRepository interface {
+	GetRecipient(ctx context.Context, userType string, userID uuid.UUID) (*models.NotificationRecipient, error)
+	// GetPreferences returns the preferences the user has saved, types they
+	// never changed are missing.
+	GetPreferences(ctx context.Context, userType string, userID uuid.UUID) ([]models.NotificationPreference, error)
+	SavePreferences(ctx context.Context, userType string, userID uuid.UUID, preferences []models.NotificationPreference) error
+}
+
+type notificationRepository struct {
+	db database.Querier
+}
+
+func NewNotificationRepository(db database.Querier) NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+func (r *notificationRepository) GetRecipient(ctx context.Context, userType string, userID uuid.UUID) (*models.NotificationRecipient, error) {
+	var query string
+	switch userType {
+	case constants.USER_INVESTOR:
+		query = `SELECT id, full_name, email, phone_number FROM investors WHERE id = $1`
+	case constants.USER_BORROWER:
+		query = `SELECT id, full_name, COALESCE(email, ''), phone_number FROM borrowers WHERE id = $1`
+	default:
+		return nil, fmt.Errorf("unsupported recipient type: %s", userType)
+	}
+
+	recipient := models.NotificationRecipient{UserType: userType}
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&recipient.UserID,
+		&recipient.FullName,
+		&recipient.Email,
+		&recipient.PhoneNumber,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("recipient not found")
+		}
+		return nil, fmt.Errorf("failed to get recipient: %w", err)
+	}
+
+	return &recipient, nil
+}
+
+func (r *notificationRepository) GetPreferences(ctx context.Context, userType string, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	query := `
+		SELECT notification_type, email_enabled, sms_enabled
+		FROM notification_preferences
+		WHERE user_type = $1 AND user_id = $2
+		ORDER BY notification_type
+	`
+
+	rows, err := r.db.Query(ctx, query, userType, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var preferences []models.NotificationPreference
+	for rows.Next() {
+		var preference models.NotificationPreference
+		if err := rows.Scan(&preference.NotificationType, &preference.Email, &preference.SMS); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		preferences = append(preferences, preference)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification preferences: %w", err)
+	}
+
+	return preferences, nil
+}
+
+func (r *notificationRepository) SavePreferences(ctx context.Context, userType string, userID uuid.UUID, preferences []models.NotificationPreference) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO notification_preferences (user_id, user_type, notification_type, email_enabled, sms_enabled, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_type, user_id, notification_type)
+		DO UPDATE SET email_enabled = EXCLUDED.email_enabled, sms_enabled = EXCLUDED.sms_enabled, updated_at = EXCLUDED.updated_at
+	`
+
+	for _, preference := range preferences {
+		_, err := tx.Exec(ctx, query, userID, userType, preference.NotificationType, preference.Email, preference.SMS)
+		if err != nil {
+			return fmt.Errorf("failed to save notification preference: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
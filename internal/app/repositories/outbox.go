@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type OutboxRepository interface {
+	// ClaimEvents leases the oldest unpublished event of each loan when it is
+	// due, so a later event is never published before an earlier one.
+	ClaimEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error
+}
+
+type outboxRepository struct {
+	db database.Querier
+}
+
+func NewOutboxRepository(db database.Querier) OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+func (r *outboxRepository) ClaimEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.OutboxEvent, error) {
+	// The lease keeps other relays off a claimed event, the row is checked
+	// again when it is updated
+	rows, err := r.db.Query(ctx, `
+		UPDATE outbox o
+		SET locked_until = $2
+		FROM (
+		    SELECT head.id
+		    FROM (
+		        SELECT DISTINCT ON (aggregate_id) id, next_attempt_at, locked_until
+		        FROM outbox
+		        WHERE published_at IS NULL
+		        ORDER BY aggregate_id, id
+		    ) head
+		    WHERE head.next_attempt_at <= $1
+		      AND (head.locked_until IS NULL OR head.locked_until <= $1)
+		    ORDER BY head.id
+		    LIMIT $3
+		) due
+		WHERE o.id = due.id AND o.published_at IS NULL
+		  AND (o.locked_until IS NULL OR o.locked_until <= $1)
+		RETURNING o.id, o.event_id, o.aggregate_type, o.aggregate_id, o.event_type,
+		          o.payload, o.occurred_at, o.attempts
+	`, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.OutboxEvent{}
+	for rows.Next() {
+		var event models.OutboxEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.EventID,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.EventType,
+			&event.Payload,
+			&event.OccurredAt,
+			&event.Attempts,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox events: %w", err)
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	return events, nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	_, err := db.Exec(ctx, `
+		UPDATE outbox
+		SET published_at = $2, attempts = attempts + 1, locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`, id, publishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, lastError string) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	_, err := db.Exec(ctx, `
+		UPDATE outbox
+		SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3, locked_until = NULL
+		WHERE id = $1
+	`, id, nextAttemptAt, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+// insertOutboxEvent saves an event inside the transaction that makes the
+// change it describes.
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, event *models.OutboxEvent) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO outbox (event_id, aggregate_type, aggregate_id, event_type, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`,
+		event.EventID,
+		event.AggregateType,
+		event.AggregateID,
+		event.EventType,
+		event.Payload,
+		event.OccurredAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save %s event: %w", event.EventType, err)
+	}
+
+	return nil
+}
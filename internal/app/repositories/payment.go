@@ -0,0 +1,302 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type PaymentRepository interface {
+	// EnsureVirtualAccount returns the borrower's virtual account, assigning
+	// prefix followed by the next number of the sequence when they have none.
+	EnsureVirtualAccount(ctx context.Context, borrowerID uuid.UUID, prefix string) (*models.VirtualAccount, error)
+	FindBorrowerByVirtualAccount(ctx context.Context, virtualAccountNumber string) (uuid.UUID, error)
+	ListDisbursedLoanIDs(ctx context.Context, borrowerID uuid.UUID) ([]uuid.UUID, error)
+	// CreatePayment records a payment and its event, if any. It returns false
+	// without changes when the gateway already reported the payment.
+	CreatePayment(ctx context.Context, payment *models.IncomingPayment, event *models.OutboxEvent) (bool, error)
+	GetPaymentByGatewayID(ctx context.Context, gateway, gatewayPaymentID string) (*models.IncomingPayment, error)
+	GetPayment(ctx context.Context, paymentID uuid.UUID) (*models.IncomingPayment, error)
+	// ListPayments returns the latest payments with status.
+	ListPayments(ctx context.Context, status string, limit int) ([]models.IncomingPayment, error)
+	// ResolvePayment saves the resolution of a payment in suspense.
+	ResolvePayment(ctx context.Context, payment *models.IncomingPayment, event *models.OutboxEvent) error
+}
+
+type paymentRepository struct {
+	db database.Querier
+}
+
+func NewPaymentRepository(db database.Querier) PaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+func (r *paymentRepository) EnsureVirtualAccount(ctx context.Context, borrowerID uuid.UUID, prefix string) (*models.VirtualAccount, error) {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return nil, fmt.Errorf("database does not support Exec operation")
+	}
+
+	_, err := db.Exec(ctx, `
+		UPDATE borrowers
+		SET virtual_account_number = $2 || LPAD(nextval('borrower_virtual_account_seq')::text, 10, '0'),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND virtual_account_number IS NULL
+	`, borrowerID, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign virtual account: %w", err)
+	}
+
+	var account models.VirtualAccount
+	err = r.db.QueryRow(ctx, `
+		SELECT id, full_name, virtual_account_number
+		FROM borrowers
+		WHERE id = $1
+	`, borrowerID).Scan(
+		&account.BorrowerID,
+		&account.BorrowerName,
+		&account.VirtualAccountNumber,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("borrower not found")
+		}
+		return nil, fmt.Errorf("failed to get virtual account: %w", err)
+	}
+
+	return &account, nil
+}
+
+func (r *paymentRepository) FindBorrowerByVirtualAccount(ctx context.Context, virtualAccountNumber string) (uuid.UUID, error) {
+	var borrowerID uuid.UUID
+	err := r.db.QueryRow(ctx, `SELECT id FROM borrowers WHERE virtual_account_number = $1`, virtualAccountNumber).Scan(&borrowerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, fmt.Errorf("virtual account not found")
+		}
+		return uuid.Nil, fmt.Errorf("failed to find virtual account: %w", err)
+	}
+
+	return borrowerID, nil
+}
+
+func (r *paymentRepository) ListDisbursedLoanIDs(ctx context.Context, borrowerID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id FROM loans
+		WHERE borrower_id = $1 AND current_state = $2
+		ORDER BY disbursement_date
+	`, borrowerID, constants.DISBURSED)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disbursed loans: %w", err)
+	}
+	defer rows.Close()
+
+	var loanIDs []uuid.UUID
+	for rows.Next() {
+		var loanID uuid.UUID
+		if err := rows.Scan(&loanID); err != nil {
+			return nil, fmt.Errorf("failed to scan loan: %w", err)
+		}
+		loanIDs = append(loanIDs, loanID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate loans: %w", err)
+	}
+
+	return loanIDs, nil
+}
+
+func (r *paymentRepository) CreatePayment(ctx context.Context, payment *models.IncomingPayment, event *models.OutboxEvent) (bool, error) {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return false, fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO incoming_payments (
+			id, gateway, gateway_payment_id, virtual_account_number, amount, payer_name,
+			paid_at, payload, status, borrower_id, loan_id, suspense_reason, created_at
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, NULLIF($12, ''), $13)
+		ON CONFLICT (gateway, gateway_payment_id) DO NOTHING
+	`,
+		payment.ID,
+		payment.Gateway,
+		payment.GatewayPaymentID,
+		payment.VirtualAccountNumber,
+		payment.Amount,
+		payment.PayerName,
+		payment.PaidAt,
+		payment.Payload,
+		payment.Status,
+		payment.BorrowerID,
+		payment.LoanID,
+		payment.SuspenseReason,
+		payment.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if event != nil {
+		if err := insertOutboxEvent(ctx, tx, event); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+const paymentColumns = `
+	id, gateway, gateway_payment_id, virtual_account_number, amount, COALESCE(payer_name, ''),
+	paid_at, status, borrower_id, loan_id, COALESCE(suspense_reason, ''), resolved_by_id,
+	resolved_at, COALESCE(resolution_note, ''), created_at`
+
+func scanPayment(row pgx.Row, payment *models.IncomingPayment) error {
+	return row.Scan(
+		&payment.ID,
+		&payment.Gateway,
+		&payment.GatewayPaymentID,
+		&payment.VirtualAccountNumber,
+		&payment.Amount,
+		&payment.PayerName,
+		&payment.PaidAt,
+		&payment.Status,
+		&payment.BorrowerID,
+		&payment.LoanID,
+		&payment.SuspenseReason,
+		&payment.ResolvedByID,
+		&payment.ResolvedAt,
+		&payment.ResolutionNote,
+		&payment.CreatedAt,
+	)
+}
+
+func (r *paymentRepository) GetPaymentByGatewayID(ctx context.Context, gateway, gatewayPaymentID string) (*models.IncomingPayment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM incoming_payments WHERE gateway = $1 AND gateway_payment_id = $2`
+
+	var payment models.IncomingPayment
+	if err := scanPayment(r.db.QueryRow(ctx, query, gateway, gatewayPaymentID), &payment); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("payment not found")
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) GetPayment(ctx context.Context, paymentID uuid.UUID) (*models.IncomingPayment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM incoming_payments WHERE id = $1`
+
+	var payment models.IncomingPayment
+	if err := scanPayment(r.db.QueryRow(ctx, query, paymentID), &payment); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("payment not found")
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) ListPayments(ctx context.Context, status string, limit int) ([]models.IncomingPayment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM incoming_payments
+		WHERE status = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []models.IncomingPayment{}
+	for rows.Next() {
+		var payment models.IncomingPayment
+		if err := scanPayment(rows, &payment); err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+func (r *paymentRepository) ResolvePayment(ctx context.Context, payment *models.IncomingPayment, event *models.OutboxEvent) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE incoming_payments
+		SET status = $2, borrower_id = $3, loan_id = $4, resolved_by_id = $5,
+		    resolved_at = $6, resolution_note = $7
+		WHERE id = $1 AND status = $8
+	`,
+		payment.ID,
+		payment.Status,
+		payment.BorrowerID,
+		payment.LoanID,
+		payment.ResolvedByID,
+		payment.ResolvedAt,
+		payment.ResolutionNote,
+		constants.PAYMENT_SUSPENSE,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to resolve payment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("payment is not in suspense")
+	}
+
+	if event != nil {
+		if err := insertOutboxEvent(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
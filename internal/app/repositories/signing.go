@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type SigningRepository interface {
+	GetBorrowerContact(ctx context.Context, borrowerID uuid.UUID) (*models.BorrowerContact, error)
+	GetLoanAgreement(ctx context.Context, loanID uuid.UUID) (*models.Document, error)
+	GetLatestSession(ctx context.Context, loanID uuid.UUID) (*models.SigningSession, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*models.SigningSession, error)
+	CreateSession(ctx context.Context, session *models.SigningSession) error
+	FailSession(ctx context.Context, sessionID uuid.UUID) error
+	RecordFailedAttempt(ctx context.Context, sessionID uuid.UUID, maxAttempts int) (int, error)
+	CompleteSigning(ctx context.Context, session *models.SigningSession, signedAgreement *models.Document) error
+}
+
+type signingRepository struct {
+	db database.Querier
+}
+
+func NewSigningRepository(db database.Querier) SigningRepository {
+	return &signingRepository{
+		db: db,
+	}
+}
+
+const signingSessionSelect = `
+	SELECT id, loan_id, borrower_id, agreement_document_id, agreement_sha256, phone_number,
+	       otp_hash, expires_at, failed_attempts, status, requested_ip, requested_user_agent,
+	       signed_at, signed_ip, signed_user_agent, signed_document_id, created_at
+	FROM signing_sessions
+`
+
+func (r *signingRepository) GetBorrowerContact(ctx context.Context, borrowerID uuid.UUID) (*models.BorrowerContact, error) {
+	query := `SELECT id, full_name, phone_number FROM borrowers WHERE id = $1`
+
+	var contact models.BorrowerContact
+	err := r.db.QueryRow(ctx, query, borrowerID).Scan(&contact.BorrowerID, &contact.FullName, &contact.PhoneNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("borrower not found")
+		}
+		return nil, fmt.Errorf("failed to get borrower: %w", err)
+	}
+
+	return &contact, nil
+}
+
+// GetLoanAgreement returns the latest live loan agreement, the one the
+// borrower reviews and signs.
+func (r *signingRepository) GetLoanAgreement(ctx context.Context, loanID uuid.UUID) (*models.Document, error) {
+	query := documentSelect + `
+		WHERE d.loan_id = $1 AND d.document_type = $2
+		  AND d.deleted_at IS NULL AND d.scan_status = 'CLEAN'
+		ORDER BY d.version DESC
+		LIMIT 1
+	`
+
+	document, err := scanDocument(r.db.QueryRow(ctx, query, loanID, constants.DOCUMENT_LOAN_AGREEMENT))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("loan agreement not found")
+		}
+		return nil, fmt.Errorf("failed to get loan agreement: %w", err)
+	}
+
+	return document, nil
+}
+
+func (r *signingRepository) GetLatestSession(ctx context.Context, loanID uuid.UUID) (*models.SigningSession, error) {
+	query := signingSessionSelect + ` WHERE loan_id = $1 ORDER BY created_at DESC LIMIT 1`
+
+	session, err := scanSigningSession(r.db.QueryRow(ctx, query, loanID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("signing session not found")
+		}
+		return nil, fmt.Errorf("failed to get signing session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (r *signingRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.SigningSession, error) {
+	query := signingSessionSelect + ` WHERE id = $1`
+
+	session, err := scanSigningSession(r.db.QueryRow(ctx, query, sessionID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("signing session not found")
+		}
+		return nil, fmt.Errorf("failed to get signing session: %w", err)
+	}
+
+	return session, nil
+}
+
+// CreateSession starts a new session, codes sent earlier for the loan stop
+// working.
+func (r *signingRepository) CreateSession(ctx context.Context, session *models.SigningSession) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE signing_sessions SET status = $2
+		WHERE loan_id = $1 AND status = $3
+	`, session.LoanID, constants.SIGNING_SUPERSEDED, constants.SIGNING_PENDING)
+	if err != nil {
+		return fmt.Errorf("failed to supersede signing sessions: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO signing_sessions (
+			id, loan_id, borrower_id, agreement_document_id, agreement_sha256, phone_number,
+			otp_hash, expires_at, failed_attempts, status, requested_ip, requested_user_agent, created_at
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11, $12, $13)
+	`,
+		session.ID,
+		session.LoanID,
+		session.BorrowerID,
+		session.AgreementDocumentID,
+		session.AgreementSHA256,
+		session.PhoneNumber,
+		session.OTPHash,
+		session.ExpiresAt,
+		session.FailedAttempts,
+		session.Status,
+		session.RequestedIP,
+		session.RequestedUserAgent,
+		session.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create signing session: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FailSession closes a session whose code could not be delivered.
+func (r *signingRepository) FailSession(ctx context.Context, sessionID uuid.UUID) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	_, err := db.Exec(ctx, `
+		UPDATE signing_sessions SET status = $2
+		WHERE id = $1 AND status = $3
+	`, sessionID, constants.SIGNING_FAILED, constants.SIGNING_PENDING)
+	if err != nil {
+		return fmt.Errorf("failed to close signing session: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailedAttempt counts a wrong code and closes the session once
+// maxAttempts is reached. It returns the attempts so far.
+func (r *signingRepository) RecordFailedAttempt(ctx context.Context, sessionID uuid.UUID, maxAttempts int) (int, error) {
+	var attempts int
+	err := r.db.QueryRow(ctx, `
+		UPDATE signing_sessions
+		SET failed_attempts = failed_attempts + 1,
+		    status = CASE WHEN failed_attempts + 1 >= $2 THEN $3 ELSE status END
+		WHERE id = $1 AND status = $4
+		RETURNING failed_attempts
+	`, sessionID, maxAttempts, constants.SIGNING_FAILED, constants.SIGNING_PENDING).Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("signing session is no longer active")
+		}
+		return 0, fmt.Errorf("failed to record signing attempt: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// CompleteSigning closes the session as signed and records the signed
+// agreement, which becomes the loan's signed agreement.
+func (r *signingRepository) CompleteSigning(ctx context.Context, session *models.SigningSession, signedAgreement *models.Document) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertDocument(ctx, tx, signedAgreement); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE signing_sessions
+		SET status = $2, signed_at = $3, signed_ip = $4, signed_user_agent = $5, signed_document_id = $6
+		WHERE id = $1 AND status = $7
+	`,
+		session.ID,
+		constants.SIGNING_SIGNED,
+		session.SignedAt,
+		session.SignedIP,
+		session.SignedUserAgent,
+		signedAgreement.ID,
+		constants.SIGNING_PENDING,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete signing: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("signing session is no longer active")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func scanSigningSession(row pgx.Row) (*models.SigningSession, error) {
+	var session models.SigningSession
+	var agreementSHA256 sql.NullString
+	var requestedIP sql.NullString
+	var requestedUserAgent sql.NullString
+	var signedIP sql.NullString
+	var signedUserAgent sql.NullString
+
+	err := row.Scan(
+		&session.ID,
+		&session.LoanID,
+		&session.BorrowerID,
+		&session.AgreementDocumentID,
+		&agreementSHA256,
+		&session.PhoneNumber,
+		&session.OTPHash,
+		&session.ExpiresAt,
+		&session.FailedAttempts,
+		&session.Status,
+		&requestedIP,
+		&requestedUserAgent,
+		&session.SignedAt,
+		&signedIP,
+		&signedUserAgent,
+		&session.SignedDocumentID,
+		&session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	session.AgreementSHA256 = agreementSHA256.String
+	session.RequestedIP = requestedIP.String
+	session.RequestedUserAgent = requestedUserAgent.String
+	session.SignedIP = signedIP.String
+	session.SignedUserAgent = signedUserAgent.String
+	return &session, nil
+}
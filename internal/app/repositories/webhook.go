@@ -0,0 +1,439 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+	// EnqueueDeliveries queues body for every active subscription to the
+	// event's type. An event already queued for a subscription is skipped.
+	EnqueueDeliveries(ctx context.Context, event *models.OutboxEvent, body []byte, now time.Time) (int, error)
+	// ClaimDeliveries leases due deliveries, loaded with their subscription's
+	// URL and secret.
+	ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	// RecordAttempt logs an attempt and moves the delivery to attempt.Status.
+	RecordAttempt(ctx context.Context, attempt *models.WebhookAttempt) error
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	ListAttempts(ctx context.Context, deliveryID uuid.UUID) ([]models.WebhookAttempt, error)
+	// Redeliver queues a delivery again with a fresh set of attempts.
+	Redeliver(ctx context.Context, subscriptionID, deliveryID uuid.UUID, now time.Time) error
+}
+
+type webhookRepository struct {
+	db database.Querier
+}
+
+func NewWebhookRepository(db database.Querier) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+const webhookSubscriptionColumns = `
+	id, url, event_types, secret, COALESCE(description, ''), is_active,
+	created_by_id, created_at, updated_at`
+
+func scanWebhookSubscription(row pgx.Row, subscription *models.WebhookSubscription) error {
+	return row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		&subscription.EventTypes,
+		&subscription.Secret,
+		&subscription.Description,
+		&subscription.IsActive,
+		&subscription.CreatedByID,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO webhook_subscriptions (
+			id, url, event_types, secret, description, is_active,
+			created_by_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+	`,
+		subscription.ID,
+		subscription.URL,
+		subscription.EventTypes,
+		subscription.Secret,
+		subscription.Description,
+		subscription.IsActive,
+		subscription.CreatedByID,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE deleted_at IS NULL
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []models.WebhookSubscription{}
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		if err := scanWebhookSubscription(rows, &subscription); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := scanWebhookSubscription(r.db.QueryRow(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id), &subscription)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("webhook subscription not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	result, err := db.Exec(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $2, event_types = $3, description = NULLIF($4, ''), is_active = $5, updated_at = $6
+		WHERE id = $1 AND deleted_at IS NULL
+	`,
+		subscription.ID,
+		subscription.URL,
+		subscription.EventTypes,
+		subscription.Description,
+		subscription.IsActive,
+		subscription.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook subscription not found")
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	// The delivery log is kept, pending deliveries are not sent anymore
+	result, err := db.Exec(ctx, `
+		UPDATE webhook_subscriptions
+		SET is_active = false, deleted_at = $2, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, deletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook subscription not found")
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, event *models.OutboxEvent, body []byte, now time.Time) (int, error) {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return 0, fmt.Errorf("database does not support Exec operation")
+	}
+
+	result, err := db.Exec(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, body, status, next_attempt_at, created_at)
+		SELECT id, $1, $2, $3, $4, $5, $5
+		FROM webhook_subscriptions
+		WHERE is_active = true AND deleted_at IS NULL
+		  AND ($2 = ANY(event_types) OR $6 = ANY(event_types))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, event.EventID, event.EventType, body, constants.WEBHOOK_PENDING, now, constants.WEBHOOK_ALL_EVENTS)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *webhookRepository) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH due AS (
+		    SELECT d.id
+		    FROM webhook_deliveries d
+		    JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		    WHERE d.status IN ($3, $4) AND d.next_attempt_at <= $1
+		      AND (d.locked_until IS NULL OR d.locked_until <= $1)
+		      AND s.is_active = true AND s.deleted_at IS NULL
+		    ORDER BY d.next_attempt_at
+		    LIMIT $5
+		    FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET locked_until = $2
+		FROM due, webhook_subscriptions s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.body, d.status,
+		          d.attempts, d.next_attempt_at, d.last_status_code, COALESCE(d.last_error, ''),
+		          d.delivered_at, d.created_at, s.url, s.secret
+	`, now, leaseUntil, constants.WEBHOOK_PENDING, constants.WEBHOOK_RETRYING, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Body,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastStatusCode,
+			&delivery.LastError,
+			&delivery.DeliveredAt,
+			&delivery.CreatedAt,
+			&delivery.URL,
+			&delivery.Secret,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, attempt *models.WebhookAttempt) error {
+	txDB, ok := r.db.(database.Tx)
+	if !ok {
+		return fmt.Errorf("database does not support transactions")
+	}
+
+	tx, err := txDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+	`, attempt.DeliveryID, attempt.AttemptedAt, attempt.StatusCode, attempt.Error, attempt.DurationMS)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+
+	var deliveredAt *time.Time
+	if attempt.Status == constants.WEBHOOK_DELIVERED {
+		deliveredAt = &attempt.AttemptedAt
+	}
+
+	// A delivery that is not retried keeps its last next_attempt_at
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    attempts = attempts + 1,
+		    next_attempt_at = COALESCE($3, next_attempt_at),
+		    last_status_code = $4,
+		    last_error = NULLIF($5, ''),
+		    delivered_at = COALESCE($6, delivered_at),
+		    locked_until = NULL
+		WHERE id = $1
+	`, attempt.DeliveryID, attempt.Status, attempt.NextAttemptAt, attempt.StatusCode, attempt.Error, deliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+const webhookDeliveryColumns = `
+	id, subscription_id, event_id, event_type, body, status, attempts,
+	next_attempt_at, last_status_code, COALESCE(last_error, ''), delivered_at, created_at`
+
+func scanWebhookDelivery(row pgx.Row, delivery *models.WebhookDelivery) error {
+	return row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Body,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, subscriptionID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := scanWebhookDelivery(r.db.QueryRow(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE id = $1 AND subscription_id = $2
+	`, deliveryID, subscriptionID), &delivery)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("webhook delivery not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListAttempts(ctx context.Context, deliveryID uuid.UUID) ([]models.WebhookAttempt, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT delivery_id, attempted_at, status_code, COALESCE(error, ''), duration_ms
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY id
+	`, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []models.WebhookAttempt{}
+	for rows.Next() {
+		var attempt models.WebhookAttempt
+		if err := rows.Scan(
+			&attempt.DeliveryID,
+			&attempt.AttemptedAt,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMS,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhook attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func (r *webhookRepository) Redeliver(ctx context.Context, subscriptionID, deliveryID uuid.UUID, now time.Time) error {
+	db, ok := r.db.(database.Executor)
+	if !ok {
+		return fmt.Errorf("database does not support Exec operation")
+	}
+
+	// Earlier attempts stay in the log
+	result, err := db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $3, attempts = 0, next_attempt_at = $4, locked_until = NULL
+		WHERE id = $1 AND subscription_id = $2
+	`, deliveryID, subscriptionID, constants.WEBHOOK_PENDING, now)
+	if err != nil {
+		return fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("webhook delivery not found")
+	}
+
+	return nil
+}
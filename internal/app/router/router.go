@@ -1,19 +1,32 @@
 package router
 
 import (
+	"context"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/authz"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/controllers"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/database"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/notification"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/outbox"
 	repositories2 "github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
 	usecase2 "github.com/fajar-andriansyah/loan-engine/internal/app/usecase"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/webhook"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/bankverify"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/email"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/payout"
 	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdf"
-	"net/http"
-	"path/filepath"
-	"strings"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdfsign"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/scanner"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/sms"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"time"
+	// Survey time zones resolve even on images without zoneinfo
+	_ "time/tzdata"
 
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
 
@@ -25,30 +38,141 @@ func GetRouter() chi.Router {
 
 	db := database.GetConn()
 
-	pdfGenerator := pdf.NewPDFGenerator()
+	store := loadStore()
+	pdfGenerator := pdf.NewPDFGenerator(store, pdf.Config{
+		CompanyName:   viper.GetString("agreement.company_name"),
+		DefaultLocale: viper.GetString("agreement.default_locale"),
+		VerifyBaseURL: viper.GetString("agreement.verify_base_url"),
+		Signer:        loadSigner(),
+	})
 
 	// Repositories
 	authRepo := repositories2.NewAuthRepository(db)
+	mfaRepo := repositories2.NewMFARepository(db)
+	accessRepo := repositories2.NewAccessRepository(db)
+
+	// Authorization
+	policy := loadPolicy(db != nil, accessRepo)
+	guard := authz.NewGuard(accessRepo, policy)
 	loanRepo := repositories2.NewLoanRepository(db)
 	fileRepo := repositories2.NewFileRepository(db)
+	documentRepo := repositories2.NewDocumentRepository(db)
 	investmentRepo := repositories2.NewInvestmentRepository(db)
+	employeeRepo := repositories2.NewEmployeeRepository(db)
+	apiKeyRepo := repositories2.NewAPIKeyRepository(db)
+	templateRepo := repositories2.NewAgreementTemplateRepository(db)
+	signingRepo := repositories2.NewSigningRepository(db)
+	notificationRepo := repositories2.NewNotificationRepository(db)
+	outboxRepo := repositories2.NewOutboxRepository(db)
+	webhookRepo := repositories2.NewWebhookRepository(db)
+	bankVerificationRepo := repositories2.NewBankVerificationRepository(db)
+	paymentRepo := repositories2.NewPaymentRepository(db)
+	idempotencyRepo := repositories2.NewIdempotencyRepository(db)
 
 	// Usecases
 	jwtSecret := viper.GetString("jwt.secret")
-	authUsecase := usecase2.NewAuthUsecase(authRepo, jwtSecret)
-	loanUsecase := usecase2.NewLoanUsecase(loanRepo, pdfGenerator)
-	fileUsecase := usecase2.NewFileUsecase(fileRepo)
-	investmentUsecase := usecase2.NewInvestmentUsecase(investmentRepo, pdfGenerator)
+	mfaConfig := usecase2.MFAConfig{
+		Issuer:        viper.GetString("mfa.issuer"),
+		RequiredRoles: viper.GetStringSlice("mfa.required_roles"),
+		ChallengeTTL:  viper.GetDuration("mfa.challenge_ttl"),
+		Skew:          viper.GetInt("mfa.skew"),
+		MaxAttempts:   viper.GetInt("mfa.max_attempts"),
+		Lockout:       viper.GetDuration("mfa.lockout"),
+	}
+	authUsecase := usecase2.NewAuthUsecase(authRepo, mfaRepo, jwtSecret, mfaConfig)
+	bankVerificationUsecase := usecase2.NewBankVerificationUsecase(bankVerificationRepo, loadBankVerifier(), guard, usecase2.BankVerificationConfig{
+		MatchThreshold: viper.GetFloat64("bank_verification.match_threshold"),
+	})
+	// The payout provider reports transfers back to the loan usecase it is
+	// built for
+	var loanUsecase usecase2.LoanUsecase
+	payouts := loadPayout(func(ctx context.Context, transfer payout.Transfer) error {
+		return loanUsecase.CompletePayout(ctx, transfer)
+	})
+	loanUsecase = usecase2.NewLoanUsecase(loanRepo, templateRepo, pdfGenerator, guard, loadApprovalTiers(), payouts, bankVerificationUsecase)
+	surveyConfig := loadSurveyConfig()
+	uploadIntake := usecase2.NewUploadIntake(documentRepo, store, loadScanner(), surveyConfig.Location)
+	fileUsecase := usecase2.NewFileUsecase(fileRepo, documentRepo, guard, store, uploadIntake, usecase2.FileConfig{
+		SigningSecret:   viper.GetString("files.signing_secret"),
+		SignedURLTTL:    viper.GetDuration("files.signed_url_ttl"),
+		MaxSignedURLTTL: viper.GetDuration("files.max_signed_url_ttl"),
+		Survey:          surveyConfig,
+	})
+	smsSender := loadSMS()
+	notifier := notification.NewNotifier(notificationRepo, store, loadEmail(), smsSender, notification.Config{
+		CompanyName: viper.GetString("agreement.company_name"),
+		Location:    surveyConfig.Location,
+	})
+	investmentUsecase := usecase2.NewInvestmentUsecase(investmentRepo, templateRepo, pdfGenerator, notifier, usecase2.InvestmentConfig{
+		AcceptanceWindow: viper.GetDuration("investments.acceptance_window"),
+	})
+	webhookUsecase := usecase2.NewWebhookUsecase(webhookRepo)
+	idempotencyUsecase := usecase2.NewIdempotencyUsecase(idempotencyRepo, usecase2.IdempotencyConfig{
+		TTL:         viper.GetDuration("idempotency.ttl"),
+		LockTimeout: viper.GetDuration("idempotency.lock_timeout"),
+	})
+	paymentUsecase := usecase2.NewPaymentUsecase(paymentRepo, loanRepo, guard, usecase2.PaymentConfig{
+		Gateway:              viper.GetString("payments.gateway"),
+		WebhookSecret:        viper.GetString("payments.webhook_secret"),
+		WebhookTolerance:     viper.GetDuration("payments.webhook_tolerance"),
+		VirtualAccountPrefix: viper.GetString("payments.virtual_account_prefix"),
+		VirtualAccountBank:   viper.GetString("payments.virtual_account_bank"),
+	})
+	employeeUsecase := usecase2.NewEmployeeUsecase(employeeRepo)
+	apiKeyUsecase := usecase2.NewAPIKeyUsecase(apiKeyRepo, usecase2.APIKeyConfig{
+		DefaultTTL:    viper.GetDuration("api_keys.default_ttl"),
+		MaxTTL:        viper.GetDuration("api_keys.max_ttl"),
+		RotationGrace: viper.GetDuration("api_keys.rotation_grace"),
+	})
+	templateUsecase := usecase2.NewAgreementTemplateUsecase(templateRepo)
+	notificationUsecase := usecase2.NewNotificationUsecase(notificationRepo)
+	signingUsecase := usecase2.NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGenerator, smsSender, usecase2.SigningConfig{
+		OTPTTL:         viper.GetDuration("e_signing.otp_ttl"),
+		MaxAttempts:    viper.GetInt("e_signing.max_attempts"),
+		ResendInterval: viper.GetDuration("e_signing.resend_interval"),
+	})
+
+	if db != nil {
+		startRescan(uploadIntake)
+		startInvestmentExpiry(investmentUsecase)
+		startIdempotencyPurge(idempotencyUsecase)
+		// Webhooks are queued first, queueing them again on a retry is a no-op
+		publisher := outbox.Publishers(
+			webhook.NewPublisher(webhookRepo),
+			outbox.PublisherFunc(investmentUsecase.NotifyEvent),
+		)
+		startOutboxRelay(outbox.NewRelay(outboxRepo, publisher, outbox.Config{
+			BatchSize: viper.GetInt("outbox.batch_size"),
+			Lease:     viper.GetDuration("outbox.lease"),
+			RetryBase: viper.GetDuration("outbox.retry_base"),
+			RetryMax:  viper.GetDuration("outbox.retry_max"),
+		}))
+		startWebhookDelivery(webhook.NewDispatcher(webhookRepo, webhook.Config{
+			BatchSize:   viper.GetInt("webhooks.batch_size"),
+			Lease:       viper.GetDuration("webhooks.lease"),
+			Timeout:     viper.GetDuration("webhooks.timeout"),
+			MaxAttempts: viper.GetInt("webhooks.max_attempts"),
+			RetryBase:   viper.GetDuration("webhooks.retry_base"),
+			RetryMax:    viper.GetDuration("webhooks.retry_max"),
+		}))
+		if err := templateUsecase.EnsureDefaultTemplates(context.Background()); err != nil {
+			log.Error().Err(err).Msg("Failed to publish default agreement templates")
+		}
+	}
 
 	// Controllers
 	authController := controller.NewAuthController(authUsecase)
-	loanController := controller.NewLoanController(loanUsecase)
+	loanController := controller.NewLoanController(loanUsecase, uploadIntake)
+	bankVerificationController := controller.NewBankVerificationController(bankVerificationUsecase)
 	fileController := controller.NewFileController(fileUsecase)
 	investmentController := controller.NewInvestmentController(investmentUsecase)
-
-	workDir, _ := filepath.Abs(".")
-	filesDir := http.Dir(filepath.Join(workDir, "uploads"))
-	FileServer(r, "/uploads", filesDir)
+	employeeController := controller.NewEmployeeController(employeeUsecase)
+	apiKeyController := controller.NewAPIKeyController(apiKeyUsecase)
+	templateController := controller.NewAgreementTemplateController(templateUsecase)
+	signingController := controller.NewSigningController(signingUsecase)
+	notificationController := controller.NewNotificationController(notificationUsecase)
+	webhookController := controller.NewWebhookController(webhookUsecase)
+	paymentController := controller.NewPaymentController(paymentUsecase)
 
 	// Routes
 	r.Get("/__health", controller.GetHealth)
@@ -58,38 +182,143 @@ func GetRouter() chi.Router {
 		// Public auth routes
 		r.Post("/auth/login", authController.Login)
 
+		// Signed links carry their own credential
+		r.Get("/shared-files/{file_id}", fileController.GetSharedFile)
+
+		// Public agreement verification, linked from the QR code on every page
+		r.Get("/verify/{id}", fileController.VerifyDocument)
+		r.Post("/verify/{id}", fileController.VerifyUploadedDocument)
+
+		// Payment gateway notifications, authenticated by their signature
+		r.Post("/payments/callback", paymentController.PaymentCallback)
+
+		// Second login step, authenticated with the MFA challenge token
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.MFAChallengeMiddleware())
+			r.Post("/auth/mfa/challenge/setup", authController.SetupMFA)
+			r.Post("/auth/mfa/challenge/verify", authController.VerifyMFA)
+		})
+
+		// Routes issuing credentials. Their responses hold secrets, which must
+		// not end up in the idempotency store.
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.JWTAuthMiddleware())
+			r.Use(middleware.JWTAuthMiddleware(apiKeyUsecase))
 
-			// Employee only routes
+			r.With(middleware.RequireUserType(constants.USER_EMPLOYEE, constants.USER_INVESTOR)).
+				Post("/auth/mfa/setup", authController.SetupMFA)
+			r.With(middleware.RequireUserType(constants.USER_EMPLOYEE, constants.USER_INVESTOR)).
+				Post("/auth/mfa/activate", authController.ActivateMFA)
+			r.With(middleware.RequirePermission(guard, constants.PERM_EMPLOYEE_MANAGE)).
+				Put("/employees/{id}/password", employeeController.ResetPassword)
+			r.With(middleware.RequirePermission(guard, constants.PERM_SERVICE_MANAGE)).
+				Post("/service-principals/{id}/api-keys", apiKeyController.CreateAPIKey)
+			r.With(middleware.RequirePermission(guard, constants.PERM_SERVICE_MANAGE)).
+				Post("/api-keys/{id}/rotate", apiKeyController.RotateAPIKey)
+			r.With(middleware.RequirePermission(guard, constants.PERM_WEBHOOK_MANAGE)).
+				Post("/webhooks", webhookController.CreateSubscription)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.JWTAuthMiddleware(apiKeyUsecase))
+			r.Use(middleware.Idempotency(idempotencyUsecase))
+
+			r.With(middleware.RequireUserType(constants.USER_EMPLOYEE, constants.USER_INVESTOR)).
+				Post("/auth/mfa/disable", authController.DisableMFA)
+
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_CREATE)).
+				Post("/loans", loanController.CreateLoanProposal)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_READ)).
+				Get("/loans/{id}", loanController.GetLoan)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_ASSIGN)).
+				Put("/loans/{id}/assign-validator", loanController.AssignValidator)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_APPROVE)).
+				Put("/loans/{id}/approve", loanController.ApproveLoan)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_APPROVE)).
+				Get("/loans/pending-approval", loanController.ListPendingApprovals)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_DISBURSE)).
+				Put("/loans/{id}/disburse", loanController.DisburseLoan)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_DISBURSE)).
+				Get("/loans/{id}/payouts", loanController.ListPayouts)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_DISBURSE)).
+				Post("/loans/{id}/bank-verifications", bankVerificationController.Verify)
+			r.With(middleware.RequirePermission(guard, constants.PERM_LOAN_DISBURSE)).
+				Get("/loans/{id}/bank-verifications", bankVerificationController.ListVerifications)
+			r.With(middleware.RequirePermission(guard, constants.PERM_BANK_OVERRIDE)).
+				Post("/loans/{id}/bank-verifications/override", bankVerificationController.Override)
+			r.With(middleware.RequirePermission(guard, constants.PERM_SURVEY_UPLOAD)).
+				Post("/files/upload", fileController.UploadSurveyDocument)
+			r.Get("/files/{file_id}", fileController.GetFile)
+			r.Post("/files/{file_id}/share", fileController.ShareFile)
+			r.Get("/loans/{id}/documents", fileController.ListDocuments)
+			r.With(middleware.RequirePermission(guard, constants.PERM_DOCUMENT_DELETE)).
+				Delete("/documents/{id}", fileController.DeleteDocument)
+			r.With(middleware.RequirePermission(guard, constants.PERM_INVESTMENT_CREATE)).
+				Post("/loans/{id}/investments", investmentController.CreateInvestment)
+			r.With(middleware.RequirePermission(guard, constants.PERM_INVESTMENT_CREATE)).
+				Post("/investments/{id}/accept", investmentController.AcceptAgreement)
+			r.With(middleware.RequirePermission(guard, constants.PERM_INVESTMENT_CREATE)).
+				Get("/investors/{investor_id}/portfolio", investmentController.GetPortfolio)
+			r.With(middleware.RequirePermission(guard, constants.PERM_AGREEMENT_SIGN)).
+				Post("/loans/{id}/e-sign/request", signingController.RequestSignature)
+			r.With(middleware.RequirePermission(guard, constants.PERM_AGREEMENT_SIGN)).
+				Post("/loans/{id}/e-sign/confirm", signingController.ConfirmSignature)
+
+			// Notification channels of borrowers and investors
 			r.Group(func(r chi.Router) {
-				r.Use(middleware.RequireUserType(constants.USER_EMPLOYEE))
+				r.Use(middleware.RequireUserType(constants.USER_BORROWER, constants.USER_INVESTOR))
+				r.Get("/notifications/preferences", notificationController.GetPreferences)
+				r.Put("/notifications/preferences", notificationController.UpdatePreferences)
+			})
 
-				// Field validator routes
-				r.Group(func(r chi.Router) {
-					r.Use(middleware.RequireRole(constants.ROLE_FIELD_VALIDATOR))
-					r.Post("/files/upload", fileController.UploadSurveyDocument)
-				})
+			r.With(middleware.RequireUserType(constants.USER_BORROWER)).
+				Get("/virtual-account", paymentController.GetVirtualAccount)
 
-				// Field officer routes
-				r.Group(func(r chi.Router) {
-					r.Use(middleware.RequireRole(constants.ROLE_FIELD_OFFICER))
-					r.Put("/loans/{id}/approve", loanController.ApproveLoan)
-					r.Put("/loans/{id}/disburse", loanController.DisburseLoan)
-				})
+			// Reconciliation of incoming payments
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(guard, constants.PERM_PAYMENT_RECONCILE))
+				r.Get("/payments", paymentController.ListPayments)
+				r.Post("/payments/{id}/resolve", paymentController.ResolvePayment)
+			})
 
+			// Employee administration
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(guard, constants.PERM_EMPLOYEE_MANAGE))
+				r.Get("/employees", employeeController.ListEmployees)
+				r.Post("/employees", employeeController.CreateEmployee)
+				r.Get("/employees/{id}", employeeController.GetEmployee)
+				r.Put("/employees/{id}", employeeController.UpdateEmployee)
+				r.Delete("/employees/{id}", employeeController.DeactivateEmployee)
+				r.Put("/employees/{id}/activate", employeeController.ActivateEmployee)
+				r.Get("/employees/{id}/audit-logs", employeeController.ListAuditLogs)
 			})
 
-			// Borrower routes
+			// Service principals and their API keys
 			r.Group(func(r chi.Router) {
-				r.Use(middleware.RequireUserType(constants.USER_BORROWER))
-				r.Post("/loans", loanController.CreateLoanProposal)
+				r.Use(middleware.RequirePermission(guard, constants.PERM_SERVICE_MANAGE))
+				r.Get("/service-principals", apiKeyController.ListServicePrincipals)
+				r.Post("/service-principals", apiKeyController.CreateServicePrincipal)
+				r.Get("/service-principals/{id}/api-keys", apiKeyController.ListAPIKeys)
+				r.Delete("/api-keys/{id}", apiKeyController.RevokeAPIKey)
 			})
 
-			// Investor routes
+			// Agreement templates
 			r.Group(func(r chi.Router) {
-				r.Use(middleware.RequireUserType(constants.USER_INVESTOR))
-				r.Post("/loans/{id}/investments", investmentController.CreateInvestment)
+				r.Use(middleware.RequirePermission(guard, constants.PERM_TEMPLATE_MANAGE))
+				r.Get("/agreement-templates", templateController.ListTemplates)
+				r.Post("/agreement-templates", templateController.PublishTemplate)
+				r.Get("/agreement-templates/{type}/versions/{version}", templateController.GetTemplate)
+			})
+
+			// Webhook subscriptions and their delivery log
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(guard, constants.PERM_WEBHOOK_MANAGE))
+				r.Get("/webhooks", webhookController.ListSubscriptions)
+				r.Get("/webhooks/{id}", webhookController.GetSubscription)
+				r.Put("/webhooks/{id}", webhookController.UpdateSubscription)
+				r.Delete("/webhooks/{id}", webhookController.DeleteSubscription)
+				r.Get("/webhooks/{id}/deliveries", webhookController.ListDeliveries)
+				r.Get("/webhooks/{id}/deliveries/{delivery_id}", webhookController.GetDelivery)
+				r.Post("/webhooks/{id}/deliveries/{delivery_id}/redeliver", webhookController.Redeliver)
 			})
 		})
 
@@ -98,17 +327,319 @@ func GetRouter() chi.Router {
 	return r
 }
 
-func FileServer(r chi.Router, path string, root http.FileSystem) {
-	if path != "/" && path[len(path)-1] != '/' {
-		r.Get(path, http.RedirectHandler(path+"/", 301).ServeHTTP)
-		path += "/"
+// loadPolicy reads the role to permission mapping from config or the database.
+// Database policies are refreshed in the background.
+func loadPolicy(dbAvailable bool, accessRepo repositories2.AccessRepository) authz.Policy {
+	if viper.GetString("permissions.source") == "config" {
+		return authz.NewPolicy(viper.GetStringMapStringSlice("permissions.roles"))
+	}
+
+	if !dbAvailable {
+		log.Warn().Msg("Database unavailable, using default role permissions")
+		return authz.NewPolicy(authz.DefaultRolePermissions)
+	}
+
+	policy, err := authz.LoadPolicy(context.Background(), accessRepo)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load role permissions, using defaults")
+		policy = authz.NewPolicy(authz.DefaultRolePermissions)
+	}
+
+	if interval := viper.GetDuration("permissions.refresh_interval"); interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for range ticker.C {
+				rolePermissions, err := accessRepo.GetRolePermissions(context.Background())
+				if err != nil || len(rolePermissions) == 0 {
+					log.Warn().Err(err).Msg("Failed to refresh role permissions")
+					continue
+				}
+				policy.Replace(rolePermissions)
+			}
+		}()
+	}
+
+	return policy
+}
+
+// loadApprovalTiers reads approval.tiers. An invalid configuration falls back to
+// a single approver so the service still starts, and is logged loudly.
+func loadApprovalTiers() usecase2.ApprovalTiers {
+	var configured []usecase2.ApprovalTier
+	if err := viper.UnmarshalKey("approval.tiers", &configured); err != nil {
+		log.Error().Err(err).Msg("Failed to read approval tiers")
+	}
+
+	tiers, err := usecase2.NewApprovalTiers(configured)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid approval tiers, requiring a single FIELD_OFFICER approval")
+		tiers, _ = usecase2.NewApprovalTiers([]usecase2.ApprovalTier{
+			{RequiredApprovals: 1, Roles: []string{constants.ROLE_FIELD_OFFICER}},
+		})
+	}
+
+	return tiers
+}
+
+// loadStore builds the object store from storage.*. Uploads cannot work without
+// it, so a bad configuration stops the service.
+func loadStore() storage.Store {
+	store, err := storage.New(storage.Config{
+		Driver: viper.GetString("storage.driver"),
+		Local: storage.LocalConfig{
+			Root: viper.GetString("storage.local.root"),
+		},
+		S3: storage.S3Config{
+			Endpoint:     viper.GetString("storage.s3.endpoint"),
+			Region:       viper.GetString("storage.s3.region"),
+			Bucket:       viper.GetString("storage.s3.bucket"),
+			AccessKey:    viper.GetString("storage.s3.access_key"),
+			SecretKey:    viper.GetString("storage.s3.secret_key"),
+			UseSSL:       viper.GetBool("storage.s3.use_ssl"),
+			Prefix:       viper.GetString("storage.s3.prefix"),
+			CreateBucket: viper.GetBool("storage.s3.create_bucket"),
+		},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialise object storage")
 	}
-	path += "*"
 
-	r.Get(path, func(w http.ResponseWriter, r *http.Request) {
-		rctx := chi.RouteContext(r.Context())
-		pathPrefix := strings.TrimSuffix(rctx.RoutePattern(), "/*")
-		fs := http.StripPrefix(pathPrefix, http.FileServer(root))
-		fs.ServeHTTP(w, r)
+	return store
+}
+
+// loadScanner builds the malware scanner from scanner.*. Scanning is off unless
+// a driver is configured.
+func loadScanner() scanner.Scanner {
+	fileScanner, err := scanner.New(scanner.Config{
+		Driver: viper.GetString("scanner.driver"),
+		Clamd: scanner.ClamdConfig{
+			Network: viper.GetString("scanner.clamd.network"),
+			Address: viper.GetString("scanner.clamd.address"),
+			Timeout: viper.GetDuration("scanner.clamd.timeout"),
+		},
 	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialise malware scanner")
+	}
+
+	return fileScanner
+}
+
+// loadSurveyConfig reads survey.*. The time zone must resolve, otherwise
+// survey dates and photo times would be compared in the wrong zone.
+func loadSurveyConfig() usecase2.SurveyConfig {
+	location, err := time.LoadLocation(viper.GetString("survey.timezone"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid survey time zone")
+	}
+
+	return usecase2.SurveyConfig{
+		MaxDistanceMeters:    viper.GetFloat64("survey.max_distance_meters"),
+		CaptureTimeTolerance: viper.GetDuration("survey.capture_time_tolerance"),
+		Location:             location,
+	}
+}
+
+// startRescan retries documents stored while the scanner was unreachable.
+func startRescan(intake usecase2.UploadIntake) {
+	interval := viper.GetDuration("scanner.rescan_interval")
+	if interval <= 0 {
+		return
+	}
+	batch := viper.GetInt("scanner.rescan_batch")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			resolved, err := intake.RescanPending(context.Background(), batch)
+			if err != nil {
+				log.Warn().Err(err).Int("resolved", resolved).Msg("Failed to rescan pending documents")
+			}
+		}
+	}()
+}
+
+// startIdempotencyPurge deletes expired idempotency keys, every
+// idempotency.purge_interval.
+func startIdempotencyPurge(idempotencyUsecase usecase2.IdempotencyUsecase) {
+	interval := viper.GetDuration("idempotency.purge_interval")
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purged, err := idempotencyUsecase.PurgeExpired(context.Background())
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to purge expired idempotency keys")
+				continue
+			}
+			if purged > 0 {
+				log.Info().Int64("purged", purged).Msg("Expired idempotency keys purged")
+			}
+		}
+	}()
+}
+
+// startInvestmentExpiry cancels investments whose agreement was not accepted
+// in time, every investments.expiry_interval.
+func startInvestmentExpiry(investmentUsecase usecase2.InvestmentUsecase) {
+	interval := viper.GetDuration("investments.expiry_interval")
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			cancelled, err := investmentUsecase.CancelExpiredInvestments(context.Background())
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to cancel expired investments")
+				continue
+			}
+			for _, investment := range cancelled {
+				log.Info().
+					Str("investment_id", investment.ID.String()).
+					Str("loan_id", investment.LoanID.String()).
+					Msg("Investment cancelled, agreement not accepted in time")
+			}
+		}
+	}()
+}
+
+// startOutboxRelay publishes saved domain events every outbox.relay_interval.
+func startOutboxRelay(relay outbox.Relay) {
+	interval := viper.GetDuration("outbox.relay_interval")
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := relay.PublishPending(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("Failed to relay outbox events")
+			}
+		}
+	}()
+}
+
+// startWebhookDelivery sends queued webhook deliveries every
+// webhooks.delivery_interval.
+func startWebhookDelivery(dispatcher webhook.Dispatcher) {
+	interval := viper.GetDuration("webhooks.delivery_interval")
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := dispatcher.DeliverPending(context.Background()); err != nil {
+				log.Warn().Err(err).Msg("Failed to deliver webhooks")
+			}
+		}
+	}()
+}
+
+// loadSigner builds the agreement signer from signing.*. Agreements are left
+// unsigned unless a certificate is configured.
+func loadSigner() pdfsign.Signer {
+	signer, err := pdfsign.New(pdfsign.Config{
+		CertificatePath: viper.GetString("signing.certificate_path"),
+		Password:        viper.GetString("signing.password"),
+		Reason:          viper.GetString("signing.reason"),
+		Location:        viper.GetString("signing.location"),
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load signing certificate")
+	}
+
+	return signer
+}
+
+// loadPayout builds the payout provider from payout.*. Loans cannot be
+// disbursed without it, so a bad configuration stops the service.
+func loadPayout(callback payout.Callback) payout.Provider {
+	provider, err := payout.New(payout.Config{
+		Driver: viper.GetString("payout.driver"),
+		Simulated: payout.SimulatedConfig{
+			Delay:        viper.GetDuration("payout.simulated.delay"),
+			FailAccounts: viper.GetStringSlice("payout.simulated.fail_accounts"),
+		},
+	}, callback)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialise payout provider")
+	}
+
+	return provider
+}
+
+// loadBankVerifier builds the bank account verifier from bank_verification.*.
+// Loans cannot be disbursed without it, so a bad configuration stops the
+// service.
+func loadBankVerifier() bankverify.BankVerifier {
+	verifier, err := bankverify.New(bankverify.Config{
+		Driver: viper.GetString("bank_verification.driver"),
+		Fixture: bankverify.FixtureConfig{
+			Path: viper.GetString("bank_verification.fixture.path"),
+		},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialise bank account verifier")
+	}
+
+	return verifier
+}
+
+// loadSMS builds the SMS sender from sms.*. Borrowers cannot e-sign without
+// it, so a bad configuration stops the service.
+func loadSMS() sms.Sender {
+	sender, err := sms.New(sms.Config{
+		Driver: viper.GetString("sms.driver"),
+		File: sms.FileConfig{
+			Path: viper.GetString("sms.file.path"),
+		},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialise SMS sender")
+	}
+
+	return sender
+}
+
+// loadEmail builds the email sender from email.*. A bad configuration stops
+// the service.
+func loadEmail() email.Sender {
+	sender, err := email.New(email.Config{
+		Driver: viper.GetString("email.driver"),
+		From:   viper.GetString("email.from"),
+		File: email.FileConfig{
+			Dir: viper.GetString("email.file.dir"),
+		},
+		SMTP: email.SMTPConfig{
+			Host:     viper.GetString("email.smtp.host"),
+			Port:     viper.GetInt("email.smtp.port"),
+			Username: viper.GetString("email.smtp.username"),
+			Password: viper.GetString("email.smtp.password"),
+		},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialise email sender")
+	}
+
+	return sender
 }
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/fajar-andriansyah/loan-engine/internal/app/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Responses of these routes hold secrets, which must not be stored for replay.
+var credentialRoutes = []string{
+	"POST /api/v1/auth/mfa/setup",
+	"POST /api/v1/auth/mfa/activate",
+	"PUT /api/v1/employees/{id}/password",
+	"POST /api/v1/service-principals/{id}/api-keys",
+	"POST /api/v1/api-keys/{id}/rotate",
+	"POST /api/v1/webhooks",
+}
+
+func TestGetRouter_CredentialRoutesSkipIdempotency(t *testing.T) {
+	viper.Set("storage.local.root", t.TempDir())
+	viper.Set("bank_verification.fixture.path", "../../../config/bank_accounts.json")
+	t.Cleanup(viper.Reset)
+
+	r := GetRouter()
+	// Closures are named after the function returning them
+	idempotency := runtime.FuncForPC(reflect.ValueOf(middleware.Idempotency).Pointer()).Name() + "."
+
+	idempotent := map[string]bool{}
+	err := chi.Walk(r, func(method, route string, _ http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		for _, mw := range middlewares {
+			if strings.HasPrefix(runtime.FuncForPC(reflect.ValueOf(mw).Pointer()).Name(), idempotency) {
+				idempotent[method+" "+route] = true
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The walk must see the middleware, otherwise the check below proves nothing
+	require.True(t, idempotent["POST /api/v1/loans"])
+	for _, route := range credentialRoutes {
+		assert.False(t, idempotent[route], "%s is behind the idempotency middleware", route)
+	}
+}
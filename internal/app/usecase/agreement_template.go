@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdf"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// agreementTemplateTypes are the documents generated from templates.
+var agreementTemplateTypes = []string{constants.DOCUMENT_LOAN_AGREEMENT, constants.DOCUMENT_INVESTMENT_AGREEMENT}
+
+type AgreementTemplateUsecase interface {
+	PublishTemplate(ctx context.Context, employeeID string, req *models.PublishTemplateRequest) (*models.AgreementTemplate, error)
+	ListTemplates(ctx context.Context, templateType string) ([]models.AgreementTemplate, error)
+	GetTemplate(ctx context.Context, templateType string, version int) (*models.AgreementTemplate, error)
+	// EnsureDefaultTemplates publishes the built-in text as version 1 of each
+	// type that has no template yet.
+	EnsureDefaultTemplates(ctx context.Context) error
+}
+
+type agreementTemplateUsecase struct {
+	templateRepo repositories.AgreementTemplateRepository
+}
+
+func NewAgreementTemplateUsecase(templateRepo repositories.AgreementTemplateRepository) AgreementTemplateUsecase {
+	return &agreementTemplateUsecase{
+		templateRepo: templateRepo,
+	}
+}
+
+func (u *agreementTemplateUsecase) PublishTemplate(ctx context.Context, employeeID string, req *models.PublishTemplateRequest) (*models.AgreementTemplate, error) {
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	if err := pdf.ValidateTemplate(req.TemplateType, req.Body); err != nil {
+		return nil, err
+	}
+
+	agreementTemplate := &models.AgreementTemplate{
+		ID:           uuid.New(),
+		TemplateType: req.TemplateType,
+		Body:         req.Body,
+		ChangeNotes:  req.ChangeNotes,
+		CreatedByID:  &employeeUUID,
+		CreatedAt:    time.Now(),
+	}
+	if err := u.templateRepo.PublishTemplate(ctx, agreementTemplate); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("template_type", agreementTemplate.TemplateType).
+		Int("version", agreementTemplate.Version).
+		Str("employee_id", employeeID).
+		Msg("Agreement template published")
+
+	return agreementTemplate, nil
+}
+
+func (u *agreementTemplateUsecase) ListTemplates(ctx context.Context, templateType string) ([]models.AgreementTemplate, error) {
+	if templateType != "" && !isAgreementTemplateType(templateType) {
+		return nil, fmt.Errorf("unknown template type: %s", templateType)
+	}
+
+	return u.templateRepo.ListTemplates(ctx, templateType)
+}
+
+func (u *agreementTemplateUsecase) GetTemplate(ctx context.Context, templateType string, version int) (*models.AgreementTemplate, error) {
+	if !isAgreementTemplateType(templateType) {
+		return nil, fmt.Errorf("unknown template type: %s", templateType)
+	}
+
+	return u.templateRepo.GetTemplate(ctx, templateType, version)
+}
+
+func (u *agreementTemplateUsecase) EnsureDefaultTemplates(ctx context.Context) error {
+	for _, templateType := range agreementTemplateTypes {
+		_, err := u.templateRepo.GetLatestTemplate(ctx, templateType)
+		if err == nil {
+			continue
+		}
+		if err.Error() != "agreement template not found" {
+			return err
+		}
+
+		body, err := pdf.DefaultTemplate(templateType)
+		if err != nil {
+			return err
+		}
+
+		agreementTemplate := &models.AgreementTemplate{
+			ID:           uuid.New(),
+			TemplateType: templateType,
+			Body:         body,
+			ChangeNotes:  "Built-in template",
+			CreatedAt:    time.Now(),
+		}
+		if err := u.templateRepo.PublishTemplate(ctx, agreementTemplate); err != nil {
+			return err
+		}
+		log.Info().Str("template_type", templateType).Int("version", agreementTemplate.Version).Msg("Default agreement template published")
+	}
+
+	return nil
+}
+
+// agreementTemplateFor picks the template a loan's agreement is generated
+// with: the version its first agreement of that type used, so regenerating
+// never changes the terms, or the latest one for a first agreement.
+func agreementTemplateFor(ctx context.Context, templateRepo repositories.AgreementTemplateRepository, loanID uuid.UUID, documentType string) (*models.AgreementTemplate, error) {
+	version, err := templateRepo.GetLoanTemplateVersion(ctx, loanID, documentType)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		return templateRepo.GetLatestTemplate(ctx, documentType)
+	}
+	return templateRepo.GetTemplate(ctx, documentType, version)
+}
+
+func isAgreementTemplateType(templateType string) bool {
+	for _, candidate := range agreementTemplateTypes {
+		if candidate == templateType {
+			return true
+		}
+	}
+	return false
+}
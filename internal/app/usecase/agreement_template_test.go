@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishTemplate_RejectsInvalidTemplate(t *testing.T) {
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	templateUsecase := NewAgreementTemplateUsecase(mockTemplateRepo)
+
+	result, err := templateUsecase.PublishTemplate(context.Background(), uuid.New().String(), &models.PublishTemplateRequest{
+		TemplateType: "LOAN_AGREEMENT",
+		Body:         "# LOAN AGREEMENT\nBorrower: {{.Borrower.Name}}",
+	})
+
+	assert.Nil(t, result)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid template")
+	mockTemplateRepo.AssertNotCalled(t, "PublishTemplate", mock.Anything, mock.Anything)
+}
+
+func TestPublishTemplate_Success(t *testing.T) {
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	templateUsecase := NewAgreementTemplateUsecase(mockTemplateRepo)
+
+	employeeID := uuid.New()
+	mockTemplateRepo.On("PublishTemplate", mock.Anything, mock.MatchedBy(func(tmpl *models.AgreementTemplate) bool {
+		return tmpl.TemplateType == "LOAN_AGREEMENT" && *tmpl.CreatedByID == employeeID
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.AgreementTemplate).Version = 2
+	}).Return(nil)
+
+	result, err := templateUsecase.PublishTemplate(context.Background(), employeeID.String(), &models.PublishTemplateRequest{
+		TemplateType: "LOAN_AGREEMENT",
+		Body:         "# LOAN AGREEMENT\n{{.Company.Name}} lends {{rupiah .Loan.PrincipalAmount}} to {{.Loan.BorrowerName}}.",
+		ChangeNotes:  "Shorter wording",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Version)
+	assert.Equal(t, "Shorter wording", result.ChangeNotes)
+}
+
+func TestGetTemplate_UnknownType(t *testing.T) {
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	templateUsecase := NewAgreementTemplateUsecase(mockTemplateRepo)
+
+	_, err := templateUsecase.GetTemplate(context.Background(), "SURVEY_PHOTO", 1)
+
+	assert.EqualError(t, err, "unknown template type: SURVEY_PHOTO")
+}
+
+func TestEnsureDefaultTemplates_PublishesMissingTypes(t *testing.T) {
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	templateUsecase := NewAgreementTemplateUsecase(mockTemplateRepo)
+
+	mockTemplateRepo.On("GetLatestTemplate", mock.Anything, "LOAN_AGREEMENT").Return(testLoanTemplate, nil)
+	mockTemplateRepo.On("GetLatestTemplate", mock.Anything, "INVESTMENT_AGREEMENT").Return(nil, fmt.Errorf("agreement template not found"))
+	mockTemplateRepo.On("PublishTemplate", mock.Anything, mock.MatchedBy(func(tmpl *models.AgreementTemplate) bool {
+		return tmpl.TemplateType == "INVESTMENT_AGREEMENT" && tmpl.CreatedByID == nil && tmpl.Body != ""
+	})).Return(nil).Once()
+
+	assert.NoError(t, templateUsecase.EnsureDefaultTemplates(context.Background()))
+}
+
+func TestAgreementTemplateFor_RegeneratesWithPinnedVersion(t *testing.T) {
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	loanID := uuid.New()
+
+	pinned := &models.AgreementTemplate{TemplateType: "INVESTMENT_AGREEMENT", Version: 1}
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "INVESTMENT_AGREEMENT").Return(1, nil)
+	mockTemplateRepo.On("GetTemplate", mock.Anything, "INVESTMENT_AGREEMENT", 1).Return(pinned, nil)
+
+	result, err := agreementTemplateFor(context.Background(), mockTemplateRepo, loanID, "INVESTMENT_AGREEMENT")
+
+	require.NoError(t, err)
+	assert.Same(t, pinned, result)
+	mockTemplateRepo.AssertNotCalled(t, "GetLatestTemplate", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,307 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/authz"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	API_KEY_PREFIX       = "lek"
+	API_KEY_ID_BYTES     = 6
+	API_KEY_SECRET_BYTES = 32
+	// Avoid a write on every request, last_used_at is only advanced this often
+	API_KEY_TOUCH_INTERVAL = time.Minute
+)
+
+type APIKeyConfig struct {
+	DefaultTTL    time.Duration
+	MaxTTL        time.Duration
+	RotationGrace time.Duration
+}
+
+type APIKeyUsecase interface {
+	CreateServicePrincipal(ctx context.Context, actorID string, req *models.CreateServicePrincipalRequest) (*models.ServicePrincipal, error)
+	ListServicePrincipals(ctx context.Context) ([]models.ServicePrincipal, error)
+	CreateAPIKey(ctx context.Context, actorID, principalID string, req *models.CreateAPIKeyRequest) (*models.APIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, principalID string) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, actorID, keyID string, req *models.RotateAPIKeyRequest) (*models.APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, keyID string) error
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.JWTClaims, error)
+}
+
+type apiKeyUsecase struct {
+	apiKeyRepo repositories.APIKeyRepository
+	config     APIKeyConfig
+	now        func() time.Time
+}
+
+func NewAPIKeyUsecase(apiKeyRepo repositories.APIKeyRepository, config APIKeyConfig) APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepo: apiKeyRepo,
+		config:     config,
+		now:        time.Now,
+	}
+}
+
+func (u *apiKeyUsecase) CreateServicePrincipal(ctx context.Context, actorID string, req *models.CreateServicePrincipalRequest) (*models.ServicePrincipal, error) {
+	actorUUID, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+
+	now := u.now()
+	principal := &models.ServicePrincipal{
+		ID:          uuid.New(),
+		Name:        strings.ToLower(strings.TrimSpace(req.Name)),
+		Description: req.Description,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.apiKeyRepo.CreateServicePrincipal(ctx, principal, actorUUID); err != nil {
+		return nil, err
+	}
+
+	return principal, nil
+}
+
+func (u *apiKeyUsecase) ListServicePrincipals(ctx context.Context) ([]models.ServicePrincipal, error) {
+	return u.apiKeyRepo.ListServicePrincipals(ctx)
+}
+
+func (u *apiKeyUsecase) CreateAPIKey(ctx context.Context, actorID, principalID string, req *models.CreateAPIKeyRequest) (*models.APIKeyResponse, error) {
+	actorUUID, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+
+	principalUUID, err := uuid.Parse(principalID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service principal ID")
+	}
+
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := u.config.DefaultTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if u.config.MaxTTL > 0 && ttl > u.config.MaxTTL {
+		return nil, fmt.Errorf("api key lifetime exceeds maximum")
+	}
+
+	principal, err := u.apiKeyRepo.GetServicePrincipal(ctx, principalUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !principal.IsActive {
+		return nil, fmt.Errorf("service principal inactive")
+	}
+
+	rawKey, key, err := u.newAPIKey(principalUUID, scopes, ttl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.apiKeyRepo.CreateAPIKey(ctx, key, actorUUID); err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeyResponse{Key: rawKey, APIKey: *key}, nil
+}
+
+func (u *apiKeyUsecase) ListAPIKeys(ctx context.Context, principalID string) ([]models.APIKey, error) {
+	principalUUID, err := uuid.Parse(principalID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service principal ID")
+	}
+
+	return u.apiKeyRepo.ListAPIKeys(ctx, principalUUID)
+}
+
+// RotateAPIKey issues a new key with the same scopes and lifetime, and keeps the
+// old key working for the grace period so callers can switch without downtime.
+func (u *apiKeyUsecase) RotateAPIKey(ctx context.Context, actorID, keyID string, req *models.RotateAPIKeyRequest) (*models.APIKeyResponse, error) {
+	actorUUID, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+
+	keyUUID, err := uuid.Parse(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api key ID")
+	}
+
+	oldKey, err := u.apiKeyRepo.GetAPIKey(ctx, keyUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	if oldKey.RevokedAt != nil {
+		return nil, fmt.Errorf("api key revoked")
+	}
+	if !oldKey.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("api key expired")
+	}
+
+	grace := u.config.RotationGrace
+	if req != nil && req.GracePeriodHours != nil {
+		grace = time.Duration(*req.GracePeriodHours) * time.Hour
+	}
+
+	ttl := oldKey.ExpiresAt.Sub(oldKey.CreatedAt)
+	if ttl <= 0 || (u.config.MaxTTL > 0 && ttl > u.config.MaxTTL) {
+		ttl = u.config.DefaultTTL
+	}
+
+	rawKey, newKey, err := u.newAPIKey(oldKey.ServicePrincipalID, oldKey.Scopes, ttl, &oldKey.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.apiKeyRepo.RotateAPIKey(ctx, oldKey.ID, now.Add(grace), newKey, actorUUID); err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeyResponse{Key: rawKey, APIKey: *newKey}, nil
+}
+
+func (u *apiKeyUsecase) RevokeAPIKey(ctx context.Context, keyID string) error {
+	keyUUID, err := uuid.Parse(keyID)
+	if err != nil {
+		return fmt.Errorf("invalid api key ID")
+	}
+
+	return u.apiKeyRepo.RevokeAPIKey(ctx, keyUUID)
+}
+
+// AuthenticateAPIKey resolves an X-API-Key header value to the claims of its
+// service principal, in the same shape handlers get for JWT users.
+func (u *apiKeyUsecase) AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.JWTClaims, error) {
+	prefix, ok := parseAPIKey(rawKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	credential, err := u.apiKeyRepo.GetCredentialByPrefix(ctx, prefix)
+	if err != nil {
+		if err.Error() == "api key not found" {
+			return nil, fmt.Errorf("invalid api key")
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(rawKey)), []byte(credential.KeyHash)) != 1 {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	now := u.now()
+	if credential.RevokedAt != nil {
+		return nil, fmt.Errorf("api key revoked")
+	}
+	if !credential.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("api key expired")
+	}
+	if !credential.PrincipalIsActive {
+		return nil, fmt.Errorf("service principal inactive")
+	}
+
+	if credential.LastUsedAt == nil || now.Sub(*credential.LastUsedAt) >= API_KEY_TOUCH_INTERVAL {
+		if err := u.apiKeyRepo.TouchAPIKey(ctx, credential.ID, now); err != nil {
+			log.Warn().Err(err).Str("api_key_id", credential.ID.String()).Msg("Failed to record api key usage")
+		}
+	}
+
+	return &models.JWTClaims{
+		UserID:   credential.ServicePrincipalID.String(),
+		UserType: constants.USER_SERVICE,
+		Role:     credential.PrincipalName,
+		TokenUse: constants.TOKEN_USE_API_KEY,
+		Scopes:   credential.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        credential.ID.String(),
+			Subject:   credential.PrincipalName,
+			ExpiresAt: jwt.NewNumericDate(credential.ExpiresAt),
+		},
+	}, nil
+}
+
+func (u *apiKeyUsecase) newAPIKey(principalID uuid.UUID, scopes []string, ttl time.Duration, rotatedFrom *uuid.UUID) (string, *models.APIKey, error) {
+	idBytes := make([]byte, API_KEY_ID_BYTES)
+	secretBytes := make([]byte, API_KEY_SECRET_BYTES)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	prefix := hex.EncodeToString(idBytes)
+	rawKey := fmt.Sprintf("%s_%s_%s", API_KEY_PREFIX, prefix, hex.EncodeToString(secretBytes))
+
+	now := u.now()
+	return rawKey, &models.APIKey{
+		ID:                 uuid.New(),
+		ServicePrincipalID: principalID,
+		KeyPrefix:          prefix,
+		KeyHash:            hashAPIKey(rawKey),
+		Scopes:             scopes,
+		ExpiresAt:          now.Add(ttl),
+		RotatedFromID:      rotatedFrom,
+		CreatedAt:          now,
+	}, nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !authz.IsServiceScope(scope) {
+			return nil, fmt.Errorf("invalid scope: %s", scope)
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		normalized = append(normalized, scope)
+	}
+	return normalized, nil
+}
+
+// parseAPIKey returns the lookup prefix of a key in the form lek_<prefix>_<secret>.
+func parseAPIKey(rawKey string) (string, bool) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != API_KEY_PREFIX {
+		return "", false
+	}
+	if len(parts[1]) != API_KEY_ID_BYTES*2 || len(parts[2]) != API_KEY_SECRET_BYTES*2 {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// API keys carry 256 bits of entropy, so a plain SHA-256 is enough to store them.
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestAPIKeyUsecase(t *testing.T) (APIKeyUsecase, *mocksRepo.APIKeyRepository) {
+	apiKeyRepo := mocksRepo.NewAPIKeyRepository(t)
+	apiKeyUsecase := NewAPIKeyUsecase(apiKeyRepo, APIKeyConfig{
+		DefaultTTL:    90 * 24 * time.Hour,
+		MaxTTL:        365 * 24 * time.Hour,
+		RotationGrace: 24 * time.Hour,
+	})
+	return apiKeyUsecase, apiKeyRepo
+}
+
+// issueTestKey creates a key through the usecase and returns the plaintext with
+// the record the repository would have stored.
+func issueTestKey(t *testing.T, apiKeyUsecase APIKeyUsecase, apiKeyRepo *mocksRepo.APIKeyRepository, scopes []string) (string, *models.APIKey) {
+	principalID := uuid.New()
+	apiKeyRepo.On("GetServicePrincipal", mock.Anything, principalID).
+		Return(&models.ServicePrincipal{ID: principalID, Name: "collections", IsActive: true}, nil).Once()
+
+	var stored *models.APIKey
+	apiKeyRepo.On("CreateAPIKey", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.APIKey) }).
+		Return(nil).Once()
+
+	response, err := apiKeyUsecase.CreateAPIKey(context.Background(), uuid.New().String(), principalID.String(),
+		&models.CreateAPIKeyRequest{Scopes: scopes})
+	assert.NoError(t, err)
+
+	return response.Key, stored
+}
+
+func TestCreateAPIKey_StoresHashNotPlaintext(t *testing.T) {
+	apiKeyUsecase, apiKeyRepo := newTestAPIKeyUsecase(t)
+
+	rawKey, stored := issueTestKey(t, apiKeyUsecase, apiKeyRepo, []string{"loan:read", "loan:read"})
+
+	assert.Contains(t, rawKey, "lek_"+stored.KeyPrefix+"_")
+	assert.Equal(t, hashAPIKey(rawKey), stored.KeyHash)
+	assert.NotContains(t, stored.KeyHash, rawKey)
+	assert.Equal(t, []string{"loan:read"}, stored.Scopes)
+	assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), stored.ExpiresAt, time.Minute)
+}
+
+func TestCreateAPIKey_AdministrativeScopeRejected(t *testing.T) {
+	apiKeyUsecase, _ := newTestAPIKeyUsecase(t)
+
+	response, err := apiKeyUsecase.CreateAPIKey(context.Background(), uuid.New().String(), uuid.New().String(),
+		&models.CreateAPIKeyRequest{Scopes: []string{"employee:manage"}})
+
+	assert.Nil(t, response)
+	assert.EqualError(t, err, "invalid scope: employee:manage")
+}
+
+func TestCreateAPIKey_ScopeWithoutServiceHandlersRejected(t *testing.T) {
+	apiKeyUsecase, _ := newTestAPIKeyUsecase(t)
+
+	// Their handlers need a borrower, investor or employee behind the request
+	for _, scope := range []string{"loan:create", "loan:approve", "loan:disburse", "loan:assign", "survey:upload", "investment:create"} {
+		response, err := apiKeyUsecase.CreateAPIKey(context.Background(), uuid.New().String(), uuid.New().String(),
+			&models.CreateAPIKeyRequest{Scopes: []string{scope}})
+
+		assert.Nil(t, response)
+		assert.EqualError(t, err, "invalid scope: "+scope)
+	}
+}
+
+func TestAuthenticateAPIKey_ReturnsServiceClaims(t *testing.T) {
+	apiKeyUsecase, apiKeyRepo := newTestAPIKeyUsecase(t)
+	rawKey, stored := issueTestKey(t, apiKeyUsecase, apiKeyRepo, []string{"loan:read"})
+
+	apiKeyRepo.On("GetCredentialByPrefix", mock.Anything, stored.KeyPrefix).Return(&models.APIKeyCredential{
+		APIKey:            *stored,
+		PrincipalName:     "collections",
+		PrincipalIsActive: true,
+	}, nil)
+	apiKeyRepo.On("TouchAPIKey", mock.Anything, stored.ID, mock.Anything).Return(nil)
+
+	claims, err := apiKeyUsecase.AuthenticateAPIKey(context.Background(), rawKey)
+
+	assert.NoError(t, err)
+	assert.Equal(t, stored.ServicePrincipalID.String(), claims.UserID)
+	assert.Equal(t, "service", claims.UserType)
+	assert.Equal(t, "collections", claims.Role)
+	assert.Equal(t, []string{"loan:read"}, claims.Scopes)
+	assert.Equal(t, stored.ID.String(), claims.ID)
+}
+
+func TestAuthenticateAPIKey_WrongSecretRejected(t *testing.T) {
+	apiKeyUsecase, apiKeyRepo := newTestAPIKeyUsecase(t)
+	rawKey, stored := issueTestKey(t, apiKeyUsecase, apiKeyRepo, []string{"loan:read"})
+
+	apiKeyRepo.On("GetCredentialByPrefix", mock.Anything, stored.KeyPrefix).Return(&models.APIKeyCredential{
+		APIKey:            *stored,
+		PrincipalIsActive: true,
+	}, nil)
+
+	// Same prefix, different secret
+	forged := rawKey[:len(rawKey)-4] + "0000"
+	if forged == rawKey {
+		forged = rawKey[:len(rawKey)-4] + "1111"
+	}
+	claims, err := apiKeyUsecase.AuthenticateAPIKey(context.Background(), forged)
+
+	assert.Nil(t, claims)
+	assert.EqualError(t, err, "invalid api key")
+}
+
+func TestAuthenticateAPIKey_ExpiredKeyRejected(t *testing.T) {
+	apiKeyUsecase, apiKeyRepo := newTestAPIKeyUsecase(t)
+	rawKey, stored := issueTestKey(t, apiKeyUsecase, apiKeyRepo, []string{"loan:read"})
+
+	expired := *stored
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	apiKeyRepo.On("GetCredentialByPrefix", mock.Anything, stored.KeyPrefix).Return(&models.APIKeyCredential{
+		APIKey:            expired,
+		PrincipalIsActive: true,
+	}, nil)
+
+	claims, err := apiKeyUsecase.AuthenticateAPIKey(context.Background(), rawKey)
+
+	assert.Nil(t, claims)
+	assert.EqualError(t, err, "api key expired")
+}
+
+func TestAuthenticateAPIKey_MalformedKeySkipsLookup(t *testing.T) {
+	apiKeyUsecase, _ := newTestAPIKeyUsecase(t)
+
+	claims, err := apiKeyUsecase.AuthenticateAPIKey(context.Background(), "Bearer something")
+
+	assert.Nil(t, claims)
+	assert.EqualError(t, err, "invalid api key")
+}
+
+func TestRotateAPIKey_OldKeyKeepsGracePeriod(t *testing.T) {
+	apiKeyUsecase, apiKeyRepo := newTestAPIKeyUsecase(t)
+
+	oldKey := &models.APIKey{
+		ID:                 uuid.New(),
+		ServicePrincipalID: uuid.New(),
+		KeyPrefix:          "a1b2c3d4e5f6",
+		Scopes:             []string{"loan:read"},
+		CreatedAt:          time.Now().Add(-30 * 24 * time.Hour),
+		ExpiresAt:          time.Now().Add(60 * 24 * time.Hour),
+	}
+	apiKeyRepo.On("GetAPIKey", mock.Anything, oldKey.ID).Return(oldKey, nil)
+	apiKeyRepo.On("RotateAPIKey", mock.Anything, oldKey.ID,
+		mock.MatchedBy(func(expiresAt time.Time) bool {
+			return expiresAt.Sub(time.Now()) > 23*time.Hour && expiresAt.Sub(time.Now()) <= 24*time.Hour
+		}),
+		mock.MatchedBy(func(k *models.APIKey) bool {
+			return *k.RotatedFromID == oldKey.ID && k.ServicePrincipalID == oldKey.ServicePrincipalID && k.KeyPrefix != oldKey.KeyPrefix
+		}),
+		mock.Anything,
+	).Return(nil)
+
+	response, err := apiKeyUsecase.RotateAPIKey(context.Background(), uuid.New().String(), oldKey.ID.String(), nil)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Key)
+	assert.Equal(t, oldKey.Scopes, response.APIKey.Scopes)
+}
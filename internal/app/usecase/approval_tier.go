@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ApprovalTier sets how many distinct approvers a loan needs. MaxAmount is the
+// inclusive upper bound of the principal, 0 means no upper bound.
+type ApprovalTier struct {
+	MaxAmount         float64  `mapstructure:"max_amount"`
+	RequiredApprovals int      `mapstructure:"required_approvals"`
+	Roles             []string `mapstructure:"roles"`
+}
+
+type ApprovalTiers []ApprovalTier
+
+// NewApprovalTiers validates the tiers and orders them by amount, with the
+// unbounded tier last.
+func NewApprovalTiers(tiers []ApprovalTier) (ApprovalTiers, error) {
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("at least one approval tier is required")
+	}
+
+	sorted := make(ApprovalTiers, 0, len(tiers))
+	unbounded := 0
+	for _, tier := range tiers {
+		if tier.RequiredApprovals < 1 {
+			return nil, fmt.Errorf("approval tier requires at least one approver")
+		}
+		if len(tier.Roles) == 0 {
+			return nil, fmt.Errorf("approval tier must list approver roles")
+		}
+		if tier.MaxAmount < 0 {
+			return nil, fmt.Errorf("approval tier max amount must not be negative")
+		}
+		if tier.MaxAmount == 0 {
+			unbounded++
+		}
+
+		roles := make([]string, len(tier.Roles))
+		for i, role := range tier.Roles {
+			roles[i] = strings.ToUpper(role)
+		}
+		tier.Roles = roles
+		sorted = append(sorted, tier)
+	}
+
+	if unbounded != 1 {
+		return nil, fmt.Errorf("exactly one approval tier must have no max amount")
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].MaxAmount == 0 {
+			return false
+		}
+		if sorted[j].MaxAmount == 0 {
+			return true
+		}
+		return sorted[i].MaxAmount < sorted[j].MaxAmount
+	})
+
+	return sorted, nil
+}
+
+func (t ApprovalTiers) For(principalAmount float64) ApprovalTier {
+	for _, tier := range t {
+		if tier.MaxAmount == 0 || principalAmount <= tier.MaxAmount {
+			return tier
+		}
+	}
+	return t[len(t)-1]
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalTiers_PicksTierByPrincipal(t *testing.T) {
+	tiers, err := NewApprovalTiers([]ApprovalTier{
+		{MaxAmount: 0, RequiredApprovals: 3, Roles: []string{"field_officer"}},
+		{MaxAmount: 250000000, RequiredApprovals: 2, Roles: []string{"FIELD_OFFICER"}},
+		{MaxAmount: 50000000, RequiredApprovals: 1, Roles: []string{"FIELD_OFFICER"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tiers.For(50000000).RequiredApprovals)
+	assert.Equal(t, 2, tiers.For(50000001).RequiredApprovals)
+	assert.Equal(t, 3, tiers.For(1000000000).RequiredApprovals)
+	assert.Equal(t, []string{"FIELD_OFFICER"}, tiers.For(1000000000).Roles)
+}
+
+func TestNewApprovalTiers_RequiresSingleUnboundedTier(t *testing.T) {
+	_, err := NewApprovalTiers([]ApprovalTier{
+		{MaxAmount: 50000000, RequiredApprovals: 1, Roles: []string{"FIELD_OFFICER"}},
+	})
+
+	assert.EqualError(t, err, "exactly one approval tier must have no max amount")
+}
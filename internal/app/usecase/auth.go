@@ -2,30 +2,58 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/totp"
 	"github.com/google/uuid"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	RECOVERY_CODE_COUNT  = 10
+	RECOVERY_CODE_LENGTH = 10
+	recoveryCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
 type AuthUsecase interface {
 	Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error)
+	VerifyMFAChallenge(ctx context.Context, challenge *models.JWTClaims, req *models.MFAVerifyRequest) (*models.LoginResponse, error)
+	SetupMFA(ctx context.Context, userID, userType string) (*models.MFASetupResponse, error)
+	ActivateMFA(ctx context.Context, userID, userType string, req *models.MFACodeRequest) (*models.MFAActivateResponse, error)
+	DisableMFA(ctx context.Context, userID, userType, role string, req *models.MFACodeRequest) error
+}
+
+type MFAConfig struct {
+	Issuer        string
+	RequiredRoles []string
+	ChallengeTTL  time.Duration
+	Skew          int
+	MaxAttempts   int
+	Lockout       time.Duration
 }
 
 type authUsecase struct {
 	authRepo  repositories.AuthRepository
+	mfaRepo   repositories.MFARepository
 	jwtSecret string
+	mfaConfig MFAConfig
 }
 
-func NewAuthUsecase(authRepo repositories.AuthRepository, jwtSecret string) AuthUsecase {
+func NewAuthUsecase(authRepo repositories.AuthRepository, mfaRepo repositories.MFARepository, jwtSecret string, mfaConfig MFAConfig) AuthUsecase {
 	return &authUsecase{
 		authRepo:  authRepo,
+		mfaRepo:   mfaRepo,
 		jwtSecret: jwtSecret,
+		mfaConfig: mfaConfig,
 	}
 }
 
@@ -34,7 +62,6 @@ func (u *authUsecase) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	var profile interface{}
 	var passwordHash string
 	var role string
-	var err error
 
 	switch req.UserType {
 	case constants.USER_EMPLOYEE:
@@ -74,22 +101,268 @@ func (u *authUsecase) Login(ctx context.Context, req *models.LoginRequest) (*mod
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	// Generate JWT token
+	// Employees and investors may have to pass a TOTP challenge first
+	if supportsMFA(req.UserType) {
+		mfa, err := u.mfaRepo.GetUserMFA(ctx, userID, req.UserType)
+		if err != nil {
+			return nil, err
+		}
+
+		enrolled := mfa != nil && mfa.IsEnabled
+		if enrolled || u.isMFARequired(req.UserType, role) {
+			return u.issueMFAChallenge(userID, req.UserType, role, profile, !enrolled)
+		}
+	}
+
+	return u.issueAccessToken(userID, req.UserType, role, profile)
+}
+
+func (u *authUsecase) VerifyMFAChallenge(ctx context.Context, challenge *models.JWTClaims, req *models.MFAVerifyRequest) (*models.LoginResponse, error) {
+	if challenge.TokenUse != constants.TOKEN_USE_MFA_CHALLENGE {
+		return nil, fmt.Errorf("invalid mfa challenge")
+	}
+
+	userID, err := uuid.Parse(challenge.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mfa challenge")
+	}
+
+	mfa, err := u.mfaRepo.GetUserMFA(ctx, userID, challenge.UserType)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, fmt.Errorf("mfa not set up")
+	}
+	if mfa.LockedUntil != nil && mfa.LockedUntil.After(time.Now()) {
+		return nil, fmt.Errorf("too many mfa attempts")
+	}
+
+	var recoveryCodes []string
+
+	switch {
+	case !mfa.IsEnabled:
+		// Mandatory enrolment completes with the first valid code
+		if req.Code == "" {
+			return nil, fmt.Errorf("mfa code is required")
+		}
+		step, ok := totp.Validate(mfa.TOTPSecret, req.Code, time.Now(), u.mfaConfig.Skew)
+		if !ok {
+			return nil, u.failMFAAttempt(ctx, userID, challenge.UserType)
+		}
+		recoveryCodes, err = u.enableMFA(ctx, userID, challenge.UserType, step)
+		if err != nil {
+			return nil, err
+		}
+
+	case req.Code != "":
+		if err := u.verifyTOTP(ctx, mfa, req.Code); err != nil {
+			return nil, err
+		}
+
+	default:
+		used, err := u.mfaRepo.ConsumeRecoveryCode(ctx, userID, challenge.UserType, hashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			return nil, err
+		}
+		if !used {
+			return nil, u.failMFAAttempt(ctx, userID, challenge.UserType)
+		}
+	}
+
+	profile, role, err := u.getProfile(ctx, userID, challenge.UserType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := u.issueAccessToken(userID, challenge.UserType, role, profile)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+
+	return resp, nil
+}
+
+func (u *authUsecase) SetupMFA(ctx context.Context, userID, userType string) (*models.MFASetupResponse, error) {
+	if !supportsMFA(userType) {
+		return nil, fmt.Errorf("mfa not supported for user type")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	profile, _, err := u.getProfile(ctx, userUUID, userType)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.mfaRepo.SavePendingSecret(ctx, userUUID, userType, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(u.mfaConfig.Issuer, profileEmail(profile), secret),
+	}, nil
+}
+
+func (u *authUsecase) ActivateMFA(ctx context.Context, userID, userType string, req *models.MFACodeRequest) (*models.MFAActivateResponse, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	mfa, err := u.mfaRepo.GetUserMFA(ctx, userUUID, userType)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, fmt.Errorf("mfa not set up")
+	}
+	if mfa.IsEnabled {
+		return nil, fmt.Errorf("mfa already enabled")
+	}
+	if mfa.LockedUntil != nil && mfa.LockedUntil.After(time.Now()) {
+		return nil, fmt.Errorf("too many mfa attempts")
+	}
+
+	step, ok := totp.Validate(mfa.TOTPSecret, req.Code, time.Now(), u.mfaConfig.Skew)
+	if !ok {
+		return nil, u.failMFAAttempt(ctx, userUUID, userType)
+	}
+
+	recoveryCodes, err := u.enableMFA(ctx, userUUID, userType, step)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAActivateResponse{
+		RecoveryCodes: recoveryCodes,
+		EnabledAt:     time.Now(),
+	}, nil
+}
+
+func (u *authUsecase) DisableMFA(ctx context.Context, userID, userType, role string, req *models.MFACodeRequest) error {
+	if u.isMFARequired(userType, role) {
+		return fmt.Errorf("mfa is mandatory for this role")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID")
+	}
+
+	mfa, err := u.mfaRepo.GetUserMFA(ctx, userUUID, userType)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || !mfa.IsEnabled {
+		return fmt.Errorf("mfa not enabled")
+	}
+
+	if err := u.verifyTOTP(ctx, mfa, req.Code); err != nil {
+		return err
+	}
+
+	return u.mfaRepo.DisableMFA(ctx, userUUID, userType)
+}
+
+func (u *authUsecase) verifyTOTP(ctx context.Context, mfa *models.UserMFA, code string) error {
+	if mfa.LockedUntil != nil && mfa.LockedUntil.After(time.Now()) {
+		return fmt.Errorf("too many mfa attempts")
+	}
+
+	step, ok := totp.Validate(mfa.TOTPSecret, code, time.Now(), u.mfaConfig.Skew)
+	if !ok {
+		return u.failMFAAttempt(ctx, mfa.UserID, mfa.UserType)
+	}
+
+	fresh, err := u.mfaRepo.MarkStepUsed(ctx, mfa.UserID, mfa.UserType, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return fmt.Errorf("mfa code already used")
+	}
+
+	return nil
+}
+
+func (u *authUsecase) failMFAAttempt(ctx context.Context, userID uuid.UUID, userType string) error {
+	if err := u.mfaRepo.RecordFailedAttempt(ctx, userID, userType, u.mfaConfig.MaxAttempts, u.mfaConfig.Lockout); err != nil {
+		return err
+	}
+	return fmt.Errorf("invalid mfa code")
+}
+
+func (u *authUsecase) enableMFA(ctx context.Context, userID uuid.UUID, userType string, step int64) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.mfaRepo.EnableMFA(ctx, userID, userType, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (u *authUsecase) getProfile(ctx context.Context, userID uuid.UUID, userType string) (interface{}, string, error) {
+	switch userType {
+	case constants.USER_EMPLOYEE:
+		profile, err := u.authRepo.GetEmployeeByID(ctx, userID)
+		if err != nil {
+			return nil, "", err
+		}
+		return profile, profile.EmployeeRole, nil
+	case constants.USER_INVESTOR:
+		profile, err := u.authRepo.GetInvestorByID(ctx, userID)
+		if err != nil {
+			return nil, "", err
+		}
+		return profile, "", nil
+	default:
+		return nil, "", fmt.Errorf("mfa not supported for user type")
+	}
+}
+
+func (u *authUsecase) isMFARequired(userType, role string) bool {
+	if userType != constants.USER_EMPLOYEE {
+		return false
+	}
+	for _, r := range u.mfaConfig.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *authUsecase) issueAccessToken(userID uuid.UUID, userType, role string, profile interface{}) (*models.LoginResponse, error) {
 	expiresIn := 3600 // 1 hour
 	claims := &models.JWTClaims{
 		UserID:   userID.String(),
-		UserType: req.UserType,
+		UserType: userType,
 		Role:     role,
+		TokenUse: constants.TOKEN_USE_ACCESS,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expiresIn) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(u.jwtSecret))
+	tokenString, err := u.signToken(claims)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
 	return &models.LoginResponse{
@@ -98,8 +371,90 @@ func (u *authUsecase) Login(ctx context.Context, req *models.LoginRequest) (*mod
 		ExpiresIn:   expiresIn,
 		User: models.UserInfo{
 			ID:       userID.String(),
-			UserType: req.UserType,
+			UserType: userType,
 			Profile:  profile,
 		},
 	}, nil
 }
+
+func (u *authUsecase) issueMFAChallenge(userID uuid.UUID, userType, role string, profile interface{}, enrollmentRequired bool) (*models.LoginResponse, error) {
+	claims := &models.JWTClaims{
+		UserID:   userID.String(),
+		UserType: userType,
+		Role:     role,
+		TokenUse: constants.TOKEN_USE_MFA_CHALLENGE,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(u.mfaConfig.ChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	tokenString, err := u.signToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		MFARequired:           true,
+		MFAToken:              tokenString,
+		MFAEnrollmentRequired: enrollmentRequired,
+		User: models.UserInfo{
+			ID:       userID.String(),
+			UserType: userType,
+			Profile:  profile,
+		},
+	}, nil
+}
+
+func (u *authUsecase) signToken(claims *models.JWTClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(u.jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return tokenString, nil
+}
+
+func supportsMFA(userType string) bool {
+	return userType == constants.USER_EMPLOYEE || userType == constants.USER_INVESTOR
+}
+
+func profileEmail(profile interface{}) string {
+	switch p := profile.(type) {
+	case *models.EmployeeProfile:
+		return p.Email
+	case *models.InvestorProfile:
+		return p.Email
+	default:
+		return ""
+	}
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, RECOVERY_CODE_COUNT)
+	hashes := make([]string, 0, RECOVERY_CODE_COUNT)
+
+	buf := make([]byte, RECOVERY_CODE_LENGTH)
+	for i := 0; i < RECOVERY_CODE_COUNT; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		code := make([]byte, RECOVERY_CODE_LENGTH)
+		for j, b := range buf {
+			code[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+
+		formatted := string(code[:5]) + "-" + string(code[5:])
+		codes = append(codes, formatted)
+		hashes = append(hashes, hashRecoveryCode(formatted))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/totp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// bcrypt hash of "password123", same as cmd/seed
+const testPasswordHash = "$2a$10$WYmkE5HjRSYrJcUS9OGL/u9biq0iYc6GoUPiYLVd1UvO8hZPo98fO"
+
+func newTestAuthUsecase(t *testing.T) (AuthUsecase, *mocksRepo.AuthRepository, *mocksRepo.MFARepository) {
+	authRepo := mocksRepo.NewAuthRepository(t)
+	mfaRepo := mocksRepo.NewMFARepository(t)
+	authUsecase := NewAuthUsecase(authRepo, mfaRepo, "test-secret", MFAConfig{
+		Issuer:        "Loan Engine",
+		RequiredRoles: []string{"FIELD_OFFICER", "ADMIN"},
+		ChallengeTTL:  5 * time.Minute,
+		Skew:          1,
+		MaxAttempts:   5,
+		Lockout:       15 * time.Minute,
+	})
+	return authUsecase, authRepo, mfaRepo
+}
+
+func TestLogin_BorrowerSkipsMFA(t *testing.T) {
+	authUsecase, authRepo, _ := newTestAuthUsecase(t)
+
+	borrowerID := uuid.New()
+	authRepo.On("GetBorrowerByEmail", mock.Anything, "siti.peminjam@gmail.com").
+		Return(borrowerID, &models.BorrowerProfile{FullName: "Siti Peminjam"}, testPasswordHash, nil)
+
+	result, err := authUsecase.Login(context.Background(), &models.LoginRequest{
+		Email:    "siti.peminjam@gmail.com",
+		Password: "password123",
+		UserType: "borrower",
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, result.MFARequired)
+	assert.NotEmpty(t, result.AccessToken)
+}
+
+func TestLogin_RequiredRoleWithoutEnrolmentGetsChallenge(t *testing.T) {
+	authUsecase, authRepo, mfaRepo := newTestAuthUsecase(t)
+
+	employeeID := uuid.New()
+	authRepo.On("GetEmployeeByEmail", mock.Anything, "officer@amartha.com").
+		Return(employeeID, &models.EmployeeProfile{EmployeeRole: "FIELD_OFFICER"}, testPasswordHash, nil)
+	mfaRepo.On("GetUserMFA", mock.Anything, employeeID, "employee").Return(nil, nil)
+
+	result, err := authUsecase.Login(context.Background(), &models.LoginRequest{
+		Email:    "officer@amartha.com",
+		Password: "password123",
+		UserType: "employee",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, result.MFARequired)
+	assert.True(t, result.MFAEnrollmentRequired)
+	assert.NotEmpty(t, result.MFAToken)
+	assert.Empty(t, result.AccessToken)
+}
+
+func TestLogin_OptionalRoleWithoutEnrolmentGetsAccessToken(t *testing.T) {
+	authUsecase, authRepo, mfaRepo := newTestAuthUsecase(t)
+
+	employeeID := uuid.New()
+	authRepo.On("GetEmployeeByEmail", mock.Anything, "validator@amartha.com").
+		Return(employeeID, &models.EmployeeProfile{EmployeeRole: "FIELD_VALIDATOR"}, testPasswordHash, nil)
+	mfaRepo.On("GetUserMFA", mock.Anything, employeeID, "employee").Return(nil, nil)
+
+	result, err := authUsecase.Login(context.Background(), &models.LoginRequest{
+		Email:    "validator@amartha.com",
+		Password: "password123",
+		UserType: "employee",
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, result.MFARequired)
+	assert.NotEmpty(t, result.AccessToken)
+}
+
+func TestVerifyMFAChallenge_ValidCodeIssuesAccessToken(t *testing.T) {
+	authUsecase, authRepo, mfaRepo := newTestAuthUsecase(t)
+
+	investorID := uuid.New()
+	secret, _ := totp.GenerateSecret()
+	now := time.Now()
+	code, _ := totp.GenerateCode(secret, now)
+
+	mfaRepo.On("GetUserMFA", mock.Anything, investorID, "investor").Return(&models.UserMFA{
+		UserID:     investorID,
+		UserType:   "investor",
+		TOTPSecret: secret,
+		IsEnabled:  true,
+	}, nil)
+	mfaRepo.On("MarkStepUsed", mock.Anything, investorID, "investor", totp.Step(now)).Return(true, nil)
+	authRepo.On("GetInvestorByID", mock.Anything, investorID).Return(&models.InvestorProfile{FullName: "Rina Investor"}, nil)
+
+	challenge := &models.JWTClaims{UserID: investorID.String(), UserType: "investor", TokenUse: "mfa_challenge"}
+	result, err := authUsecase.VerifyMFAChallenge(context.Background(), challenge, &models.MFAVerifyRequest{Code: code})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccessToken)
+	assert.Empty(t, result.RecoveryCodes)
+}
+
+func TestVerifyMFAChallenge_ReplayedCodeRejected(t *testing.T) {
+	authUsecase, _, mfaRepo := newTestAuthUsecase(t)
+
+	investorID := uuid.New()
+	secret, _ := totp.GenerateSecret()
+	now := time.Now()
+	code, _ := totp.GenerateCode(secret, now)
+
+	mfaRepo.On("GetUserMFA", mock.Anything, investorID, "investor").Return(&models.UserMFA{
+		UserID:     investorID,
+		UserType:   "investor",
+		TOTPSecret: secret,
+		IsEnabled:  true,
+	}, nil)
+	mfaRepo.On("MarkStepUsed", mock.Anything, investorID, "investor", mock.Anything).Return(false, nil)
+
+	challenge := &models.JWTClaims{UserID: investorID.String(), UserType: "investor", TokenUse: "mfa_challenge"}
+	result, err := authUsecase.VerifyMFAChallenge(context.Background(), challenge, &models.MFAVerifyRequest{Code: code})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "mfa code already used", err.Error())
+}
+
+func TestVerifyMFAChallenge_EnrolmentReturnsRecoveryCodes(t *testing.T) {
+	authUsecase, authRepo, mfaRepo := newTestAuthUsecase(t)
+
+	employeeID := uuid.New()
+	secret, _ := totp.GenerateSecret()
+	now := time.Now()
+	code, _ := totp.GenerateCode(secret, now)
+
+	mfaRepo.On("GetUserMFA", mock.Anything, employeeID, "employee").Return(&models.UserMFA{
+		UserID:     employeeID,
+		UserType:   "employee",
+		TOTPSecret: secret,
+		IsEnabled:  false,
+	}, nil)
+	mfaRepo.On("EnableMFA", mock.Anything, employeeID, "employee", totp.Step(now),
+		mock.MatchedBy(func(hashes []string) bool { return len(hashes) == RECOVERY_CODE_COUNT })).Return(nil)
+	authRepo.On("GetEmployeeByID", mock.Anything, employeeID).Return(&models.EmployeeProfile{EmployeeRole: "FIELD_OFFICER"}, nil)
+
+	challenge := &models.JWTClaims{UserID: employeeID.String(), UserType: "employee", Role: "FIELD_OFFICER", TokenUse: "mfa_challenge"}
+	result, err := authUsecase.VerifyMFAChallenge(context.Background(), challenge, &models.MFAVerifyRequest{Code: code})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccessToken)
+	assert.Len(t, result.RecoveryCodes, RECOVERY_CODE_COUNT)
+}
+
+func TestVerifyMFAChallenge_WrongCodeRecordsFailure(t *testing.T) {
+	authUsecase, _, mfaRepo := newTestAuthUsecase(t)
+
+	employeeID := uuid.New()
+	secret, _ := totp.GenerateSecret()
+
+	mfaRepo.On("GetUserMFA", mock.Anything, employeeID, "employee").Return(&models.UserMFA{
+		UserID:     employeeID,
+		UserType:   "employee",
+		TOTPSecret: secret,
+		IsEnabled:  true,
+	}, nil)
+	mfaRepo.On("RecordFailedAttempt", mock.Anything, employeeID, "employee", 5, 15*time.Minute).Return(nil)
+
+	challenge := &models.JWTClaims{UserID: employeeID.String(), UserType: "employee", TokenUse: "mfa_challenge"}
+	result, err := authUsecase.VerifyMFAChallenge(context.Background(), challenge, &models.MFAVerifyRequest{Code: "000000"})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "invalid mfa code", err.Error())
+}
+
+func TestActivateMFA_WrongCodeRecordsFailure(t *testing.T) {
+	authUsecase, _, mfaRepo := newTestAuthUsecase(t)
+
+	investorID := uuid.New()
+	secret, _ := totp.GenerateSecret()
+
+	mfaRepo.On("GetUserMFA", mock.Anything, investorID, "investor").Return(&models.UserMFA{
+		UserID:     investorID,
+		UserType:   "investor",
+		TOTPSecret: secret,
+	}, nil)
+	mfaRepo.On("RecordFailedAttempt", mock.Anything, investorID, "investor", 5, 15*time.Minute).Return(nil)
+
+	result, err := authUsecase.ActivateMFA(context.Background(), investorID.String(), "investor", &models.MFACodeRequest{Code: "000000"})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "invalid mfa code", err.Error())
+	mfaRepo.AssertExpectations(t)
+}
+
+func TestActivateMFA_LockedOutRejected(t *testing.T) {
+	authUsecase, _, mfaRepo := newTestAuthUsecase(t)
+
+	investorID := uuid.New()
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.GenerateCode(secret, time.Now())
+	lockedUntil := time.Now().Add(10 * time.Minute)
+
+	mfaRepo.On("GetUserMFA", mock.Anything, investorID, "investor").Return(&models.UserMFA{
+		UserID:      investorID,
+		UserType:    "investor",
+		TOTPSecret:  secret,
+		LockedUntil: &lockedUntil,
+	}, nil)
+
+	result, err := authUsecase.ActivateMFA(context.Background(), investorID.String(), "investor", &models.MFACodeRequest{Code: code})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "too many mfa attempts", err.Error())
+}
+
+func TestVerifyMFAChallenge_RecoveryCodeIsNormalized(t *testing.T) {
+	authUsecase, authRepo, mfaRepo := newTestAuthUsecase(t)
+
+	investorID := uuid.New()
+	mfaRepo.On("GetUserMFA", mock.Anything, investorID, "investor").Return(&models.UserMFA{
+		UserID:    investorID,
+		UserType:  "investor",
+		IsEnabled: true,
+	}, nil)
+	mfaRepo.On("ConsumeRecoveryCode", mock.Anything, investorID, "investor", hashRecoveryCode("ABCDE-FGHJK")).Return(true, nil)
+	authRepo.On("GetInvestorByID", mock.Anything, investorID).Return(&models.InvestorProfile{}, nil)
+
+	challenge := &models.JWTClaims{UserID: investorID.String(), UserType: "investor", TokenUse: "mfa_challenge"}
+	result, err := authUsecase.VerifyMFAChallenge(context.Background(), challenge, &models.MFAVerifyRequest{RecoveryCode: "abcdefghjk"})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.AccessToken)
+}
+
+func TestDisableMFA_MandatoryRoleCannotDisable(t *testing.T) {
+	authUsecase, _, _ := newTestAuthUsecase(t)
+
+	err := authUsecase.DisableMFA(context.Background(), uuid.New().String(), "employee", "ADMIN", &models.MFACodeRequest{Code: "123456"})
+
+	assert.Error(t, err)
+	assert.Equal(t, "mfa is mandatory for this role", err.Error())
+}
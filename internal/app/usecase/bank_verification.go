@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/authz"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/bankverify"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type BankVerificationUsecase interface {
+	// Verify asks the bank who holds the borrower's account and records how
+	// well the name matches the borrower.
+	Verify(ctx context.Context, loanID string, employeeID string) (*models.BankAccountVerification, error)
+	ListVerifications(ctx context.Context, loanID string, employeeID string) ([]models.BankAccountVerification, error)
+	// Override accepts a mismatched holder name, the reason is kept for audit.
+	Override(ctx context.Context, loanID string, employeeID string, req *models.OverrideBankVerificationRequest) (*models.BankAccountVerification, error)
+	// CheckDisbursable fails unless the borrower's current account matched or
+	// was overridden. An account not verified yet is verified first.
+	CheckDisbursable(ctx context.Context, loanID, employeeID uuid.UUID) error
+}
+
+type BankVerificationConfig struct {
+	// MatchThreshold is the lowest name similarity, from 0 to 1, that counts
+	// as a match
+	MatchThreshold float64
+}
+
+func (c BankVerificationConfig) withDefaults() BankVerificationConfig {
+	if c.MatchThreshold <= 0 || c.MatchThreshold > 1 {
+		c.MatchThreshold = 0.85
+	}
+	return c
+}
+
+type bankVerificationUsecase struct {
+	repo     repositories.BankVerificationRepository
+	verifier bankverify.BankVerifier
+	guard    authz.Guard
+	config   BankVerificationConfig
+	now      func() time.Time
+}
+
+func NewBankVerificationUsecase(repo repositories.BankVerificationRepository, verifier bankverify.BankVerifier, guard authz.Guard, config BankVerificationConfig) BankVerificationUsecase {
+	return &bankVerificationUsecase{
+		repo:     repo,
+		verifier: verifier,
+		guard:    guard,
+		config:   config.withDefaults(),
+		now:      time.Now,
+	}
+}
+
+func (u *bankVerificationUsecase) Verify(ctx context.Context, loanID string, employeeID string) (*models.BankAccountVerification, error) {
+	loanUUID, employeeUUID, err := parseLoanAndEmployee(loanID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.guard.CheckLoanAccess(ctx, employeeUUID, loanUUID, constants.PERM_LOAN_DISBURSE); err != nil {
+		return nil, err
+	}
+
+	account, err := u.bankAccount(ctx, loanUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.verify(ctx, account, employeeUUID)
+}
+
+func (u *bankVerificationUsecase) ListVerifications(ctx context.Context, loanID string, employeeID string) ([]models.BankAccountVerification, error) {
+	loanUUID, employeeUUID, err := parseLoanAndEmployee(loanID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.guard.CheckLoanAccess(ctx, employeeUUID, loanUUID, constants.PERM_LOAN_DISBURSE); err != nil {
+		return nil, err
+	}
+
+	return u.repo.ListVerifications(ctx, loanUUID)
+}
+
+func (u *bankVerificationUsecase) Override(ctx context.Context, loanID string, employeeID string, req *models.OverrideBankVerificationRequest) (*models.BankAccountVerification, error) {
+	loanUUID, employeeUUID, err := parseLoanAndEmployee(loanID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.guard.CheckLoanAccess(ctx, employeeUUID, loanUUID, constants.PERM_BANK_OVERRIDE); err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("override reason is required")
+	}
+
+	account, err := u.bankAccount(ctx, loanUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	verification, err := u.repo.GetLatestVerification(ctx, loanUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The borrower changed their account after it was checked
+	if !coversAccount(verification, account) {
+		return nil, fmt.Errorf("bank verification is outdated")
+	}
+
+	if verification.Status != constants.BANK_VERIFICATION_MISMATCHED {
+		return nil, fmt.Errorf("bank verification cannot be overridden")
+	}
+
+	now := u.now()
+	if err := u.repo.OverrideVerification(ctx, verification.ID, employeeUUID, reason, now); err != nil {
+		return nil, err
+	}
+
+	log.Warn().
+		Str("loan_id", loanUUID.String()).
+		Str("verification_id", verification.ID.String()).
+		Str("employee_id", employeeUUID.String()).
+		Str("account_holder_name", verification.AccountHolderName).
+		Str("borrower_name", verification.BorrowerName).
+		Msg("Bank account name mismatch overridden")
+
+	verification.Status = constants.BANK_VERIFICATION_OVERRIDDEN
+	verification.OverrideReason = reason
+	verification.OverriddenByID = &employeeUUID
+	verification.OverriddenAt = &now
+
+	return verification, nil
+}
+
+func (u *bankVerificationUsecase) CheckDisbursable(ctx context.Context, loanID, employeeID uuid.UUID) error {
+	account, err := u.bankAccount(ctx, loanID)
+	if err != nil {
+		return err
+	}
+
+	verification, err := u.repo.GetLatestVerification(ctx, loanID)
+	if err != nil && err.Error() != "bank verification not found" {
+		return err
+	}
+	if verification == nil || !coversAccount(verification, account) {
+		verification, err = u.verify(ctx, account, employeeID)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch verification.Status {
+	case constants.BANK_VERIFICATION_MATCHED, constants.BANK_VERIFICATION_OVERRIDDEN:
+		return nil
+	case constants.BANK_VERIFICATION_NOT_FOUND:
+		return fmt.Errorf("bank account not found")
+	default:
+		return fmt.Errorf("bank account name mismatch")
+	}
+}
+
+func (u *bankVerificationUsecase) bankAccount(ctx context.Context, loanID uuid.UUID) (*models.LoanBankAccount, error) {
+	account, err := u.repo.GetLoanBankAccount(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.BankName == "" || account.BankAccountNumber == "" {
+		return nil, fmt.Errorf("borrower has no bank account")
+	}
+
+	return account, nil
+}
+
+func (u *bankVerificationUsecase) verify(ctx context.Context, account *models.LoanBankAccount, employeeID uuid.UUID) (*models.BankAccountVerification, error) {
+	verification := &models.BankAccountVerification{
+		ID:                uuid.New(),
+		LoanID:            account.LoanID,
+		BorrowerID:        account.BorrowerID,
+		BankName:          account.BankName,
+		BankAccountNumber: account.BankAccountNumber,
+		BorrowerName:      account.BorrowerName,
+		MatchThreshold:    u.config.MatchThreshold,
+		Provider:          u.verifier.Name(),
+		CheckedByID:       &employeeID,
+		CreatedAt:         u.now(),
+	}
+
+	holder, err := u.verifier.Inquire(ctx, account.BankName, account.BankAccountNumber)
+	switch {
+	case errors.Is(err, bankverify.ErrAccountNotFound):
+		verification.Status = constants.BANK_VERIFICATION_NOT_FOUND
+	case err != nil:
+		return nil, fmt.Errorf("bank account inquiry failed: %w", err)
+	default:
+		verification.AccountHolderName = holder.AccountHolderName
+		// Stored with four decimals
+		verification.MatchScore = math.Round(bankverify.NameSimilarity(account.BorrowerName, holder.AccountHolderName)*10000) / 10000
+		verification.Status = constants.BANK_VERIFICATION_MISMATCHED
+		if verification.MatchScore >= u.config.MatchThreshold {
+			verification.Status = constants.BANK_VERIFICATION_MATCHED
+		}
+	}
+
+	if err := u.repo.CreateVerification(ctx, verification); err != nil {
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+// coversAccount reports whether verification was made for account as it is
+// now.
+func coversAccount(verification *models.BankAccountVerification, account *models.LoanBankAccount) bool {
+	return verification.BankName == account.BankName &&
+		verification.BankAccountNumber == account.BankAccountNumber &&
+		verification.BorrowerName == account.BorrowerName
+}
+
+func parseLoanAndEmployee(loanID, employeeID string) (uuid.UUID, uuid.UUID, error) {
+	loanUUID, err := uuid.Parse(loanID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid loan ID")
+	}
+
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid employee ID")
+	}
+
+	return loanUUID, employeeUUID, nil
+}
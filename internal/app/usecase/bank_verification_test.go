@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	mocksAuthz "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/authz"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/bankverify"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeBank holds accounts by account number.
+type fakeBank map[string]string
+
+func (f fakeBank) Name() string {
+	return "fake"
+}
+
+func (f fakeBank) Inquire(ctx context.Context, bankName, accountNumber string) (*bankverify.Account, error) {
+	if accountNumber == "down" {
+		return nil, bankverify.ErrUnavailable
+	}
+	holder, ok := f[accountNumber]
+	if !ok {
+		return nil, bankverify.ErrAccountNotFound
+	}
+	return &bankverify.Account{BankName: bankName, AccountNumber: accountNumber, AccountHolderName: holder}, nil
+}
+
+var testBank = fakeBank{
+	"1122334455": "SITI PEMINJAM",
+	"5566778899": "BUDI SANTOSO",
+}
+
+func testLoanBankAccount(loanID uuid.UUID, accountNumber string) *models.LoanBankAccount {
+	return &models.LoanBankAccount{
+		LoanID:            loanID,
+		BorrowerID:        uuid.New(),
+		BorrowerName:      "Siti Peminjam",
+		BankName:          "Bank BRI",
+		BankAccountNumber: accountNumber,
+	}
+}
+
+func TestCheckDisbursable_VerifiesMatchingAccount(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, mocksAuthz.NewGuard(t), BankVerificationConfig{MatchThreshold: 0.85})
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(testLoanBankAccount(loanID, "1122334455"), nil)
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(nil, fmt.Errorf("bank verification not found"))
+	repo.On("CreateVerification", mock.Anything, mock.MatchedBy(func(v *models.BankAccountVerification) bool {
+		return v.Status == "MATCHED" && v.MatchScore == 1 && v.MatchThreshold == 0.85 &&
+			v.AccountHolderName == "SITI PEMINJAM" && v.Provider == "fake" && *v.CheckedByID == officerID
+	})).Return(nil)
+
+	assert.NoError(t, bankUsecase.CheckDisbursable(context.Background(), loanID, officerID))
+}
+
+func TestCheckDisbursable_MismatchBlocks(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, mocksAuthz.NewGuard(t), BankVerificationConfig{})
+
+	loanID := uuid.New()
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(testLoanBankAccount(loanID, "5566778899"), nil)
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(nil, fmt.Errorf("bank verification not found"))
+	repo.On("CreateVerification", mock.Anything, mock.MatchedBy(func(v *models.BankAccountVerification) bool {
+		return v.Status == "MISMATCHED" && v.MatchScore < 0.85 && v.AccountHolderName == "BUDI SANTOSO"
+	})).Return(nil)
+
+	err := bankUsecase.CheckDisbursable(context.Background(), loanID, uuid.New())
+
+	assert.EqualError(t, err, "bank account name mismatch")
+}
+
+func TestCheckDisbursable_UnknownAccount(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, mocksAuthz.NewGuard(t), BankVerificationConfig{})
+
+	loanID := uuid.New()
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(testLoanBankAccount(loanID, "0000000000"), nil)
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(nil, fmt.Errorf("bank verification not found"))
+	repo.On("CreateVerification", mock.Anything, mock.MatchedBy(func(v *models.BankAccountVerification) bool {
+		return v.Status == "NOT_FOUND" && v.AccountHolderName == ""
+	})).Return(nil)
+
+	err := bankUsecase.CheckDisbursable(context.Background(), loanID, uuid.New())
+
+	assert.EqualError(t, err, "bank account not found")
+}
+
+func TestCheckDisbursable_UsesOverride(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, mocksAuthz.NewGuard(t), BankVerificationConfig{})
+
+	loanID := uuid.New()
+	account := testLoanBankAccount(loanID, "5566778899")
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(account, nil)
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(&models.BankAccountVerification{
+		LoanID:            loanID,
+		BankName:          account.BankName,
+		BankAccountNumber: account.BankAccountNumber,
+		BorrowerName:      account.BorrowerName,
+		Status:            "OVERRIDDEN",
+	}, nil)
+
+	assert.NoError(t, bankUsecase.CheckDisbursable(context.Background(), loanID, uuid.New()))
+	repo.AssertNotCalled(t, "CreateVerification", mock.Anything, mock.Anything)
+}
+
+func TestCheckDisbursable_ChangedAccountIsVerifiedAgain(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, mocksAuthz.NewGuard(t), BankVerificationConfig{})
+
+	loanID := uuid.New()
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(testLoanBankAccount(loanID, "5566778899"), nil)
+	// The override was for the borrower's previous account
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(&models.BankAccountVerification{
+		LoanID:            loanID,
+		BankName:          "Bank BRI",
+		BankAccountNumber: "1122334455",
+		BorrowerName:      "Siti Peminjam",
+		Status:            "OVERRIDDEN",
+	}, nil)
+	repo.On("CreateVerification", mock.Anything, mock.MatchedBy(func(v *models.BankAccountVerification) bool {
+		return v.BankAccountNumber == "5566778899" && v.Status == "MISMATCHED"
+	})).Return(nil)
+
+	err := bankUsecase.CheckDisbursable(context.Background(), loanID, uuid.New())
+
+	assert.EqualError(t, err, "bank account name mismatch")
+}
+
+func TestCheckDisbursable_InquiryUnavailable(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, mocksAuthz.NewGuard(t), BankVerificationConfig{})
+
+	loanID := uuid.New()
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(testLoanBankAccount(loanID, "down"), nil)
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(nil, fmt.Errorf("bank verification not found"))
+
+	err := bankUsecase.CheckDisbursable(context.Background(), loanID, uuid.New())
+
+	assert.ErrorIs(t, err, bankverify.ErrUnavailable)
+	repo.AssertNotCalled(t, "CreateVerification", mock.Anything, mock.Anything)
+}
+
+func TestOverride_AcceptsMismatchWithReason(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, guard, BankVerificationConfig{})
+
+	loanID := uuid.New()
+	adminID := uuid.New()
+	verificationID := uuid.New()
+	account := testLoanBankAccount(loanID, "5566778899")
+	guard.On("CheckLoanAccess", mock.Anything, adminID, loanID, "bank:override").Return(nil)
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(account, nil)
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(&models.BankAccountVerification{
+		ID:                verificationID,
+		LoanID:            loanID,
+		BankName:          account.BankName,
+		BankAccountNumber: account.BankAccountNumber,
+		BorrowerName:      account.BorrowerName,
+		AccountHolderName: "BUDI SANTOSO",
+		Status:            "MISMATCHED",
+	}, nil)
+	repo.On("OverrideVerification", mock.Anything, verificationID, adminID, "Joint account with husband, marriage certificate checked", mock.AnythingOfType("time.Time")).Return(nil)
+
+	verification, err := bankUsecase.Override(context.Background(), loanID.String(), adminID.String(), &models.OverrideBankVerificationRequest{
+		Reason: "  Joint account with husband, marriage certificate checked ",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "OVERRIDDEN", verification.Status)
+	assert.Equal(t, adminID, *verification.OverriddenByID)
+	assert.NotNil(t, verification.OverriddenAt)
+}
+
+func TestOverride_OnlyMismatches(t *testing.T) {
+	repo := mocksRepo.NewBankVerificationRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	bankUsecase := NewBankVerificationUsecase(repo, testBank, guard, BankVerificationConfig{})
+
+	loanID := uuid.New()
+	adminID := uuid.New()
+	account := testLoanBankAccount(loanID, "0000000000")
+	guard.On("CheckLoanAccess", mock.Anything, adminID, loanID, "bank:override").Return(nil)
+	repo.On("GetLoanBankAccount", mock.Anything, loanID).Return(account, nil)
+	repo.On("GetLatestVerification", mock.Anything, loanID).Return(&models.BankAccountVerification{
+		LoanID:            loanID,
+		BankName:          account.BankName,
+		BankAccountNumber: account.BankAccountNumber,
+		BorrowerName:      account.BorrowerName,
+		Status:            "NOT_FOUND",
+	}, nil)
+
+	verification, err := bankUsecase.Override(context.Background(), loanID.String(), adminID.String(), &models.OverrideBankVerificationRequest{Reason: "Trust me"})
+
+	assert.Nil(t, verification)
+	assert.EqualError(t, err, "bank verification cannot be overridden")
+	repo.AssertNotCalled(t, "OverrideVerification", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	TEMP_PASSWORD_LENGTH = 16
+	DEFAULT_PAGE_SIZE    = 50
+	MAX_PAGE_SIZE        = 200
+	tempPasswordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+type EmployeeUsecase interface {
+	ListEmployees(ctx context.Context, filter models.EmployeeListFilter) ([]models.Employee, error)
+	GetEmployee(ctx context.Context, employeeID string) (*models.Employee, error)
+	CreateEmployee(ctx context.Context, actorID string, req *models.CreateEmployeeRequest) (*models.Employee, error)
+	UpdateEmployee(ctx context.Context, actorID, employeeID string, req *models.UpdateEmployeeRequest) (*models.Employee, error)
+	SetEmployeeActive(ctx context.Context, actorID, employeeID string, active bool) (*models.Employee, error)
+	ResetPassword(ctx context.Context, actorID, employeeID string, req *models.ResetPasswordRequest) (*models.ResetPasswordResponse, error)
+	ListAuditLogs(ctx context.Context, employeeID string) ([]models.EmployeeAuditLog, error)
+}
+
+type employeeUsecase struct {
+	employeeRepo repositories.EmployeeRepository
+}
+
+func NewEmployeeUsecase(employeeRepo repositories.EmployeeRepository) EmployeeUsecase {
+	return &employeeUsecase{
+		employeeRepo: employeeRepo,
+	}
+}
+
+func (u *employeeUsecase) ListEmployees(ctx context.Context, filter models.EmployeeListFilter) ([]models.Employee, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DEFAULT_PAGE_SIZE
+	}
+	if filter.Limit > MAX_PAGE_SIZE {
+		filter.Limit = MAX_PAGE_SIZE
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return u.employeeRepo.ListEmployees(ctx, filter)
+}
+
+func (u *employeeUsecase) GetEmployee(ctx context.Context, employeeID string) (*models.Employee, error) {
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	return u.employeeRepo.GetEmployee(ctx, employeeUUID)
+}
+
+func (u *employeeUsecase) CreateEmployee(ctx context.Context, actorID string, req *models.CreateEmployeeRequest) (*models.Employee, error) {
+	actorUUID, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	employee := &models.Employee{
+		ID:           uuid.New(),
+		Username:     req.Username,
+		Email:        strings.ToLower(req.Email),
+		FullName:     req.FullName,
+		PhoneNumber:  req.PhoneNumber,
+		EmployeeRole: req.EmployeeRole,
+		Department:   req.Department,
+		Branch:       req.Branch,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	audit := newEmployeeAudit(employee.ID, actorUUID, constants.AUDIT_EMPLOYEE_CREATE, map[string]interface{}{
+		"username":      employee.Username,
+		"email":         employee.Email,
+		"employee_role": employee.EmployeeRole,
+		"department":    employee.Department,
+		"branch":        employee.Branch,
+	})
+
+	if err := u.employeeRepo.CreateEmployee(ctx, employee, string(passwordHash), audit); err != nil {
+		return nil, err
+	}
+
+	return employee, nil
+}
+
+func (u *employeeUsecase) UpdateEmployee(ctx context.Context, actorID, employeeID string, req *models.UpdateEmployeeRequest) (*models.Employee, error) {
+	actorUUID, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	employee, err := u.employeeRepo.GetEmployee(ctx, employeeUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	applyStringChange(changes, "full_name", &employee.FullName, req.FullName)
+	applyStringChange(changes, "phone_number", &employee.PhoneNumber, req.PhoneNumber)
+	applyStringChange(changes, "employee_role", &employee.EmployeeRole, req.EmployeeRole)
+	applyStringChange(changes, "department", &employee.Department, req.Department)
+	applyStringChange(changes, "branch", &employee.Branch, req.Branch)
+	if req.IsActive != nil && *req.IsActive != employee.IsActive {
+		changes["is_active"] = map[string]interface{}{"from": employee.IsActive, "to": *req.IsActive}
+		employee.IsActive = *req.IsActive
+	}
+
+	// An admin demoting or deactivating themselves could lock everyone out
+	if employeeUUID == actorUUID && (changes["employee_role"] != nil || changes["is_active"] != nil) {
+		return nil, fmt.Errorf("cannot change own role or status")
+	}
+
+	if len(changes) == 0 {
+		return employee, nil
+	}
+
+	action := constants.AUDIT_EMPLOYEE_UPDATE
+	if len(changes) == 1 && req.IsActive != nil && changes["is_active"] != nil {
+		action = activationAction(employee.IsActive)
+	}
+
+	if err := u.employeeRepo.UpdateEmployee(ctx, employee, newEmployeeAudit(employee.ID, actorUUID, action, changes)); err != nil {
+		return nil, err
+	}
+
+	employee.UpdatedAt = time.Now()
+	return employee, nil
+}
+
+func (u *employeeUsecase) SetEmployeeActive(ctx context.Context, actorID, employeeID string, active bool) (*models.Employee, error) {
+	return u.UpdateEmployee(ctx, actorID, employeeID, &models.UpdateEmployeeRequest{IsActive: &active})
+}
+
+func (u *employeeUsecase) ResetPassword(ctx context.Context, actorID, employeeID string, req *models.ResetPasswordRequest) (*models.ResetPasswordResponse, error) {
+	actorUUID, err := uuid.Parse(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	if employeeUUID == actorUUID {
+		return nil, fmt.Errorf("cannot reset own password")
+	}
+
+	response := &models.ResetPasswordResponse{
+		EmployeeID: employeeUUID,
+		MFAReset:   req.ResetMFA,
+	}
+
+	password := req.NewPassword
+	if password == "" {
+		password, err = generateTemporaryPassword()
+		if err != nil {
+			return nil, err
+		}
+		response.TemporaryPassword = password
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// Never record the password itself in the audit trail
+	audit := newEmployeeAudit(employeeUUID, actorUUID, constants.AUDIT_EMPLOYEE_RESET_PASSWORD, map[string]interface{}{
+		"generated": req.NewPassword == "",
+		"mfa_reset": req.ResetMFA,
+	})
+
+	if err := u.employeeRepo.UpdatePassword(ctx, employeeUUID, string(passwordHash), req.ResetMFA, audit); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (u *employeeUsecase) ListAuditLogs(ctx context.Context, employeeID string) ([]models.EmployeeAuditLog, error) {
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	return u.employeeRepo.ListAuditLogs(ctx, employeeUUID)
+}
+
+func applyStringChange(changes map[string]interface{}, field string, current *string, next *string) {
+	if next == nil || *next == *current {
+		return
+	}
+	changes[field] = map[string]interface{}{"from": *current, "to": *next}
+	*current = *next
+}
+
+func activationAction(active bool) string {
+	if active {
+		return constants.AUDIT_EMPLOYEE_ACTIVATE
+	}
+	return constants.AUDIT_EMPLOYEE_DEACTIVATE
+}
+
+func newEmployeeAudit(employeeID, actorID uuid.UUID, action string, changes map[string]interface{}) *models.EmployeeAuditLog {
+	return &models.EmployeeAuditLog{
+		ID:              uuid.New(),
+		EmployeeID:      employeeID,
+		ActorEmployeeID: actorID,
+		Action:          action,
+		Changes:         changes,
+		CreatedAt:       time.Now(),
+	}
+}
+
+func generateTemporaryPassword() (string, error) {
+	buf := make([]byte, TEMP_PASSWORD_LENGTH)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	password := make([]byte, TEMP_PASSWORD_LENGTH)
+	for i, b := range buf {
+		password[i] = tempPasswordAlphabet[int(b)%len(tempPasswordAlphabet)]
+	}
+
+	return string(password), nil
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCreateEmployee_HashesPasswordAndAudits(t *testing.T) {
+	employeeRepo := mocksRepo.NewEmployeeRepository(t)
+	employeeUsecase := NewEmployeeUsecase(employeeRepo)
+
+	actorID := uuid.New()
+	employeeRepo.On("CreateEmployee", mock.Anything,
+		mock.MatchedBy(func(e *models.Employee) bool { return e.Email == "new.officer@amartha.com" && e.IsActive }),
+		mock.MatchedBy(func(hash string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte("password123")) == nil
+		}),
+		mock.MatchedBy(func(a *models.EmployeeAuditLog) bool {
+			_, hasPassword := a.Changes["password"]
+			return a.Action == "CREATE" && a.ActorEmployeeID == actorID && !hasPassword
+		}),
+	).Return(nil)
+
+	employee, err := employeeUsecase.CreateEmployee(context.Background(), actorID.String(), &models.CreateEmployeeRequest{
+		Username:     "new.officer",
+		Email:        "New.Officer@amartha.com",
+		Password:     "password123",
+		FullName:     "New Officer",
+		EmployeeRole: "FIELD_OFFICER",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "FIELD_OFFICER", employee.EmployeeRole)
+}
+
+func TestUpdateEmployee_RecordsOnlyChangedFields(t *testing.T) {
+	employeeRepo := mocksRepo.NewEmployeeRepository(t)
+	employeeUsecase := NewEmployeeUsecase(employeeRepo)
+
+	actorID := uuid.New()
+	employeeID := uuid.New()
+	employeeRepo.On("GetEmployee", mock.Anything, employeeID).Return(&models.Employee{
+		ID: employeeID, FullName: "Budi", EmployeeRole: "FIELD_OFFICER", Branch: "Bekasi", IsActive: true,
+	}, nil)
+	employeeRepo.On("UpdateEmployee", mock.Anything,
+		mock.MatchedBy(func(e *models.Employee) bool { return e.EmployeeRole == "FIELD_VALIDATOR" }),
+		mock.MatchedBy(func(a *models.EmployeeAuditLog) bool {
+			_, hasBranch := a.Changes["branch"]
+			return a.Action == "UPDATE" && len(a.Changes) == 1 && a.Changes["employee_role"] != nil && !hasBranch
+		}),
+	).Return(nil)
+
+	role := "FIELD_VALIDATOR"
+	branch := "Bekasi"
+	employee, err := employeeUsecase.UpdateEmployee(context.Background(), actorID.String(), employeeID.String(),
+		&models.UpdateEmployeeRequest{EmployeeRole: &role, Branch: &branch})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "FIELD_VALIDATOR", employee.EmployeeRole)
+}
+
+func TestSetEmployeeActive_LastAdminRejected(t *testing.T) {
+	employeeRepo := mocksRepo.NewEmployeeRepository(t)
+	employeeUsecase := NewEmployeeUsecase(employeeRepo)
+
+	adminID := uuid.New()
+	employeeRepo.On("GetEmployee", mock.Anything, adminID).Return(&models.Employee{
+		ID: adminID, EmployeeRole: "ADMIN", IsActive: true,
+	}, nil)
+	employeeRepo.On("UpdateEmployee", mock.Anything, mock.Anything,
+		mock.MatchedBy(func(a *models.EmployeeAuditLog) bool { return a.Action == "DEACTIVATE" }),
+	).Return(fmt.Errorf("cannot remove the last active admin"))
+
+	employee, err := employeeUsecase.SetEmployeeActive(context.Background(), uuid.New().String(), adminID.String(), false)
+
+	assert.Nil(t, employee)
+	assert.EqualError(t, err, "cannot remove the last active admin")
+}
+
+func TestUpdateEmployee_OwnRoleAndStatusRejected(t *testing.T) {
+	employeeRepo := mocksRepo.NewEmployeeRepository(t)
+	employeeUsecase := NewEmployeeUsecase(employeeRepo)
+
+	adminID := uuid.New()
+	for i := 0; i < 3; i++ {
+		employeeRepo.On("GetEmployee", mock.Anything, adminID).Return(&models.Employee{
+			ID: adminID, FullName: "Sari", EmployeeRole: "ADMIN", IsActive: true,
+		}, nil).Once()
+	}
+
+	role := "FIELD_OFFICER"
+	employee, err := employeeUsecase.UpdateEmployee(context.Background(), adminID.String(), adminID.String(),
+		&models.UpdateEmployeeRequest{EmployeeRole: &role})
+	assert.Nil(t, employee)
+	assert.EqualError(t, err, "cannot change own role or status")
+
+	employee, err = employeeUsecase.SetEmployeeActive(context.Background(), adminID.String(), adminID.String(), false)
+	assert.Nil(t, employee)
+	assert.EqualError(t, err, "cannot change own role or status")
+
+	// Unchanged values and other fields are still accepted
+	name := "Sari Dewi"
+	same := "ADMIN"
+	employeeRepo.On("UpdateEmployee", mock.Anything, mock.Anything,
+		mock.MatchedBy(func(a *models.EmployeeAuditLog) bool { return len(a.Changes) == 1 && a.Changes["full_name"] != nil }),
+	).Return(nil)
+	employee, err = employeeUsecase.UpdateEmployee(context.Background(), adminID.String(), adminID.String(),
+		&models.UpdateEmployeeRequest{FullName: &name, EmployeeRole: &same})
+	assert.NoError(t, err)
+	assert.Equal(t, "Sari Dewi", employee.FullName)
+}
+
+func TestResetPassword_GeneratesTemporaryPassword(t *testing.T) {
+	employeeRepo := mocksRepo.NewEmployeeRepository(t)
+	employeeUsecase := NewEmployeeUsecase(employeeRepo)
+
+	employeeID := uuid.New()
+	var savedHash string
+	employeeRepo.On("UpdatePassword", mock.Anything, employeeID, mock.Anything, true, mock.Anything).
+		Run(func(args mock.Arguments) { savedHash = args.String(2) }).
+		Return(nil)
+
+	response, err := employeeUsecase.ResetPassword(context.Background(), uuid.New().String(), employeeID.String(),
+		&models.ResetPasswordRequest{ResetMFA: true})
+
+	assert.NoError(t, err)
+	assert.Len(t, response.TemporaryPassword, TEMP_PASSWORD_LENGTH)
+	assert.True(t, response.MFAReset)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(savedHash), []byte(response.TemporaryPassword)))
+}
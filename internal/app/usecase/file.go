@@ -2,38 +2,75 @@ package usecase
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/authz"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
 	"io"
 	"mime/multipart"
-	"os"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-const (
-	SURVEY_URL_PATH = "uploads/survey_documents"
-)
+type FileConfig struct {
+	// SigningSecret signs shared links, sharing is disabled while it is empty
+	SigningSecret   string
+	SignedURLTTL    time.Duration
+	MaxSignedURLTTL time.Duration
+	Survey          SurveyConfig
+}
 
 type FileUsecase interface {
 	UploadSurveyDocument(ctx context.Context, req *models.UploadDocumentRequest, file multipart.File, header *multipart.FileHeader, validatorID string) (*models.UploadDocumentResponse, error)
+	GetFile(ctx context.Context, fileID string, user *models.JWTClaims) (*models.FileDownload, error)
+	ShareFile(ctx context.Context, fileID string, user *models.JWTClaims, req *models.ShareFileRequest) (*models.ShareFileResponse, error)
+	GetSharedFile(ctx context.Context, fileID, expires, signature string) (*models.FileDownload, error)
+	ListDocuments(ctx context.Context, loanID string, user *models.JWTClaims, includeDeleted bool) ([]models.Document, error)
+	DeleteDocument(ctx context.Context, documentID string, employeeID string, req *models.DeleteDocumentRequest) error
+	// VerifyDocument reports a stamped document's registry entry and, when a
+	// hash is presented, whether it matches.
+	VerifyDocument(ctx context.Context, verificationID, presentedSHA256 string) (*models.DocumentVerification, error)
+	VerifyDocumentContent(ctx context.Context, verificationID string, content io.Reader) (*models.DocumentVerification, error)
 }
 
 type fileUsecase struct {
-	fileRepo repositories.FileRepository
+	fileRepo     repositories.FileRepository
+	documentRepo repositories.DocumentRepository
+	guard        authz.Guard
+	store        storage.Store
+	intake       UploadIntake
+	config       FileConfig
+	now          func() time.Time
 }
 
-func NewFileUsecase(fileRepo repositories.FileRepository) FileUsecase {
+func NewFileUsecase(fileRepo repositories.FileRepository, documentRepo repositories.DocumentRepository, guard authz.Guard, store storage.Store, intake UploadIntake, config FileConfig) FileUsecase {
 	return &fileUsecase{
-		fileRepo: fileRepo,
+		fileRepo:     fileRepo,
+		documentRepo: documentRepo,
+		guard:        guard,
+		store:        store,
+		intake:       intake,
+		config:       config.withDefaults(),
+		now:          time.Now,
 	}
 }
 
+func (c FileConfig) withDefaults() FileConfig {
+	c.Survey = c.Survey.withDefaults()
+	return c
+}
+
 func (u *fileUsecase) UploadSurveyDocument(ctx context.Context, req *models.UploadDocumentRequest, file multipart.File, header *multipart.FileHeader, validatorID string) (*models.UploadDocumentResponse, error) {
 	loanUUID, err := uuid.Parse(req.LoanID)
 	if err != nil {
@@ -45,11 +82,19 @@ func (u *fileUsecase) UploadSurveyDocument(ctx context.Context, req *models.Uplo
 		return nil, fmt.Errorf("invalid validator ID: %w", err)
 	}
 
-	surveyDate, err := time.Parse("2006-01-02", req.SurveyDate)
+	surveyDate, err := time.ParseInLocation("2006-01-02", req.SurveyDate, u.config.Survey.Location)
 	if err != nil {
 		return nil, fmt.Errorf("invalid survey date format: %w", err)
 	}
 
+	if surveyDate.After(u.now().In(u.config.Survey.Location)) {
+		return nil, fmt.Errorf("survey date cannot be in the future")
+	}
+
+	if err := u.guard.CheckLoanAccess(ctx, validatorUUID, loanUUID, constants.PERM_SURVEY_UPLOAD); err != nil {
+		return nil, err
+	}
+
 	currentState, err := u.fileRepo.GetLoanCurrentState(ctx, loanUUID)
 	if err != nil {
 		return nil, fmt.Errorf("loan not found: %w", err)
@@ -63,43 +108,399 @@ func (u *fileUsecase) UploadSurveyDocument(ctx context.Context, req *models.Uplo
 		return nil, fmt.Errorf("invalid file type, allowed: .jpg, .jpeg, .png, .pdf")
 	}
 
-	fileExt := filepath.Ext(header.Filename)
-	fileName := fmt.Sprintf("survey_%s_%d%s", loanUUID.String(), time.Now().Unix(), fileExt)
-
-	// Save file to local storage
-	filePath := filepath.Join(SURVEY_URL_PATH, fileName)
-	dst, err := os.Create(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+	documentType := req.DocumentType
+	if documentType == "" {
+		documentType = constants.DOCUMENT_SURVEY_PHOTO
+		if strings.ToLower(filepath.Ext(header.Filename)) == ".pdf" {
+			documentType = constants.DOCUMENT_SURVEY_DOCUMENT
+		}
 	}
-	defer dst.Close()
 
-	_, err = io.Copy(dst, file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to save file: %w", err)
+	document := &models.Document{
+		ID:             uuid.New(),
+		LoanID:         loanUUID,
+		DocumentType:   documentType,
+		FileName:       filepath.Base(header.Filename),
+		UploadedByID:   &validatorUUID,
+		UploadedByType: constants.USER_EMPLOYEE,
+		CreatedAt:      time.Now(),
+	}
+	if err := u.intake.Accept(ctx, document, file); err != nil {
+		return nil, err
 	}
 
-	// TODO: Get location from config
-	fileURL := fmt.Sprintf("/%s/%s", SURVEY_URL_PATH, fileName)
+	flags := []models.SurveyFlag{}
+	if documentType == constants.DOCUMENT_SURVEY_PHOTO && strings.HasPrefix(document.ContentType, "image/") {
+		address, err := u.fileRepo.GetBorrowerCoordinates(ctx, loanUUID)
+		if err != nil {
+			u.intake.Discard(ctx, document)
+			return nil, err
+		}
+		flags = append(flags, verifySurveyPhoto(u.config.Survey, document, address, surveyDate)...)
+	}
 
-	err = u.fileRepo.UpdateLoanSurveyInfo(ctx, loanUUID, validatorUUID, surveyDate, fileURL, req.SurveyNotes)
+	err = u.fileRepo.UpdateLoanSurveyInfo(ctx, loanUUID, validatorUUID, surveyDate, req.SurveyNotes, document, flags)
 	if err != nil {
-		os.Remove(filePath)
+		u.intake.Discard(ctx, document)
 		return nil, fmt.Errorf("failed to update loan: %w", err)
 	}
 
 	return &models.UploadDocumentResponse{
 		LoanID:                   loanUUID,
-		FileName:                 fileName,
-		FileURL:                  fileURL,
-		FileType:                 header.Header.Get("Content-Type"),
+		DocumentID:               document.ID,
+		DocumentType:             document.DocumentType,
+		Version:                  document.Version,
+		FileName:                 document.FileName,
+		FileURL:                  documentURL(document.ID),
+		FileType:                 document.ContentType,
+		SizeBytes:                *document.SizeBytes,
+		SHA256:                   document.SHA256,
+		ScanStatus:               document.ScanStatus,
 		FieldValidatorEmployeeID: validatorUUID,
 		SurveyDate:               req.SurveyDate,
 		SurveyNotes:              req.SurveyNotes,
-		UploadedAt:               time.Now(),
+		UploadedAt:               document.CreatedAt,
+		SurveyFlags:              flags,
 	}, nil
 }
 
+// GetFile returns the file after checking the caller's relation to its loan.
+func (u *fileUsecase) GetFile(ctx context.Context, fileID string, user *models.JWTClaims) (*models.FileDownload, error) {
+	document, err := u.authorizeFile(ctx, fileID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.openFile(ctx, document)
+}
+
+// ShareFile issues a signed link to a file the caller can read, for people
+// outside the platform such as a notary.
+func (u *fileUsecase) ShareFile(ctx context.Context, fileID string, user *models.JWTClaims, req *models.ShareFileRequest) (*models.ShareFileResponse, error) {
+	if u.config.SigningSecret == "" {
+		return nil, fmt.Errorf("file sharing is not configured")
+	}
+
+	if _, err := u.authorizeFile(ctx, fileID, user); err != nil {
+		return nil, err
+	}
+
+	ttl := u.config.SignedURLTTL
+	if req.ExpiresInMinutes > 0 {
+		ttl = time.Duration(req.ExpiresInMinutes) * time.Minute
+	}
+	if ttl > u.config.MaxSignedURLTTL {
+		return nil, fmt.Errorf("expiry exceeds maximum of %s", u.config.MaxSignedURLTTL)
+	}
+
+	expiresAt := u.now().Add(ttl).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", u.signFile(fileID, expires))
+
+	return &models.ShareFileResponse{
+		FileID:    fileID,
+		URL:       constants.FILE_SHARED_PATH + url.PathEscape(fileID) + "?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// GetSharedFile serves a file through a signed link without authentication.
+func (u *fileUsecase) GetSharedFile(ctx context.Context, fileID, expires, signature string) (*models.FileDownload, error) {
+	if u.config.SigningSecret == "" {
+		return nil, fmt.Errorf("file sharing is not configured")
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file signature")
+	}
+
+	if !hmac.Equal([]byte(u.signFile(fileID, expires)), []byte(signature)) {
+		return nil, fmt.Errorf("invalid file signature")
+	}
+
+	if u.now().Unix() > expiresAt {
+		return nil, fmt.Errorf("file link expired")
+	}
+
+	document, err := u.findDocument(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.openFile(ctx, document)
+}
+
+// ListDocuments returns the loan's documents the caller may read. Only
+// employees can include soft-deleted documents.
+func (u *fileUsecase) ListDocuments(ctx context.Context, loanID string, user *models.JWTClaims, includeDeleted bool) ([]models.Document, error) {
+	loanUUID, err := uuid.Parse(loanID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loan ID")
+	}
+
+	userUUID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	if user.UserType == constants.USER_EMPLOYEE {
+		if err := u.guard.CheckLoanAccess(ctx, userUUID, loanUUID, constants.PERM_DOCUMENT_READ); err != nil {
+			return nil, err
+		}
+	} else {
+		includeDeleted = false
+	}
+
+	documents, err := u.documentRepo.ListDocuments(ctx, loanUUID, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make([]models.Document, 0, len(documents))
+	for _, document := range documents {
+		if user.UserType != constants.USER_EMPLOYEE && !canReadDocument(&document, user, userUUID) {
+			continue
+		}
+		document.DownloadURL = documentURL(document.ID)
+		readable = append(readable, document)
+	}
+
+	return readable, nil
+}
+
+// DeleteDocument soft-deletes an uploaded document while the loan is still
+// under review. Generated agreements are never deleted.
+func (u *fileUsecase) DeleteDocument(ctx context.Context, documentID string, employeeID string, req *models.DeleteDocumentRequest) error {
+	documentUUID, err := uuid.Parse(documentID)
+	if err != nil {
+		return fmt.Errorf("document not found")
+	}
+
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return fmt.Errorf("invalid employee ID")
+	}
+
+	document, err := u.documentRepo.GetDocument(ctx, documentUUID)
+	if err != nil {
+		return err
+	}
+
+	if document.DeletedAt != nil {
+		return fmt.Errorf("document not found")
+	}
+
+	if err := u.guard.CheckLoanAccess(ctx, employeeUUID, document.LoanID, constants.PERM_DOCUMENT_DELETE); err != nil {
+		return err
+	}
+
+	if !isUploadedDocumentType(document.DocumentType) {
+		return fmt.Errorf("document type cannot be deleted: %s", document.DocumentType)
+	}
+
+	currentState, err := u.fileRepo.GetLoanCurrentState(ctx, document.LoanID)
+	if err != nil {
+		return fmt.Errorf("loan not found: %w", err)
+	}
+
+	if currentState != constants.PROPOSED {
+		return fmt.Errorf("loan must be in PROPOSED state, current state: %s", currentState)
+	}
+
+	return u.documentRepo.SoftDeleteDocument(ctx, documentUUID, employeeUUID, req.Reason)
+}
+
+func (u *fileUsecase) VerifyDocument(ctx context.Context, verificationID, presentedSHA256 string) (*models.DocumentVerification, error) {
+	verificationID = strings.ToUpper(strings.TrimSpace(verificationID))
+	if verificationID == "" {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	presentedSHA256 = strings.ToLower(strings.TrimSpace(presentedSHA256))
+	if presentedSHA256 != "" {
+		if decoded, err := hex.DecodeString(presentedSHA256); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid sha256")
+		}
+	}
+
+	document, err := u.documentRepo.GetDocumentByVerificationID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &models.DocumentVerification{
+		VerificationID: document.VerificationID,
+		DocumentType:   document.DocumentType,
+		Status:         constants.VERIFICATION_VALID,
+		SHA256:         document.SHA256,
+		IssuedAt:       document.CreatedAt,
+	}
+	if document.DeletedAt != nil {
+		verification.Status = constants.VERIFICATION_REVOKED
+	}
+	if presentedSHA256 != "" {
+		matches := hmac.Equal([]byte(presentedSHA256), []byte(document.SHA256))
+		verification.PresentedSHA256 = presentedSHA256
+		verification.Matches = &matches
+	}
+
+	return verification, nil
+}
+
+// VerifyDocumentContent hashes a presented copy and checks it against the
+// registry.
+func (u *fileUsecase) VerifyDocumentContent(ctx context.Context, verificationID string, content io.Reader) (*models.DocumentVerification, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	return u.VerifyDocument(ctx, verificationID, hex.EncodeToString(hash.Sum(nil)))
+}
+
+// authorizeFile resolves the document and checks the caller against its loan.
+// Borrowers and investors get "file not found" for files that are not theirs so
+// file IDs cannot be probed.
+func (u *fileUsecase) authorizeFile(ctx context.Context, fileID string, user *models.JWTClaims) (*models.Document, error) {
+	document, err := u.findDocument(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	switch user.UserType {
+	case constants.USER_EMPLOYEE:
+		if err := u.guard.CheckLoanAccess(ctx, userUUID, document.LoanID, constants.PERM_DOCUMENT_READ); err != nil {
+			return nil, err
+		}
+	case constants.USER_SERVICE:
+		if !containsScope(user.Scopes, constants.PERM_DOCUMENT_READ) {
+			return nil, fmt.Errorf("permission denied: %s", constants.PERM_DOCUMENT_READ)
+		}
+	default:
+		if !canReadDocument(document, user, userUUID) {
+			return nil, fmt.Errorf("file not found")
+		}
+	}
+
+	return document, nil
+}
+
+// findDocument accepts a document ID, or the stored file name used by links
+// issued before documents were tracked. Deleted documents are not served.
+func (u *fileUsecase) findDocument(ctx context.Context, fileID string) (*models.Document, error) {
+	var document *models.Document
+	var err error
+
+	if documentUUID, parseErr := uuid.Parse(fileID); parseErr == nil {
+		document, err = u.documentRepo.GetDocument(ctx, documentUUID)
+	} else if isValidFileID(fileID) {
+		document, err = u.documentRepo.GetDocumentByFileName(ctx, fileID)
+	} else {
+		return nil, fmt.Errorf("file not found")
+	}
+
+	if err != nil {
+		if err.Error() == "document not found" {
+			return nil, fmt.Errorf("file not found")
+		}
+		return nil, err
+	}
+
+	if document.DeletedAt != nil {
+		return nil, fmt.Errorf("file not found")
+	}
+
+	if document.ScanStatus == constants.SCAN_PENDING {
+		return nil, fmt.Errorf("file pending malware scan")
+	}
+
+	return document, nil
+}
+
+func (u *fileUsecase) openFile(ctx context.Context, document *models.Document) (*models.FileDownload, error) {
+	content, info, err := u.store.Get(ctx, document.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("file not found")
+		}
+		return nil, err
+	}
+
+	contentType := document.ContentType
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = info.ContentType
+	}
+
+	return &models.FileDownload{
+		FileName:     document.FileName,
+		ContentType:  contentType,
+		Size:         info.Size,
+		LastModified: info.LastModified,
+		Content:      content,
+	}, nil
+}
+
+func (u *fileUsecase) signFile(fileID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(u.config.SigningSecret))
+	mac.Write([]byte(fileID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// canReadDocument covers borrowers and investors, who may read documents on
+// their own loans and their own investment agreements respectively.
+func canReadDocument(document *models.Document, user *models.JWTClaims, userUUID uuid.UUID) bool {
+	switch user.UserType {
+	case constants.USER_BORROWER:
+		return document.BorrowerID == userUUID
+	case constants.USER_INVESTOR:
+		return document.InvestorID != nil && *document.InvestorID == userUUID
+	case constants.USER_SERVICE:
+		return containsScope(user.Scopes, constants.PERM_DOCUMENT_READ)
+	}
+	return false
+}
+
+// documentURL is the authorized download link for a document.
+func documentURL(documentID uuid.UUID) string {
+	return constants.FILE_DOWNLOAD_PATH + documentID.String()
+}
+
+// documentKey places every document version under its own key so uploads never
+// overwrite each other.
+func documentKey(loanID, documentID uuid.UUID, fileName string) string {
+	return fmt.Sprintf("%s/%s/%s%s", constants.DOCUMENT_KEY_PREFIX, loanID, documentID, strings.ToLower(filepath.Ext(fileName)))
+}
+
+func isUploadedDocumentType(documentType string) bool {
+	switch documentType {
+	case constants.DOCUMENT_SURVEY_PHOTO, constants.DOCUMENT_SURVEY_DOCUMENT, constants.DOCUMENT_SUPPORTING_DOCUMENT:
+		return true
+	}
+	return false
+}
+
+func isValidFileID(fileID string) bool {
+	return fileID != "" && fileID != "." && fileID != ".." && !strings.ContainsAny(fileID, "/\\")
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, candidate := range scopes {
+		if candidate == scope {
+			return true
+		}
+	}
+	return false
+}
+
 func isValidFileType(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
 	validTypes := []string{".jpg", ".jpeg", ".png", ".pdf"}
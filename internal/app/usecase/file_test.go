@@ -0,0 +1,723 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	mocksAuthz "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/authz"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/scanner"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/upload"
+	"image"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testFileConfig = FileConfig{
+	SigningSecret:   "test-signing-secret",
+	SignedURLTTL:    15 * time.Minute,
+	MaxSignedURLTTL: 24 * time.Hour,
+	Survey: SurveyConfig{
+		MaxDistanceMeters:    500,
+		CaptureTimeTolerance: 12 * time.Hour,
+		Location:             time.FixedZone("WIB", 7*3600),
+	},
+}
+
+func TestGetFile_BorrowerReadsOwnAgreement(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	require.NoError(t, store.Put(context.Background(), document.StorageKey, strings.NewReader("%PDF-1.4 agreement"), 18, "application/pdf"))
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+
+	download, err := fileUsecase.GetFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   document.BorrowerID.String(),
+		UserType: "borrower",
+	})
+
+	require.NoError(t, err)
+	defer download.Content.Close()
+	content, _ := io.ReadAll(download.Content)
+	assert.Equal(t, "%PDF-1.4 agreement", string(content))
+	assert.Equal(t, "application/pdf", download.ContentType)
+}
+
+func TestGetFile_OtherBorrowerSeesNotFound(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+
+	download, err := fileUsecase.GetFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   uuid.New().String(),
+		UserType: "borrower",
+	})
+
+	assert.Nil(t, download)
+	assert.EqualError(t, err, "file not found")
+}
+
+func TestGetFile_InvestorLimitedToOwnInvestmentAgreement(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+
+	download, err := fileUsecase.GetFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   uuid.New().String(),
+		UserType: "investor",
+	})
+
+	assert.Nil(t, download)
+	assert.EqualError(t, err, "file not found")
+}
+
+func TestGetFile_EmployeeOutsideBranchDenied(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	employeeID := uuid.New()
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+	guard.On("CheckLoanAccess", mock.Anything, employeeID, document.LoanID, "document:read").
+		Return(fmt.Errorf("loan outside employee branch"))
+
+	download, err := fileUsecase.GetFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   employeeID.String(),
+		UserType: "employee",
+		Role:     "FIELD_OFFICER",
+	})
+
+	assert.Nil(t, download)
+	assert.EqualError(t, err, "loan outside employee branch")
+}
+
+func TestGetFile_PathInFileIDRejected(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	download, err := fileUsecase.GetFile(context.Background(), "../config.yml", &models.JWTClaims{
+		UserID:   uuid.New().String(),
+		UserType: "borrower",
+	})
+
+	assert.Nil(t, download)
+	assert.EqualError(t, err, "file not found")
+}
+
+func TestShareFile_SignedLinkServesFileUntilExpiry(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	require.NoError(t, store.Put(context.Background(), document.StorageKey, strings.NewReader("%PDF-1.4 agreement"), 18, "application/pdf"))
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+
+	now := time.Now()
+	fileUsecase.now = func() time.Time { return now }
+
+	response, err := fileUsecase.ShareFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   document.BorrowerID.String(),
+		UserType: "borrower",
+	}, &models.ShareFileRequest{ExpiresInMinutes: 10})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(response.URL, "/api/v1/shared-files/"+document.ID.String()+"?"))
+
+	link, err := url.Parse(response.URL)
+	require.NoError(t, err)
+	expires := link.Query().Get("expires")
+	signature := link.Query().Get("signature")
+
+	download, err := fileUsecase.GetSharedFile(context.Background(), document.ID.String(), expires, signature)
+	require.NoError(t, err)
+	download.Content.Close()
+
+	// A signature only covers its own file and expiry
+	_, err = fileUsecase.GetSharedFile(context.Background(), uuid.NewString(), expires, signature)
+	assert.EqualError(t, err, "invalid file signature")
+
+	fileUsecase.now = func() time.Time { return now.Add(11 * time.Minute) }
+	_, err = fileUsecase.GetSharedFile(context.Background(), document.ID.String(), expires, signature)
+	assert.EqualError(t, err, "file link expired")
+}
+
+func TestShareFile_ExpiryCapped(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+
+	response, err := fileUsecase.ShareFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   document.BorrowerID.String(),
+		UserType: "borrower",
+	}, &models.ShareFileRequest{ExpiresInMinutes: 48 * 60})
+
+	assert.Nil(t, response)
+	assert.EqualError(t, err, "expiry exceeds maximum of 24h0m0s")
+}
+
+func TestGetFile_LegacyFileNameResolvesDocument(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	require.NoError(t, store.Put(context.Background(), document.StorageKey, strings.NewReader("%PDF-1.4 agreement"), 18, "application/pdf"))
+	documentRepo.On("GetDocumentByFileName", mock.Anything, "loan_agreement_legacy.pdf").Return(document, nil)
+
+	download, err := fileUsecase.GetFile(context.Background(), "loan_agreement_legacy.pdf", &models.JWTClaims{
+		UserID:   document.BorrowerID.String(),
+		UserType: "borrower",
+	})
+
+	require.NoError(t, err)
+	download.Content.Close()
+	assert.Equal(t, document.FileName, download.FileName)
+}
+
+func TestGetFile_DeletedDocumentNotServed(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	deletedAt := time.Now()
+	document.DeletedAt = &deletedAt
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+
+	download, err := fileUsecase.GetFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   document.BorrowerID.String(),
+		UserType: "borrower",
+	})
+
+	assert.Nil(t, download)
+	assert.EqualError(t, err, "file not found")
+}
+
+func TestGetFile_PendingScanNotServed(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	document.ScanStatus = "PENDING"
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+
+	download, err := fileUsecase.GetFile(context.Background(), document.ID.String(), &models.JWTClaims{
+		UserID:   document.BorrowerID.String(),
+		UserType: "borrower",
+	})
+
+	assert.Nil(t, download)
+	assert.EqualError(t, err, "file pending malware scan")
+}
+
+func TestListDocuments_InvestorSeesOnlyOwnAgreements(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	loanID := uuid.New()
+	investorID := uuid.New()
+	otherInvestorID := uuid.New()
+	documents := []models.Document{
+		{ID: uuid.New(), LoanID: loanID, DocumentType: "LOAN_AGREEMENT", BorrowerID: uuid.New()},
+		{ID: uuid.New(), LoanID: loanID, DocumentType: "INVESTMENT_AGREEMENT", InvestorID: &investorID},
+		{ID: uuid.New(), LoanID: loanID, DocumentType: "INVESTMENT_AGREEMENT", InvestorID: &otherInvestorID},
+	}
+	documentRepo.On("ListDocuments", mock.Anything, loanID, false).Return(documents, nil)
+
+	result, err := fileUsecase.ListDocuments(context.Background(), loanID.String(), &models.JWTClaims{
+		UserID:   investorID.String(),
+		UserType: "investor",
+	}, true)
+
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, documents[1].ID, result[0].ID)
+	assert.Equal(t, "/api/v1/files/"+documents[1].ID.String(), result[0].DownloadURL)
+}
+
+func TestDeleteDocument_GeneratedAgreementKept(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	employeeID := uuid.New()
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+	guard.On("CheckLoanAccess", mock.Anything, employeeID, document.LoanID, "document:delete").Return(nil)
+
+	err = fileUsecase.DeleteDocument(context.Background(), document.ID.String(), employeeID.String(),
+		&models.DeleteDocumentRequest{Reason: "wrong file"})
+
+	assert.EqualError(t, err, "document type cannot be deleted: LOAN_AGREEMENT")
+	documentRepo.AssertNotCalled(t, "SoftDeleteDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteDocument_SurveyPhotoSoftDeleted(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	employeeID := uuid.New()
+	document := &models.Document{ID: uuid.New(), LoanID: uuid.New(), DocumentType: "SURVEY_PHOTO", FileName: "survey.jpg"}
+	documentRepo.On("GetDocument", mock.Anything, document.ID).Return(document, nil)
+	guard.On("CheckLoanAccess", mock.Anything, employeeID, document.LoanID, "document:delete").Return(nil)
+	fileRepo.On("GetLoanCurrentState", mock.Anything, document.LoanID).Return("PROPOSED", nil)
+	documentRepo.On("SoftDeleteDocument", mock.Anything, document.ID, employeeID, "blurry photo").Return(nil)
+
+	err = fileUsecase.DeleteDocument(context.Background(), document.ID.String(), employeeID.String(),
+		&models.DeleteDocumentRequest{Reason: "blurry photo"})
+
+	assert.NoError(t, err)
+}
+
+type testUploadFile struct {
+	*bytes.Reader
+}
+
+func (testUploadFile) Close() error { return nil }
+
+// newSurveyUpload builds the upload of fileName by a validator assigned to the
+// loan.
+func newSurveyUpload(loanID uuid.UUID, fileName string, content []byte) (*models.UploadDocumentRequest, *multipart.FileHeader) {
+	header := &multipart.FileHeader{
+		Filename: fileName,
+		Size:     int64(len(content)),
+		Header:   textproto.MIMEHeader{"Content-Type": {"image/jpeg"}},
+	}
+	return &models.UploadDocumentRequest{
+		LoanID:     loanID.String(),
+		SurveyDate: "2025-06-15",
+	}, header
+}
+
+func TestUploadSurveyDocument_StoresDetectedContent(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	loanID := uuid.New()
+	validatorID := uuid.New()
+	content := []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF")
+	req, header := newSurveyUpload(loanID, "survey.pdf", content)
+	guard.On("CheckLoanAccess", mock.Anything, validatorID, loanID, "survey:upload").Return(nil)
+	fileRepo.On("GetLoanCurrentState", mock.Anything, loanID).Return("PROPOSED", nil)
+
+	sum := sha256.Sum256(content)
+	fileRepo.On("UpdateLoanSurveyInfo", mock.Anything, loanID, validatorID, mock.Anything, "",
+		mock.MatchedBy(func(document *models.Document) bool {
+			return document.DocumentType == "SURVEY_DOCUMENT" &&
+				document.ScanStatus == "CLEAN" &&
+				document.ContentType == "application/pdf" &&
+				document.SHA256 == hex.EncodeToString(sum[:]) &&
+				*document.SizeBytes == int64(len(content))
+		}), []models.SurveyFlag{}).Return(nil)
+
+	response, err := fileUsecase.UploadSurveyDocument(context.Background(), req, testUploadFile{bytes.NewReader(content)}, header, validatorID.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", response.FileType)
+	assert.Equal(t, "/api/v1/files/"+response.DocumentID.String(), response.FileURL)
+}
+
+func TestUploadSurveyDocument_RejectsDisguisedContent(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	loanID := uuid.New()
+	validatorID := uuid.New()
+	content := []byte("%PDF-1.4\n<< /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >>\n%%EOF")
+	req, header := newSurveyUpload(loanID, "survey.jpg", content)
+	guard.On("CheckLoanAccess", mock.Anything, validatorID, loanID, "survey:upload").Return(nil)
+	fileRepo.On("GetLoanCurrentState", mock.Anything, loanID).Return("PROPOSED", nil)
+
+	response, err := fileUsecase.UploadSurveyDocument(context.Background(), req, testUploadFile{bytes.NewReader(content)}, header, validatorID.String())
+
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, upload.ErrTypeMismatch)
+	fileRepo.AssertNotCalled(t, "UpdateLoanSurveyInfo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadSurveyDocument_FutureDateRejected(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	validatorID := uuid.New()
+	fileUsecase.now = func() time.Time { return time.Date(2025, 6, 15, 23, 0, 0, 0, time.UTC) }
+
+	// Already the 16th in Jakarta, the 17th is still ahead
+	response, err := fileUsecase.UploadSurveyDocument(context.Background(), &models.UploadDocumentRequest{
+		LoanID:     uuid.NewString(),
+		SurveyDate: "2025-06-17",
+	}, testUploadFile{bytes.NewReader(nil)}, &multipart.FileHeader{Filename: "survey.jpg"}, validatorID.String())
+
+	assert.Nil(t, response)
+	assert.EqualError(t, err, "survey date cannot be in the future")
+}
+
+func TestUploadSurveyDocument_FlagsPhotoWithoutMetadata(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	loanID := uuid.New()
+	validatorID := uuid.New()
+	var content bytes.Buffer
+	require.NoError(t, jpeg.Encode(&content, image.NewGray(image.Rect(0, 0, 2, 2)), nil))
+	req, header := newSurveyUpload(loanID, "survey.jpg", content.Bytes())
+	guard.On("CheckLoanAccess", mock.Anything, validatorID, loanID, "survey:upload").Return(nil)
+	fileRepo.On("GetLoanCurrentState", mock.Anything, loanID).Return("PROPOSED", nil)
+
+	fileRepo.On("GetBorrowerCoordinates", mock.Anything, loanID).
+		Return(&models.Coordinates{Latitude: -6.2, Longitude: 106.8}, nil)
+	fileRepo.On("UpdateLoanSurveyInfo", mock.Anything, loanID, validatorID, mock.Anything, "", mock.Anything,
+		mock.MatchedBy(func(flags []models.SurveyFlag) bool {
+			return len(flags) == 2 &&
+				flags[0].FlagType == "GPS_MISSING" &&
+				flags[1].FlagType == "CAPTURE_TIME_MISSING"
+		})).Return(nil)
+
+	response, err := fileUsecase.UploadSurveyDocument(context.Background(), req, testUploadFile{bytes.NewReader(content.Bytes())}, header, validatorID.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, "SURVEY_PHOTO", response.DocumentType)
+	require.Len(t, response.SurveyFlags, 2)
+	assert.Equal(t, response.DocumentID, response.SurveyFlags[0].DocumentID)
+}
+
+func TestVerifyDocument_ReportsRegistryEntry(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	sum := sha256.Sum256([]byte("%PDF-1.4 agreement"))
+	checksum := hex.EncodeToString(sum[:])
+	document.SHA256 = checksum
+	document.VerificationID = "MFRGGZDFMZTWQ2LK"
+	documentRepo.On("GetDocumentByVerificationID", mock.Anything, "MFRGGZDFMZTWQ2LK").Return(document, nil)
+
+	verification, err := fileUsecase.VerifyDocument(context.Background(), " mfrggzdfmztwq2lk", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "VALID", verification.Status)
+	assert.Equal(t, checksum, verification.SHA256)
+	assert.Equal(t, "LOAN_AGREEMENT", verification.DocumentType)
+	assert.Nil(t, verification.Matches)
+}
+
+func TestVerifyDocument_PresentedHash(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	sum := sha256.Sum256([]byte("%PDF-1.4 agreement"))
+	checksum := hex.EncodeToString(sum[:])
+	document.SHA256 = checksum
+	document.VerificationID = "MFRGGZDFMZTWQ2LK"
+	documentRepo.On("GetDocumentByVerificationID", mock.Anything, "MFRGGZDFMZTWQ2LK").Return(document, nil)
+
+	verification, err := fileUsecase.VerifyDocument(context.Background(), "MFRGGZDFMZTWQ2LK", strings.ToUpper(checksum))
+	require.NoError(t, err)
+	require.NotNil(t, verification.Matches)
+	assert.True(t, *verification.Matches)
+
+	edited := sha256.Sum256([]byte("%PDF-1.4 agreement, amount edited"))
+	verification, err = fileUsecase.VerifyDocument(context.Background(), "MFRGGZDFMZTWQ2LK", hex.EncodeToString(edited[:]))
+	require.NoError(t, err)
+	assert.False(t, *verification.Matches)
+}
+
+func TestVerifyDocumentContent_HashesUploadedCopy(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	sum := sha256.Sum256([]byte("%PDF-1.4 agreement"))
+	checksum := hex.EncodeToString(sum[:])
+	document.SHA256 = checksum
+	document.VerificationID = "MFRGGZDFMZTWQ2LK"
+	documentRepo.On("GetDocumentByVerificationID", mock.Anything, "MFRGGZDFMZTWQ2LK").Return(document, nil)
+
+	verification, err := fileUsecase.VerifyDocumentContent(context.Background(), "MFRGGZDFMZTWQ2LK", strings.NewReader("%PDF-1.4 agreement"))
+
+	require.NoError(t, err)
+	assert.True(t, *verification.Matches)
+}
+
+func TestVerifyDocument_DeletedDocumentRevoked(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	document := &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "LOAN_AGREEMENT",
+		FileName:     "loan_agreement.pdf",
+		StorageKey:   "documents/agreement.pdf",
+		ContentType:  "application/pdf",
+		Version:      1,
+		BorrowerID:   uuid.New(),
+	}
+	sum := sha256.Sum256([]byte("%PDF-1.4 agreement"))
+	checksum := hex.EncodeToString(sum[:])
+	document.SHA256 = checksum
+	document.VerificationID = "MFRGGZDFMZTWQ2LK"
+	deletedAt := time.Now()
+	document.DeletedAt = &deletedAt
+	documentRepo.On("GetDocumentByVerificationID", mock.Anything, "MFRGGZDFMZTWQ2LK").Return(document, nil)
+
+	verification, err := fileUsecase.VerifyDocument(context.Background(), "MFRGGZDFMZTWQ2LK", "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "REVOKED", verification.Status)
+}
+
+func TestVerifyDocument_InvalidHashRejected(t *testing.T) {
+	fileRepo := mocksRepo.NewFileRepository(t)
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, scanner.NewNoopScanner(), testFileConfig.Survey.Location)
+	fileUsecase := NewFileUsecase(fileRepo, documentRepo, guard, store, intake, testFileConfig).(*fileUsecase)
+
+	_, err = fileUsecase.VerifyDocument(context.Background(), "MFRGGZDFMZTWQ2LK", "not-a-hash")
+
+	assert.EqualError(t, err, "invalid sha256")
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"time"
+)
+
+// IDEMPOTENCY_KEY_MAX_LENGTH bounds the Idempotency-Key header
+const IDEMPOTENCY_KEY_MAX_LENGTH = 255
+
+type IdempotencyUsecase interface {
+	// Begin claims key for the principal's request with fingerprint. It
+	// returns nil when the request should run, or the stored key when it was
+	// already answered and its response is to be replayed.
+	Begin(ctx context.Context, principal, key, fingerprint string) (*models.IdempotencyKey, error)
+	// Complete stores the response of a request Begin let run.
+	Complete(ctx context.Context, principal, key, fingerprint string, statusCode int, contentType string, body []byte) error
+	// Release gives the key up without a response, a retry runs the request
+	// again.
+	Release(ctx context.Context, principal, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed for its key
+	TTL time.Duration
+	// LockTimeout is how long a request may hold its key before a retry can
+	// take the key over, for requests whose instance died
+	LockTimeout time.Duration
+}
+
+func (c IdempotencyConfig) withDefaults() IdempotencyConfig {
+	if c.TTL <= 0 {
+		c.TTL = 24 * time.Hour
+	}
+	if c.LockTimeout <= 0 {
+		c.LockTimeout = time.Minute
+	}
+	return c
+}
+
+type idempotencyUsecase struct {
+	repo   repositories.IdempotencyRepository
+	config IdempotencyConfig
+	now    func() time.Time
+}
+
+func NewIdempotencyUsecase(repo repositories.IdempotencyRepository, config IdempotencyConfig) IdempotencyUsecase {
+	return &idempotencyUsecase{
+		repo:   repo,
+		config: config.withDefaults(),
+		now:    time.Now,
+	}
+}
+
+func (u *idempotencyUsecase) Begin(ctx context.Context, principal, key, fingerprint string) (*models.IdempotencyKey, error) {
+	if key == "" || len(key) > IDEMPOTENCY_KEY_MAX_LENGTH {
+		return nil, fmt.Errorf("invalid idempotency key")
+	}
+
+	now := u.now()
+	claimed, err := u.repo.ClaimKey(ctx, &models.IdempotencyKey{
+		Principal:   principal,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      constants.IDEMPOTENCY_PROCESSING,
+		LockedAt:    now,
+		ExpiresAt:   now.Add(u.config.TTL),
+		CreatedAt:   now,
+	}, now.Add(-u.config.LockTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed {
+		return nil, nil
+	}
+
+	existing, err := u.repo.GetKey(ctx, principal, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key was sent before with another request, replaying its response
+	// would answer a request that never ran
+	if existing.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("idempotency key reused with a different request")
+	}
+
+	if existing.Status != constants.IDEMPOTENCY_COMPLETED {
+		return nil, fmt.Errorf("request with this idempotency key is in progress")
+	}
+
+	return existing, nil
+}
+
+func (u *idempotencyUsecase) Complete(ctx context.Context, principal, key, fingerprint string, statusCode int, contentType string, body []byte) error {
+	return u.repo.CompleteKey(ctx, &models.IdempotencyKey{
+		Principal:           principal,
+		Key:                 key,
+		Fingerprint:         fingerprint,
+		Status:              constants.IDEMPOTENCY_COMPLETED,
+		ResponseStatus:      statusCode,
+		ResponseContentType: contentType,
+		ResponseBody:        body,
+		ExpiresAt:           u.now().Add(u.config.TTL),
+	})
+}
+
+func (u *idempotencyUsecase) Release(ctx context.Context, principal, key string) error {
+	return u.repo.ReleaseKey(ctx, principal, key)
+}
+
+func (u *idempotencyUsecase) PurgeExpired(ctx context.Context) (int64, error) {
+	return u.repo.DeleteExpiredKeys(ctx, u.now())
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testIdempotencyTime = time.Date(2025, 7, 11, 9, 0, 0, 0, time.UTC)
+
+func newTestIdempotencyUsecase(t *testing.T) (*idempotencyUsecase, *mocksRepo.IdempotencyRepository) {
+	repo := mocksRepo.NewIdempotencyRepository(t)
+	keys := NewIdempotencyUsecase(repo, IdempotencyConfig{TTL: 2 * time.Hour}).(*idempotencyUsecase)
+	keys.now = func() time.Time { return testIdempotencyTime }
+	return keys, repo
+}
+
+func TestBegin_ClaimsNewKey(t *testing.T) {
+	keys, repo := newTestIdempotencyUsecase(t)
+
+	repo.On("ClaimKey", mock.Anything, mock.MatchedBy(func(k *models.IdempotencyKey) bool {
+		return k.Principal == "investor:abc" && k.Key == "retry-1" && k.Fingerprint == "fp" &&
+			k.Status == "PROCESSING" && k.ExpiresAt.Equal(testIdempotencyTime.Add(2*time.Hour))
+	}), testIdempotencyTime.Add(-time.Minute)).Return(true, nil)
+
+	stored, err := keys.Begin(context.Background(), "investor:abc", "retry-1", "fp")
+
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestBegin_ReplaysCompletedRequest(t *testing.T) {
+	keys, repo := newTestIdempotencyUsecase(t)
+
+	completed := &models.IdempotencyKey{
+		Principal:      "investor:abc",
+		Key:            "retry-1",
+		Fingerprint:    "fp",
+		Status:         "COMPLETED",
+		ResponseStatus: 201,
+		ResponseBody:   []byte(`{"data":{"success":true}}`),
+	}
+	repo.On("ClaimKey", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	repo.On("GetKey", mock.Anything, "investor:abc", "retry-1").Return(completed, nil)
+
+	stored, err := keys.Begin(context.Background(), "investor:abc", "retry-1", "fp")
+
+	require.NoError(t, err)
+	assert.Equal(t, completed, stored)
+}
+
+func TestBegin_RejectsKeyReusedForAnotherRequest(t *testing.T) {
+	keys, repo := newTestIdempotencyUsecase(t)
+
+	repo.On("ClaimKey", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	repo.On("GetKey", mock.Anything, "borrower:abc", "retry-1").Return(&models.IdempotencyKey{
+		Fingerprint: "first-body",
+		Status:      "COMPLETED",
+	}, nil)
+
+	_, err := keys.Begin(context.Background(), "borrower:abc", "retry-1", "second-body")
+
+	assert.EqualError(t, err, "idempotency key reused with a different request")
+}
+
+func TestBegin_RequestInProgress(t *testing.T) {
+	keys, repo := newTestIdempotencyUsecase(t)
+
+	repo.On("ClaimKey", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	repo.On("GetKey", mock.Anything, "borrower:abc", "retry-1").Return(&models.IdempotencyKey{
+		Fingerprint: "fp",
+		Status:      "PROCESSING",
+	}, nil)
+
+	_, err := keys.Begin(context.Background(), "borrower:abc", "retry-1", "fp")
+
+	assert.EqualError(t, err, "request with this idempotency key is in progress")
+}
+
+func TestBegin_RejectsOversizedKey(t *testing.T) {
+	keys, repo := newTestIdempotencyUsecase(t)
+
+	_, err := keys.Begin(context.Background(), "borrower:abc", strings.Repeat("k", 256), "fp")
+
+	assert.EqualError(t, err, "invalid idempotency key")
+	repo.AssertNotCalled(t, "ClaimKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestComplete_StoresResponseForTTL(t *testing.T) {
+	keys, repo := newTestIdempotencyUsecase(t)
+
+	repo.On("CompleteKey", mock.Anything, mock.MatchedBy(func(k *models.IdempotencyKey) bool {
+		return k.Status == "COMPLETED" && k.ResponseStatus == 409 && k.ResponseContentType == "application/json" &&
+			string(k.ResponseBody) == `{}` && k.ExpiresAt.Equal(testIdempotencyTime.Add(2*time.Hour))
+	})).Return(nil)
+
+	err := keys.Complete(context.Background(), "investor:abc", "retry-1", "fp", 409, "application/json", []byte(`{}`))
+
+	assert.NoError(t, err)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	keys, repo := newTestIdempotencyUsecase(t)
+
+	repo.On("DeleteExpiredKeys", mock.Anything, testIdempotencyTime).Return(int64(3), nil).Once()
+	purged, err := keys.PurgeExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+
+	repo.On("DeleteExpiredKeys", mock.Anything, testIdempotencyTime).Return(int64(0), fmt.Errorf("failed to delete expired idempotency keys: connection refused"))
+	_, err = keys.PurgeExpired(context.Background())
+	assert.Error(t, err)
+}
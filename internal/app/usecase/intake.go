@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/scanner"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/upload"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// UploadIntake validates, scans and stores files supplied by users before the
+// caller records them against a loan.
+type UploadIntake interface {
+	// Accept stores the file and fills in the document's key, content type,
+	// size, checksum, scan status and photo geotag and capture time. Infected
+	// files are quarantined and rejected with scanner.ErrInfected.
+	Accept(ctx context.Context, document *models.Document, file io.Reader) error
+	// Discard removes a stored file whose document was never recorded.
+	Discard(ctx context.Context, document *models.Document)
+	// RescanPending scans documents stored while the scanner was down and
+	// returns how many were resolved.
+	RescanPending(ctx context.Context, limit int) (int, error)
+}
+
+type uploadIntake struct {
+	documentRepo repositories.DocumentRepository
+	store        storage.Store
+	scanner      scanner.Scanner
+	// captureLocation is assumed for photos that record no time zone
+	captureLocation *time.Location
+	now             func() time.Time
+}
+
+func NewUploadIntake(documentRepo repositories.DocumentRepository, store storage.Store, fileScanner scanner.Scanner, captureLocation *time.Location) UploadIntake {
+	if captureLocation == nil {
+		captureLocation = time.UTC
+	}
+
+	return &uploadIntake{
+		documentRepo:    documentRepo,
+		store:           store,
+		scanner:         fileScanner,
+		captureLocation: captureLocation,
+		now:             time.Now,
+	}
+}
+
+func (i *uploadIntake) Accept(ctx context.Context, document *models.Document, file io.Reader) error {
+	original, err := io.ReadAll(io.LimitReader(file, constants.MAX_UPLOAD_SIZE+1))
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(original) > constants.MAX_UPLOAD_SIZE {
+		return upload.ErrTooLarge
+	}
+
+	inspected, err := upload.Inspect(bytes.NewReader(original), document.FileName, constants.MAX_UPLOAD_SIZE)
+	if err != nil {
+		return err
+	}
+	document.ContentType = inspected.ContentType
+	applyPhotoMetadata(document, inspected.Metadata, i.captureLocation)
+
+	// The original is scanned, sanitizing may have dropped the payload but the
+	// upload is still hostile
+	result, err := i.scanner.Scan(ctx, bytes.NewReader(original))
+	switch {
+	case err != nil:
+		log.Warn().Err(err).Str("document_id", document.ID.String()).Msg("Malware scan unavailable, holding document as pending")
+		document.ScanStatus = constants.SCAN_PENDING
+		// Kept for the rescan, which has to scan the original too
+		key := pendingScanKey(document.LoanID, document.ID, document.FileName)
+		if err := i.store.Put(ctx, key, bytes.NewReader(original), int64(len(original)), "application/octet-stream"); err != nil {
+			return fmt.Errorf("failed to hold file for scanning: %w", err)
+		}
+	case result.Infected:
+		return i.quarantine(ctx, document, original, result.Signature)
+	default:
+		scannedAt := i.now()
+		document.ScanStatus = constants.SCAN_CLEAN
+		document.ScannedAt = &scannedAt
+	}
+
+	document.StorageKey = documentKey(document.LoanID, document.ID, document.FileName)
+	size, checksum, err := storage.PutWithChecksum(ctx, i.store, document.StorageKey, inspected.Reader(), inspected.Size(), inspected.ContentType)
+	if err != nil {
+		i.Discard(ctx, document)
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	document.SizeBytes = &size
+	document.SHA256 = checksum
+
+	return nil
+}
+
+func (i *uploadIntake) Discard(ctx context.Context, document *models.Document) {
+	if document.StorageKey == "" {
+		return
+	}
+	i.delete(ctx, document.StorageKey)
+	if document.ScanStatus == constants.SCAN_PENDING {
+		i.delete(ctx, pendingScanKey(document.LoanID, document.ID, document.FileName))
+	}
+}
+
+func (i *uploadIntake) delete(ctx context.Context, key string) {
+	if err := i.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Error().Err(err).Str("storage_key", key).Msg("Failed to discard stored file")
+	}
+}
+
+// quarantine keeps the untouched upload under the quarantine prefix and
+// records it as a deleted document so it shows up in the loan's audit trail.
+func (i *uploadIntake) quarantine(ctx context.Context, document *models.Document, original []byte, signature string) error {
+	now := i.now()
+	document.StorageKey = quarantineKey(document.LoanID, document.ID, document.FileName)
+	document.ScanStatus = constants.SCAN_INFECTED
+	document.ScanSignature = signature
+	document.ScannedAt = &now
+	document.DeletedAt = &now
+	document.DeletedReason = "malware detected: " + signature
+
+	size, checksum, err := storage.PutWithChecksum(ctx, i.store, document.StorageKey, bytes.NewReader(original), int64(len(original)), "application/octet-stream")
+	if err != nil {
+		return fmt.Errorf("failed to quarantine file: %w", err)
+	}
+	document.SizeBytes = &size
+	document.SHA256 = checksum
+
+	if err := i.documentRepo.CreateDocument(ctx, document); err != nil {
+		log.Error().Err(err).Str("storage_key", document.StorageKey).Msg("Failed to record quarantined file")
+	}
+
+	log.Warn().
+		Str("loan_id", document.LoanID.String()).
+		Str("document_id", document.ID.String()).
+		Str("signature", signature).
+		Msg("Infected upload quarantined")
+
+	return fmt.Errorf("%w: %s", scanner.ErrInfected, signature)
+}
+
+func (i *uploadIntake) RescanPending(ctx context.Context, limit int) (int, error) {
+	documents, err := i.documentRepo.ListPendingDocuments(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for _, document := range documents {
+		// The original is scanned, as on upload, not the sanitized copy
+		original := pendingScanKey(document.LoanID, document.ID, document.FileName)
+		content, _, err := i.store.Get(ctx, original)
+		if err != nil {
+			log.Error().Err(err).Str("document_id", document.ID.String()).Msg("Failed to read pending document")
+			continue
+		}
+		result, err := i.scanner.Scan(ctx, content)
+		content.Close()
+		if err != nil {
+			// Still down, try the rest on the next run
+			return resolved, err
+		}
+
+		status, storageKey := constants.SCAN_CLEAN, document.StorageKey
+		if result.Infected {
+			status = constants.SCAN_INFECTED
+			storageKey, err = i.copyToQuarantine(ctx, &document, original)
+			if err != nil {
+				log.Error().Err(err).Str("document_id", document.ID.String()).Msg("Failed to quarantine document")
+				continue
+			}
+		}
+
+		if err := i.documentRepo.UpdateDocumentScan(ctx, document.ID, status, result.Signature, storageKey); err != nil {
+			log.Error().Err(err).Str("document_id", document.ID.String()).Msg("Failed to record document scan")
+			continue
+		}
+
+		// The servable copy goes once the document points at quarantine
+		if result.Infected {
+			i.Discard(ctx, &document)
+		} else {
+			i.delete(ctx, original)
+		}
+
+		log.Info().
+			Str("document_id", document.ID.String()).
+			Str("scan_status", status).
+			Str("signature", result.Signature).
+			Msg("Pending document scanned")
+		resolved++
+	}
+
+	return resolved, nil
+}
+
+func (i *uploadIntake) copyToQuarantine(ctx context.Context, document *models.Document, original string) (string, error) {
+	content, info, err := i.store.Get(ctx, original)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	key := quarantineKey(document.LoanID, document.ID, document.FileName)
+	if err := i.store.Put(ctx, key, content, info.Size, "application/octet-stream"); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func pendingScanKey(loanID, documentID uuid.UUID, fileName string) string {
+	return fmt.Sprintf("%s/%s/%s%s", constants.PENDING_SCAN_KEY_PREFIX, loanID, documentID, strings.ToLower(filepath.Ext(fileName)))
+}
+
+func quarantineKey(loanID, documentID uuid.UUID, fileName string) string {
+	return fmt.Sprintf("%s/%s/%s%s", constants.QUARANTINE_KEY_PREFIX, loanID, documentID, strings.ToLower(filepath.Ext(fileName)))
+}
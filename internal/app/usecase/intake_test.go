@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	mocksScanner "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/scanner"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/scanner"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const intakePDF = "%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF"
+
+func newIntakeDocument() *models.Document {
+	return &models.Document{
+		ID:           uuid.New(),
+		LoanID:       uuid.New(),
+		DocumentType: "SURVEY_DOCUMENT",
+		FileName:     "survey.pdf",
+		CreatedAt:    time.Now(),
+	}
+}
+
+// readStored returns the stored object's content.
+func readStored(t *testing.T, store storage.Store, key string) string {
+	content, _, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestUploadIntake_CleanFileStored(t *testing.T) {
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	fileScanner := mocksScanner.NewScanner(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, fileScanner, time.UTC)
+
+	fileScanner.On("Scan", mock.Anything, mock.Anything).Return(&scanner.Result{}, nil)
+	document := newIntakeDocument()
+
+	err = intake.Accept(context.Background(), document, strings.NewReader(intakePDF))
+
+	require.NoError(t, err)
+	assert.Equal(t, "CLEAN", document.ScanStatus)
+	assert.NotNil(t, document.ScannedAt)
+	assert.Equal(t, "documents/"+document.LoanID.String()+"/"+document.ID.String()+".pdf", document.StorageKey)
+	_, err = store.Stat(context.Background(), document.StorageKey)
+	assert.NoError(t, err)
+}
+
+func TestUploadIntake_InfectedFileQuarantined(t *testing.T) {
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	fileScanner := mocksScanner.NewScanner(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, fileScanner, time.UTC)
+
+	fileScanner.On("Scan", mock.Anything, mock.Anything).Return(&scanner.Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil)
+	documentRepo.On("CreateDocument", mock.Anything, mock.MatchedBy(func(document *models.Document) bool {
+		return document.ScanStatus == "INFECTED" && document.DeletedAt != nil &&
+			strings.HasPrefix(document.StorageKey, "quarantine/")
+	})).Return(nil)
+	document := newIntakeDocument()
+
+	err = intake.Accept(context.Background(), document, strings.NewReader(intakePDF))
+
+	assert.ErrorIs(t, err, scanner.ErrInfected)
+	assert.EqualError(t, err, "malware detected: Eicar-Test-Signature")
+	_, err = store.Stat(context.Background(), document.StorageKey)
+	assert.NoError(t, err)
+	_, err = store.Stat(context.Background(), documentKey(document.LoanID, document.ID, document.FileName))
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestUploadIntake_ScannerDownHoldsPending(t *testing.T) {
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	fileScanner := mocksScanner.NewScanner(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, fileScanner, time.UTC)
+
+	fileScanner.On("Scan", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: connection refused", scanner.ErrUnavailable))
+	document := newIntakeDocument()
+
+	err = intake.Accept(context.Background(), document, strings.NewReader(intakePDF))
+
+	require.NoError(t, err)
+	assert.Equal(t, "PENDING", document.ScanStatus)
+	assert.Nil(t, document.ScannedAt)
+	// The untouched upload waits for the rescan
+	assert.Equal(t, intakePDF, readStored(t, store, pendingScanKey(document.LoanID, document.ID, document.FileName)))
+
+	intake.Discard(context.Background(), document)
+	_, err = store.Stat(context.Background(), pendingScanKey(document.LoanID, document.ID, document.FileName))
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestUploadIntake_RescanPending(t *testing.T) {
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	fileScanner := mocksScanner.NewScanner(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, fileScanner, time.UTC)
+
+	ctx := context.Background()
+
+	// Sanitizing dropped the payload from the servable copy, the original
+	// still has it
+	const original = intakePDF + "\n% payload"
+	clean := newIntakeDocument()
+	infected := newIntakeDocument()
+	for _, document := range []*models.Document{clean, infected} {
+		document.ScanStatus = "PENDING"
+		document.StorageKey = documentKey(document.LoanID, document.ID, document.FileName)
+		require.NoError(t, store.Put(ctx, document.StorageKey, strings.NewReader(intakePDF), int64(len(intakePDF)), "application/pdf"))
+		key := pendingScanKey(document.LoanID, document.ID, document.FileName)
+		require.NoError(t, store.Put(ctx, key, strings.NewReader(original), int64(len(original)), "application/octet-stream"))
+	}
+	cleanKey, infectedKey := clean.StorageKey, infected.StorageKey
+
+	var scanned []string
+	recordScan := func(args mock.Arguments) {
+		content, _ := io.ReadAll(args.Get(1).(io.Reader))
+		scanned = append(scanned, string(content))
+	}
+	documentRepo.On("ListPendingDocuments", mock.Anything, 10).Return([]models.Document{*clean, *infected}, nil)
+	fileScanner.On("Scan", mock.Anything, mock.Anything).Run(recordScan).Return(&scanner.Result{}, nil).Once()
+	fileScanner.On("Scan", mock.Anything, mock.Anything).Run(recordScan).Return(&scanner.Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil).Once()
+	documentRepo.On("UpdateDocumentScan", mock.Anything, clean.ID, "CLEAN", "", cleanKey).Return(nil)
+	quarantined := quarantineKey(infected.LoanID, infected.ID, infected.FileName)
+	documentRepo.On("UpdateDocumentScan", mock.Anything, infected.ID, "INFECTED", "Eicar-Test-Signature", quarantined).Return(nil)
+
+	resolved, err := intake.RescanPending(ctx, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, resolved)
+	assert.Equal(t, []string{original, original}, scanned)
+	assert.Equal(t, original, readStored(t, store, quarantined))
+	_, err = store.Stat(ctx, infectedKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = store.Stat(ctx, cleanKey)
+	assert.NoError(t, err)
+	for _, document := range []*models.Document{clean, infected} {
+		_, err = store.Stat(ctx, pendingScanKey(document.LoanID, document.ID, document.FileName))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	}
+}
+
+func TestUploadIntake_RescanStopsWhileScannerDown(t *testing.T) {
+	documentRepo := mocksRepo.NewDocumentRepository(t)
+	fileScanner := mocksScanner.NewScanner(t)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	intake := NewUploadIntake(documentRepo, store, fileScanner, time.UTC)
+
+	ctx := context.Background()
+
+	pending := []models.Document{*newIntakeDocument(), *newIntakeDocument()}
+	for i := range pending {
+		key := pendingScanKey(pending[i].LoanID, pending[i].ID, pending[i].FileName)
+		require.NoError(t, store.Put(ctx, key, strings.NewReader(intakePDF), int64(len(intakePDF)), "application/octet-stream"))
+	}
+
+	documentRepo.On("ListPendingDocuments", mock.Anything, 10).Return(pending, nil)
+	fileScanner.On("Scan", mock.Anything, mock.Anything).Return(nil, scanner.ErrUnavailable).Once()
+
+	resolved, err := intake.RescanPending(ctx, 10)
+
+	assert.ErrorIs(t, err, scanner.ErrUnavailable)
+	assert.Equal(t, 0, resolved)
+	documentRepo.AssertNotCalled(t, "UpdateDocumentScan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,29 +2,78 @@ package usecase
 
 import (
 	"context"
+	"crypto/hmac"
+	"encoding/json"
 	"fmt"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/notification"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
 	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdf"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
+const DEFAULT_ACCEPTANCE_WINDOW = 72 * time.Hour
+
+// InvestmentConfig sets how long investors have to accept their agreement
+// before the investment is cancelled.
+type InvestmentConfig struct {
+	AcceptanceWindow time.Duration
+}
+
+func (c InvestmentConfig) withDefaults() InvestmentConfig {
+	if c.AcceptanceWindow <= 0 {
+		c.AcceptanceWindow = DEFAULT_ACCEPTANCE_WINDOW
+	}
+	return c
+}
+
+// ClientInfo is what the request tells about the caller, kept as evidence of
+// acceptances and signatures.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
 type InvestmentUsecase interface {
 	CreateInvestment(ctx context.Context, loanID, investorID string, req *models.CreateInvestmentRequest) (*models.InvestmentResponse, error)
+	// AcceptAgreement records the investor's click-to-accept of the current
+	// version of their agreement.
+	AcceptAgreement(ctx context.Context, investmentID, investorID string, req *models.AcceptAgreementRequest, evidence ClientInfo) (*models.AcceptAgreementResponse, error)
+	// CancelExpiredInvestments cancels investments whose agreement was not
+	// accepted before the deadline and returns them. An InvestmentCancelled
+	// event is saved for each.
+	CancelExpiredInvestments(ctx context.Context) ([]models.Investment, error)
+	// GetPortfolio returns the investor's loans with their tranches added up.
+	// Investors only see their own portfolio.
+	GetPortfolio(ctx context.Context, investorID, callerID string) (*models.InvestorPortfolio, error)
+	// NotifyEvent sends the notifications an outbox event calls for: the
+	// agreement for InvestmentCreated and the completion notice for
+	// LoanFullyFunded. Other events are ignored.
+	NotifyEvent(ctx context.Context, event *models.OutboxEvent) error
 }
 
 type investmentUsecase struct {
 	investmentRepo repositories.InvestmentRepository
+	templateRepo   repositories.AgreementTemplateRepository
 	pdfGenerator   pdf.PDFGenerator
+	notifier       notification.Notifier
+	config         InvestmentConfig
+	now            func() time.Time
 }
 
-func NewInvestmentUsecase(investmentRepo repositories.InvestmentRepository, pdfGenerator pdf.PDFGenerator) InvestmentUsecase {
+func NewInvestmentUsecase(investmentRepo repositories.InvestmentRepository, templateRepo repositories.AgreementTemplateRepository, pdfGenerator pdf.PDFGenerator, notifier notification.Notifier, config InvestmentConfig) InvestmentUsecase {
 	return &investmentUsecase{
 		investmentRepo: investmentRepo,
+		templateRepo:   templateRepo,
 		pdfGenerator:   pdfGenerator,
+		notifier:       notifier,
+		config:         config.withDefaults(),
+		now:            time.Now,
 	}
 }
 
@@ -48,14 +97,7 @@ func (u *investmentUsecase) CreateInvestment(ctx context.Context, loanID, invest
 		return nil, fmt.Errorf("loan must be in APPROVED or FUNDING state")
 	}
 
-	exists, err := u.investmentRepo.CheckExistingInvestment(ctx, loanUUID, investorUUID)
-	if err != nil {
-		return nil, err
-	}
-	if exists {
-		return nil, fmt.Errorf("investor has already invested in this loan")
-	}
-
+	// Checked again while the loan is locked, this fails early
 	remainingAmount := loan.PrincipalAmount - loan.TotalInvested
 	if req.InvestmentAmount > remainingAmount {
 		return nil, fmt.Errorf("investment amount exceeds remaining loan amount")
@@ -68,63 +110,319 @@ func (u *investmentUsecase) CreateInvestment(ctx context.Context, loanID, invest
 		return nil, err
 	}
 
-	now := time.Now()
-	investment := &models.Investment{
-		ID:               uuid.New(),
-		LoanID:           loanUUID,
-		InvestorID:       investorUUID,
-		InvestmentAmount: req.InvestmentAmount,
-		ExpectedReturn:   expectedReturn,
-		InvestmentDate:   now,
-		CreatedAt:        now,
-	}
-
-	err = u.investmentRepo.CreateInvestment(ctx, investment)
+	// Every investor in a loan signs the same template version
+	agreementTemplate, err := agreementTemplateFor(ctx, u.templateRepo, loanUUID, constants.DOCUMENT_INVESTMENT_AGREEMENT)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate individual investment agreement PDF
-	agreementURL, err := u.pdfGenerator.GenerateInvestmentAgreement(investment, loan, investorName)
+	// An investor already in the loan tops up with a new tranche, with its
+	// own agreement to accept, so tranches they accepted stay as they are
+	tranche, err := u.investmentRepo.GetNextTranche(ctx, loanUUID, investorUUID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate investment agreement: %w", err)
+		return nil, err
 	}
 
-	newTotalInvested := loan.TotalInvested + req.InvestmentAmount
-	newRemainingAmount := loan.PrincipalAmount - newTotalInvested
-
-	var newState string
+	now := u.now()
+	acceptanceDeadline := now.Add(u.config.AcceptanceWindow)
+	investment := &models.Investment{
+		ID:                 uuid.New(),
+		LoanID:             loanUUID,
+		InvestorID:         investorUUID,
+		Tranche:            tranche,
+		InvestmentAmount:   req.InvestmentAmount,
+		ExpectedReturn:     expectedReturn,
+		InvestmentDate:     now,
+		Status:             constants.INVESTMENT_PENDING_ACCEPTANCE,
+		AcceptanceDeadline: &acceptanceDeadline,
+		CreatedAt:          now,
+	}
 
-	if newRemainingAmount == 0 {
-		// Loan = principal - move to invested
-		newState = constants.INVESTED
-	} else if loan.CurrentState == constants.APPROVED {
-		// First investment - transition to FUNDING
-		newState = constants.FUNDING
-	} else {
-		newState = constants.FUNDING
+	// Rendered and stored before the loan is locked, so other investments in
+	// the loan do not wait on it
+	agreement, err := u.pdfGenerator.GenerateInvestmentAgreement(investment, loan, investorName, agreementTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate investment agreement: %w", err)
 	}
 
-	if newState != loan.CurrentState {
-		err = u.investmentRepo.UpdateLoanState(ctx, loanUUID, newState)
+	placement, err := u.investmentRepo.CreateInvestment(ctx, investment, agreement, func(investment *models.Investment, placement *models.InvestmentPlacement) ([]*models.OutboxEvent, error) {
+		created, err := newLoanEvent(constants.EVENT_INVESTMENT_CREATED, loanUUID, now, models.InvestmentCreatedEvent{
+			InvestmentID:       investment.ID,
+			LoanID:             loanUUID,
+			InvestorID:         investorUUID,
+			Tranche:            investment.Tranche,
+			InvestmentAmount:   investment.InvestmentAmount,
+			ExpectedReturn:     investment.ExpectedReturn,
+			AcceptanceDeadline: investment.AcceptanceDeadline,
+		})
 		if err != nil {
 			return nil, err
 		}
+		events := []*models.OutboxEvent{created}
+
+		if placement.Loan.CurrentState == constants.INVESTED {
+			funded, err := newLoanEvent(constants.EVENT_LOAN_FULLY_FUNDED, loanUUID, now, models.LoanFullyFundedEvent{
+				LoanID:          loanUUID,
+				PrincipalAmount: placement.Loan.PrincipalAmount,
+				TotalInvested:   placement.Loan.TotalInvested,
+			})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, funded)
+		}
+
+		return events, nil
+	})
+	if err != nil {
+		u.pdfGenerator.Discard(ctx, agreement)
+		return nil, err
 	}
 
 	response := &models.InvestmentResponse{
 		ID:                  investment.ID,
 		LoanID:              investment.LoanID,
 		InvestorID:          investment.InvestorID,
+		Tranche:             investment.Tranche,
 		InvestmentAmount:    investment.InvestmentAmount,
 		ExpectedReturn:      investment.ExpectedReturn,
 		InvestmentDate:      investment.InvestmentDate.Format("2006-01-02"),
-		LoanCurrentState:    newState,
-		TotalInvestedAmount: newTotalInvested,
-		RemainingAmount:     newRemainingAmount,
-		AgreementURL:        agreementURL,
+		LoanCurrentState:    placement.Loan.CurrentState,
+		TotalInvestedAmount: placement.Loan.TotalInvested,
+		RemainingAmount:     placement.Loan.PrincipalAmount - placement.Loan.TotalInvested,
+		AgreementURL:        documentURL(agreement.ID),
+		Status:              investment.Status,
+		AcceptanceDeadline:  acceptanceDeadline,
 		CreatedAt:           investment.CreatedAt,
+
+		InvestorTotalAmount:    placement.Position.InvestedAmount,
+		InvestorExpectedReturn: placement.Position.ExpectedReturn,
 	}
 
 	return response, nil
 }
+
+func (u *investmentUsecase) AcceptAgreement(ctx context.Context, investmentID, investorID string, req *models.AcceptAgreementRequest, evidence ClientInfo) (*models.AcceptAgreementResponse, error) {
+	investmentUUID, err := uuid.Parse(investmentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid investment ID")
+	}
+
+	investorUUID, err := uuid.Parse(investorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid investor ID")
+	}
+
+	investment, err := u.investmentRepo.GetInvestment(ctx, investmentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other investors' investments are not revealed
+	if investment.InvestorID != investorUUID {
+		return nil, fmt.Errorf("investment not found")
+	}
+
+	now := u.now()
+	switch {
+	case investment.Status == constants.INVESTMENT_ACCEPTED:
+		return nil, fmt.Errorf("agreement already accepted")
+	case investment.Status == constants.INVESTMENT_CANCELLED:
+		return nil, fmt.Errorf("investment has been cancelled")
+	case investment.AcceptanceDeadline != nil && !now.Before(*investment.AcceptanceDeadline):
+		return nil, fmt.Errorf("acceptance deadline has passed")
+	}
+
+	agreement, err := u.investmentRepo.GetInvestmentAgreement(ctx, investmentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The investor accepts the copy they read, a regenerated agreement has to
+	// be read again
+	if req.AgreementSHA256 != "" && !hmac.Equal([]byte(strings.ToLower(req.AgreementSHA256)), []byte(agreement.SHA256)) {
+		return nil, fmt.Errorf("agreement has changed")
+	}
+
+	acceptance := &models.AgreementAcceptance{
+		InvestmentID:    investmentUUID,
+		DocumentID:      agreement.ID,
+		AgreementSHA256: agreement.SHA256,
+		AcceptedAt:      now,
+		IPAddress:       evidence.IPAddress,
+		UserAgent:       evidence.UserAgent,
+	}
+	if err := u.investmentRepo.AcceptAgreement(ctx, acceptance); err != nil {
+		return nil, err
+	}
+
+	return &models.AcceptAgreementResponse{
+		InvestmentID:    investmentUUID,
+		LoanID:          investment.LoanID,
+		Status:          constants.INVESTMENT_ACCEPTED,
+		AgreementURL:    documentURL(agreement.ID),
+		AgreementSHA256: agreement.SHA256,
+		AcceptedAt:      now,
+	}, nil
+}
+
+func (u *investmentUsecase) NotifyEvent(ctx context.Context, event *models.OutboxEvent) error {
+	switch event.EventType {
+	case constants.EVENT_INVESTMENT_CREATED:
+		var payload models.InvestmentCreatedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode %s event: %w", event.EventType, err)
+		}
+
+		investment, err := u.investmentRepo.GetInvestment(ctx, payload.InvestmentID)
+		if err != nil {
+			return err
+		}
+		// Accepted or cancelled before the event went out, there is nothing
+		// left to accept
+		if investment.Status != constants.INVESTMENT_PENDING_ACCEPTANCE {
+			return nil
+		}
+
+		loan, err := u.investmentRepo.GetLoanForInvestment(ctx, investment.LoanID)
+		if err != nil {
+			return err
+		}
+		agreement, err := u.investmentRepo.GetInvestmentAgreement(ctx, investment.ID)
+		if err != nil {
+			return err
+		}
+
+		u.sendInvestmentAgreement(ctx, investment, loan, agreement)
+
+	case constants.EVENT_LOAN_FULLY_FUNDED:
+		var payload models.LoanFullyFundedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to decode %s event: %w", event.EventType, err)
+		}
+
+		return u.sendCompletionNotification(ctx, &models.LoanInvestmentInfo{
+			ID:              payload.LoanID,
+			PrincipalAmount: payload.PrincipalAmount,
+		})
+	}
+
+	return nil
+}
+
+// sendInvestmentAgreement sends the investor their individual agreement.
+func (u *investmentUsecase) sendInvestmentAgreement(ctx context.Context, investment *models.Investment, loan *models.LoanInvestmentInfo, agreement *models.Document) {
+	err := u.notifier.Notify(ctx, &notification.Notification{
+		Type:     constants.NOTIFICATION_INVESTMENT_AGREEMENT,
+		UserID:   investment.InvestorID,
+		UserType: constants.USER_INVESTOR,
+		Data: notification.InvestmentAgreementData{
+			InvestmentID:       investment.ID,
+			LoanID:             investment.LoanID,
+			Tranche:            investment.Tranche,
+			InvestmentAmount:   investment.InvestmentAmount,
+			ExpectedReturn:     investment.ExpectedReturn,
+			ROIRate:            loan.ROIRate,
+			AcceptanceDeadline: *investment.AcceptanceDeadline,
+			AgreementURL:       documentURL(agreement.ID),
+			VerificationID:     agreement.VerificationID,
+		},
+		Documents: []*models.Document{agreement},
+	})
+	if err != nil {
+		log.Warn().Err(err).
+			Str("investment_id", investment.ID.String()).
+			Msg("Failed to send investment agreement to investor")
+	}
+}
+
+// sendCompletionNotification tells every investor in the loan that it is
+// fully funded, once per investor with their tranches added up. Only failing
+// to find the investors is an error, the event is retried without anyone
+// notified twice.
+func (u *investmentUsecase) sendCompletionNotification(ctx context.Context, loan *models.LoanInvestmentInfo) error {
+	investments, err := u.investmentRepo.ListLoanInvestments(ctx, loan.ID)
+	if err != nil {
+		return err
+	}
+
+	var positions []*models.InvestorPosition
+	byInvestor := make(map[uuid.UUID]*models.InvestorPosition)
+	for _, investment := range investments {
+		position, ok := byInvestor[investment.InvestorID]
+		if !ok {
+			position = &models.InvestorPosition{LoanID: loan.ID, InvestorID: investment.InvestorID}
+			byInvestor[investment.InvestorID] = position
+			positions = append(positions, position)
+		}
+		position.InvestedAmount += investment.InvestmentAmount
+		position.ExpectedReturn += investment.ExpectedReturn
+		position.Tranches++
+	}
+
+	for _, position := range positions {
+		err := u.notifier.Notify(ctx, &notification.Notification{
+			Type:     constants.NOTIFICATION_LOAN_FULLY_INVESTED,
+			UserID:   position.InvestorID,
+			UserType: constants.USER_INVESTOR,
+			Data: notification.LoanFullyInvestedData{
+				LoanID:           loan.ID,
+				PrincipalAmount:  loan.PrincipalAmount,
+				InvestorCount:    len(positions),
+				InvestmentAmount: position.InvestedAmount,
+				ExpectedReturn:   position.ExpectedReturn,
+			},
+		})
+		if err != nil {
+			log.Warn().Err(err).
+				Str("loan_id", loan.ID.String()).
+				Str("investor_id", position.InvestorID.String()).
+				Msg("Failed to send completion notification to investor")
+		}
+	}
+
+	return nil
+}
+
+func (u *investmentUsecase) CancelExpiredInvestments(ctx context.Context) ([]models.Investment, error) {
+	now := u.now()
+	return u.investmentRepo.CancelExpiredInvestments(ctx, now, func(investment *models.Investment, loanState string) (*models.OutboxEvent, error) {
+		return newLoanEvent(constants.EVENT_INVESTMENT_CANCELLED, investment.LoanID, now, models.InvestmentCancelledEvent{
+			InvestmentID:     investment.ID,
+			LoanID:           investment.LoanID,
+			InvestorID:       investment.InvestorID,
+			Tranche:          investment.Tranche,
+			InvestmentAmount: investment.InvestmentAmount,
+			Reason:           constants.INVESTMENT_EXPIRED_REASON,
+			LoanState:        loanState,
+		})
+	})
+}
+
+func (u *investmentUsecase) GetPortfolio(ctx context.Context, investorID, callerID string) (*models.InvestorPortfolio, error) {
+	investorUUID, err := uuid.Parse(investorID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid investor ID")
+	}
+
+	if investorID != callerID {
+		return nil, fmt.Errorf("permission denied: portfolio of another investor")
+	}
+
+	loans, err := u.investmentRepo.GetInvestorPortfolio(ctx, investorUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio := &models.InvestorPortfolio{
+		InvestorID: investorUUID,
+		LoanCount:  len(loans),
+		Loans:      loans,
+	}
+	for _, loan := range loans {
+		portfolio.TotalInvested += loan.InvestedAmount
+		portfolio.TotalExpectedReturn += loan.ExpectedReturn
+	}
+
+	return portfolio, nil
+}
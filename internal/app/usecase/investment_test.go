@@ -2,21 +2,64 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	mocksNotification "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/notification"
 	mocksPdf "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/pdf"
 	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/notification"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+var testInvestmentTemplate = &models.AgreementTemplate{TemplateType: "INVESTMENT_AGREEMENT", Version: 2, Body: "# INVESTMENT AGREEMENT"}
+
+// investmentRecords is what the usecase had saved with a new investment.
+type investmentRecords struct {
+	investment *models.Investment
+	agreement  *models.Document
+	events     []*models.OutboxEvent
+	err        error
+}
+
+// eventTypes lists the types of the saved events in order.
+func (r *investmentRecords) eventTypes() []string {
+	types := []string{}
+	for _, event := range r.events {
+		types = append(types, event.EventType)
+	}
+	return types
+}
+
+// expectCreateInvestment has the repository number the investment as tranche
+// and place it as placement, the way it does with the loan locked.
+func expectCreateInvestment(mockRepo *mocksRepo.InvestmentRepository, tranche int, placement *models.InvestmentPlacement) *investmentRecords {
+	saved := &investmentRecords{}
+	mockRepo.On("GetNextTranche", mock.Anything, placement.Position.LoanID, placement.Position.InvestorID).Return(tranche, nil)
+	mockRepo.On("CreateInvestment", mock.Anything, mock.AnythingOfType("*models.Investment"), mock.AnythingOfType("*models.Document"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			saved.investment = args.Get(1).(*models.Investment)
+			saved.agreement = args.Get(2).(*models.Document)
+			newEvents := args.Get(3).(repositories.InvestmentEventsFunc)
+			saved.events, saved.err = newEvents(saved.investment, placement)
+		}).
+		Return(placement, nil)
+	return saved
+}
+
 // State Transition (APPROVED -> FUNDING)
 func TestCreateInvestment_FirstInvestmentTransitionsToFunding(t *testing.T) {
 	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	investmentUsecase := NewInvestmentUsecase(mockRepo, mockPdfGen)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
 
 	loanID := uuid.New()
 	investorID := uuid.New()
@@ -34,31 +77,43 @@ func TestCreateInvestment_FirstInvestmentTransitionsToFunding(t *testing.T) {
 	}
 
 	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(loanInfo, nil)
-	mockRepo.On("CheckExistingInvestment", mock.Anything, loanID, investorID).Return(false, nil)
 	mockRepo.On("GetInvestorName", mock.Anything, investorID).Return("Test Investor", nil)
-	mockRepo.On("CreateInvestment", mock.Anything, mock.AnythingOfType("*models.Investment")).Return(nil)
 
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT"}
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "INVESTMENT_AGREEMENT").Return(0, nil)
+	mockTemplateRepo.On("GetLatestTemplate", mock.Anything, "INVESTMENT_AGREEMENT").Return(testInvestmentTemplate, nil)
 	mockPdfGen.On("GenerateInvestmentAgreement",
 		mock.AnythingOfType("*models.Investment"),
 		mock.AnythingOfType("*models.LoanInvestmentInfo"),
-		"Test Investor").Return("/uploads/agreements/investment_agreement.pdf", nil)
+		"Test Investor", testInvestmentTemplate).Return(agreement, nil)
 
-	mockRepo.On("UpdateLoanState", mock.Anything, loanID, "FUNDING").Return(nil)
+	saved := expectCreateInvestment(mockRepo, 1, &models.InvestmentPlacement{
+		Loan:     models.LoanInvestmentInfo{ID: loanID, PrincipalAmount: 5000000, ROIRate: 8, CurrentState: "FUNDING", TotalInvested: 2000000},
+		Position: models.InvestorPosition{LoanID: loanID, InvestorID: investorID, InvestedAmount: 2000000, ExpectedReturn: 160000, Tranches: 1},
+	})
 
 	result, err := investmentUsecase.CreateInvestment(context.Background(), loanID.String(), investorID.String(), req)
 
 	assert.NoError(t, err)
+	assert.NoError(t, saved.err)
+	assert.Equal(t, agreement, saved.agreement)
+	assert.Equal(t, []string{"InvestmentCreated"}, saved.eventTypes())
+	assert.Equal(t, loanID, saved.events[0].AggregateID)
 	assert.Equal(t, "FUNDING", result.LoanCurrentState)
 	assert.Equal(t, float64(2000000), result.TotalInvestedAmount)
 	assert.Equal(t, float64(3000000), result.RemainingAmount) // 5M - 2M = 3M
-	assert.Equal(t, "/uploads/agreements/investment_agreement.pdf", result.AgreementURL)
+	assert.Equal(t, "/api/v1/files/"+agreement.ID.String(), result.AgreementURL)
+	assert.Equal(t, "PENDING_ACCEPTANCE", result.Status)
+	assert.WithinDuration(t, time.Now().Add(DEFAULT_ACCEPTANCE_WINDOW), result.AcceptanceDeadline, time.Minute)
 }
 
 // State Transition (FUNDING -> INVESTED)
 func TestCreateInvestment_FullInvestmentTransitionsToInvested(t *testing.T) {
 	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	investmentUsecase := NewInvestmentUsecase(mockRepo, mockPdfGen)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
 
 	loanID := uuid.New()
 	investorID := uuid.New()
@@ -76,20 +131,30 @@ func TestCreateInvestment_FullInvestmentTransitionsToInvested(t *testing.T) {
 	}
 
 	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(loanInfo, nil)
-	mockRepo.On("CheckExistingInvestment", mock.Anything, loanID, investorID).Return(false, nil)
 	mockRepo.On("GetInvestorName", mock.Anything, investorID).Return("Test Investor", nil)
-	mockRepo.On("CreateInvestment", mock.Anything, mock.AnythingOfType("*models.Investment")).Return(nil)
 
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT"}
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "INVESTMENT_AGREEMENT").Return(0, nil)
+	mockTemplateRepo.On("GetLatestTemplate", mock.Anything, "INVESTMENT_AGREEMENT").Return(testInvestmentTemplate, nil)
 	mockPdfGen.On("GenerateInvestmentAgreement",
 		mock.AnythingOfType("*models.Investment"),
 		mock.AnythingOfType("*models.LoanInvestmentInfo"),
-		"Test Investor").Return("/uploads/agreements/investment_agreement.pdf", nil)
+		"Test Investor", testInvestmentTemplate).Return(agreement, nil)
 
-	mockRepo.On("UpdateLoanState", mock.Anything, loanID, "INVESTED").Return(nil)
+	saved := expectCreateInvestment(mockRepo, 1, &models.InvestmentPlacement{
+		Loan:     models.LoanInvestmentInfo{ID: loanID, PrincipalAmount: 5000000, ROIRate: 8, CurrentState: "INVESTED", TotalInvested: 5000000},
+		Position: models.InvestorPosition{LoanID: loanID, InvestorID: investorID, InvestedAmount: 2000000, ExpectedReturn: 160000, Tranches: 1},
+	})
 
 	result, err := investmentUsecase.CreateInvestment(context.Background(), loanID.String(), investorID.String(), req)
 
 	assert.NoError(t, err)
+	assert.NoError(t, saved.err)
+	// The funding event is saved with the investment that completes the loan
+	assert.Equal(t, []string{"InvestmentCreated", "LoanFullyFunded"}, saved.eventTypes())
+	var funded models.LoanFullyFundedEvent
+	assert.NoError(t, json.Unmarshal(saved.events[1].Payload, &funded))
+	assert.Equal(t, funded.PrincipalAmount, funded.TotalInvested)
 	assert.Equal(t, "INVESTED", result.LoanCurrentState)
 	assert.Equal(t, float64(5000000), result.TotalInvestedAmount) // Fully funded
 	assert.Equal(t, float64(0), result.RemainingAmount)           // No remaining amount
@@ -97,8 +162,10 @@ func TestCreateInvestment_FullInvestmentTransitionsToInvested(t *testing.T) {
 
 func TestCreateInvestment_ROICalculation(t *testing.T) {
 	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	investmentUsecase := NewInvestmentUsecase(mockRepo, mockPdfGen)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
 
 	loanID := uuid.New()
 	investorID := uuid.New()
@@ -116,31 +183,35 @@ func TestCreateInvestment_ROICalculation(t *testing.T) {
 	}
 
 	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(loanInfo, nil)
-	mockRepo.On("CheckExistingInvestment", mock.Anything, loanID, investorID).Return(false, nil)
 	mockRepo.On("GetInvestorName", mock.Anything, investorID).Return("Test Investor", nil)
 
-	mockRepo.On("CreateInvestment", mock.Anything, mock.MatchedBy(func(investment *models.Investment) bool {
-		expectedReturn := float64(1000000) * (float64(12) / 100) // 1M * 12% = 120K
-		return investment.ExpectedReturn == expectedReturn
-	})).Return(nil)
-
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT"}
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "INVESTMENT_AGREEMENT").Return(0, nil)
+	mockTemplateRepo.On("GetLatestTemplate", mock.Anything, "INVESTMENT_AGREEMENT").Return(testInvestmentTemplate, nil)
 	mockPdfGen.On("GenerateInvestmentAgreement",
 		mock.AnythingOfType("*models.Investment"),
 		mock.AnythingOfType("*models.LoanInvestmentInfo"),
-		"Test Investor").Return("/uploads/agreements/investment_agreement.pdf", nil)
+		"Test Investor", testInvestmentTemplate).Return(agreement, nil)
 
-	mockRepo.On("UpdateLoanState", mock.Anything, loanID, "FUNDING").Return(nil)
+	saved := expectCreateInvestment(mockRepo, 1, &models.InvestmentPlacement{
+		Loan:     models.LoanInvestmentInfo{ID: loanID, PrincipalAmount: 5000000, ROIRate: 12, CurrentState: "FUNDING", TotalInvested: 1000000},
+		Position: models.InvestorPosition{LoanID: loanID, InvestorID: investorID, InvestedAmount: 1000000, ExpectedReturn: 120000, Tranches: 1},
+	})
 
 	result, err := investmentUsecase.CreateInvestment(context.Background(), loanID.String(), investorID.String(), req)
 
 	assert.NoError(t, err)
+	expectedReturn := float64(1000000) * (float64(12) / 100) // 1M * 12% = 120K
+	assert.Equal(t, expectedReturn, saved.investment.ExpectedReturn)
 	assert.Equal(t, float64(120000), result.ExpectedReturn)
 }
 
 func TestCreateInvestment_PreventOverInvestment(t *testing.T) {
 	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	investmentUsecase := NewInvestmentUsecase(mockRepo, mockPdfGen)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
 
 	loanID := uuid.New()
 	investorID := uuid.New()
@@ -158,7 +229,6 @@ func TestCreateInvestment_PreventOverInvestment(t *testing.T) {
 	}
 
 	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(loanInfo, nil)
-	mockRepo.On("CheckExistingInvestment", mock.Anything, loanID, investorID).Return(false, nil)
 
 	result, err := investmentUsecase.CreateInvestment(context.Background(), loanID.String(), investorID.String(), req)
 
@@ -167,10 +237,69 @@ func TestCreateInvestment_PreventOverInvestment(t *testing.T) {
 	assert.Contains(t, err.Error(), "investment amount exceeds remaining loan amount")
 }
 
-func TestCreateInvestment_PreventDuplicateInvestment(t *testing.T) {
+// Top-up by an investor already in the loan
+func TestCreateInvestment_TopUpAddsTranche(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
+
+	loanID := uuid.New()
+	investorID := uuid.New()
+
+	req := &models.CreateInvestmentRequest{
+		InvestmentAmount: 4000000, // Tops up the rest of the loan
+	}
+
+	loanInfo := &models.LoanInvestmentInfo{
+		ID:              loanID,
+		PrincipalAmount: 5000000,
+		ROIRate:         8,
+		CurrentState:    "FUNDING",
+		TotalInvested:   1000000, // The investor's first tranche
+	}
+
+	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(loanInfo, nil)
+	mockRepo.On("GetInvestorName", mock.Anything, investorID).Return("Test Investor", nil)
+
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT"}
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "INVESTMENT_AGREEMENT").Return(2, nil)
+	mockTemplateRepo.On("GetTemplate", mock.Anything, "INVESTMENT_AGREEMENT", 2).Return(testInvestmentTemplate, nil)
+	mockPdfGen.On("GenerateInvestmentAgreement",
+		mock.MatchedBy(func(investment *models.Investment) bool { return investment.Tranche == 2 }),
+		mock.AnythingOfType("*models.LoanInvestmentInfo"),
+		"Test Investor", testInvestmentTemplate).Return(agreement, nil)
+
+	saved := expectCreateInvestment(mockRepo, 2, &models.InvestmentPlacement{
+		Loan:     models.LoanInvestmentInfo{ID: loanID, PrincipalAmount: 5000000, ROIRate: 8, CurrentState: "INVESTED", TotalInvested: 5000000},
+		Position: models.InvestorPosition{LoanID: loanID, InvestorID: investorID, InvestedAmount: 5000000, ExpectedReturn: 400000, Tranches: 2},
+	})
+
+	result, err := investmentUsecase.CreateInvestment(context.Background(), loanID.String(), investorID.String(), req)
+
+	assert.NoError(t, err)
+	assert.NoError(t, saved.err)
+	assert.Equal(t, []string{"InvestmentCreated", "LoanFullyFunded"}, saved.eventTypes())
+	var created models.InvestmentCreatedEvent
+	assert.NoError(t, json.Unmarshal(saved.events[0].Payload, &created))
+	assert.Equal(t, 2, created.Tranche)
+	assert.Equal(t, 2, result.Tranche)
+	assert.Equal(t, float64(4000000), result.InvestmentAmount)
+	assert.Equal(t, float64(320000), result.ExpectedReturn)
+	assert.Equal(t, float64(5000000), result.InvestorTotalAmount)
+	assert.Equal(t, float64(400000), result.InvestorExpectedReturn)
+	assert.Equal(t, "INVESTED", result.LoanCurrentState)
+}
+
+// Another investment landed since the loan was read, the locked placement
+// decides the loan is funded
+func TestCreateInvestment_PlacementFundsLoan(t *testing.T) {
 	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	investmentUsecase := NewInvestmentUsecase(mockRepo, mockPdfGen)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
 
 	loanID := uuid.New()
 	investorID := uuid.New()
@@ -183,24 +312,79 @@ func TestCreateInvestment_PreventDuplicateInvestment(t *testing.T) {
 		ID:              loanID,
 		PrincipalAmount: 5000000,
 		ROIRate:         8,
-		CurrentState:    "APPROVED",
-		TotalInvested:   0,
+		CurrentState:    "FUNDING",
+		TotalInvested:   0, // Stale, 3M was invested meanwhile
 	}
 
 	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(loanInfo, nil)
-	mockRepo.On("CheckExistingInvestment", mock.Anything, loanID, investorID).Return(true, nil)
+	mockRepo.On("GetInvestorName", mock.Anything, investorID).Return("Test Investor", nil)
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "INVESTMENT_AGREEMENT").Return(2, nil)
+	mockTemplateRepo.On("GetTemplate", mock.Anything, "INVESTMENT_AGREEMENT", 2).Return(testInvestmentTemplate, nil)
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT"}
+	mockPdfGen.On("GenerateInvestmentAgreement", mock.Anything, mock.Anything, "Test Investor", testInvestmentTemplate).Return(agreement, nil)
+
+	saved := expectCreateInvestment(mockRepo, 1, &models.InvestmentPlacement{
+		Loan:     models.LoanInvestmentInfo{ID: loanID, PrincipalAmount: 5000000, ROIRate: 8, CurrentState: "INVESTED", TotalInvested: 5000000},
+		Position: models.InvestorPosition{LoanID: loanID, InvestorID: investorID, InvestedAmount: 2000000, ExpectedReturn: 160000, Tranches: 1},
+	})
+
+	result, err := investmentUsecase.CreateInvestment(context.Background(), loanID.String(), investorID.String(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"InvestmentCreated", "LoanFullyFunded"}, saved.eventTypes())
+	assert.Equal(t, "INVESTED", result.LoanCurrentState)
+	assert.Equal(t, float64(0), result.RemainingAmount)
+}
+
+// The agreement is stored before the loan is locked, it is deleted again when
+// the investment cannot be saved
+func TestCreateInvestment_FailedSaveDiscardsAgreement(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
+
+	loanID := uuid.New()
+	investorID := uuid.New()
+
+	req := &models.CreateInvestmentRequest{
+		InvestmentAmount: 2000000,
+	}
+
+	loanInfo := &models.LoanInvestmentInfo{
+		ID:              loanID,
+		PrincipalAmount: 5000000,
+		ROIRate:         8,
+		CurrentState:    "FUNDING",
+		TotalInvested:   1000000,
+	}
+
+	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(loanInfo, nil)
+	mockRepo.On("GetInvestorName", mock.Anything, investorID).Return("Test Investor", nil)
+	mockRepo.On("GetNextTranche", mock.Anything, loanID, investorID).Return(2, nil)
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "INVESTMENT_AGREEMENT").Return(2, nil)
+	mockTemplateRepo.On("GetTemplate", mock.Anything, "INVESTMENT_AGREEMENT", 2).Return(testInvestmentTemplate, nil)
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT", StorageKey: "documents/agreement.pdf"}
+	mockPdfGen.On("GenerateInvestmentAgreement", mock.Anything, mock.Anything, "Test Investor", testInvestmentTemplate).Return(agreement, nil)
+	// The investor's other request took tranche 2 first
+	mockRepo.On("CreateInvestment", mock.Anything, mock.AnythingOfType("*models.Investment"), agreement, mock.Anything).
+		Return(nil, errors.New("investment tranche already taken"))
+	mockPdfGen.On("Discard", mock.Anything, agreement).Return()
 
 	result, err := investmentUsecase.CreateInvestment(context.Background(), loanID.String(), investorID.String(), req)
 
-	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "investor has already invested in this loan")
+	assert.EqualError(t, err, "investment tranche already taken")
+	mockPdfGen.AssertCalled(t, "Discard", mock.Anything, agreement)
 }
 
 func TestCreateInvestment_RequiresApprovedOrFundingState(t *testing.T) {
 	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	investmentUsecase := NewInvestmentUsecase(mockRepo, mockPdfGen)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
 
 	loanID := uuid.New()
 	investorID := uuid.New()
@@ -228,8 +412,10 @@ func TestCreateInvestment_RequiresApprovedOrFundingState(t *testing.T) {
 
 func TestCreateInvestment_InvalidUUIDs(t *testing.T) {
 	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	investmentUsecase := NewInvestmentUsecase(mockRepo, mockPdfGen)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockNotifier, InvestmentConfig{})
 
 	req := &models.CreateInvestmentRequest{
 		InvestmentAmount: 2000000,
@@ -246,3 +432,264 @@ func TestCreateInvestment_InvalidUUIDs(t *testing.T) {
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "invalid investor ID")
 }
+
+func TestAcceptAgreement_RecordsEvidence(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{AcceptanceWindow: 24 * time.Hour}).(*investmentUsecase)
+	now := time.Date(2025, 7, 3, 10, 0, 0, 0, time.UTC)
+	investmentUsecase.now = func() time.Time { return now }
+
+	deadline := now.Add(2 * time.Hour)
+	investment := &models.Investment{ID: uuid.New(), LoanID: uuid.New(), InvestorID: uuid.New(), Status: "PENDING_ACCEPTANCE", AcceptanceDeadline: &deadline}
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT", SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"}
+
+	mockRepo.On("GetInvestment", mock.Anything, investment.ID).Return(investment, nil)
+	mockRepo.On("GetInvestmentAgreement", mock.Anything, investment.ID).Return(agreement, nil)
+	mockRepo.On("AcceptAgreement", mock.Anything, &models.AgreementAcceptance{
+		InvestmentID:    investment.ID,
+		DocumentID:      agreement.ID,
+		AgreementSHA256: agreement.SHA256,
+		AcceptedAt:      now,
+		IPAddress:       "203.0.113.7",
+		UserAgent:       "Mozilla/5.0",
+	}).Return(nil)
+
+	req := &models.AcceptAgreementRequest{AgreementSHA256: "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"}
+	result, err := investmentUsecase.AcceptAgreement(context.Background(), investment.ID.String(), investment.InvestorID.String(), req,
+		ClientInfo{IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ACCEPTED", result.Status)
+	assert.Equal(t, investment.LoanID, result.LoanID)
+	assert.Equal(t, agreement.SHA256, result.AgreementSHA256)
+	assert.Equal(t, "/api/v1/files/"+agreement.ID.String(), result.AgreementURL)
+	assert.Equal(t, now, result.AcceptedAt)
+}
+
+func TestAcceptAgreement_OtherInvestorsInvestmentNotFound(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{AcceptanceWindow: 24 * time.Hour})
+
+	deadline := time.Now().Add(2 * time.Hour)
+	investment := &models.Investment{ID: uuid.New(), LoanID: uuid.New(), InvestorID: uuid.New(), Status: "PENDING_ACCEPTANCE", AcceptanceDeadline: &deadline}
+
+	mockRepo.On("GetInvestment", mock.Anything, investment.ID).Return(investment, nil)
+
+	result, err := investmentUsecase.AcceptAgreement(context.Background(), investment.ID.String(), uuid.New().String(), &models.AcceptAgreementRequest{}, ClientInfo{})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "investment not found")
+}
+
+func TestAcceptAgreement_RejectsClosedInvestments(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		deadline time.Duration
+		wantErr  string
+	}{
+		{"accepted", "ACCEPTED", time.Hour, "agreement already accepted"},
+		{"cancelled", "CANCELLED", time.Hour, "investment has been cancelled"},
+		{"deadline passed", "PENDING_ACCEPTANCE", 0, "acceptance deadline has passed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocksRepo.NewInvestmentRepository(t)
+			investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{AcceptanceWindow: 24 * time.Hour}).(*investmentUsecase)
+			now := time.Date(2025, 7, 3, 10, 0, 0, 0, time.UTC)
+			investmentUsecase.now = func() time.Time { return now }
+
+			deadline := now.Add(tt.deadline)
+			investment := &models.Investment{ID: uuid.New(), LoanID: uuid.New(), InvestorID: uuid.New(), Status: tt.status, AcceptanceDeadline: &deadline}
+
+			mockRepo.On("GetInvestment", mock.Anything, investment.ID).Return(investment, nil)
+
+			result, err := investmentUsecase.AcceptAgreement(context.Background(), investment.ID.String(), investment.InvestorID.String(), &models.AcceptAgreementRequest{}, ClientInfo{})
+
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAcceptAgreement_RejectsOutdatedCopy(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{AcceptanceWindow: 24 * time.Hour})
+
+	deadline := time.Now().Add(2 * time.Hour)
+	investment := &models.Investment{ID: uuid.New(), LoanID: uuid.New(), InvestorID: uuid.New(), Status: "PENDING_ACCEPTANCE", AcceptanceDeadline: &deadline}
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT", SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"}
+
+	mockRepo.On("GetInvestment", mock.Anything, investment.ID).Return(investment, nil)
+	mockRepo.On("GetInvestmentAgreement", mock.Anything, investment.ID).Return(agreement, nil)
+
+	req := &models.AcceptAgreementRequest{AgreementSHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}
+	result, err := investmentUsecase.AcceptAgreement(context.Background(), investment.ID.String(), investment.InvestorID.String(), req, ClientInfo{})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "agreement has changed")
+	mockRepo.AssertNotCalled(t, "AcceptAgreement", mock.Anything, mock.Anything)
+}
+
+func TestCancelExpiredInvestments_UsesCurrentTime(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{AcceptanceWindow: 24 * time.Hour}).(*investmentUsecase)
+	now := time.Date(2025, 7, 3, 10, 0, 0, 0, time.UTC)
+	investmentUsecase.now = func() time.Time { return now }
+
+	investment := &models.Investment{ID: uuid.New(), LoanID: uuid.New(), InvestorID: uuid.New(), Status: "CANCELLED"}
+
+	var event *models.OutboxEvent
+	mockRepo.On("CancelExpiredInvestments", mock.Anything, now, mock.AnythingOfType("repositories.CancelledEventFunc")).
+		Run(func(args mock.Arguments) {
+			newEvent := args.Get(2).(repositories.CancelledEventFunc)
+			var err error
+			event, err = newEvent(investment, "FUNDING")
+			assert.NoError(t, err)
+		}).
+		Return([]models.Investment{*investment}, nil)
+
+	cancelled, err := investmentUsecase.CancelExpiredInvestments(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, cancelled, 1)
+
+	// Saved with the cancellation, carrying the state the loan is left in
+	assert.Equal(t, "InvestmentCancelled", event.EventType)
+	assert.Equal(t, investment.LoanID, event.AggregateID)
+	assert.Equal(t, now, event.OccurredAt)
+	var payload models.InvestmentCancelledEvent
+	assert.NoError(t, json.Unmarshal(event.Payload, &payload))
+	assert.Equal(t, investment.ID, payload.InvestmentID)
+	assert.Equal(t, "FUNDING", payload.LoanState)
+	assert.Equal(t, "agreement not accepted before the deadline", payload.Reason)
+}
+
+func TestGetPortfolio_AddsUpLoans(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{})
+
+	investorID := uuid.New()
+	mockRepo.On("GetInvestorPortfolio", mock.Anything, investorID).Return([]models.PortfolioLoan{
+		{LoanID: uuid.New(), InvestedAmount: 5000000, ExpectedReturn: 400000, Tranches: 2},
+		{LoanID: uuid.New(), InvestedAmount: 1000000, ExpectedReturn: 120000, Tranches: 1},
+	}, nil)
+
+	portfolio, err := investmentUsecase.GetPortfolio(context.Background(), investorID.String(), investorID.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, investorID, portfolio.InvestorID)
+	assert.Equal(t, 2, portfolio.LoanCount)
+	assert.Equal(t, float64(6000000), portfolio.TotalInvested)
+	assert.Equal(t, float64(520000), portfolio.TotalExpectedReturn)
+}
+
+func TestGetPortfolio_OnlyOwnPortfolio(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{})
+
+	portfolio, err := investmentUsecase.GetPortfolio(context.Background(), uuid.New().String(), uuid.New().String())
+
+	assert.Nil(t, portfolio)
+	assert.EqualError(t, err, "permission denied: portfolio of another investor")
+	mockRepo.AssertNotCalled(t, "GetInvestorPortfolio", mock.Anything, mock.Anything)
+}
+
+func TestNotifyEvent_InvestmentCreatedSendsAgreement(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mockNotifier, InvestmentConfig{})
+
+	loanID := uuid.New()
+	investorID := uuid.New()
+	deadline := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	investment := &models.Investment{ID: uuid.New(), LoanID: loanID, InvestorID: investorID, Tranche: 2, InvestmentAmount: 2000000, ExpectedReturn: 160000, Status: "PENDING_ACCEPTANCE", AcceptanceDeadline: &deadline}
+	agreement := &models.Document{ID: uuid.New(), DocumentType: "INVESTMENT_AGREEMENT"}
+	event, err := newLoanEvent("InvestmentCreated", loanID, deadline, models.InvestmentCreatedEvent{InvestmentID: investment.ID, LoanID: loanID, InvestorID: investorID, Tranche: 2})
+	assert.NoError(t, err)
+
+	mockRepo.On("GetInvestment", mock.Anything, investment.ID).Return(investment, nil)
+	mockRepo.On("GetLoanForInvestment", mock.Anything, loanID).Return(&models.LoanInvestmentInfo{ID: loanID, ROIRate: 8}, nil)
+	mockRepo.On("GetInvestmentAgreement", mock.Anything, investment.ID).Return(agreement, nil)
+	// A notification that cannot be delivered does not hold back the event
+	mockNotifier.On("Notify", mock.Anything, mock.MatchedBy(func(n *notification.Notification) bool {
+		data := n.Data.(notification.InvestmentAgreementData)
+		return n.Type == "INVESTMENT_AGREEMENT" && n.UserID == investorID && n.UserType == "investor" &&
+			data.Tranche == 2 && data.ROIRate == 8 && data.InvestmentAmount == 2000000 &&
+			len(n.Documents) == 1 && n.Documents[0] == agreement
+	})).Return(errors.New("smtp unavailable")).Once()
+
+	assert.NoError(t, investmentUsecase.NotifyEvent(context.Background(), event))
+}
+
+func TestNotifyEvent_SkipsClosedInvestment(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{})
+
+	investment := &models.Investment{ID: uuid.New(), LoanID: uuid.New(), Status: "ACCEPTED"}
+	event, err := newLoanEvent("InvestmentCreated", investment.LoanID, time.Now(), models.InvestmentCreatedEvent{InvestmentID: investment.ID})
+	assert.NoError(t, err)
+
+	mockRepo.On("GetInvestment", mock.Anything, investment.ID).Return(investment, nil)
+
+	assert.NoError(t, investmentUsecase.NotifyEvent(context.Background(), event))
+}
+
+func TestNotifyEvent_LoanFullyFundedNotifiesEveryInvestor(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	mockNotifier := mocksNotification.NewNotifier(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mockNotifier, InvestmentConfig{})
+
+	loanID := uuid.New()
+	firstInvestorID := uuid.New()
+	toppedUpInvestorID := uuid.New()
+	event, err := newLoanEvent("LoanFullyFunded", loanID, time.Now(), models.LoanFullyFundedEvent{LoanID: loanID, PrincipalAmount: 5000000, TotalInvested: 5000000})
+	assert.NoError(t, err)
+
+	// Each investor is told once, with their tranches added up
+	mockRepo.On("ListLoanInvestments", mock.Anything, loanID).Return([]models.Investment{
+		{LoanID: loanID, InvestorID: firstInvestorID, Tranche: 1, InvestmentAmount: 1000000, ExpectedReturn: 80000},
+		{LoanID: loanID, InvestorID: toppedUpInvestorID, Tranche: 1, InvestmentAmount: 1000000, ExpectedReturn: 80000},
+		{LoanID: loanID, InvestorID: toppedUpInvestorID, Tranche: 2, InvestmentAmount: 3000000, ExpectedReturn: 240000},
+	}, nil)
+	var sent []*notification.Notification
+	mockNotifier.On("Notify", mock.Anything, mock.AnythingOfType("*notification.Notification")).
+		Run(func(args mock.Arguments) { sent = append(sent, args.Get(1).(*notification.Notification)) }).
+		Return(nil)
+
+	assert.NoError(t, investmentUsecase.NotifyEvent(context.Background(), event))
+
+	assert.Len(t, sent, 2)
+	for i, investor := range []uuid.UUID{firstInvestorID, toppedUpInvestorID} {
+		assert.Equal(t, "LOAN_FULLY_INVESTED", sent[i].Type)
+		assert.Equal(t, investor, sent[i].UserID)
+		data := sent[i].Data.(notification.LoanFullyInvestedData)
+		assert.Equal(t, 2, data.InvestorCount)
+		assert.Equal(t, float64(5000000), data.PrincipalAmount)
+	}
+	assert.Equal(t, float64(4000000), sent[1].Data.(notification.LoanFullyInvestedData).InvestmentAmount)
+	assert.Equal(t, float64(320000), sent[1].Data.(notification.LoanFullyInvestedData).ExpectedReturn)
+}
+
+func TestNotifyEvent_RetriedWhenInvestorsCannotBeListed(t *testing.T) {
+	mockRepo := mocksRepo.NewInvestmentRepository(t)
+	investmentUsecase := NewInvestmentUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{})
+
+	loanID := uuid.New()
+	event, err := newLoanEvent("LoanFullyFunded", loanID, time.Now(), models.LoanFullyFundedEvent{LoanID: loanID})
+	assert.NoError(t, err)
+
+	mockRepo.On("ListLoanInvestments", mock.Anything, loanID).Return(nil, errors.New("failed to list investments: connection reset"))
+
+	assert.EqualError(t, investmentUsecase.NotifyEvent(context.Background(), event), "failed to list investments: connection reset")
+}
+
+func TestNotifyEvent_IgnoresOtherEvents(t *testing.T) {
+	investmentUsecase := NewInvestmentUsecase(mocksRepo.NewInvestmentRepository(t), mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksNotification.NewNotifier(t), InvestmentConfig{})
+
+	event, err := newLoanEvent("LoanDisbursed", uuid.New(), time.Now(), models.LoanDisbursedEvent{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, investmentUsecase.NotifyEvent(context.Background(), event))
+}
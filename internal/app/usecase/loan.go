@@ -2,31 +2,61 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/authz"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/payout"
 	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdf"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
 type LoanUsecase interface {
 	CreateLoanProposal(ctx context.Context, req *models.CreateLoanRequest, borrowerID string) (*models.LoanResponse, error)
+	// GetLoan returns the loan's terms and state, for services holding
+	// loan:read.
+	GetLoan(ctx context.Context, loanID string) (*models.LoanResponse, error)
 	ApproveLoan(ctx context.Context, loanID string, approvingEmployeeID string, req *models.ApproveLoanRequest) (*models.ApproveLoanResponse, error)
-	DisburseLoan(ctx context.Context, loanID string, fieldOfficerID string, req *models.DisburseLoanRequest, signedAgreementURL string) (*models.DisburseLoanResponse, error)
+	// DisburseLoan records the signed agreement photographed by the officer, or
+	// uses the borrower's electronic signature when signedAgreement is nil, and
+	// starts the payout to the borrower once their bank account is verified.
+	// The loan stays DISBURSING until the provider reports the transfer
+	// through CompletePayout.
+	DisburseLoan(ctx context.Context, loanID string, fieldOfficerID string, req *models.DisburseLoanRequest, signedAgreement *models.Document) (*models.DisburseLoanResponse, error)
+	// CompletePayout settles a payout from the provider's callback: a
+	// successful transfer disburses the loan, a failed one returns it to
+	// INVESTED to be disbursed again.
+	CompletePayout(ctx context.Context, transfer payout.Transfer) error
+	ListPayouts(ctx context.Context, loanID string, employeeID string) ([]models.Payout, error)
+	AssignValidator(ctx context.Context, loanID string, assignerID string, req *models.AssignValidatorRequest) (*models.AssignValidatorResponse, error)
+	ListPendingApprovals(ctx context.Context, employeeID string) ([]models.PendingApproval, error)
 }
 
 type loanUsecase struct {
-	loanRepo     repositories.LoanRepository
-	pdfGenerator pdf.PDFGenerator
+	loanRepo      repositories.LoanRepository
+	templateRepo  repositories.AgreementTemplateRepository
+	pdfGenerator  pdf.PDFGenerator
+	guard         authz.Guard
+	approvalTiers ApprovalTiers
+	payouts       payout.Provider
+	bankCheck     BankVerificationUsecase
 }
 
-func NewLoanUsecase(loanRepo repositories.LoanRepository, pdfGenerator pdf.PDFGenerator) LoanUsecase {
+func NewLoanUsecase(loanRepo repositories.LoanRepository, templateRepo repositories.AgreementTemplateRepository, pdfGenerator pdf.PDFGenerator, guard authz.Guard, approvalTiers ApprovalTiers, payouts payout.Provider, bankCheck BankVerificationUsecase) LoanUsecase {
 	return &loanUsecase{
-		loanRepo:     loanRepo,
-		pdfGenerator: pdfGenerator,
+		loanRepo:      loanRepo,
+		templateRepo:  templateRepo,
+		pdfGenerator:  pdfGenerator,
+		guard:         guard,
+		approvalTiers: approvalTiers,
+		payouts:       payouts,
+		bankCheck:     bankCheck,
 	}
 }
 
@@ -51,7 +81,19 @@ func (u *loanUsecase) CreateLoanProposal(ctx context.Context, req *models.Create
 		UpdatedAt:       now,
 	}
 
-	if err := u.loanRepo.CreateLoan(ctx, loan); err != nil {
+	event, err := newLoanEvent(constants.EVENT_LOAN_PROPOSED, loanID, now, models.LoanProposedEvent{
+		LoanID:          loanID,
+		BorrowerID:      borrowerUUID,
+		PrincipalAmount: loan.PrincipalAmount,
+		InterestRate:    loan.InterestRate,
+		ROIRate:         loan.ROIRate,
+		LoanTermMonth:   loan.LoanTermMonth,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.loanRepo.CreateLoan(ctx, loan, event); err != nil {
 		return nil, fmt.Errorf("failed to create loan: %w", err)
 	}
 
@@ -69,6 +111,29 @@ func (u *loanUsecase) CreateLoanProposal(ctx context.Context, req *models.Create
 	return response, nil
 }
 
+func (u *loanUsecase) GetLoan(ctx context.Context, loanID string) (*models.LoanResponse, error) {
+	loanUUID, err := uuid.Parse(loanID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loan ID")
+	}
+
+	loan, err := u.loanRepo.GetLoan(ctx, loanUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoanResponse{
+		ID:              loan.ID,
+		BorrowerID:      loan.BorrowerID,
+		PrincipalAmount: loan.PrincipalAmount,
+		InterestRate:    loan.InterestRate,
+		ROIRate:         loan.ROIRate,
+		LoanTermMonth:   loan.LoanTermMonth,
+		CurrentState:    loan.CurrentState,
+		CreatedAt:       loan.CreatedAt,
+	}, nil
+}
+
 func (u *loanUsecase) ApproveLoan(ctx context.Context, loanID string, approvingEmployeeID string, req *models.ApproveLoanRequest) (*models.ApproveLoanResponse, error) {
 	loanUUID, err := uuid.Parse(loanID)
 	if err != nil {
@@ -80,6 +145,10 @@ func (u *loanUsecase) ApproveLoan(ctx context.Context, loanID string, approvingE
 		return nil, fmt.Errorf("invalid employee ID")
 	}
 
+	if err := u.guard.CheckLoanAccess(ctx, employeeUUID, loanUUID, constants.PERM_LOAN_APPROVE); err != nil {
+		return nil, err
+	}
+
 	loan, err := u.loanRepo.GetLoanForApproval(ctx, loanUUID)
 	if err != nil {
 		return nil, err // Repository already handles "loan not found" and "survey not completed"
@@ -89,13 +158,87 @@ func (u *loanUsecase) ApproveLoan(ctx context.Context, loanID string, approvingE
 		return nil, fmt.Errorf("loan must be in proposed state")
 	}
 
-	// Generate loan agreement PDF
-	agreementURL, err := u.pdfGenerator.GenerateLoanAgreement(loan)
+	// Four-eyes: whoever surveyed the loan cannot sign it off
+	if loan.FieldValidatorEmployeeID == employeeUUID {
+		return nil, fmt.Errorf("approver cannot be the field validator")
+	}
+
+	// Photos that did not match the address or survey date need a conscious sign-off
+	surveyFlags, err := u.loanRepo.GetSurveyFlags(ctx, loanUUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(surveyFlags) > 0 && !req.AcknowledgeSurveyFlags {
+		return nil, fmt.Errorf("survey flags must be acknowledged")
+	}
+
+	approvals, err := u.loanRepo.GetLoanApprovals(ctx, loanUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	received := len(approvals)
+	required := loan.RequiredApprovals
+	if received < required {
+		tier := u.approvalTiers.For(loan.PrincipalAmount)
+		approval := &models.LoanApproval{
+			ID:            uuid.New(),
+			LoanID:        loanUUID,
+			EmployeeID:    employeeUUID,
+			ApprovalNotes: req.ApprovalNotes,
+			CreatedAt:     time.Now(),
+
+			SurveyFlagsAcknowledged: len(surveyFlags) > 0,
+		}
+
+		received, required, err = u.loanRepo.AddLoanApproval(ctx, approval, tier.RequiredApprovals, tier.Roles)
+		if err != nil {
+			return nil, err
+		}
+	} else if !hasApproved(approvals, employeeUUID) {
+		// All approvals are in but finalising failed earlier, only an approver may retry it
+		return nil, fmt.Errorf("loan already has required approvals")
+	}
+
+	if received < required {
+		return &models.ApproveLoanResponse{
+			ID:                       loan.ID,
+			BorrowerID:               loan.BorrowerID,
+			PrincipalAmount:          loan.PrincipalAmount,
+			InterestRate:             loan.InterestRate,
+			ROIRate:                  loan.ROIRate,
+			LoanTermMonth:            loan.LoanTermMonth,
+			CurrentState:             constants.PROPOSED,
+			FieldValidatorEmployeeID: loan.FieldValidatorEmployeeID,
+			SurveyDate:               loan.SurveyDate.Format("2006-01-02"),
+			UpdatedAt:                time.Now(),
+			RequiredApprovals:        required,
+			ReceivedApprovals:        received,
+			SurveyFlags:              surveyFlags,
+		}, nil
+	}
+
+	// Generate loan agreement PDF once the final approval lands
+	agreementTemplate, err := agreementTemplateFor(ctx, u.templateRepo, loanUUID, constants.DOCUMENT_LOAN_AGREEMENT)
+	if err != nil {
+		return nil, err
+	}
+
+	agreement, err := u.pdfGenerator.GenerateLoanAgreement(loan, agreementTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate agreement: %w", err)
 	}
 
-	err = u.loanRepo.ApproveLoan(ctx, loanUUID, employeeUUID, req.ApprovalNotes, agreementURL)
+	event, err := newLoanEvent(constants.EVENT_LOAN_APPROVED, loanUUID, time.Now(), models.LoanApprovedEvent{
+		LoanID:              loanUUID,
+		ApprovingEmployeeID: employeeUUID,
+		AgreementID:         agreement.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.loanRepo.ApproveLoan(ctx, loanUUID, employeeUUID, req.ApprovalNotes, agreement, event)
 	if err != nil {
 		return nil, err
 	}
@@ -104,11 +247,19 @@ func (u *loanUsecase) ApproveLoan(ctx context.Context, loanID string, approvingE
 	if err != nil {
 		return nil, fmt.Errorf("failed to get approved loan data: %w", err)
 	}
+	response.LoanAgreementPDFURL = documentURL(agreement.ID)
+
+	response.RequiredApprovals = required
+	response.ReceivedApprovals = received
+	response.SurveyFlags = surveyFlags
+	if approvals, err := u.loanRepo.GetLoanApprovals(ctx, loanUUID); err == nil {
+		response.Approvals = approvals
+	}
 
 	return response, nil
 }
 
-func (u *loanUsecase) DisburseLoan(ctx context.Context, loanID string, fieldOfficerID string, req *models.DisburseLoanRequest, signedAgreementURL string) (*models.DisburseLoanResponse, error) {
+func (u *loanUsecase) DisburseLoan(ctx context.Context, loanID string, fieldOfficerID string, req *models.DisburseLoanRequest, signedAgreement *models.Document) (*models.DisburseLoanResponse, error) {
 	loanUUID, err := uuid.Parse(loanID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid loan ID")
@@ -119,24 +270,349 @@ func (u *loanUsecase) DisburseLoan(ctx context.Context, loanID string, fieldOffi
 		return nil, fmt.Errorf("invalid officer ID")
 	}
 
+	if err := u.guard.CheckLoanAccess(ctx, officerUUID, loanUUID, constants.PERM_LOAN_DISBURSE); err != nil {
+		return nil, err
+	}
+
 	loan, err := u.loanRepo.GetLoanForDisbursement(ctx, loanUUID)
 	if err != nil {
 		return nil, err
 	}
 
+	if loan.CurrentState == constants.DISBURSING {
+		return u.resendPayout(ctx, loanUUID)
+	}
 	if loan.CurrentState != constants.INVESTED {
 		return nil, fmt.Errorf("loan must be in invested state")
 	}
 
-	err = u.loanRepo.DisburseLoan(ctx, loanUUID, officerUUID, signedAgreementURL, req.DisbursementNotes)
+	// Every investor has to have accepted their agreement
+	unaccepted, err := u.loanRepo.CountUnacceptedInvestments(ctx, loanUUID)
 	if err != nil {
 		return nil, err
 	}
+	if unaccepted > 0 {
+		return nil, fmt.Errorf("investor agreements not accepted: %d pending", unaccepted)
+	}
+
+	// Without a photographed paper agreement the borrower must have signed
+	// electronically
+	var signedAgreementID uuid.UUID
+	if signedAgreement != nil {
+		signedAgreement.LoanID = loanUUID
+		signedAgreement.DocumentType = constants.DOCUMENT_SIGNED_AGREEMENT
+		signedAgreement.UploadedByID = &officerUUID
+		signedAgreement.UploadedByType = constants.USER_EMPLOYEE
+		signedAgreementID = signedAgreement.ID
+	} else {
+		signed, err := u.loanRepo.GetSignedAgreement(ctx, loanUUID)
+		if err != nil {
+			return nil, err
+		}
+		signedAgreementID = signed.ID
+	}
+
+	// The holder of the account has to be the borrower, or an employee has
+	// accepted the difference
+	if err := u.bankCheck.CheckDisbursable(ctx, loanUUID, officerUUID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	loanPayout := &models.Payout{
+		ID:            uuid.New(),
+		LoanID:        loanUUID,
+		Provider:      u.payouts.Name(),
+		Status:        constants.PAYOUT_PENDING,
+		RequestedByID: &officerUUID,
+		CreatedAt:     now,
+	}
 
-	response, err := u.loanRepo.GetDisbursedLoan(ctx, loanUUID)
+	err = u.loanRepo.StartDisbursement(ctx, loanUUID, officerUUID, signedAgreement, req.DisbursementNotes, loanPayout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.sendPayout(ctx, loanPayout); err != nil {
+		return nil, err
+	}
+
+	return u.disbursementResponse(ctx, loanUUID, loanPayout.ID, signedAgreementID)
+}
+
+// resendPayout sends the loan's pending payout again when the provider never
+// acknowledged it, after a timeout for example. The reference is the same, so
+// the provider makes the transfer only once.
+func (u *loanUsecase) resendPayout(ctx context.Context, loanID uuid.UUID) (*models.DisburseLoanResponse, error) {
+	payouts, err := u.loanRepo.ListPayouts(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending *models.Payout
+	for i := range payouts {
+		if payouts[i].Status == constants.PAYOUT_PENDING {
+			pending = &payouts[i]
+		}
+	}
+	if pending == nil || pending.ProviderReference != "" {
+		return nil, fmt.Errorf("loan disbursement already in progress")
+	}
+
+	signed, err := u.loanRepo.GetSignedAgreement(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.sendPayout(ctx, pending); err != nil {
+		return nil, err
+	}
+
+	return u.disbursementResponse(ctx, loanID, pending.ID, signed.ID)
+}
+
+// sendPayout asks the provider for the payout's transfer. Only a transfer the
+// provider refused fails the payout and returns the loan to INVESTED. When the
+// outcome is unknown the payout stays PENDING, the provider may have made the
+// transfer and reports it through the callback.
+func (u *loanUsecase) sendPayout(ctx context.Context, loanPayout *models.Payout) error {
+	// The loan is DISBURSING already, a client going away must not stop the
+	// transfer halfway
+	ctx = context.WithoutCancel(ctx)
+
+	transfer, err := u.payouts.Transfer(ctx, payout.TransferRequest{
+		Reference:         loanPayout.ID.String(),
+		Amount:            loanPayout.Amount,
+		BankName:          loanPayout.BankName,
+		AccountNumber:     loanPayout.BankAccountNumber,
+		AccountHolderName: loanPayout.AccountHolderName,
+		Description:       fmt.Sprintf("Loan %s disbursement", loanPayout.LoanID),
+	})
+	switch {
+	case payout.IsRejected(err):
+		// The agreement is kept, the loan goes back to INVESTED so the
+		// disbursement can be retried
+		log.Error().Err(err).Str("loan_id", loanPayout.LoanID.String()).Str("payout_id", loanPayout.ID.String()).Msg("Payout provider rejected transfer")
+		transfer = &payout.Transfer{
+			Reference:     loanPayout.ID.String(),
+			Status:        payout.STATUS_FAILED,
+			FailureReason: err.Error(),
+			UpdatedAt:     time.Now(),
+		}
+	case err != nil:
+		log.Error().Err(err).Str("loan_id", loanPayout.LoanID.String()).Str("payout_id", loanPayout.ID.String()).Msg("Payout transfer outcome unknown, left pending")
+		return nil
+	case transfer.ProviderReference != "":
+		if err := u.loanRepo.SetPayoutReference(ctx, loanPayout.ID, transfer.ProviderReference); err != nil {
+			log.Error().Err(err).Str("payout_id", loanPayout.ID.String()).Msg("Failed to save payout reference")
+		}
+		loanPayout.ProviderReference = transfer.ProviderReference
+	}
+
+	// Some transfers settle at once, there will be no callback for them
+	if transfer.Status != payout.STATUS_PENDING {
+		return u.CompletePayout(ctx, *transfer)
+	}
+
+	return nil
+}
+
+func (u *loanUsecase) disbursementResponse(ctx context.Context, loanID, payoutID, signedAgreementID uuid.UUID) (*models.DisburseLoanResponse, error) {
+	settled, err := u.loanRepo.GetPayout(ctx, payoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := u.loanRepo.GetDisbursedLoan(ctx, loanID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disbursed loan data: %w", err)
 	}
+	response.SignedAgreementURL = documentURL(signedAgreementID)
+	response.Payout = settled
 
 	return response, nil
 }
+
+func (u *loanUsecase) CompletePayout(ctx context.Context, transfer payout.Transfer) error {
+	payoutID, err := uuid.Parse(transfer.Reference)
+	if err != nil {
+		return fmt.Errorf("invalid payout reference")
+	}
+
+	loanPayout, err := u.loanRepo.GetPayout(ctx, payoutID)
+	if err != nil {
+		return err
+	}
+
+	// Providers may report the same outcome more than once
+	if loanPayout.Status != constants.PAYOUT_PENDING {
+		if loanPayout.Status == transfer.Status {
+			return nil
+		}
+		return fmt.Errorf("payout already settled")
+	}
+
+	settledAt := transfer.UpdatedAt
+	if settledAt.IsZero() {
+		settledAt = time.Now()
+	}
+
+	switch transfer.Status {
+	case payout.STATUS_SUCCESS:
+		event, err := newLoanEvent(constants.EVENT_LOAN_DISBURSED, loanPayout.LoanID, settledAt, models.LoanDisbursedEvent{
+			LoanID:                 loanPayout.LoanID,
+			FieldOfficerEmployeeID: loanPayout.RequestedByID,
+			PayoutID:               loanPayout.ID,
+			Amount:                 loanPayout.Amount,
+			ProviderReference:      transfer.ProviderReference,
+		})
+		if err != nil {
+			return err
+		}
+		return u.loanRepo.CompleteDisbursement(ctx, loanPayout.ID, transfer.ProviderReference, settledAt, event)
+	case payout.STATUS_FAILED:
+		log.Warn().Str("loan_id", loanPayout.LoanID.String()).Str("payout_id", loanPayout.ID.String()).
+			Str("reason", transfer.FailureReason).Msg("Loan payout failed")
+		return u.loanRepo.FailDisbursement(ctx, loanPayout.ID, transfer.ProviderReference, transfer.FailureReason, settledAt)
+	case payout.STATUS_PENDING:
+		return nil
+	default:
+		return fmt.Errorf("unknown payout status: %s", transfer.Status)
+	}
+}
+
+func (u *loanUsecase) ListPayouts(ctx context.Context, loanID string, employeeID string) ([]models.Payout, error) {
+	loanUUID, err := uuid.Parse(loanID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loan ID")
+	}
+
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	if err := u.guard.CheckLoanAccess(ctx, employeeUUID, loanUUID, constants.PERM_LOAN_DISBURSE); err != nil {
+		return nil, err
+	}
+
+	return u.loanRepo.ListPayouts(ctx, loanUUID)
+}
+
+func (u *loanUsecase) AssignValidator(ctx context.Context, loanID string, assignerID string, req *models.AssignValidatorRequest) (*models.AssignValidatorResponse, error) {
+	loanUUID, err := uuid.Parse(loanID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loan ID")
+	}
+
+	assignerUUID, err := uuid.Parse(assignerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	validatorUUID, err := uuid.Parse(req.ValidatorEmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator ID")
+	}
+
+	if err := u.guard.CheckLoanAccess(ctx, assignerUUID, loanUUID, constants.PERM_LOAN_ASSIGN); err != nil {
+		return nil, err
+	}
+
+	if err := u.guard.CheckAssignee(ctx, validatorUUID, loanUUID, constants.PERM_SURVEY_UPLOAD); err != nil {
+		return nil, err
+	}
+
+	if err := u.loanRepo.AssignValidator(ctx, loanUUID, validatorUUID); err != nil {
+		return nil, err
+	}
+
+	return &models.AssignValidatorResponse{
+		LoanID:                      loanUUID,
+		AssignedValidatorEmployeeID: validatorUUID,
+		AssignedByEmployeeID:        assignerUUID,
+	}, nil
+}
+
+// ListPendingApprovals returns the approval queue for the employee: loans in
+// their branch that still need approvals and that they are allowed to approve.
+func (u *loanUsecase) ListPendingApprovals(ctx context.Context, employeeID string) ([]models.PendingApproval, error) {
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	employee, err := u.guard.CheckPermission(ctx, employeeUUID, constants.PERM_LOAN_APPROVE)
+	if err != nil {
+		return nil, err
+	}
+
+	loans, err := u.loanRepo.ListPendingApprovals(ctx, employee.Branch, employee.AllBranches)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := make([]models.PendingApproval, 0, len(loans))
+	for _, loan := range loans {
+		tier := u.approvalTiers.For(loan.PrincipalAmount)
+		if loan.ReceivedApprovals == 0 {
+			loan.RequiredApprovals = tier.RequiredApprovals
+		}
+
+		if loan.ReceivedApprovals >= loan.RequiredApprovals ||
+			loan.FieldValidatorEmployeeID == employeeUUID ||
+			containsID(loan.ApproverEmployeeIDs, employeeUUID) ||
+			!containsRole(tier.Roles, employee.Role) {
+			continue
+		}
+
+		queue = append(queue, loan)
+	}
+
+	return queue, nil
+}
+
+func hasApproved(approvals []models.LoanApproval, employeeID uuid.UUID) bool {
+	for _, approval := range approvals {
+		if approval.EmployeeID == employeeID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, candidate := range roles {
+		if strings.EqualFold(candidate, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// newLoanEvent builds the outbox event saved together with a change to the
+// loan.
+func newLoanEvent(eventType string, loanID uuid.UUID, occurredAt time.Time, payload interface{}) (*models.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return &models.OutboxEvent{
+		EventID:       uuid.New(),
+		AggregateType: constants.AGGREGATE_LOAN,
+		AggregateID:   loanID,
+		EventType:     eventType,
+		Payload:       data,
+		OccurredAt:    occurredAt,
+	}, nil
+}
@@ -2,10 +2,13 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	mocksAuthz "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/authz"
 	mocksPdf "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/pdf"
 	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
 	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/payout"
 	"testing"
 	"time"
 
@@ -14,10 +17,61 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+var testApprovalTiers, _ = NewApprovalTiers([]ApprovalTier{
+	{MaxAmount: 10000000, RequiredApprovals: 1, Roles: []string{"FIELD_OFFICER"}},
+	{MaxAmount: 0, RequiredApprovals: 2, Roles: []string{"FIELD_OFFICER"}},
+})
+
+var testLoanTemplate = &models.AgreementTemplate{TemplateType: "LOAN_AGREEMENT", Version: 3, Body: "# LOAN AGREEMENT"}
+
+// fakePayouts accepts transfers with status, or rejects them with err.
+type fakePayouts struct {
+	status   string
+	err      error
+	requests []payout.TransferRequest
+	contexts []context.Context
+}
+
+func (f *fakePayouts) Name() string {
+	return "fake"
+}
+
+func (f *fakePayouts) Transfer(ctx context.Context, req payout.TransferRequest) (*payout.Transfer, error) {
+	f.requests = append(f.requests, req)
+	f.contexts = append(f.contexts, ctx)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &payout.Transfer{Reference: req.Reference, ProviderReference: "FAKE-1", Status: f.status}, nil
+}
+
+// fakeBankCheck answers CheckDisbursable with err.
+type fakeBankCheck struct {
+	BankVerificationUsecase
+	err error
+}
+
+func (f *fakeBankCheck) CheckDisbursable(ctx context.Context, loanID, employeeID uuid.UUID) error {
+	return f.err
+}
+
+// startDisbursement fills in the payout like the repository does from the
+// borrower's bank account.
+func startDisbursement(args mock.Arguments) {
+	p := args.Get(5).(*models.Payout)
+	p.LoanID = args.Get(1).(uuid.UUID)
+	p.Amount = 5000000
+	p.BankName = "BCA"
+	p.BankAccountNumber = "1234567890"
+	p.AccountHolderName = "Budi Santoso"
+}
+
 func TestCreateLoanProposal_InitialStateIsProposed(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	borrowerID := uuid.New()
 	req := &models.CreateLoanRequest{
@@ -31,6 +85,11 @@ func TestCreateLoanProposal_InitialStateIsProposed(t *testing.T) {
 		return loan.CurrentState == "PROPOSED" &&
 			loan.BorrowerID == borrowerID &&
 			loan.PrincipalAmount == 5000000
+	}), mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		var payload models.LoanProposedEvent
+		return event.EventType == "LoanProposed" && event.AggregateType == "loan" &&
+			json.Unmarshal(event.Payload, &payload) == nil &&
+			payload.LoanID == event.AggregateID && payload.BorrowerID == borrowerID
 	})).Return(nil)
 
 	result, err := loanUsecase.CreateLoanProposal(context.Background(), req, borrowerID.String())
@@ -41,10 +100,36 @@ func TestCreateLoanProposal_InitialStateIsProposed(t *testing.T) {
 	assert.Equal(t, float64(5000000), result.PrincipalAmount)
 }
 
+func TestGetLoan_ReturnsTermsAndState(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	loanUsecase := &loanUsecase{loanRepo: mockRepo}
+
+	loanID := uuid.New()
+	mockRepo.On("GetLoan", mock.Anything, loanID).Return(&models.Loan{
+		ID:              loanID,
+		PrincipalAmount: 5000000,
+		ROIRate:         8,
+		LoanTermMonth:   12,
+		CurrentState:    "FUNDING",
+	}, nil)
+
+	result, err := loanUsecase.GetLoan(context.Background(), loanID.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, loanID, result.ID)
+	assert.Equal(t, float64(5000000), result.PrincipalAmount)
+	assert.Equal(t, "FUNDING", result.CurrentState)
+
+	_, err = loanUsecase.GetLoan(context.Background(), "not-a-uuid")
+	assert.EqualError(t, err, "invalid loan ID")
+}
+
 func TestApproveLoan_RequiresSurveyCompletion(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	loanID := uuid.New()
 	employeeID := uuid.New()
@@ -53,6 +138,7 @@ func TestApproveLoan_RequiresSurveyCompletion(t *testing.T) {
 		ApprovalNotes: "Approval attempt",
 	}
 
+	mockGuard.On("CheckLoanAccess", mock.Anything, employeeID, loanID, "loan:approve").Return(nil)
 	mockRepo.On("GetLoanForApproval", mock.Anything, loanID).Return(nil, fmt.Errorf("survey not completed"))
 
 	result, err := loanUsecase.ApproveLoan(context.Background(), loanID.String(), employeeID.String(), req)
@@ -64,8 +150,10 @@ func TestApproveLoan_RequiresSurveyCompletion(t *testing.T) {
 
 func TestApproveLoan_PreventInvalidStateTransition(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	loanID := uuid.New()
 	employeeID := uuid.New()
@@ -80,6 +168,7 @@ func TestApproveLoan_PreventInvalidStateTransition(t *testing.T) {
 		SurveyDate:   time.Now(),
 	}
 
+	mockGuard.On("CheckLoanAccess", mock.Anything, employeeID, loanID, "loan:approve").Return(nil)
 	mockRepo.On("GetLoanForApproval", mock.Anything, loanID).Return(loanForApproval, nil)
 
 	result, err := loanUsecase.ApproveLoan(context.Background(), loanID.String(), employeeID.String(), req)
@@ -91,8 +180,10 @@ func TestApproveLoan_PreventInvalidStateTransition(t *testing.T) {
 
 func TestApproveLoan_InvalidLoanID(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	employeeID := uuid.New()
 
@@ -109,8 +200,10 @@ func TestApproveLoan_InvalidLoanID(t *testing.T) {
 
 func TestApproveLoan_SuccessfulApprovalWithPDFGeneration(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	loanID := uuid.New()
 	employeeID := uuid.New()
@@ -131,9 +224,10 @@ func TestApproveLoan_SuccessfulApprovalWithPDFGeneration(t *testing.T) {
 		CurrentState:             "PROPOSED",
 		FieldValidatorEmployeeID: uuid.New(),
 		SurveyDate:               time.Now(),
+		RequiredApprovals:        1,
 	}
 
-	expectedAgreementURL := "/uploads/agreements/loan_agreement_" + loanID.String() + ".pdf"
+	agreement := &models.Document{ID: uuid.New(), LoanID: loanID, DocumentType: "LOAN_AGREEMENT"}
 
 	approvedLoanResponse := &models.ApproveLoanResponse{
 		ID:                       loanID,
@@ -146,15 +240,26 @@ func TestApproveLoan_SuccessfulApprovalWithPDFGeneration(t *testing.T) {
 		ApprovalDate:             time.Now().Format("2006-01-02"),
 		ApprovingEmployeeID:      employeeID,
 		ApprovalNotes:            req.ApprovalNotes,
-		LoanAgreementPDFURL:      expectedAgreementURL,
+		LoanAgreementPDFURL:      "/uploads/documents/" + loanID.String() + "/" + agreement.ID.String() + ".pdf",
 		FieldValidatorEmployeeID: loanForApproval.FieldValidatorEmployeeID,
 		SurveyDate:               loanForApproval.SurveyDate.Format("2006-01-02"),
 		UpdatedAt:                time.Now(),
 	}
 
+	mockGuard.On("CheckLoanAccess", mock.Anything, employeeID, loanID, "loan:approve").Return(nil)
 	mockRepo.On("GetLoanForApproval", mock.Anything, loanID).Return(loanForApproval, nil)
-	mockPdfGen.On("GenerateLoanAgreement", loanForApproval).Return(expectedAgreementURL, nil)
-	mockRepo.On("ApproveLoan", mock.Anything, loanID, employeeID, req.ApprovalNotes, expectedAgreementURL).Return(nil)
+	mockRepo.On("GetSurveyFlags", mock.Anything, loanID).Return([]models.SurveyFlag{}, nil)
+	mockRepo.On("GetLoanApprovals", mock.Anything, loanID).Return([]models.LoanApproval{}, nil)
+	mockRepo.On("AddLoanApproval", mock.Anything, mock.Anything, 1, []string{"FIELD_OFFICER"}).Return(1, 1, nil)
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "LOAN_AGREEMENT").Return(0, nil)
+	mockTemplateRepo.On("GetLatestTemplate", mock.Anything, "LOAN_AGREEMENT").Return(testLoanTemplate, nil)
+	mockPdfGen.On("GenerateLoanAgreement", loanForApproval, testLoanTemplate).Return(agreement, nil)
+	mockRepo.On("ApproveLoan", mock.Anything, loanID, employeeID, req.ApprovalNotes, agreement, mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		var payload models.LoanApprovedEvent
+		return event.EventType == "LoanApproved" && event.AggregateID == loanID &&
+			json.Unmarshal(event.Payload, &payload) == nil &&
+			payload.ApprovingEmployeeID == employeeID && payload.AgreementID == agreement.ID
+	})).Return(nil)
 	mockRepo.On("GetApprovedLoan", mock.Anything, loanID).Return(approvedLoanResponse, nil)
 	
 	result, err := loanUsecase.ApproveLoan(context.Background(), loanID.String(), employeeID.String(), req)
@@ -162,14 +267,16 @@ func TestApproveLoan_SuccessfulApprovalWithPDFGeneration(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "APPROVED", result.CurrentState)
 	assert.Equal(t, employeeID, result.ApprovingEmployeeID)
-	assert.Equal(t, expectedAgreementURL, result.LoanAgreementPDFURL)
+	assert.Equal(t, "/api/v1/files/"+agreement.ID.String(), result.LoanAgreementPDFURL)
 	assert.Equal(t, req.ApprovalNotes, result.ApprovalNotes)
 }
 
 func TestDisburseLoan_RequiresInvestedState(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	loanID := uuid.New()
 	officerID := uuid.New()
@@ -183,9 +290,10 @@ func TestDisburseLoan_RequiresInvestedState(t *testing.T) {
 		CurrentState: "APPROVED",
 	}
 
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
 	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(loan, nil)
 
-	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), req, "/signed-agreement.pdf")
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), req, &models.Document{ID: uuid.New()})
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -195,12 +303,15 @@ func TestDisburseLoan_RequiresInvestedState(t *testing.T) {
 // Valid Disbursement Flow
 func TestDisburseLoan_SuccessfulStateTransition(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	payouts := &fakePayouts{status: payout.STATUS_PENDING}
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, payouts, &fakeBankCheck{})
 
 	loanID := uuid.New()
 	officerID := uuid.New()
-	signedAgreementURL := "/uploads/agreements/signed_agreement_" + loanID.String() + ".pdf"
+	signedAgreement := &models.Document{ID: uuid.New(), FileName: "signed.pdf", StorageKey: "documents/" + loanID.String() + "/signed.pdf"}
 
 	req := &models.DisburseLoanRequest{
 		DisbursementNotes: "Money disbursed successfully",
@@ -211,31 +322,52 @@ func TestDisburseLoan_SuccessfulStateTransition(t *testing.T) {
 		CurrentState: "INVESTED",
 	}
 
-	disbursedLoan := &models.DisburseLoanResponse{
+	disbursingLoan := &models.DisburseLoanResponse{
 		ID:                     loanID,
-		CurrentState:           "DISBURSED",
+		CurrentState:           "DISBURSING",
 		FieldOfficerEmployeeID: officerID,
-		SignedAgreementURL:     signedAgreementURL,
+		SignedAgreementURL:     "/uploads/" + signedAgreement.StorageKey,
 		DisbursementNotes:      req.DisbursementNotes,
 	}
 
+	var payoutID uuid.UUID
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
 	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(loan, nil)
-	mockRepo.On("DisburseLoan", mock.Anything, loanID, officerID, signedAgreementURL, req.DisbursementNotes).Return(nil)
-	mockRepo.On("GetDisbursedLoan", mock.Anything, loanID).Return(disbursedLoan, nil)
-
-	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), req, signedAgreementURL)
+	mockRepo.On("CountUnacceptedInvestments", mock.Anything, loanID).Return(0, nil)
+	mockRepo.On("StartDisbursement", mock.Anything, loanID, officerID, mock.MatchedBy(func(d *models.Document) bool {
+		return d == signedAgreement && d.LoanID == loanID && d.DocumentType == "SIGNED_AGREEMENT" && *d.UploadedByID == officerID
+	}), req.DisbursementNotes, mock.MatchedBy(func(p *models.Payout) bool {
+		payoutID = p.ID
+		return p.Status == "PENDING" && p.Provider == "fake" && *p.RequestedByID == officerID
+	})).Run(startDisbursement).Return(nil)
+	mockRepo.On("SetPayoutReference", mock.Anything, mock.AnythingOfType("uuid.UUID"), "FAKE-1").Return(nil)
+	mockRepo.On("GetPayout", mock.Anything, mock.AnythingOfType("uuid.UUID")).
+		Return(func(ctx context.Context, id uuid.UUID) (*models.Payout, error) {
+			return &models.Payout{ID: id, LoanID: loanID, Status: "PENDING", ProviderReference: "FAKE-1"}, nil
+		})
+	mockRepo.On("GetDisbursedLoan", mock.Anything, loanID).Return(disbursingLoan, nil)
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), req, signedAgreement)
 
 	assert.NoError(t, err)
-	assert.Equal(t, "DISBURSED", result.CurrentState)
+	assert.Equal(t, "DISBURSING", result.CurrentState)
 	assert.Equal(t, officerID, result.FieldOfficerEmployeeID)
-	assert.Equal(t, signedAgreementURL, result.SignedAgreementURL)
+	assert.Equal(t, "/api/v1/files/"+signedAgreement.ID.String(), result.SignedAgreementURL)
+	assert.Equal(t, "PENDING", result.Payout.Status)
+	assert.Len(t, payouts.requests, 1)
+	assert.Equal(t, payoutID.String(), payouts.requests[0].Reference)
+	assert.Equal(t, float64(5000000), payouts.requests[0].Amount)
+	assert.Equal(t, "1234567890", payouts.requests[0].AccountNumber)
+	mockRepo.AssertNotCalled(t, "CompleteDisbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestDisburseLoan_InvalidEmployeeID(t *testing.T) {
 	// Arrange
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	loanID := uuid.New()
 
@@ -243,17 +375,331 @@ func TestDisburseLoan_InvalidEmployeeID(t *testing.T) {
 		DisbursementNotes: "Money disbursed",
 	}
 
-	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), "invalid-officer-id", req, "/agreement.pdf")
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), "invalid-officer-id", req, &models.Document{ID: uuid.New()})
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "invalid officer ID")
 }
 
+func TestDisburseLoan_UsesElectronicallySignedAgreement(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, &fakePayouts{status: payout.STATUS_PENDING}, &fakeBankCheck{})
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	eSigned := &models.Document{ID: uuid.New(), DocumentType: "SIGNED_AGREEMENT"}
+
+	req := &models.DisburseLoanRequest{
+		DisbursementNotes: "Money disbursed",
+	}
+
+	loan := &models.Loan{
+		ID:           loanID,
+		CurrentState: "INVESTED",
+	}
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(loan, nil)
+	mockRepo.On("CountUnacceptedInvestments", mock.Anything, loanID).Return(0, nil)
+	mockRepo.On("GetSignedAgreement", mock.Anything, loanID).Return(eSigned, nil)
+	mockRepo.On("StartDisbursement", mock.Anything, loanID, officerID, (*models.Document)(nil), req.DisbursementNotes, mock.AnythingOfType("*models.Payout")).
+		Run(startDisbursement).Return(nil)
+	mockRepo.On("SetPayoutReference", mock.Anything, mock.AnythingOfType("uuid.UUID"), "FAKE-1").Return(nil)
+	mockRepo.On("GetPayout", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(&models.Payout{Status: "PENDING"}, nil)
+	mockRepo.On("GetDisbursedLoan", mock.Anything, loanID).Return(&models.DisburseLoanResponse{ID: loanID, CurrentState: "DISBURSING"}, nil)
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), req, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DISBURSING", result.CurrentState)
+	assert.Equal(t, "/api/v1/files/"+eSigned.ID.String(), result.SignedAgreementURL)
+}
+
+func TestDisburseLoan_RequiresSignedAgreement(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+
+	loan := &models.Loan{
+		ID:           loanID,
+		CurrentState: "INVESTED",
+	}
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(loan, nil)
+	mockRepo.On("CountUnacceptedInvestments", mock.Anything, loanID).Return(0, nil)
+	mockRepo.On("GetSignedAgreement", mock.Anything, loanID).Return(nil, fmt.Errorf("signed agreement required"))
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), &models.DisburseLoanRequest{}, nil)
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "signed agreement required")
+	mockRepo.AssertNotCalled(t, "StartDisbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDisburseLoan_BlockedUntilInvestorsAccept(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+
+	req := &models.DisburseLoanRequest{
+		DisbursementNotes: "Money disbursed",
+	}
+
+	loan := &models.Loan{
+		ID:           loanID,
+		CurrentState: "INVESTED",
+	}
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(loan, nil)
+	mockRepo.On("CountUnacceptedInvestments", mock.Anything, loanID).Return(2, nil)
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), req, &models.Document{ID: uuid.New()})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "investor agreements not accepted: 2 pending", err.Error())
+	mockRepo.AssertNotCalled(t, "StartDisbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDisburseLoan_AlreadyInProgress(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mockGuard, testApprovalTiers, &fakePayouts{}, nil)
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(&models.Loan{ID: loanID, CurrentState: "DISBURSING"}, nil)
+	// The provider acknowledged the transfer, its outcome comes with the callback
+	mockRepo.On("ListPayouts", mock.Anything, loanID).Return([]models.Payout{
+		{ID: uuid.New(), LoanID: loanID, Status: "FAILED"},
+		{ID: uuid.New(), LoanID: loanID, Status: "PENDING", ProviderReference: "SIM-01"},
+	}, nil)
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), &models.DisburseLoanRequest{}, nil)
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "loan disbursement already in progress")
+}
+
+func TestDisburseLoan_UnknownTransferOutcomeStaysPending(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	payouts := &fakePayouts{err: context.DeadlineExceeded}
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mockGuard, testApprovalTiers, payouts, &fakeBankCheck{})
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	signedAgreement := &models.Document{ID: uuid.New()}
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(&models.Loan{ID: loanID, CurrentState: "INVESTED"}, nil)
+	mockRepo.On("CountUnacceptedInvestments", mock.Anything, loanID).Return(0, nil)
+	mockRepo.On("StartDisbursement", mock.Anything, loanID, officerID, signedAgreement, "", mock.AnythingOfType("*models.Payout")).
+		Run(startDisbursement).Return(nil)
+	mockRepo.On("GetPayout", mock.Anything, mock.AnythingOfType("uuid.UUID")).
+		Return(func(ctx context.Context, id uuid.UUID) (*models.Payout, error) {
+			return &models.Payout{ID: id, LoanID: loanID, Status: "PENDING"}, nil
+		})
+	mockRepo.On("GetDisbursedLoan", mock.Anything, loanID).Return(&models.DisburseLoanResponse{ID: loanID, CurrentState: "DISBURSING"}, nil)
+
+	// The request goes away while the provider is asked
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := loanUsecase.DisburseLoan(ctx, loanID.String(), officerID.String(), &models.DisburseLoanRequest{}, signedAgreement)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DISBURSING", result.CurrentState)
+	assert.Equal(t, "PENDING", result.Payout.Status)
+	assert.Len(t, payouts.contexts, 1)
+	assert.NoError(t, payouts.contexts[0].Err())
+	// The provider may have made the transfer, only its callback settles it
+	mockRepo.AssertNotCalled(t, "FailDisbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDisburseLoan_ResendsUnacknowledgedPayout(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	payouts := &fakePayouts{status: payout.STATUS_PENDING}
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mockGuard, testApprovalTiers, payouts, &fakeBankCheck{})
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	payoutID := uuid.New()
+	signed := &models.Document{ID: uuid.New(), DocumentType: "SIGNED_AGREEMENT"}
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(&models.Loan{ID: loanID, CurrentState: "DISBURSING"}, nil)
+	mockRepo.On("ListPayouts", mock.Anything, loanID).Return([]models.Payout{
+		{ID: payoutID, LoanID: loanID, Amount: 5000000, BankName: "BCA", BankAccountNumber: "1234567890", Status: "PENDING"},
+	}, nil)
+	mockRepo.On("GetSignedAgreement", mock.Anything, loanID).Return(signed, nil)
+	mockRepo.On("SetPayoutReference", mock.Anything, payoutID, "FAKE-1").Return(nil)
+	mockRepo.On("GetPayout", mock.Anything, payoutID).Return(&models.Payout{ID: payoutID, LoanID: loanID, Status: "PENDING", ProviderReference: "FAKE-1"}, nil)
+	mockRepo.On("GetDisbursedLoan", mock.Anything, loanID).Return(&models.DisburseLoanResponse{ID: loanID, CurrentState: "DISBURSING"}, nil)
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), &models.DisburseLoanRequest{}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/files/"+signed.ID.String(), result.SignedAgreementURL)
+	assert.Equal(t, "FAKE-1", result.Payout.ProviderReference)
+	// Same reference as the first attempt, the provider makes it once
+	assert.Len(t, payouts.requests, 1)
+	assert.Equal(t, payoutID.String(), payouts.requests[0].Reference)
+	mockRepo.AssertNotCalled(t, "StartDisbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDisburseLoan_BlockedOnBankAccountMismatch(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	payouts := &fakePayouts{status: payout.STATUS_PENDING}
+	bankCheck := &fakeBankCheck{err: fmt.Errorf("bank account name mismatch")}
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mockGuard, testApprovalTiers, payouts, bankCheck)
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(&models.Loan{ID: loanID, CurrentState: "INVESTED"}, nil)
+	mockRepo.On("CountUnacceptedInvestments", mock.Anything, loanID).Return(0, nil)
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), &models.DisburseLoanRequest{}, &models.Document{ID: uuid.New()})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "bank account name mismatch")
+	assert.Empty(t, payouts.requests)
+	mockRepo.AssertNotCalled(t, "StartDisbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDisburseLoan_RejectedTransferReturnsLoanToInvested(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	payouts := &fakePayouts{err: payout.ErrInvalidAccount}
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mockGuard, testApprovalTiers, payouts, &fakeBankCheck{})
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	signedAgreement := &models.Document{ID: uuid.New()}
+	var loanPayout *models.Payout
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:disburse").Return(nil)
+	mockRepo.On("GetLoanForDisbursement", mock.Anything, loanID).Return(&models.Loan{ID: loanID, CurrentState: "INVESTED"}, nil)
+	mockRepo.On("CountUnacceptedInvestments", mock.Anything, loanID).Return(0, nil)
+	mockRepo.On("StartDisbursement", mock.Anything, loanID, officerID, signedAgreement, "", mock.AnythingOfType("*models.Payout")).
+		Run(func(args mock.Arguments) {
+			startDisbursement(args)
+			loanPayout = args.Get(5).(*models.Payout)
+		}).Return(nil)
+	mockRepo.On("GetPayout", mock.Anything, mock.AnythingOfType("uuid.UUID")).
+		Return(func(ctx context.Context, id uuid.UUID) (*models.Payout, error) {
+			settled := *loanPayout
+			return &settled, nil
+		})
+	mockRepo.On("FailDisbursement", mock.Anything, mock.AnythingOfType("uuid.UUID"), "", payout.ErrInvalidAccount.Error(), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			loanPayout.Status = "FAILED"
+			loanPayout.FailureReason = args.String(3)
+		}).Return(nil)
+	mockRepo.On("GetDisbursedLoan", mock.Anything, loanID).Return(&models.DisburseLoanResponse{ID: loanID, CurrentState: "INVESTED"}, nil)
+
+	result, err := loanUsecase.DisburseLoan(context.Background(), loanID.String(), officerID.String(), &models.DisburseLoanRequest{}, signedAgreement)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INVESTED", result.CurrentState)
+	assert.Equal(t, "FAILED", result.Payout.Status)
+	assert.Equal(t, payout.ErrInvalidAccount.Error(), result.Payout.FailureReason)
+	mockRepo.AssertNotCalled(t, "SetPayoutReference", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCompletePayout_SuccessDisbursesLoan(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksAuthz.NewGuard(t), testApprovalTiers, &fakePayouts{}, nil)
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	payoutID := uuid.New()
+	settledAt := time.Date(2025, 7, 8, 10, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetPayout", mock.Anything, payoutID).
+		Return(&models.Payout{ID: payoutID, LoanID: loanID, Amount: 5000000, Status: "PENDING", RequestedByID: &officerID}, nil)
+	mockRepo.On("CompleteDisbursement", mock.Anything, payoutID, "SIM-01", settledAt, mock.MatchedBy(func(event *models.OutboxEvent) bool {
+		var payload models.LoanDisbursedEvent
+		return event.EventType == "LoanDisbursed" && event.AggregateID == loanID && event.OccurredAt.Equal(settledAt) &&
+			json.Unmarshal(event.Payload, &payload) == nil &&
+			*payload.FieldOfficerEmployeeID == officerID && payload.PayoutID == payoutID &&
+			payload.Amount == 5000000 && payload.ProviderReference == "SIM-01"
+	})).Return(nil)
+
+	err := loanUsecase.CompletePayout(context.Background(), payout.Transfer{
+		Reference:         payoutID.String(),
+		ProviderReference: "SIM-01",
+		Status:            payout.STATUS_SUCCESS,
+		UpdatedAt:         settledAt,
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestCompletePayout_FailureReturnsLoanToInvested(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksAuthz.NewGuard(t), testApprovalTiers, &fakePayouts{}, nil)
+
+	payoutID := uuid.New()
+	settledAt := time.Date(2025, 7, 8, 10, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetPayout", mock.Anything, payoutID).Return(&models.Payout{ID: payoutID, LoanID: uuid.New(), Status: "PENDING"}, nil)
+	mockRepo.On("FailDisbursement", mock.Anything, payoutID, "SIM-02", "account rejected by beneficiary bank", settledAt).Return(nil)
+
+	err := loanUsecase.CompletePayout(context.Background(), payout.Transfer{
+		Reference:         payoutID.String(),
+		ProviderReference: "SIM-02",
+		Status:            payout.STATUS_FAILED,
+		FailureReason:     "account rejected by beneficiary bank",
+		UpdatedAt:         settledAt,
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "CompleteDisbursement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCompletePayout_RepeatedCallbackIsIgnored(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mocksRepo.NewAgreementTemplateRepository(t), mocksPdf.NewPDFGenerator(t), mocksAuthz.NewGuard(t), testApprovalTiers, &fakePayouts{}, nil)
+
+	payoutID := uuid.New()
+	mockRepo.On("GetPayout", mock.Anything, payoutID).Return(&models.Payout{ID: payoutID, Status: "SUCCESS"}, nil)
+
+	err := loanUsecase.CompletePayout(context.Background(), payout.Transfer{Reference: payoutID.String(), Status: payout.STATUS_SUCCESS})
+	assert.NoError(t, err)
+
+	// A settled payout cannot change its outcome
+	err = loanUsecase.CompletePayout(context.Background(), payout.Transfer{Reference: payoutID.String(), Status: payout.STATUS_FAILED})
+	assert.EqualError(t, err, "payout already settled")
+}
+
 func TestApproveLoan_InvalidEmployeeID(t *testing.T) {
 	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
 	mockPdfGen := mocksPdf.NewPDFGenerator(t)
-	loanUsecase := NewLoanUsecase(mockRepo, mockPdfGen)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
 
 	loanID := uuid.New()
 
@@ -267,3 +713,232 @@ func TestApproveLoan_InvalidEmployeeID(t *testing.T) {
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "invalid employee ID")
 }
+
+func TestApproveLoan_OutOfBranchEmployeeRejected(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	employeeID := uuid.New()
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, employeeID, loanID, "loan:approve").Return(fmt.Errorf("loan outside employee branch"))
+
+	result, err := loanUsecase.ApproveLoan(context.Background(), loanID.String(), employeeID.String(), &models.ApproveLoanRequest{})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "loan outside employee branch", err.Error())
+	mockRepo.AssertNotCalled(t, "GetLoanForApproval", mock.Anything, mock.Anything)
+}
+
+func TestAssignValidator_AssigneeMustHoldSurveyPermission(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	validatorID := uuid.New()
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:assign").Return(nil)
+	mockGuard.On("CheckAssignee", mock.Anything, validatorID, loanID, "survey:upload").Return(fmt.Errorf("assignee lacks permission: survey:upload"))
+
+	result, err := loanUsecase.AssignValidator(context.Background(), loanID.String(), officerID.String(), &models.AssignValidatorRequest{
+		ValidatorEmployeeID: validatorID.String(),
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "AssignValidator", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAssignValidator_Success(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	officerID := uuid.New()
+	validatorID := uuid.New()
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, officerID, loanID, "loan:assign").Return(nil)
+	mockGuard.On("CheckAssignee", mock.Anything, validatorID, loanID, "survey:upload").Return(nil)
+	mockRepo.On("AssignValidator", mock.Anything, loanID, validatorID).Return(nil)
+
+	result, err := loanUsecase.AssignValidator(context.Background(), loanID.String(), officerID.String(), &models.AssignValidatorRequest{
+		ValidatorEmployeeID: validatorID.String(),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, validatorID, result.AssignedValidatorEmployeeID)
+	assert.Equal(t, officerID, result.AssignedByEmployeeID)
+}
+
+func TestApproveLoan_FieldValidatorCannotApprove(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	employeeID := uuid.New()
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, employeeID, loanID, "loan:approve").Return(nil)
+	mockRepo.On("GetLoanForApproval", mock.Anything, loanID).Return(&models.LoanForApproval{
+		ID:                       loanID,
+		PrincipalAmount:          5000000,
+		CurrentState:             "PROPOSED",
+		FieldValidatorEmployeeID: employeeID,
+		SurveyDate:               time.Now(),
+		RequiredApprovals:        1,
+	}, nil)
+
+	result, err := loanUsecase.ApproveLoan(context.Background(), loanID.String(), employeeID.String(), &models.ApproveLoanRequest{})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "approver cannot be the field validator")
+	mockRepo.AssertNotCalled(t, "AddLoanApproval", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApproveLoan_LargeLoanWaitsForSecondApprover(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	firstApprover := uuid.New()
+	secondApprover := uuid.New()
+	loanForApproval := &models.LoanForApproval{
+		ID:                       loanID,
+		PrincipalAmount:          75000000,
+		CurrentState:             "PROPOSED",
+		FieldValidatorEmployeeID: uuid.New(),
+		SurveyDate:               time.Now(),
+		RequiredApprovals:        1,
+	}
+
+	// First approval is recorded, no agreement yet
+	mockGuard.On("CheckLoanAccess", mock.Anything, firstApprover, loanID, "loan:approve").Return(nil)
+	mockRepo.On("GetLoanForApproval", mock.Anything, loanID).Return(loanForApproval, nil).Once()
+	mockRepo.On("GetSurveyFlags", mock.Anything, loanID).Return([]models.SurveyFlag{}, nil)
+	mockRepo.On("GetLoanApprovals", mock.Anything, loanID).Return([]models.LoanApproval{}, nil).Once()
+	mockRepo.On("AddLoanApproval", mock.Anything,
+		mock.MatchedBy(func(a *models.LoanApproval) bool { return a.EmployeeID == firstApprover }),
+		2, []string{"FIELD_OFFICER"}).Return(1, 2, nil).Once()
+
+	result, err := loanUsecase.ApproveLoan(context.Background(), loanID.String(), firstApprover.String(), &models.ApproveLoanRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PROPOSED", result.CurrentState)
+	assert.Equal(t, 1, result.ReceivedApprovals)
+	assert.Equal(t, 2, result.RequiredApprovals)
+	mockPdfGen.AssertNotCalled(t, "GenerateLoanAgreement", mock.Anything, mock.Anything)
+
+	// Second distinct approver completes the tier and triggers the agreement
+	approvedLoan := *loanForApproval
+	approvedLoan.RequiredApprovals = 2
+	existing := []models.LoanApproval{{LoanID: loanID, EmployeeID: firstApprover}}
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, secondApprover, loanID, "loan:approve").Return(nil)
+	mockRepo.On("GetLoanForApproval", mock.Anything, loanID).Return(&approvedLoan, nil).Once()
+	mockRepo.On("GetLoanApprovals", mock.Anything, loanID).Return(existing, nil).Once()
+	mockRepo.On("AddLoanApproval", mock.Anything,
+		mock.MatchedBy(func(a *models.LoanApproval) bool { return a.EmployeeID == secondApprover }),
+		2, []string{"FIELD_OFFICER"}).Return(2, 2, nil).Once()
+	agreement := &models.Document{ID: uuid.New(), LoanID: loanID, DocumentType: "LOAN_AGREEMENT"}
+	mockTemplateRepo.On("GetLoanTemplateVersion", mock.Anything, loanID, "LOAN_AGREEMENT").Return(0, nil)
+	mockTemplateRepo.On("GetLatestTemplate", mock.Anything, "LOAN_AGREEMENT").Return(testLoanTemplate, nil)
+	mockPdfGen.On("GenerateLoanAgreement", &approvedLoan, testLoanTemplate).Return(agreement, nil)
+	mockRepo.On("ApproveLoan", mock.Anything, loanID, secondApprover, "", agreement, mock.AnythingOfType("*models.OutboxEvent")).Return(nil)
+	mockRepo.On("GetApprovedLoan", mock.Anything, loanID).Return(&models.ApproveLoanResponse{ID: loanID, CurrentState: "APPROVED"}, nil)
+	mockRepo.On("GetLoanApprovals", mock.Anything, loanID).Return(append(existing, models.LoanApproval{LoanID: loanID, EmployeeID: secondApprover}), nil).Once()
+
+	result, err = loanUsecase.ApproveLoan(context.Background(), loanID.String(), secondApprover.String(), &models.ApproveLoanRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "APPROVED", result.CurrentState)
+	assert.Equal(t, 2, result.ReceivedApprovals)
+	assert.Len(t, result.Approvals, 2)
+}
+
+func TestApproveLoan_SurveyFlagsNeedAcknowledgement(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	loanID := uuid.New()
+	employeeID := uuid.New()
+	flags := []models.SurveyFlag{{LoanID: loanID, DocumentID: uuid.New(), FlagType: "LOCATION_MISMATCH", Detail: "photo taken 28000 m from the registered address"}}
+
+	mockGuard.On("CheckLoanAccess", mock.Anything, employeeID, loanID, "loan:approve").Return(nil)
+	mockRepo.On("GetLoanForApproval", mock.Anything, loanID).Return(&models.LoanForApproval{
+		ID:                       loanID,
+		PrincipalAmount:          75000000,
+		CurrentState:             "PROPOSED",
+		FieldValidatorEmployeeID: uuid.New(),
+		SurveyDate:               time.Now(),
+		RequiredApprovals:        1,
+	}, nil)
+	mockRepo.On("GetSurveyFlags", mock.Anything, loanID).Return(flags, nil)
+
+	result, err := loanUsecase.ApproveLoan(context.Background(), loanID.String(), employeeID.String(), &models.ApproveLoanRequest{})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "survey flags must be acknowledged")
+	mockRepo.AssertNotCalled(t, "AddLoanApproval", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// The acknowledgement is recorded with the approval
+	mockRepo.On("GetLoanApprovals", mock.Anything, loanID).Return([]models.LoanApproval{}, nil)
+	mockRepo.On("AddLoanApproval", mock.Anything,
+		mock.MatchedBy(func(a *models.LoanApproval) bool { return a.SurveyFlagsAcknowledged }),
+		2, []string{"FIELD_OFFICER"}).Return(1, 2, nil)
+
+	result, err = loanUsecase.ApproveLoan(context.Background(), loanID.String(), employeeID.String(), &models.ApproveLoanRequest{AcknowledgeSurveyFlags: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PROPOSED", result.CurrentState)
+	assert.Equal(t, flags, result.SurveyFlags)
+}
+
+func TestListPendingApprovals_HidesLoansCallerCannotApprove(t *testing.T) {
+	mockRepo := mocksRepo.NewLoanRepository(t)
+	mockTemplateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	mockPdfGen := mocksPdf.NewPDFGenerator(t)
+	mockGuard := mocksAuthz.NewGuard(t)
+	loanUsecase := NewLoanUsecase(mockRepo, mockTemplateRepo, mockPdfGen, mockGuard, testApprovalTiers, nil, nil)
+
+	employeeID := uuid.New()
+	waiting := models.PendingApproval{LoanID: uuid.New(), PrincipalAmount: 75000000, FieldValidatorEmployeeID: uuid.New(),
+		RequiredApprovals: 2, ReceivedApprovals: 1, ApproverEmployeeIDs: []uuid.UUID{uuid.New()}}
+	fresh := models.PendingApproval{LoanID: uuid.New(), PrincipalAmount: 5000000, FieldValidatorEmployeeID: uuid.New(),
+		RequiredApprovals: 1, ApproverEmployeeIDs: []uuid.UUID{}}
+	surveyedByCaller := models.PendingApproval{LoanID: uuid.New(), PrincipalAmount: 5000000, FieldValidatorEmployeeID: employeeID,
+		RequiredApprovals: 1, ApproverEmployeeIDs: []uuid.UUID{}}
+	alreadyApproved := models.PendingApproval{LoanID: uuid.New(), PrincipalAmount: 75000000, FieldValidatorEmployeeID: uuid.New(),
+		RequiredApprovals: 2, ReceivedApprovals: 1, ApproverEmployeeIDs: []uuid.UUID{employeeID}}
+
+	mockGuard.On("CheckPermission", mock.Anything, employeeID, "loan:approve").
+		Return(&models.EmployeeScope{ID: employeeID, Role: "FIELD_OFFICER", Branch: "Bekasi", IsActive: true}, nil)
+	mockRepo.On("ListPendingApprovals", mock.Anything, "Bekasi", false).
+		Return([]models.PendingApproval{waiting, fresh, surveyedByCaller, alreadyApproved}, nil)
+
+	queue, err := loanUsecase.ListPendingApprovals(context.Background(), employeeID.String())
+
+	assert.NoError(t, err)
+	assert.Len(t, queue, 2)
+	assert.Equal(t, waiting.LoanID, queue[0].LoanID)
+	assert.Equal(t, fresh.LoanID, queue[1].LoanID)
+	assert.Equal(t, 1, queue[1].RequiredApprovals)
+}
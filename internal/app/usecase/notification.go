@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/notification"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+
+	"github.com/google/uuid"
+)
+
+// NotificationUsecase lets users choose the channels each notification is
+// sent on.
+type NotificationUsecase interface {
+	GetPreferences(ctx context.Context, userID, userType string) ([]models.NotificationPreference, error)
+	// UpdatePreferences saves the given types, the others are kept.
+	UpdatePreferences(ctx context.Context, userID, userType string, req *models.UpdateNotificationPreferencesRequest) ([]models.NotificationPreference, error)
+}
+
+type notificationUsecase struct {
+	notificationRepo repositories.NotificationRepository
+}
+
+func NewNotificationUsecase(notificationRepo repositories.NotificationRepository) NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepo: notificationRepo,
+	}
+}
+
+func (u *notificationUsecase) GetPreferences(ctx context.Context, userID, userType string) ([]models.NotificationPreference, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	saved, err := u.notificationRepo.GetPreferences(ctx, userType, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return notification.Preferences(saved), nil
+}
+
+func (u *notificationUsecase) UpdatePreferences(ctx context.Context, userID, userType string, req *models.UpdateNotificationPreferencesRequest) ([]models.NotificationPreference, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	seen := map[string]bool{}
+	for _, preference := range req.Preferences {
+		if seen[preference.NotificationType] {
+			return nil, fmt.Errorf("duplicate notification type: %s", preference.NotificationType)
+		}
+		seen[preference.NotificationType] = true
+	}
+
+	if err := u.notificationRepo.SavePreferences(ctx, userType, userUUID, req.Preferences); err != nil {
+		return nil, err
+	}
+
+	return u.GetPreferences(ctx, userID, userType)
+}
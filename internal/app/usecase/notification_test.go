@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetNotificationPreferences_DefaultsToEmail(t *testing.T) {
+	mockRepo := mocksRepo.NewNotificationRepository(t)
+	notificationUsecase := NewNotificationUsecase(mockRepo)
+
+	userID := uuid.New()
+	mockRepo.On("GetPreferences", mock.Anything, "investor", userID).Return(nil, nil)
+
+	preferences, err := notificationUsecase.GetPreferences(context.Background(), userID.String(), "investor")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.NotificationPreference{
+		{NotificationType: "INVESTMENT_AGREEMENT", Email: true},
+		{NotificationType: "LOAN_FULLY_INVESTED", Email: true},
+	}, preferences)
+}
+
+func TestUpdateNotificationPreferences_KeepsOtherTypes(t *testing.T) {
+	mockRepo := mocksRepo.NewNotificationRepository(t)
+	notificationUsecase := NewNotificationUsecase(mockRepo)
+
+	userID := uuid.New()
+	changed := []models.NotificationPreference{{NotificationType: "LOAN_FULLY_INVESTED", Email: false, SMS: true}}
+	mockRepo.On("SavePreferences", mock.Anything, "investor", userID, changed).Return(nil)
+	mockRepo.On("GetPreferences", mock.Anything, "investor", userID).Return(changed, nil)
+
+	preferences, err := notificationUsecase.UpdatePreferences(context.Background(), userID.String(), "investor",
+		&models.UpdateNotificationPreferencesRequest{Preferences: changed})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.NotificationPreference{
+		{NotificationType: "INVESTMENT_AGREEMENT", Email: true},
+		{NotificationType: "LOAN_FULLY_INVESTED", SMS: true},
+	}, preferences)
+}
+
+func TestUpdateNotificationPreferences_RejectsDuplicateTypes(t *testing.T) {
+	mockRepo := mocksRepo.NewNotificationRepository(t)
+	notificationUsecase := NewNotificationUsecase(mockRepo)
+
+	req := &models.UpdateNotificationPreferencesRequest{Preferences: []models.NotificationPreference{
+		{NotificationType: "LOAN_FULLY_INVESTED", Email: true},
+		{NotificationType: "LOAN_FULLY_INVESTED", SMS: true},
+	}}
+	preferences, err := notificationUsecase.UpdatePreferences(context.Background(), uuid.New().String(), "investor", req)
+
+	assert.Nil(t, preferences)
+	assert.EqualError(t, err, "duplicate notification type: LOAN_FULLY_INVESTED")
+}
@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/authz"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/paygate"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type PaymentUsecase interface {
+	// GetVirtualAccount returns the account the borrower repays into,
+	// assigning one on first use.
+	GetVirtualAccount(ctx context.Context, userID, userType string) (*models.VirtualAccount, error)
+	// ReceivePayment verifies a gateway notification and records the payment,
+	// matched to the borrower's disbursed loan or in suspense. A payment
+	// notified again is not recorded twice.
+	ReceivePayment(ctx context.Context, timestamp, signature string, body []byte) (*models.PaymentCallbackResponse, error)
+	// ListPayments lists the latest payments with status, those in suspense
+	// when it is empty.
+	ListPayments(ctx context.Context, status string) ([]models.IncomingPayment, error)
+	// ResolvePayment assigns a payment in suspense to a disbursed loan, or
+	// marks it returned to the payer when no loan is given.
+	ResolvePayment(ctx context.Context, paymentID string, employeeID string, req *models.ResolvePaymentRequest) (*models.IncomingPayment, error)
+}
+
+type PaymentConfig struct {
+	// Gateway names the payment gateway payments are recorded from
+	Gateway string
+	// WebhookSecret is shared with the gateway to sign notifications, they
+	// are all rejected when it is empty
+	WebhookSecret string
+	// WebhookTolerance is how far a notification timestamp may be from now,
+	// older notifications are rejected as replays
+	WebhookTolerance time.Duration
+	// VirtualAccountPrefix starts every virtual account number, it is the
+	// company code the bank assigned
+	VirtualAccountPrefix string
+	VirtualAccountBank   string
+	ListLimit            int
+}
+
+func (c PaymentConfig) withDefaults() PaymentConfig {
+	if c.Gateway == "" {
+		c.Gateway = "fake"
+	}
+	if c.WebhookTolerance <= 0 {
+		c.WebhookTolerance = 5 * time.Minute
+	}
+	if c.VirtualAccountPrefix == "" {
+		c.VirtualAccountPrefix = "88810"
+	}
+	if c.ListLimit <= 0 {
+		c.ListLimit = 100
+	}
+	return c
+}
+
+type paymentUsecase struct {
+	repo     repositories.PaymentRepository
+	loanRepo repositories.LoanRepository
+	guard    authz.Guard
+	config   PaymentConfig
+	now      func() time.Time
+}
+
+func NewPaymentUsecase(repo repositories.PaymentRepository, loanRepo repositories.LoanRepository, guard authz.Guard, config PaymentConfig) PaymentUsecase {
+	return &paymentUsecase{
+		repo:     repo,
+		loanRepo: loanRepo,
+		guard:    guard,
+		config:   config.withDefaults(),
+		now:      time.Now,
+	}
+}
+
+func (u *paymentUsecase) GetVirtualAccount(ctx context.Context, userID, userType string) (*models.VirtualAccount, error) {
+	if userType != constants.USER_BORROWER {
+		return nil, fmt.Errorf("only borrowers have a virtual account")
+	}
+
+	borrowerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	account, err := u.repo.EnsureVirtualAccount(ctx, borrowerID, u.config.VirtualAccountPrefix)
+	if err != nil {
+		return nil, err
+	}
+	account.BankName = u.config.VirtualAccountBank
+
+	return account, nil
+}
+
+func (u *paymentUsecase) ReceivePayment(ctx context.Context, timestamp, signature string, body []byte) (*models.PaymentCallbackResponse, error) {
+	if u.config.WebhookSecret == "" {
+		return nil, fmt.Errorf("payment notifications are disabled")
+	}
+
+	notified, err := paygate.Verify(u.config.WebhookSecret, u.config.WebhookTolerance, u.now(), timestamp, signature, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, err := u.repo.GetPaymentByGatewayID(ctx, u.config.Gateway, notified.PaymentID); err == nil {
+		return duplicatePayment(existing), nil
+	} else if err.Error() != "payment not found" {
+		return nil, err
+	}
+
+	payment := &models.IncomingPayment{
+		ID:                   uuid.New(),
+		Gateway:              u.config.Gateway,
+		GatewayPaymentID:     notified.PaymentID,
+		VirtualAccountNumber: notified.VirtualAccount,
+		Amount:               notified.Amount,
+		PayerName:            notified.PayerName,
+		PaidAt:               notified.PaidAt,
+		Payload:              body,
+		Status:               constants.PAYMENT_SUSPENSE,
+		CreatedAt:            u.now(),
+	}
+
+	if err := u.match(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	var event *models.OutboxEvent
+	if payment.Status == constants.PAYMENT_MATCHED {
+		event, err = repaymentEvent(payment, true, payment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := u.repo.CreatePayment(ctx, payment, event)
+	if err != nil {
+		return nil, err
+	}
+
+	// Another delivery of the same payment got recorded first
+	if !created {
+		existing, err := u.repo.GetPaymentByGatewayID(ctx, u.config.Gateway, notified.PaymentID)
+		if err != nil {
+			return nil, err
+		}
+		return duplicatePayment(existing), nil
+	}
+
+	if payment.Status == constants.PAYMENT_SUSPENSE {
+		log.Warn().
+			Str("payment_id", payment.ID.String()).
+			Str("virtual_account_number", payment.VirtualAccountNumber).
+			Float64("amount", payment.Amount).
+			Str("reason", payment.SuspenseReason).
+			Msg("Incoming payment held in suspense")
+	}
+
+	return &models.PaymentCallbackResponse{
+		PaymentID: payment.ID,
+		Status:    payment.Status,
+	}, nil
+}
+
+// match assigns the payment to the borrower owning its virtual account when
+// they have exactly one disbursed loan, otherwise gives why it stays in
+// suspense.
+func (u *paymentUsecase) match(ctx context.Context, payment *models.IncomingPayment) error {
+	borrowerID, err := u.repo.FindBorrowerByVirtualAccount(ctx, payment.VirtualAccountNumber)
+	if err != nil {
+		if err.Error() == "virtual account not found" {
+			payment.SuspenseReason = constants.SUSPENSE_UNKNOWN_ACCOUNT
+			return nil
+		}
+		return err
+	}
+	payment.BorrowerID = &borrowerID
+
+	loanIDs, err := u.repo.ListDisbursedLoanIDs(ctx, borrowerID)
+	if err != nil {
+		return err
+	}
+
+	switch len(loanIDs) {
+	case 0:
+		payment.SuspenseReason = constants.SUSPENSE_NO_DISBURSED_LOAN
+	case 1:
+		payment.LoanID = &loanIDs[0]
+		payment.Status = constants.PAYMENT_MATCHED
+	default:
+		payment.SuspenseReason = constants.SUSPENSE_SEVERAL_LOANS
+	}
+
+	return nil
+}
+
+func (u *paymentUsecase) ListPayments(ctx context.Context, status string) ([]models.IncomingPayment, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status == "" {
+		status = constants.PAYMENT_SUSPENSE
+	}
+
+	switch status {
+	case constants.PAYMENT_MATCHED, constants.PAYMENT_SUSPENSE, constants.PAYMENT_RESOLVED, constants.PAYMENT_RETURNED:
+	default:
+		return nil, fmt.Errorf("invalid payment status")
+	}
+
+	return u.repo.ListPayments(ctx, status, u.config.ListLimit)
+}
+
+func (u *paymentUsecase) ResolvePayment(ctx context.Context, paymentID string, employeeID string, req *models.ResolvePaymentRequest) (*models.IncomingPayment, error) {
+	paymentUUID, err := uuid.Parse(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment ID")
+	}
+
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, fmt.Errorf("resolution note is required")
+	}
+
+	payment, err := u.repo.GetPayment(ctx, paymentUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != constants.PAYMENT_SUSPENSE {
+		return nil, fmt.Errorf("payment is not in suspense")
+	}
+
+	payment.Status = constants.PAYMENT_RETURNED
+	if req.LoanID != "" {
+		loanUUID, err := uuid.Parse(req.LoanID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid loan ID")
+		}
+
+		if err := u.guard.CheckLoanAccess(ctx, employeeUUID, loanUUID, constants.PERM_PAYMENT_RECONCILE); err != nil {
+			return nil, err
+		}
+
+		loan, err := u.loanRepo.GetLoanForDisbursement(ctx, loanUUID)
+		if err != nil {
+			return nil, err
+		}
+
+		if loan.CurrentState != constants.DISBURSED {
+			return nil, fmt.Errorf("loan is not disbursed")
+		}
+
+		payment.Status = constants.PAYMENT_RESOLVED
+		payment.LoanID = &loan.ID
+		payment.BorrowerID = &loan.BorrowerID
+	}
+
+	now := u.now()
+	payment.ResolvedByID = &employeeUUID
+	payment.ResolvedAt = &now
+	payment.ResolutionNote = note
+
+	var event *models.OutboxEvent
+	if payment.Status == constants.PAYMENT_RESOLVED {
+		event, err = repaymentEvent(payment, false, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.repo.ResolvePayment(ctx, payment, event); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("payment_id", payment.ID.String()).
+		Str("status", payment.Status).
+		Str("employee_id", employeeUUID.String()).
+		Msg("Payment in suspense resolved")
+
+	return payment, nil
+}
+
+func duplicatePayment(payment *models.IncomingPayment) *models.PaymentCallbackResponse {
+	return &models.PaymentCallbackResponse{
+		PaymentID: payment.ID,
+		Status:    payment.Status,
+		Duplicate: true,
+	}
+}
+
+// repaymentEvent is the RepaymentReceived event of a payment assigned to a
+// loan.
+func repaymentEvent(payment *models.IncomingPayment, matched bool, occurredAt time.Time) (*models.OutboxEvent, error) {
+	return newLoanEvent(constants.EVENT_REPAYMENT_RECEIVED, *payment.LoanID, occurredAt, models.RepaymentReceivedEvent{
+		PaymentID:  payment.ID,
+		LoanID:     *payment.LoanID,
+		BorrowerID: *payment.BorrowerID,
+		Amount:     payment.Amount,
+		PaidAt:     payment.PaidAt,
+		Matched:    matched,
+	})
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	mocksAuthz "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/authz"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/paygate"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testGatewaySecret = "gateway-shared-secret"
+
+var testPaymentTime = time.Date(2025, 7, 10, 9, 0, 0, 0, time.UTC)
+
+func newTestPaymentUsecase(t *testing.T) (*paymentUsecase, *mocksRepo.PaymentRepository, *mocksRepo.LoanRepository, *mocksAuthz.Guard) {
+	repo := mocksRepo.NewPaymentRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	guard := mocksAuthz.NewGuard(t)
+	paymentUsecase := NewPaymentUsecase(repo, loanRepo, guard, PaymentConfig{
+		WebhookSecret: testGatewaySecret,
+	}).(*paymentUsecase)
+	paymentUsecase.now = func() time.Time { return testPaymentTime }
+	return paymentUsecase, repo, loanRepo, guard
+}
+
+// notify has the fake gateway notify payment the way it reaches the callback.
+func notify(t *testing.T, u *paymentUsecase, payment paygate.Payment, sentAt time.Time) (*models.PaymentCallbackResponse, error) {
+	body, header, err := paygate.NewFakeGateway(testGatewaySecret).Notification(payment, sentAt)
+	require.NoError(t, err)
+	return u.ReceivePayment(context.Background(), header.Get(paygate.HeaderTimestamp), header.Get(paygate.HeaderSignature), body)
+}
+
+func TestReceivePayment_MatchesDisbursedLoan(t *testing.T) {
+	paymentUsecase, repo, _, _ := newTestPaymentUsecase(t)
+	payment := paygate.NewFakeGateway(testGatewaySecret).NewPayment("888100000000001", 450000, "SITI PEMINJAM")
+
+	borrowerID := uuid.New()
+	loanID := uuid.New()
+	repo.On("GetPaymentByGatewayID", mock.Anything, "fake", payment.PaymentID).Return(nil, fmt.Errorf("payment not found"))
+	repo.On("FindBorrowerByVirtualAccount", mock.Anything, "888100000000001").Return(borrowerID, nil)
+	repo.On("ListDisbursedLoanIDs", mock.Anything, borrowerID).Return([]uuid.UUID{loanID}, nil)
+	repo.On("CreatePayment", mock.Anything, mock.MatchedBy(func(p *models.IncomingPayment) bool {
+		return p.Status == "MATCHED" && *p.LoanID == loanID && *p.BorrowerID == borrowerID &&
+			p.GatewayPaymentID == payment.PaymentID && p.Amount == 450000 && p.PayerName == "SITI PEMINJAM"
+	}), mock.MatchedBy(func(e *models.OutboxEvent) bool {
+		var event models.RepaymentReceivedEvent
+		json.Unmarshal(e.Payload, &event)
+		return e.EventType == "RepaymentReceived" && e.AggregateID == loanID &&
+			event.LoanID == loanID && event.Amount == 450000 && event.Matched
+	})).Return(true, nil)
+
+	response, err := notify(t, paymentUsecase, payment, testPaymentTime)
+
+	require.NoError(t, err)
+	assert.Equal(t, "MATCHED", response.Status)
+	assert.False(t, response.Duplicate)
+}
+
+func TestReceivePayment_SuspenseWhenUnmatched(t *testing.T) {
+	tests := []struct {
+		name    string
+		loanIDs []uuid.UUID
+		known   bool
+		reason  string
+	}{
+		{name: "unknown virtual account", reason: "unknown virtual account"},
+		{name: "no disbursed loan", known: true, reason: "borrower has no disbursed loan"},
+		{name: "several disbursed loans", known: true, loanIDs: []uuid.UUID{uuid.New(), uuid.New()}, reason: "borrower has several disbursed loans"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentUsecase, repo, _, _ := newTestPaymentUsecase(t)
+			payment := paygate.NewFakeGateway(testGatewaySecret).NewPayment("888100000000009", 100000, "")
+
+			repo.On("GetPaymentByGatewayID", mock.Anything, "fake", payment.PaymentID).Return(nil, fmt.Errorf("payment not found"))
+			if tt.known {
+				borrowerID := uuid.New()
+				repo.On("FindBorrowerByVirtualAccount", mock.Anything, "888100000000009").Return(borrowerID, nil)
+				repo.On("ListDisbursedLoanIDs", mock.Anything, borrowerID).Return(tt.loanIDs, nil)
+			} else {
+				repo.On("FindBorrowerByVirtualAccount", mock.Anything, "888100000000009").Return(uuid.Nil, fmt.Errorf("virtual account not found"))
+			}
+			repo.On("CreatePayment", mock.Anything, mock.MatchedBy(func(p *models.IncomingPayment) bool {
+				return p.Status == "SUSPENSE" && p.LoanID == nil && p.SuspenseReason == tt.reason
+			}), (*models.OutboxEvent)(nil)).Return(true, nil)
+
+			response, err := notify(t, paymentUsecase, payment, testPaymentTime)
+
+			require.NoError(t, err)
+			assert.Equal(t, "SUSPENSE", response.Status)
+		})
+	}
+}
+
+func TestReceivePayment_RetriedNotificationIsNotRecordedTwice(t *testing.T) {
+	paymentUsecase, repo, _, _ := newTestPaymentUsecase(t)
+	payment := paygate.NewFakeGateway(testGatewaySecret).NewPayment("888100000000001", 450000, "")
+
+	existing := &models.IncomingPayment{ID: uuid.New(), GatewayPaymentID: payment.PaymentID, Status: "MATCHED"}
+	repo.On("GetPaymentByGatewayID", mock.Anything, "fake", payment.PaymentID).Return(existing, nil)
+
+	response, err := notify(t, paymentUsecase, payment, testPaymentTime.Add(-time.Minute))
+
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, response.PaymentID)
+	assert.True(t, response.Duplicate)
+	repo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReceivePayment_ConcurrentDeliveryIsDuplicate(t *testing.T) {
+	paymentUsecase, repo, _, _ := newTestPaymentUsecase(t)
+	payment := paygate.NewFakeGateway(testGatewaySecret).NewPayment("888100000000001", 450000, "")
+
+	existing := &models.IncomingPayment{ID: uuid.New(), GatewayPaymentID: payment.PaymentID, Status: "SUSPENSE"}
+	repo.On("GetPaymentByGatewayID", mock.Anything, "fake", payment.PaymentID).Return(nil, fmt.Errorf("payment not found")).Once()
+	repo.On("FindBorrowerByVirtualAccount", mock.Anything, "888100000000001").Return(uuid.Nil, fmt.Errorf("virtual account not found"))
+	repo.On("CreatePayment", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	repo.On("GetPaymentByGatewayID", mock.Anything, "fake", payment.PaymentID).Return(existing, nil).Once()
+
+	response, err := notify(t, paymentUsecase, payment, testPaymentTime)
+
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, response.PaymentID)
+	assert.True(t, response.Duplicate)
+}
+
+func TestReceivePayment_RejectsForgedAndReplayedNotifications(t *testing.T) {
+	paymentUsecase, _, _, _ := newTestPaymentUsecase(t)
+	forger := paygate.NewFakeGateway("not-the-secret")
+	payment := forger.NewPayment("888100000000001", 450000, "")
+
+	body, header, err := forger.Notification(payment, testPaymentTime)
+	require.NoError(t, err)
+	_, err = paymentUsecase.ReceivePayment(context.Background(), header.Get(paygate.HeaderTimestamp), header.Get(paygate.HeaderSignature), body)
+	assert.ErrorIs(t, err, paygate.ErrInvalidSignature)
+
+	_, err = notify(t, paymentUsecase, payment, testPaymentTime.Add(-10*time.Minute))
+	assert.ErrorIs(t, err, paygate.ErrStaleTimestamp)
+}
+
+func TestResolvePayment_AssignsDisbursedLoan(t *testing.T) {
+	paymentUsecase, repo, loanRepo, guard := newTestPaymentUsecase(t)
+
+	paymentID := uuid.New()
+	adminID := uuid.New()
+	loan := &models.Loan{ID: uuid.New(), BorrowerID: uuid.New(), CurrentState: "DISBURSED"}
+	repo.On("GetPayment", mock.Anything, paymentID).Return(&models.IncomingPayment{
+		ID:             paymentID,
+		Amount:         450000,
+		Status:         "SUSPENSE",
+		SuspenseReason: "unknown virtual account",
+	}, nil)
+	guard.On("CheckLoanAccess", mock.Anything, adminID, loan.ID, "payment:reconcile").Return(nil)
+	loanRepo.On("GetLoanForDisbursement", mock.Anything, loan.ID).Return(loan, nil)
+	repo.On("ResolvePayment", mock.Anything, mock.MatchedBy(func(p *models.IncomingPayment) bool {
+		return p.Status == "RESOLVED" && *p.LoanID == loan.ID && *p.BorrowerID == loan.BorrowerID &&
+			*p.ResolvedByID == adminID && p.ResolutionNote == "Paid into the old account number"
+	}), mock.MatchedBy(func(e *models.OutboxEvent) bool {
+		var event models.RepaymentReceivedEvent
+		json.Unmarshal(e.Payload, &event)
+		return e.EventType == "RepaymentReceived" && event.LoanID == loan.ID && !event.Matched
+	})).Return(nil)
+
+	payment, err := paymentUsecase.ResolvePayment(context.Background(), paymentID.String(), adminID.String(), &models.ResolvePaymentRequest{
+		LoanID: loan.ID.String(),
+		Note:   " Paid into the old account number ",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "RESOLVED", payment.Status)
+	assert.NotNil(t, payment.ResolvedAt)
+}
+
+func TestResolvePayment_ReturnsWithoutLoan(t *testing.T) {
+	paymentUsecase, repo, _, _ := newTestPaymentUsecase(t)
+
+	paymentID := uuid.New()
+	repo.On("GetPayment", mock.Anything, paymentID).Return(&models.IncomingPayment{ID: paymentID, Status: "SUSPENSE"}, nil)
+	repo.On("ResolvePayment", mock.Anything, mock.MatchedBy(func(p *models.IncomingPayment) bool {
+		return p.Status == "RETURNED" && p.LoanID == nil
+	}), (*models.OutboxEvent)(nil)).Return(nil)
+
+	payment, err := paymentUsecase.ResolvePayment(context.Background(), paymentID.String(), uuid.New().String(), &models.ResolvePaymentRequest{
+		Note: "Refunded to payer",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "RETURNED", payment.Status)
+}
+
+func TestResolvePayment_RequiresDisbursedLoan(t *testing.T) {
+	paymentUsecase, repo, loanRepo, guard := newTestPaymentUsecase(t)
+
+	paymentID := uuid.New()
+	adminID := uuid.New()
+	loan := &models.Loan{ID: uuid.New(), BorrowerID: uuid.New(), CurrentState: "INVESTED"}
+	repo.On("GetPayment", mock.Anything, paymentID).Return(&models.IncomingPayment{ID: paymentID, Status: "SUSPENSE"}, nil)
+	guard.On("CheckLoanAccess", mock.Anything, adminID, loan.ID, "payment:reconcile").Return(nil)
+	loanRepo.On("GetLoanForDisbursement", mock.Anything, loan.ID).Return(loan, nil)
+
+	_, err := paymentUsecase.ResolvePayment(context.Background(), paymentID.String(), adminID.String(), &models.ResolvePaymentRequest{
+		LoanID: loan.ID.String(),
+		Note:   "Paid into the old account number",
+	})
+
+	assert.EqualError(t, err, "loan is not disbursed")
+	repo.AssertNotCalled(t, "ResolvePayment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolvePayment_OnlySuspense(t *testing.T) {
+	paymentUsecase, repo, _, _ := newTestPaymentUsecase(t)
+
+	paymentID := uuid.New()
+	repo.On("GetPayment", mock.Anything, paymentID).Return(&models.IncomingPayment{ID: paymentID, Status: "MATCHED"}, nil)
+
+	_, err := paymentUsecase.ResolvePayment(context.Background(), paymentID.String(), uuid.New().String(), &models.ResolvePaymentRequest{
+		Note: "Refunded to payer",
+	})
+
+	assert.EqualError(t, err, "payment is not in suspense")
+}
+
+func TestGetVirtualAccount_OnlyBorrowers(t *testing.T) {
+	paymentUsecase, repo, _, _ := newTestPaymentUsecase(t)
+	paymentUsecase.config.VirtualAccountBank = "Bank BCA"
+
+	borrowerID := uuid.New()
+	repo.On("EnsureVirtualAccount", mock.Anything, borrowerID, "88810").Return(&models.VirtualAccount{
+		BorrowerID:           borrowerID,
+		VirtualAccountNumber: "888100000000001",
+	}, nil)
+
+	account, err := paymentUsecase.GetVirtualAccount(context.Background(), borrowerID.String(), "borrower")
+	require.NoError(t, err)
+	assert.Equal(t, "Bank BCA", account.BankName)
+
+	_, err = paymentUsecase.GetVirtualAccount(context.Background(), uuid.New().String(), "investor")
+	assert.EqualError(t, err, "only borrowers have a virtual account")
+}
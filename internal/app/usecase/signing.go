@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdf"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/sms"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DEFAULT_SIGNING_OTP_TTL         = 5 * time.Minute
+	DEFAULT_SIGNING_MAX_ATTEMPTS    = 5
+	DEFAULT_SIGNING_RESEND_INTERVAL = time.Minute
+
+	signingOTPDigits = 6
+)
+
+// SigningConfig sets how borrowers sign their loan agreement with an OTP.
+type SigningConfig struct {
+	OTPTTL time.Duration
+	// MaxAttempts wrong codes close the session, a new code must be requested
+	MaxAttempts int
+	// ResendInterval is the least time between two codes for a loan
+	ResendInterval time.Duration
+}
+
+func (c SigningConfig) withDefaults() SigningConfig {
+	if c.OTPTTL <= 0 {
+		c.OTPTTL = DEFAULT_SIGNING_OTP_TTL
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DEFAULT_SIGNING_MAX_ATTEMPTS
+	}
+	if c.ResendInterval <= 0 {
+		c.ResendInterval = DEFAULT_SIGNING_RESEND_INTERVAL
+	}
+	return c
+}
+
+// SigningUsecase lets borrowers sign their loan agreement electronically
+// instead of on paper.
+type SigningUsecase interface {
+	// RequestSignature sends an OTP for the current loan agreement to the
+	// borrower's registered phone.
+	RequestSignature(ctx context.Context, loanID, borrowerID string, client ClientInfo) (*models.RequestSignatureResponse, error)
+	// ConfirmSignature checks the OTP and produces the signed agreement with
+	// an audit page.
+	ConfirmSignature(ctx context.Context, loanID, borrowerID string, req *models.ConfirmSignatureRequest, client ClientInfo) (*models.ConfirmSignatureResponse, error)
+}
+
+type signingUsecase struct {
+	signingRepo  repositories.SigningRepository
+	loanRepo     repositories.LoanRepository
+	templateRepo repositories.AgreementTemplateRepository
+	pdfGenerator pdf.PDFGenerator
+	smsSender    sms.Sender
+	config       SigningConfig
+	now          func() time.Time
+}
+
+func NewSigningUsecase(signingRepo repositories.SigningRepository, loanRepo repositories.LoanRepository, templateRepo repositories.AgreementTemplateRepository, pdfGenerator pdf.PDFGenerator, smsSender sms.Sender, config SigningConfig) SigningUsecase {
+	return &signingUsecase{
+		signingRepo:  signingRepo,
+		loanRepo:     loanRepo,
+		templateRepo: templateRepo,
+		pdfGenerator: pdfGenerator,
+		smsSender:    smsSender,
+		config:       config.withDefaults(),
+		now:          time.Now,
+	}
+}
+
+func (u *signingUsecase) RequestSignature(ctx context.Context, loanID, borrowerID string, client ClientInfo) (*models.RequestSignatureResponse, error) {
+	loan, borrowerUUID, err := u.signableLoan(ctx, loanID, borrowerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	latest, err := u.signingRepo.GetLatestSession(ctx, loan.ID)
+	if err != nil && err.Error() != "signing session not found" {
+		return nil, err
+	}
+	if latest != nil {
+		if latest.Status == constants.SIGNING_SIGNED {
+			return nil, fmt.Errorf("agreement already signed")
+		}
+		if latest.Status == constants.SIGNING_PENDING && now.Before(latest.CreatedAt.Add(u.config.ResendInterval)) {
+			return nil, fmt.Errorf("otp recently sent, try again later")
+		}
+	}
+
+	agreement, err := u.signingRepo.GetLoanAgreement(ctx, loan.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := u.signingRepo.GetBorrowerContact(ctx, borrowerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := generateSigningOTP()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.SigningSession{
+		ID:                  uuid.New(),
+		LoanID:              loan.ID,
+		BorrowerID:          borrowerUUID,
+		AgreementDocumentID: agreement.ID,
+		AgreementSHA256:     agreement.SHA256,
+		PhoneNumber:         contact.PhoneNumber,
+		ExpiresAt:           now.Add(u.config.OTPTTL),
+		Status:              constants.SIGNING_PENDING,
+		RequestedIP:         client.IPAddress,
+		RequestedUserAgent:  client.UserAgent,
+		CreatedAt:           now,
+	}
+	session.OTPHash = hashSigningOTP(session.ID, code)
+
+	if err := u.signingRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	err = u.smsSender.Send(ctx, sms.Message{
+		To: contact.PhoneNumber,
+		Body: fmt.Sprintf("Kode OTP tanda tangan perjanjian pinjaman Anda: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.",
+			code, int(u.config.OTPTTL.Minutes())),
+	})
+	if err != nil {
+		// Let the borrower ask again straight away
+		if failErr := u.signingRepo.FailSession(ctx, session.ID); failErr != nil {
+			log.Warn().Err(failErr).Str("session_id", session.ID.String()).Msg("Failed to close undelivered signing session")
+		}
+		return nil, fmt.Errorf("failed to send otp: %w", err)
+	}
+
+	return &models.RequestSignatureResponse{
+		SessionID:       session.ID,
+		LoanID:          loan.ID,
+		AgreementURL:    documentURL(agreement.ID),
+		AgreementSHA256: agreement.SHA256,
+		PhoneNumber:     maskPhoneNumber(contact.PhoneNumber),
+		ExpiresAt:       session.ExpiresAt,
+	}, nil
+}
+
+func (u *signingUsecase) ConfirmSignature(ctx context.Context, loanID, borrowerID string, req *models.ConfirmSignatureRequest, client ClientInfo) (*models.ConfirmSignatureResponse, error) {
+	loan, borrowerUUID, err := u.signableLoan(ctx, loanID, borrowerID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionUUID, err := uuid.Parse(req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID")
+	}
+
+	session, err := u.signingRepo.GetSession(ctx, sessionUUID)
+	if err != nil {
+		return nil, err
+	}
+	if session.LoanID != loan.ID || session.BorrowerID != borrowerUUID {
+		return nil, fmt.Errorf("signing session not found")
+	}
+
+	now := u.now()
+	switch {
+	case session.Status == constants.SIGNING_SIGNED:
+		return nil, fmt.Errorf("agreement already signed")
+	case session.Status != constants.SIGNING_PENDING:
+		return nil, fmt.Errorf("signing session is no longer active")
+	case !now.Before(session.ExpiresAt):
+		return nil, fmt.Errorf("otp expired")
+	}
+
+	if !hmac.Equal([]byte(hashSigningOTP(session.ID, req.OTP)), []byte(session.OTPHash)) {
+		attempts, err := u.signingRepo.RecordFailedAttempt(ctx, session.ID, u.config.MaxAttempts)
+		if err != nil {
+			return nil, err
+		}
+		if attempts >= u.config.MaxAttempts {
+			return nil, fmt.Errorf("too many failed attempts")
+		}
+		return nil, fmt.Errorf("invalid otp")
+	}
+
+	// The code was sent for the agreement the borrower reviewed
+	agreement, err := u.signingRepo.GetLoanAgreement(ctx, loan.ID)
+	if err != nil {
+		return nil, err
+	}
+	if agreement.ID != session.AgreementDocumentID {
+		return nil, fmt.Errorf("agreement has changed")
+	}
+
+	agreementTemplate, err := u.reviewedTemplate(ctx, agreement)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := &models.SignatureAudit{
+		SessionID:    session.ID,
+		BorrowerName: loan.BorrowerName,
+		PhoneNumber:  maskPhoneNumber(session.PhoneNumber),
+		Agreement:    agreement,
+		OTPSentAt:    session.CreatedAt,
+		SignedAt:     now,
+		IPAddress:    client.IPAddress,
+		UserAgent:    client.UserAgent,
+	}
+	signedAgreement, err := u.pdfGenerator.GenerateSignedLoanAgreement(loan, agreementTemplate, audit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signed agreement: %w", err)
+	}
+
+	session.SignedAt = &now
+	session.SignedIP = client.IPAddress
+	session.SignedUserAgent = client.UserAgent
+	if err := u.signingRepo.CompleteSigning(ctx, session, signedAgreement); err != nil {
+		return nil, err
+	}
+
+	return &models.ConfirmSignatureResponse{
+		LoanID:             loan.ID,
+		SignedAgreementURL: documentURL(signedAgreement.ID),
+		SignedSHA256:       signedAgreement.SHA256,
+		VerificationID:     signedAgreement.VerificationID,
+		SignedAt:           now,
+	}, nil
+}
+
+// signableLoan returns the borrower's loan while its agreement can still be
+// signed: once approved and until it is disbursed.
+func (u *signingUsecase) signableLoan(ctx context.Context, loanID, borrowerID string) (*models.LoanForApproval, uuid.UUID, error) {
+	loanUUID, err := uuid.Parse(loanID)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("invalid loan ID")
+	}
+
+	borrowerUUID, err := uuid.Parse(borrowerID)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("invalid borrower ID")
+	}
+
+	loan, err := u.loanRepo.GetLoanForApproval(ctx, loanUUID)
+	if err != nil {
+		if err.Error() == "survey not completed" {
+			return nil, uuid.Nil, fmt.Errorf("loan agreement cannot be signed in current state")
+		}
+		return nil, uuid.Nil, err
+	}
+
+	// Other borrowers' loans are not revealed
+	if loan.BorrowerID != borrowerUUID {
+		return nil, uuid.Nil, fmt.Errorf("loan not found")
+	}
+
+	switch loan.CurrentState {
+	case constants.APPROVED, constants.FUNDING, constants.INVESTED:
+		return loan, borrowerUUID, nil
+	default:
+		return nil, uuid.Nil, fmt.Errorf("loan agreement cannot be signed in current state")
+	}
+}
+
+// reviewedTemplate is the template version the reviewed agreement was
+// rendered with, so the signed copy carries the same terms.
+func (u *signingUsecase) reviewedTemplate(ctx context.Context, agreement *models.Document) (*models.AgreementTemplate, error) {
+	if agreement.TemplateVersion == nil {
+		return agreementTemplateFor(ctx, u.templateRepo, agreement.LoanID, constants.DOCUMENT_LOAN_AGREEMENT)
+	}
+	return u.templateRepo.GetTemplate(ctx, constants.DOCUMENT_LOAN_AGREEMENT, *agreement.TemplateVersion)
+}
+
+func generateSigningOTP() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < signingOTPDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate otp: %w", err)
+	}
+	return fmt.Sprintf("%0*d", signingOTPDigits, n.Int64()), nil
+}
+
+// hashSigningOTP binds a code to its session, so a code is useless for any
+// other session.
+func hashSigningOTP(sessionID uuid.UUID, code string) string {
+	sum := sha256.Sum256([]byte(sessionID.String() + ":" + strings.TrimSpace(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskPhoneNumber keeps the last four digits.
+func maskPhoneNumber(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
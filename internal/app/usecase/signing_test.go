@@ -0,0 +1,461 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	mocksPdf "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/pdf"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/sms"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type recordingSender struct {
+	messages []sms.Message
+	err      error
+}
+
+func (s *recordingSender) Send(ctx context.Context, message sms.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func TestRequestSignature_SendsOTPToRegisteredPhone(t *testing.T) {
+	signingRepo := mocksRepo.NewSigningRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	pdfGen := mocksPdf.NewPDFGenerator(t)
+	sender := &recordingSender{}
+	signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+	now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+	signingUsecase.now = func() time.Time { return now }
+
+	loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+	loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+	version := 2
+	agreement := &models.Document{
+		ID:              uuid.New(),
+		LoanID:          loan.ID,
+		DocumentType:    "LOAN_AGREEMENT",
+		SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TemplateVersion: &version,
+	}
+
+	var created *models.SigningSession
+	signingRepo.On("GetLatestSession", mock.Anything, loan.ID).Return(nil, errors.New("signing session not found"))
+	signingRepo.On("GetLoanAgreement", mock.Anything, loan.ID).Return(agreement, nil)
+	signingRepo.On("GetBorrowerContact", mock.Anything, loan.BorrowerID).Return(&models.BorrowerContact{
+		BorrowerID: loan.BorrowerID, FullName: loan.BorrowerName, PhoneNumber: "081234567890",
+	}, nil)
+	signingRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*models.SigningSession")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*models.SigningSession) }).
+		Return(nil)
+
+	result, err := signingUsecase.RequestSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		ClientInfo{IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "********7890", result.PhoneNumber)
+	assert.Equal(t, agreement.SHA256, result.AgreementSHA256)
+	assert.Equal(t, "/api/v1/files/"+agreement.ID.String(), result.AgreementURL)
+	assert.Equal(t, now.Add(DEFAULT_SIGNING_OTP_TTL), result.ExpiresAt)
+
+	// Only the hash of the code is stored, bound to the session
+	assert.Len(t, sender.messages, 1)
+	assert.Equal(t, "081234567890", sender.messages[0].To)
+	code := regexp.MustCompile(`\d{6}`).FindString(sender.messages[0].Body)
+	assert.NotEmpty(t, code)
+	assert.Equal(t, created.ID, result.SessionID)
+	assert.Equal(t, hashSigningOTP(created.ID, code), created.OTPHash)
+	assert.NotContains(t, created.OTPHash, code)
+	assert.Equal(t, agreement.ID, created.AgreementDocumentID)
+	assert.Equal(t, "203.0.113.7", created.RequestedIP)
+}
+
+func TestRequestSignature_ResendCooldown(t *testing.T) {
+	signingRepo := mocksRepo.NewSigningRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	pdfGen := mocksPdf.NewPDFGenerator(t)
+	sender := &recordingSender{}
+	signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+	now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+	signingUsecase.now = func() time.Time { return now }
+
+	loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+	loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+
+	signingRepo.On("GetLatestSession", mock.Anything, loan.ID).Return(&models.SigningSession{
+		ID: uuid.New(), LoanID: loan.ID, Status: "PENDING", CreatedAt: now.Add(-30 * time.Second),
+	}, nil)
+
+	result, err := signingUsecase.RequestSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		ClientInfo{IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0"})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "otp recently sent, try again later")
+	assert.Empty(t, sender.messages)
+}
+
+func TestRequestSignature_FailedDeliveryClosesSession(t *testing.T) {
+	signingRepo := mocksRepo.NewSigningRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	pdfGen := mocksPdf.NewPDFGenerator(t)
+	sender := &recordingSender{}
+	signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+	now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+	signingUsecase.now = func() time.Time { return now }
+	sender.err = errors.New("gateway unavailable")
+
+	loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+	loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+
+	signingRepo.On("GetLatestSession", mock.Anything, loan.ID).Return(nil, errors.New("signing session not found"))
+	signingRepo.On("GetLoanAgreement", mock.Anything, loan.ID).Return(&models.Document{ID: uuid.New(), LoanID: loan.ID}, nil)
+	signingRepo.On("GetBorrowerContact", mock.Anything, loan.BorrowerID).Return(&models.BorrowerContact{PhoneNumber: "081234567890"}, nil)
+	signingRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*models.SigningSession")).Return(nil)
+	signingRepo.On("FailSession", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(nil)
+
+	result, err := signingUsecase.RequestSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		ClientInfo{IPAddress: "203.0.113.7", UserAgent: "Mozilla/5.0"})
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "failed to send otp")
+}
+
+func TestRequestSignature_RejectsOtherBorrowersAndStates(t *testing.T) {
+	t.Run("other borrower", func(t *testing.T) {
+		signingRepo := mocksRepo.NewSigningRepository(t)
+		loanRepo := mocksRepo.NewLoanRepository(t)
+		templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+		pdfGen := mocksPdf.NewPDFGenerator(t)
+		sender := &recordingSender{}
+		signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+		now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+		signingUsecase.now = func() time.Time { return now }
+
+		loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+		loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+
+		result, err := signingUsecase.RequestSignature(context.Background(), loan.ID.String(), uuid.New().String(), ClientInfo{})
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "loan not found")
+	})
+
+	t.Run("disbursed", func(t *testing.T) {
+		signingRepo := mocksRepo.NewSigningRepository(t)
+		loanRepo := mocksRepo.NewLoanRepository(t)
+		templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+		pdfGen := mocksPdf.NewPDFGenerator(t)
+		sender := &recordingSender{}
+		signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+		now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+		signingUsecase.now = func() time.Time { return now }
+
+		loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), CurrentState: "DISBURSED"}
+		loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+
+		result, err := signingUsecase.RequestSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(), ClientInfo{})
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "loan agreement cannot be signed in current state")
+	})
+
+	t.Run("already signed", func(t *testing.T) {
+		signingRepo := mocksRepo.NewSigningRepository(t)
+		loanRepo := mocksRepo.NewLoanRepository(t)
+		templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+		pdfGen := mocksPdf.NewPDFGenerator(t)
+		sender := &recordingSender{}
+		signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+		now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+		signingUsecase.now = func() time.Time { return now }
+
+		loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+		loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+		signingRepo.On("GetLatestSession", mock.Anything, loan.ID).Return(&models.SigningSession{
+			ID: uuid.New(), LoanID: loan.ID, Status: "SIGNED", CreatedAt: now.Add(-2 * time.Minute),
+		}, nil)
+
+		result, err := signingUsecase.RequestSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(), ClientInfo{})
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "agreement already signed")
+	})
+}
+
+func TestConfirmSignature_ProducesSignedAgreement(t *testing.T) {
+	signingRepo := mocksRepo.NewSigningRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	pdfGen := mocksPdf.NewPDFGenerator(t)
+	sender := &recordingSender{}
+	signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+	now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+	signingUsecase.now = func() time.Time { return now }
+
+	loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+	loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+	version := 2
+	agreement := &models.Document{
+		ID:              uuid.New(),
+		LoanID:          loan.ID,
+		DocumentType:    "LOAN_AGREEMENT",
+		SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TemplateVersion: &version,
+	}
+	session := &models.SigningSession{
+		ID:                  uuid.New(),
+		LoanID:              loan.ID,
+		BorrowerID:          loan.BorrowerID,
+		AgreementDocumentID: agreement.ID,
+		AgreementSHA256:     agreement.SHA256,
+		PhoneNumber:         "081234567890",
+		ExpiresAt:           now.Add(3 * time.Minute),
+		Status:              "PENDING",
+		CreatedAt:           now.Add(-2 * time.Minute),
+	}
+	session.OTPHash = hashSigningOTP(session.ID, "482915")
+
+	template := &models.AgreementTemplate{TemplateType: "LOAN_AGREEMENT", Version: 2, Body: "# PERJANJIAN PINJAMAN"}
+	signed := &models.Document{ID: uuid.New(), DocumentType: "SIGNED_AGREEMENT", SHA256: "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", VerificationID: "LE-7Q2K9M"}
+
+	signingRepo.On("GetSession", mock.Anything, session.ID).Return(session, nil)
+	signingRepo.On("GetLoanAgreement", mock.Anything, loan.ID).Return(agreement, nil)
+	templateRepo.On("GetTemplate", mock.Anything, "LOAN_AGREEMENT", 2).Return(template, nil)
+	pdfGen.On("GenerateSignedLoanAgreement", loan, template, &models.SignatureAudit{
+		SessionID:    session.ID,
+		BorrowerName: "Siti Rahma",
+		PhoneNumber:  "********7890",
+		Agreement:    agreement,
+		OTPSentAt:    session.CreatedAt,
+		SignedAt:     now,
+		IPAddress:    "203.0.113.9",
+		UserAgent:    "Mozilla/5.0",
+	}).Return(signed, nil)
+	signingRepo.On("CompleteSigning", mock.Anything, session, signed).Return(nil)
+
+	result, err := signingUsecase.ConfirmSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		&models.ConfirmSignatureRequest{SessionID: session.ID.String(), OTP: "482915"}, ClientInfo{IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/files/"+signed.ID.String(), result.SignedAgreementURL)
+	assert.Equal(t, signed.SHA256, result.SignedSHA256)
+	assert.Equal(t, "LE-7Q2K9M", result.VerificationID)
+	assert.Equal(t, now, *session.SignedAt)
+	assert.Equal(t, "203.0.113.9", session.SignedIP)
+}
+
+func TestConfirmSignature_WrongOTPCountsAttempts(t *testing.T) {
+	signingRepo := mocksRepo.NewSigningRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	pdfGen := mocksPdf.NewPDFGenerator(t)
+	sender := &recordingSender{}
+	signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+	now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+	signingUsecase.now = func() time.Time { return now }
+
+	loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+	loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+	version := 2
+	agreement := &models.Document{
+		ID:              uuid.New(),
+		LoanID:          loan.ID,
+		DocumentType:    "LOAN_AGREEMENT",
+		SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TemplateVersion: &version,
+	}
+	session := &models.SigningSession{
+		ID:                  uuid.New(),
+		LoanID:              loan.ID,
+		BorrowerID:          loan.BorrowerID,
+		AgreementDocumentID: agreement.ID,
+		AgreementSHA256:     agreement.SHA256,
+		PhoneNumber:         "081234567890",
+		ExpiresAt:           now.Add(3 * time.Minute),
+		Status:              "PENDING",
+		CreatedAt:           now.Add(-2 * time.Minute),
+	}
+	session.OTPHash = hashSigningOTP(session.ID, "482915")
+
+	signingRepo.On("GetSession", mock.Anything, session.ID).Return(session, nil)
+	signingRepo.On("RecordFailedAttempt", mock.Anything, session.ID, 3).Return(1, nil).Once()
+	signingRepo.On("RecordFailedAttempt", mock.Anything, session.ID, 3).Return(3, nil).Once()
+
+	_, err := signingUsecase.ConfirmSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		&models.ConfirmSignatureRequest{SessionID: session.ID.String(), OTP: "000000"}, ClientInfo{IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0"})
+	assert.EqualError(t, err, "invalid otp")
+
+	_, err = signingUsecase.ConfirmSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		&models.ConfirmSignatureRequest{SessionID: session.ID.String(), OTP: "000000"}, ClientInfo{IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0"})
+	assert.EqualError(t, err, "too many failed attempts")
+
+	pdfGen.AssertNotCalled(t, "GenerateSignedLoanAgreement", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConfirmSignature_RejectsClosedSessions(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		expires time.Duration
+		wantErr string
+	}{
+		{"expired", "PENDING", 0, "otp expired"},
+		{"failed", "FAILED", time.Minute, "signing session is no longer active"},
+		{"superseded", "SUPERSEDED", time.Minute, "signing session is no longer active"},
+		{"signed", "SIGNED", time.Minute, "agreement already signed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signingRepo := mocksRepo.NewSigningRepository(t)
+			loanRepo := mocksRepo.NewLoanRepository(t)
+			templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+			pdfGen := mocksPdf.NewPDFGenerator(t)
+			sender := &recordingSender{}
+			signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+			now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+			signingUsecase.now = func() time.Time { return now }
+
+			loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+			loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+			version := 2
+			agreement := &models.Document{
+				ID:              uuid.New(),
+				LoanID:          loan.ID,
+				DocumentType:    "LOAN_AGREEMENT",
+				SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+				TemplateVersion: &version,
+			}
+			session := &models.SigningSession{
+				ID:                  uuid.New(),
+				LoanID:              loan.ID,
+				BorrowerID:          loan.BorrowerID,
+				AgreementDocumentID: agreement.ID,
+				AgreementSHA256:     agreement.SHA256,
+				PhoneNumber:         "081234567890",
+				ExpiresAt:           now.Add(3 * time.Minute),
+				Status:              "PENDING",
+				CreatedAt:           now.Add(-2 * time.Minute),
+			}
+			session.OTPHash = hashSigningOTP(session.ID, "482915")
+			session.Status = tt.status
+			session.ExpiresAt = now.Add(tt.expires)
+
+			signingRepo.On("GetSession", mock.Anything, session.ID).Return(session, nil)
+
+			result, err := signingUsecase.ConfirmSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+				&models.ConfirmSignatureRequest{SessionID: session.ID.String(), OTP: "482915"}, ClientInfo{IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0"})
+
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConfirmSignature_RejectsChangedAgreement(t *testing.T) {
+	signingRepo := mocksRepo.NewSigningRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	pdfGen := mocksPdf.NewPDFGenerator(t)
+	sender := &recordingSender{}
+	signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+	now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+	signingUsecase.now = func() time.Time { return now }
+
+	loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+	loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+	version := 2
+	agreement := &models.Document{
+		ID:              uuid.New(),
+		LoanID:          loan.ID,
+		DocumentType:    "LOAN_AGREEMENT",
+		SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TemplateVersion: &version,
+	}
+	session := &models.SigningSession{
+		ID:                  uuid.New(),
+		LoanID:              loan.ID,
+		BorrowerID:          loan.BorrowerID,
+		AgreementDocumentID: agreement.ID,
+		AgreementSHA256:     agreement.SHA256,
+		PhoneNumber:         "081234567890",
+		ExpiresAt:           now.Add(3 * time.Minute),
+		Status:              "PENDING",
+		CreatedAt:           now.Add(-2 * time.Minute),
+	}
+	session.OTPHash = hashSigningOTP(session.ID, "482915")
+	regenerated := *agreement
+	regenerated.ID = uuid.New()
+
+	signingRepo.On("GetSession", mock.Anything, session.ID).Return(session, nil)
+	signingRepo.On("GetLoanAgreement", mock.Anything, loan.ID).Return(&regenerated, nil)
+
+	result, err := signingUsecase.ConfirmSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		&models.ConfirmSignatureRequest{SessionID: session.ID.String(), OTP: "482915"}, ClientInfo{IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0"})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "agreement has changed")
+}
+
+func TestConfirmSignature_OtherBorrowersSessionNotFound(t *testing.T) {
+	signingRepo := mocksRepo.NewSigningRepository(t)
+	loanRepo := mocksRepo.NewLoanRepository(t)
+	templateRepo := mocksRepo.NewAgreementTemplateRepository(t)
+	pdfGen := mocksPdf.NewPDFGenerator(t)
+	sender := &recordingSender{}
+	signingUsecase := NewSigningUsecase(signingRepo, loanRepo, templateRepo, pdfGen, sender, SigningConfig{MaxAttempts: 3}).(*signingUsecase)
+	now := time.Date(2025, 7, 4, 9, 0, 0, 0, time.UTC)
+	signingUsecase.now = func() time.Time { return now }
+
+	loan := &models.LoanForApproval{ID: uuid.New(), BorrowerID: uuid.New(), BorrowerName: "Siti Rahma", CurrentState: "INVESTED"}
+	loanRepo.On("GetLoanForApproval", mock.Anything, loan.ID).Return(loan, nil)
+	version := 2
+	agreement := &models.Document{
+		ID:              uuid.New(),
+		LoanID:          loan.ID,
+		DocumentType:    "LOAN_AGREEMENT",
+		SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TemplateVersion: &version,
+	}
+	session := &models.SigningSession{
+		ID:                  uuid.New(),
+		LoanID:              loan.ID,
+		BorrowerID:          loan.BorrowerID,
+		AgreementDocumentID: agreement.ID,
+		AgreementSHA256:     agreement.SHA256,
+		PhoneNumber:         "081234567890",
+		ExpiresAt:           now.Add(3 * time.Minute),
+		Status:              "PENDING",
+		CreatedAt:           now.Add(-2 * time.Minute),
+	}
+	session.OTPHash = hashSigningOTP(session.ID, "482915")
+
+	// The borrower's session, but for another of their loans
+	other := *session
+	other.LoanID = uuid.New()
+	signingRepo.On("GetSession", mock.Anything, session.ID).Return(&other, nil)
+
+	result, err := signingUsecase.ConfirmSignature(context.Background(), loan.ID.String(), loan.BorrowerID.String(),
+		&models.ConfirmSignatureRequest{SessionID: session.ID.String(), OTP: "482915"}, ClientInfo{IPAddress: "203.0.113.9", UserAgent: "Mozilla/5.0"})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "signing session not found")
+}
+
+func TestMaskPhoneNumber(t *testing.T) {
+	assert.Equal(t, "********7890", maskPhoneNumber("081234567890"))
+	assert.Equal(t, "123", maskPhoneNumber("123"))
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/upload"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DEFAULT_SURVEY_MAX_DISTANCE   = 500.0
+	DEFAULT_SURVEY_TIME_TOLERANCE = 12 * time.Hour
+
+	earthRadiusMeters = 6371000.0
+)
+
+// SurveyConfig sets how far a survey photo may be from the borrower's address
+// and from the claimed survey date before it is flagged.
+type SurveyConfig struct {
+	MaxDistanceMeters float64
+	// CaptureTimeTolerance widens the survey day on both sides
+	CaptureTimeTolerance time.Duration
+	// Location is the zone survey dates are given in, and the zone assumed for
+	// photos that do not record an offset
+	Location *time.Location
+}
+
+func (c SurveyConfig) withDefaults() SurveyConfig {
+	if c.MaxDistanceMeters <= 0 {
+		c.MaxDistanceMeters = DEFAULT_SURVEY_MAX_DISTANCE
+	}
+	if c.CaptureTimeTolerance <= 0 {
+		c.CaptureTimeTolerance = DEFAULT_SURVEY_TIME_TOLERANCE
+	}
+	if c.Location == nil {
+		c.Location = time.UTC
+	}
+	return c
+}
+
+// verifySurveyPhoto compares a photo's geotag and capture time with the
+// borrower's address and the survey date. Nothing is rejected, mismatches are
+// returned as flags for the approving officer.
+func verifySurveyPhoto(config SurveyConfig, document *models.Document, address *models.Coordinates, surveyDate time.Time) []models.SurveyFlag {
+	var flags []models.SurveyFlag
+	flag := func(flagType, detail string) {
+		flags = append(flags, models.SurveyFlag{
+			ID:         uuid.New(),
+			LoanID:     document.LoanID,
+			DocumentID: document.ID,
+			FlagType:   flagType,
+			Detail:     detail,
+			CreatedAt:  document.CreatedAt,
+		})
+	}
+
+	switch {
+	case document.Latitude == nil || document.Longitude == nil:
+		flag(constants.SURVEY_FLAG_GPS_MISSING, "photo has no GPS location")
+	case address == nil:
+		flag(constants.SURVEY_FLAG_ADDRESS_COORDINATES_MISSING, "borrower address has no coordinates to compare with")
+	default:
+		distance := distanceMeters(*document.Latitude, *document.Longitude, address.Latitude, address.Longitude)
+		if distance > config.MaxDistanceMeters {
+			flag(constants.SURVEY_FLAG_LOCATION_MISMATCH, fmt.Sprintf(
+				"photo taken %.0f m from the registered address at %.6f,%.6f, limit %.0f m",
+				distance, *document.Latitude, *document.Longitude, config.MaxDistanceMeters,
+			))
+		}
+	}
+
+	if document.CapturedAt == nil {
+		flag(constants.SURVEY_FLAG_CAPTURE_TIME_MISSING, "photo has no capture time")
+	} else {
+		from := surveyDate.Add(-config.CaptureTimeTolerance)
+		to := surveyDate.AddDate(0, 0, 1).Add(config.CaptureTimeTolerance)
+		if document.CapturedAt.Before(from) || !document.CapturedAt.Before(to) {
+			flag(constants.SURVEY_FLAG_CAPTURE_TIME_MISMATCH, fmt.Sprintf(
+				"photo taken %s, survey date %s",
+				document.CapturedAt.In(config.Location).Format("2006-01-02 15:04 MST"),
+				surveyDate.Format("2006-01-02"),
+			))
+		}
+	}
+
+	return flags
+}
+
+// applyPhotoMetadata copies what survey verification needs from the photo's
+// metadata, before it is stripped from the stored copy.
+func applyPhotoMetadata(document *models.Document, metadata upload.Metadata, location *time.Location) {
+	document.Latitude = metadata.Latitude
+	document.Longitude = metadata.Longitude
+	if capturedAt, ok := metadata.CapturedAt(location); ok {
+		document.CapturedAt = capturedAt
+	}
+}
+
+// distanceMeters is the haversine distance between two points.
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
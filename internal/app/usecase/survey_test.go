@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSurveyConfig = SurveyConfig{
+	MaxDistanceMeters:    500,
+	CaptureTimeTolerance: 12 * time.Hour,
+	Location:             time.FixedZone("WIB", 7*3600),
+}
+
+func surveyPhoto(latitude, longitude float64, capturedAt time.Time) *models.Document {
+	return &models.Document{
+		ID:         uuid.New(),
+		LoanID:     uuid.New(),
+		Latitude:   &latitude,
+		Longitude:  &longitude,
+		CapturedAt: &capturedAt,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func flagTypes(flags []models.SurveyFlag) []string {
+	types := []string{}
+	for _, flag := range flags {
+		types = append(types, flag.FlagType)
+	}
+	return types
+}
+
+func TestDistanceMeters(t *testing.T) {
+	// A degree of latitude is about 111 km anywhere
+	assert.InDelta(t, 111195, distanceMeters(-6, 106.8, -7, 106.8), 1)
+	// A degree of longitude shrinks with the cosine of the latitude
+	assert.InDelta(t, 110586, distanceMeters(-6, 106, -6, 107), 10)
+	assert.Zero(t, distanceMeters(-6.2, 106.8, -6.2, 106.8))
+}
+
+func TestVerifySurveyPhoto(t *testing.T) {
+	address := &models.Coordinates{Latitude: -6.288896, Longitude: 106.717952}
+	surveyDate := time.Date(2025, 6, 15, 0, 0, 0, 0, testSurveyConfig.Location)
+	onSurveyDay := time.Date(2025, 6, 15, 10, 30, 0, 0, testSurveyConfig.Location)
+
+	cases := map[string]struct {
+		document *models.Document
+		address  *models.Coordinates
+		expected []string
+	}{
+		"matches": {
+			document: surveyPhoto(-6.289500, 106.718500, onSurveyDay),
+			address:  address,
+			expected: []string{},
+		},
+		"taken across town": {
+			document: surveyPhoto(-6.238270, 106.975573, onSurveyDay),
+			address:  address,
+			expected: []string{"LOCATION_MISMATCH"},
+		},
+		"taken the evening before, within tolerance": {
+			document: surveyPhoto(-6.289500, 106.718500, surveyDate.Add(-6*time.Hour)),
+			address:  address,
+			expected: []string{},
+		},
+		"taken a week earlier": {
+			document: surveyPhoto(-6.289500, 106.718500, surveyDate.AddDate(0, 0, -7)),
+			address:  address,
+			expected: []string{"CAPTURE_TIME_MISMATCH"},
+		},
+		"taken the day after, past tolerance": {
+			document: surveyPhoto(-6.289500, 106.718500, surveyDate.Add(36*time.Hour)),
+			address:  address,
+			expected: []string{"CAPTURE_TIME_MISMATCH"},
+		},
+		"borrower address not geocoded": {
+			document: surveyPhoto(-6.289500, 106.718500, onSurveyDay),
+			expected: []string{"ADDRESS_COORDINATES_MISSING"},
+		},
+		"metadata stripped before upload": {
+			document: &models.Document{ID: uuid.New()},
+			address:  address,
+			expected: []string{"GPS_MISSING", "CAPTURE_TIME_MISSING"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			flags := verifySurveyPhoto(testSurveyConfig, tc.document, tc.address, surveyDate)
+
+			assert.Equal(t, tc.expected, flagTypes(flags))
+			for _, flag := range flags {
+				assert.Equal(t, tc.document.ID, flag.DocumentID)
+				assert.NotEmpty(t, flag.Detail)
+			}
+		})
+	}
+}
+
+func TestVerifySurveyPhoto_DetailExplainsMismatch(t *testing.T) {
+	surveyDate := time.Date(2025, 6, 15, 0, 0, 0, 0, testSurveyConfig.Location)
+	document := surveyPhoto(-6.238270, 106.975573, time.Date(2025, 6, 1, 2, 0, 0, 0, time.UTC))
+
+	flags := verifySurveyPhoto(testSurveyConfig, document, &models.Coordinates{Latitude: -6.288896, Longitude: 106.717952}, surveyDate)
+
+	require.Len(t, flags, 2)
+	assert.Contains(t, flags[0].Detail, "limit 500 m")
+	assert.Equal(t, "photo taken 2025-06-01 09:00 WIB, survey date 2025-06-15", flags[1].Detail)
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WEBHOOK_SECRET_PREFIX = "whsec"
+	// webhookDeliveryListLimit is how many of the latest deliveries are listed
+	webhookDeliveryListLimit = 100
+)
+
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, employeeID string, req *models.CreateWebhookRequest) (*models.WebhookResponse, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, subscriptionID string) (*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscriptionID string, req *models.UpdateWebhookRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	ListDeliveries(ctx context.Context, subscriptionID, status string) ([]models.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDeliveryLog, error)
+	// Redeliver sends a delivery again, whatever its state, with a fresh set
+	// of attempts.
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+type webhookUsecase struct {
+	webhookRepo repositories.WebhookRepository
+	now         func() time.Time
+}
+
+func NewWebhookUsecase(webhookRepo repositories.WebhookRepository) WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepo: webhookRepo,
+		now:         time.Now,
+	}
+}
+
+func (u *webhookUsecase) CreateSubscription(ctx context.Context, employeeID string, req *models.CreateWebhookRequest) (*models.WebhookResponse, error) {
+	employeeUUID, err := uuid.Parse(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID")
+	}
+
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := u.now()
+	subscription := &models.WebhookSubscription{
+		ID:          uuid.New(),
+		URL:         req.URL,
+		EventTypes:  normalizeEventTypes(req.EventTypes),
+		Secret:      secret,
+		Description: req.Description,
+		IsActive:    true,
+		CreatedByID: &employeeUUID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookResponse{
+		Secret:       secret,
+		Subscription: *subscription,
+	}, nil
+}
+
+func (u *webhookUsecase) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return u.webhookRepo.ListSubscriptions(ctx)
+}
+
+func (u *webhookUsecase) GetSubscription(ctx context.Context, subscriptionID string) (*models.WebhookSubscription, error) {
+	subscriptionUUID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook subscription ID")
+	}
+
+	return u.webhookRepo.GetSubscription(ctx, subscriptionUUID)
+}
+
+func (u *webhookUsecase) UpdateSubscription(ctx context.Context, subscriptionID string, req *models.UpdateWebhookRequest) (*models.WebhookSubscription, error) {
+	subscription, err := u.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		subscription.URL = *req.URL
+	}
+	if len(req.EventTypes) > 0 {
+		subscription.EventTypes = normalizeEventTypes(req.EventTypes)
+	}
+	if req.Description != nil {
+		subscription.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+	subscription.UpdatedAt = u.now()
+
+	if err := u.webhookRepo.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (u *webhookUsecase) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	subscriptionUUID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return fmt.Errorf("invalid webhook subscription ID")
+	}
+
+	return u.webhookRepo.DeleteSubscription(ctx, subscriptionUUID, u.now())
+}
+
+func (u *webhookUsecase) ListDeliveries(ctx context.Context, subscriptionID, status string) ([]models.WebhookDelivery, error) {
+	subscription, err := u.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "", constants.WEBHOOK_PENDING, constants.WEBHOOK_RETRYING, constants.WEBHOOK_DELIVERED, constants.WEBHOOK_DEAD:
+	default:
+		return nil, fmt.Errorf("invalid delivery status: %s", status)
+	}
+
+	return u.webhookRepo.ListDeliveries(ctx, subscription.ID, status, webhookDeliveryListLimit)
+}
+
+func (u *webhookUsecase) GetDelivery(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDeliveryLog, error) {
+	subscriptionUUID, deliveryUUID, err := parseDeliveryIDs(subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := u.webhookRepo.GetDelivery(ctx, subscriptionUUID, deliveryUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := u.webhookRepo.ListAttempts(ctx, deliveryUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookDeliveryLog{
+		Delivery: *delivery,
+		Attempts: attempts,
+	}, nil
+}
+
+func (u *webhookUsecase) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDelivery, error) {
+	subscription, err := u.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryUUID, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook delivery ID")
+	}
+
+	if !subscription.IsActive {
+		return nil, fmt.Errorf("webhook subscription is inactive")
+	}
+
+	if err := u.webhookRepo.Redeliver(ctx, subscription.ID, deliveryUUID, u.now()); err != nil {
+		return nil, err
+	}
+
+	return u.webhookRepo.GetDelivery(ctx, subscription.ID, deliveryUUID)
+}
+
+func parseDeliveryIDs(subscriptionID, deliveryID string) (uuid.UUID, uuid.UUID, error) {
+	subscriptionUUID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid webhook subscription ID")
+	}
+
+	deliveryUUID, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid webhook delivery ID")
+	}
+
+	return subscriptionUUID, deliveryUUID, nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook URL: must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// normalizeEventTypes drops duplicates, a subscription to every event is
+// stored as just "*".
+func normalizeEventTypes(eventTypes []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, eventType := range eventTypes {
+		if eventType == constants.WEBHOOK_ALL_EVENTS {
+			return []string{constants.WEBHOOK_ALL_EVENTS}
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized
+}
+
+func generateWebhookSecret() (string, error) {
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return WEBHOOK_SECRET_PREFIX + "_" + hex.EncodeToString(secretBytes), nil
+}
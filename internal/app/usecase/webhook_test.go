@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateSubscription_GeneratesSecret(t *testing.T) {
+	webhookRepo := mocksRepo.NewWebhookRepository(t)
+	webhookUsecase := NewWebhookUsecase(webhookRepo)
+
+	var stored *models.WebhookSubscription
+	webhookRepo.On("CreateSubscription", mock.Anything, mock.AnythingOfType("*models.WebhookSubscription")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.WebhookSubscription) }).
+		Return(nil)
+
+	response, err := webhookUsecase.CreateSubscription(context.Background(), uuid.New().String(), &models.CreateWebhookRequest{
+		URL:        "https://accounting.example.com/hooks/loans",
+		EventTypes: []string{"LoanDisbursed", "LoanApproved", "LoanDisbursed"},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(response.Secret, "whsec_"))
+	assert.Equal(t, response.Secret, stored.Secret)
+	assert.Equal(t, []string{"LoanDisbursed", "LoanApproved"}, stored.EventTypes)
+	assert.True(t, stored.IsActive)
+}
+
+func TestCreateSubscription_WildcardCoversEveryEvent(t *testing.T) {
+	webhookRepo := mocksRepo.NewWebhookRepository(t)
+	webhookUsecase := NewWebhookUsecase(webhookRepo)
+
+	webhookRepo.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(subscription *models.WebhookSubscription) bool {
+		return len(subscription.EventTypes) == 1 && subscription.EventTypes[0] == "*" &&
+			subscription.Secret == "partner-provided-secret"
+	})).Return(nil)
+
+	response, err := webhookUsecase.CreateSubscription(context.Background(), uuid.New().String(), &models.CreateWebhookRequest{
+		URL:        "https://bank.example.com/webhooks",
+		EventTypes: []string{"LoanApproved", "*"},
+		Secret:     "partner-provided-secret",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "partner-provided-secret", response.Secret)
+}
+
+func TestCreateSubscription_RejectsNonHTTPURL(t *testing.T) {
+	webhookUsecase := NewWebhookUsecase(mocksRepo.NewWebhookRepository(t))
+
+	for _, url := range []string{"ftp://example.com/hook", "/relative/hook", "https://"} {
+		response, err := webhookUsecase.CreateSubscription(context.Background(), uuid.New().String(), &models.CreateWebhookRequest{
+			URL:        url,
+			EventTypes: []string{"*"},
+		})
+
+		assert.Nil(t, response)
+		assert.ErrorContains(t, err, "invalid webhook URL")
+	}
+}
+
+func TestRedeliver_ResetsDelivery(t *testing.T) {
+	webhookRepo := mocksRepo.NewWebhookRepository(t)
+	webhookUsecase := NewWebhookUsecase(webhookRepo)
+
+	subscriptionID := uuid.New()
+	deliveryID := uuid.New()
+	webhookRepo.On("GetSubscription", mock.Anything, subscriptionID).
+		Return(&models.WebhookSubscription{ID: subscriptionID, IsActive: true}, nil)
+	webhookRepo.On("Redeliver", mock.Anything, subscriptionID, deliveryID, mock.AnythingOfType("time.Time")).Return(nil)
+	webhookRepo.On("GetDelivery", mock.Anything, subscriptionID, deliveryID).
+		Return(&models.WebhookDelivery{ID: deliveryID, Status: "PENDING"}, nil)
+
+	delivery, err := webhookUsecase.Redeliver(context.Background(), subscriptionID.String(), deliveryID.String())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "PENDING", delivery.Status)
+}
+
+func TestRedeliver_InactiveSubscription(t *testing.T) {
+	webhookRepo := mocksRepo.NewWebhookRepository(t)
+	webhookUsecase := NewWebhookUsecase(webhookRepo)
+
+	subscriptionID := uuid.New()
+	webhookRepo.On("GetSubscription", mock.Anything, subscriptionID).
+		Return(&models.WebhookSubscription{ID: subscriptionID, IsActive: false}, nil)
+
+	delivery, err := webhookUsecase.Redeliver(context.Background(), subscriptionID.String(), uuid.New().String())
+
+	assert.Nil(t, delivery)
+	assert.EqualError(t, err, "webhook subscription is inactive")
+	webhookRepo.AssertNotCalled(t, "Redeliver", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListDeliveries_InvalidStatus(t *testing.T) {
+	webhookRepo := mocksRepo.NewWebhookRepository(t)
+	webhookUsecase := NewWebhookUsecase(webhookRepo)
+
+	subscriptionID := uuid.New()
+	webhookRepo.On("GetSubscription", mock.Anything, subscriptionID).
+		Return(&models.WebhookSubscription{ID: subscriptionID, IsActive: true}, nil)
+
+	deliveries, err := webhookUsecase.ListDeliveries(context.Background(), subscriptionID.String(), "LOST")
+
+	assert.Nil(t, deliveries)
+	assert.EqualError(t, err, "invalid delivery status: LOST")
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/outbox"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Dispatcher sends queued webhook deliveries.
+type Dispatcher interface {
+	// DeliverPending sends due deliveries until none are left and returns how
+	// many went through. A delivery that fails is retried later, after
+	// MaxAttempts it is DEAD until redelivered by hand.
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+type Config struct {
+	// BatchSize is how many deliveries are claimed at once
+	BatchSize int
+	// Lease keeps claimed deliveries from other instances, it has to outlast
+	// a batch of timed out requests
+	Lease time.Duration
+	// Timeout of one request to a receiver
+	Timeout time.Duration
+	// MaxAttempts before a delivery is DEAD
+	MaxAttempts int
+	// RetryBase is the wait after the first failure, doubled on each one after
+	RetryBase time.Duration
+	// RetryMax caps the wait between attempts
+	RetryMax time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.BatchSize <= 0 {
+		c.BatchSize = 20
+	}
+	if c.Lease <= 0 {
+		c.Lease = 5 * time.Minute
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 8
+	}
+	if c.RetryBase <= 0 {
+		c.RetryBase = 30 * time.Second
+	}
+	if c.RetryMax <= 0 {
+		c.RetryMax = time.Hour
+	}
+	return c
+}
+
+type dispatcher struct {
+	repo   repositories.WebhookRepository
+	client *http.Client
+	config Config
+	now    func() time.Time
+}
+
+func NewDispatcher(repo repositories.WebhookRepository, config Config) Dispatcher {
+	config = config.withDefaults()
+	return &dispatcher{
+		repo: repo,
+		client: &http.Client{
+			Timeout: config.Timeout,
+			// A redirect is a failed delivery, the body is not sent on
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: config,
+		now:    time.Now,
+	}
+}
+
+func (d *dispatcher) DeliverPending(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		now := d.now()
+		deliveries, err := d.repo.ClaimDeliveries(ctx, now, now.Add(d.config.Lease), d.config.BatchSize)
+		if err != nil {
+			return delivered, err
+		}
+		if len(deliveries) == 0 {
+			return delivered, nil
+		}
+
+		for i := range deliveries {
+			attempt := d.send(ctx, &deliveries[i])
+			if err := d.repo.RecordAttempt(ctx, attempt); err != nil {
+				// The lease runs out and the delivery is sent again
+				return delivered, err
+			}
+			if attempt.Status == constants.WEBHOOK_DELIVERED {
+				delivered++
+			}
+		}
+	}
+}
+
+// send makes one request for delivery and works out what happens to it next.
+func (d *dispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) *models.WebhookAttempt {
+	start := d.now()
+	attempt := &models.WebhookAttempt{
+		DeliveryID:  delivery.ID,
+		AttemptedAt: start,
+	}
+
+	statusCode, err := d.post(ctx, delivery, start)
+	attempt.DurationMS = int(d.now().Sub(start).Milliseconds())
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+
+	if err == nil {
+		attempt.Status = constants.WEBHOOK_DELIVERED
+		return attempt
+	}
+
+	attempt.Error = err.Error()
+	attempts := delivery.Attempts + 1
+	if attempts >= d.config.MaxAttempts {
+		attempt.Status = constants.WEBHOOK_DEAD
+	} else {
+		attempt.Status = constants.WEBHOOK_RETRYING
+		nextAttemptAt := start.Add(outbox.RetryDelay(attempts, d.config.RetryBase, d.config.RetryMax))
+		attempt.NextAttemptAt = &nextAttemptAt
+	}
+
+	log.Warn().Err(err).
+		Str("delivery_id", delivery.ID.String()).
+		Str("subscription_id", delivery.SubscriptionID.String()).
+		Str("event_type", delivery.EventType).
+		Int("attempts", attempts).
+		Str("status", attempt.Status).
+		Msg("Failed to deliver webhook")
+
+	return attempt
+}
+
+// post sends the signed body, any 2xx response is a success.
+func (d *dispatcher) post(ctx context.Context, delivery *models.WebhookDelivery, sentAt time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	timestamp := sentAt.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "loan-engine-webhooks")
+	req.Header.Set(HeaderDeliveryID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	mocksRepo "github.com/fajar-andriansyah/loan-engine/internal/app/mocks/repositories"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "whsec_test_secret_value"
+
+// receiver is a partner endpoint that checks signatures like a real one would.
+type receiver struct {
+	server   *httptest.Server
+	status   int
+	received []map[string]interface{}
+	headers  []http.Header
+	badSigs  int
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	rcv := &receiver{status: status}
+	rcv.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if !Verify(testSecret, timestamp, body, r.Header.Get(HeaderSignature)) {
+			rcv.badSigs++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var payload map[string]interface{}
+		json.Unmarshal(body, &payload)
+		rcv.received = append(rcv.received, payload)
+		rcv.headers = append(rcv.headers, r.Header.Clone())
+		w.WriteHeader(rcv.status)
+	}))
+	t.Cleanup(rcv.server.Close)
+	return rcv
+}
+
+func newTestDispatcher(repo *mocksRepo.WebhookRepository, now time.Time) *dispatcher {
+	d := NewDispatcher(repo, Config{BatchSize: 10, Lease: time.Minute, Timeout: time.Second, MaxAttempts: 3, RetryBase: 30 * time.Second, RetryMax: time.Hour}).(*dispatcher)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func testDelivery(url string, attempts int) models.WebhookDelivery {
+	event := &models.OutboxEvent{
+		EventID:       uuid.New(),
+		AggregateType: "loan",
+		AggregateID:   uuid.New(),
+		EventType:     "LoanApproved",
+		Payload:       json.RawMessage(`{"loan_id":"x"}`),
+		OccurredAt:    time.Date(2025, 7, 7, 9, 0, 0, 0, time.UTC),
+	}
+	body, _ := json.Marshal(event)
+
+	return models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: uuid.New(),
+		EventID:        event.EventID,
+		EventType:      event.EventType,
+		Body:           body,
+		Status:         "PENDING",
+		Attempts:       attempts,
+		URL:            url,
+		Secret:         testSecret,
+	}
+}
+
+func TestDeliverPending_SignsAndDelivers(t *testing.T) {
+	repo := mocksRepo.NewWebhookRepository(t)
+	rcv := newReceiver(t, http.StatusNoContent)
+	now := time.Date(2025, 7, 7, 10, 0, 0, 0, time.UTC)
+	dispatcher := newTestDispatcher(repo, now)
+
+	delivery := testDelivery(rcv.server.URL, 0)
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{delivery}, nil).Once()
+	repo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(attempt *models.WebhookAttempt) bool {
+		return attempt.DeliveryID == delivery.ID && attempt.Status == "DELIVERED" &&
+			*attempt.StatusCode == http.StatusNoContent && attempt.Error == "" && attempt.NextAttemptAt == nil
+	})).Return(nil).Once()
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{}, nil).Once()
+
+	delivered, err := dispatcher.DeliverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 0, rcv.badSigs)
+	require.Len(t, rcv.received, 1)
+	assert.Equal(t, delivery.EventID.String(), rcv.received[0]["event_id"])
+	assert.Equal(t, "LoanApproved", rcv.received[0]["event_type"])
+	assert.Equal(t, delivery.ID.String(), rcv.headers[0].Get(HeaderDeliveryID))
+	assert.Equal(t, "LoanApproved", rcv.headers[0].Get(HeaderEvent))
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), rcv.headers[0].Get(HeaderTimestamp))
+}
+
+func TestDeliverPending_RetriesWithBackoff(t *testing.T) {
+	repo := mocksRepo.NewWebhookRepository(t)
+	rcv := newReceiver(t, http.StatusServiceUnavailable)
+	now := time.Date(2025, 7, 7, 10, 0, 0, 0, time.UTC)
+	dispatcher := newTestDispatcher(repo, now)
+
+	// Second failure waits twice the base
+	delivery := testDelivery(rcv.server.URL, 1)
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{delivery}, nil).Once()
+	repo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(attempt *models.WebhookAttempt) bool {
+		return attempt.Status == "RETRYING" && *attempt.StatusCode == http.StatusServiceUnavailable &&
+			attempt.NextAttemptAt.Equal(now.Add(time.Minute)) &&
+			attempt.Error == "receiver responded with status 503"
+	})).Return(nil).Once()
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{}, nil).Once()
+
+	delivered, err := dispatcher.DeliverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Len(t, rcv.received, 1)
+}
+
+func TestDeliverPending_DeadAfterMaxAttempts(t *testing.T) {
+	repo := mocksRepo.NewWebhookRepository(t)
+	now := time.Date(2025, 7, 7, 10, 0, 0, 0, time.UTC)
+	dispatcher := newTestDispatcher(repo, now)
+
+	// Nothing listens on a closed server
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	delivery := testDelivery(closed.URL, 2)
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{delivery}, nil).Once()
+	repo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(attempt *models.WebhookAttempt) bool {
+		return attempt.Status == "DEAD" && attempt.StatusCode == nil && attempt.Error != "" && attempt.NextAttemptAt == nil
+	})).Return(nil).Once()
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{}, nil).Once()
+
+	delivered, err := dispatcher.DeliverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+}
+
+func TestDeliverPending_RedirectIsNotFollowed(t *testing.T) {
+	repo := mocksRepo.NewWebhookRepository(t)
+	rcv := newReceiver(t, http.StatusOK)
+	redirect := httptest.NewServer(http.RedirectHandler(rcv.server.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+	now := time.Date(2025, 7, 7, 10, 0, 0, 0, time.UTC)
+	dispatcher := newTestDispatcher(repo, now)
+
+	delivery := testDelivery(redirect.URL, 0)
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{delivery}, nil).Once()
+	repo.On("RecordAttempt", mock.Anything, mock.MatchedBy(func(attempt *models.WebhookAttempt) bool {
+		return attempt.Status == "RETRYING" && *attempt.StatusCode == http.StatusTemporaryRedirect
+	})).Return(nil).Once()
+	repo.On("ClaimDeliveries", mock.Anything, now, now.Add(time.Minute), 10).Return([]models.WebhookDelivery{}, nil).Once()
+
+	_, err := dispatcher.DeliverPending(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, rcv.received)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event_type":"LoanDisbursed"}`)
+	signature := Sign(testSecret, 1751882400, body)
+
+	assert.True(t, Verify(testSecret, 1751882400, body, signature))
+	assert.False(t, Verify("another_secret_value", 1751882400, body, signature))
+	assert.False(t, Verify(testSecret, 1751882401, body, signature))
+	assert.False(t, Verify(testSecret, 1751882400, []byte(`{"event_type":"LoanApproved"}`), signature))
+	assert.False(t, Verify(testSecret, 1751882400, body, signature[len("sha256="):]))
+}
+
+func TestPublish_QueuesEventEnvelope(t *testing.T) {
+	repo := mocksRepo.NewWebhookRepository(t)
+	now := time.Date(2025, 7, 7, 10, 0, 0, 0, time.UTC)
+	publisher := NewPublisher(repo).(*publisher)
+	publisher.now = func() time.Time { return now }
+
+	event := &models.OutboxEvent{
+		EventID:       uuid.New(),
+		AggregateType: "loan",
+		AggregateID:   uuid.New(),
+		EventType:     "LoanFullyFunded",
+		Payload:       json.RawMessage(`{"total_invested":5000000}`),
+	}
+	repo.On("EnqueueDeliveries", mock.Anything, event, mock.MatchedBy(func(body []byte) bool {
+		var envelope map[string]interface{}
+		return json.Unmarshal(body, &envelope) == nil &&
+			envelope["event_id"] == event.EventID.String() &&
+			envelope["aggregate_id"] == event.AggregateID.String() &&
+			envelope["payload"].(map[string]interface{})["total_invested"] == float64(5000000)
+	}), now).Return(2, nil)
+
+	assert.NoError(t, publisher.Publish(context.Background(), event))
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/outbox"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/repositories"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type publisher struct {
+	repo repositories.WebhookRepository
+	now  func() time.Time
+}
+
+// NewPublisher queues a delivery of each outbox event for every subscription
+// to its type. The dispatcher sends them, each with its own retries, so a
+// failing receiver never holds back the outbox.
+func NewPublisher(repo repositories.WebhookRepository) outbox.Publisher {
+	return &publisher{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (p *publisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook body: %w", err)
+	}
+
+	queued, err := p.repo.EnqueueDeliveries(ctx, event, body, p.now())
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		log.Debug().
+			Str("event_id", event.EventID.String()).
+			Str("event_type", event.EventType).
+			Int("deliveries", queued).
+			Msg("Webhook deliveries queued")
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package bankverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	DRIVER_FIXTURE = "fixture"
+)
+
+var (
+	ErrAccountNotFound = errors.New("bank account not found")
+	// ErrUnavailable means the bank could not be asked and the inquiry should
+	// be retried.
+	ErrUnavailable = errors.New("bank inquiry unavailable")
+)
+
+// Account is what the bank reports for an account number.
+type Account struct {
+	BankName          string `json:"bank_name"`
+	AccountNumber     string `json:"account_number"`
+	AccountHolderName string `json:"account_holder_name"`
+}
+
+// BankVerifier looks up the holder of a bank account before money is sent to
+// it.
+type BankVerifier interface {
+	Name() string
+	Inquire(ctx context.Context, bankName, accountNumber string) (*Account, error)
+}
+
+type Config struct {
+	Driver  string
+	Fixture FixtureConfig
+}
+
+// New returns the verifier selected by cfg.Driver. There is no bank
+// integration yet, the fixture verifier stands in for one.
+func New(cfg Config) (BankVerifier, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", DRIVER_FIXTURE:
+		return NewFixtureVerifier(cfg.Fixture)
+	default:
+		return nil, fmt.Errorf("unknown bank verifier driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,76 @@
+package bankverify
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, NameSimilarity("Siti Peminjam", "SITI PEMINJAM"))
+	assert.Equal(t, 1.0, NameSimilarity("Joko Wirausaha", "BPK. WIRAUSAHA JOKO"))
+	assert.Greater(t, NameSimilarity("Muhammad Rizky", "MUHAMAD RIZKI"), 0.8)
+	assert.Less(t, NameSimilarity("Siti Peminjam", "Budi Santoso"), 0.5)
+	assert.Equal(t, 0.0, NameSimilarity("Siti Peminjam", ""))
+	assert.Equal(t, 0.0, NameSimilarity("Ibu", "Siti"))
+}
+
+func writeFixture(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestFixtureVerifier_Inquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bank_accounts.json")
+	writeFixture(t, path, `[{"bank_name":"Bank BRI","account_number":"1122334455","account_holder_name":"SITI PEMINJAM"}]`)
+
+	verifier, err := New(Config{Driver: "fixture", Fixture: FixtureConfig{Path: path}})
+	require.NoError(t, err)
+
+	account, err := verifier.Inquire(context.Background(), "bank bri", "1122-334-455")
+	require.NoError(t, err)
+	assert.Equal(t, "SITI PEMINJAM", account.AccountHolderName)
+
+	_, err = verifier.Inquire(context.Background(), "Bank BNI", "1122334455")
+	assert.True(t, errors.Is(err, ErrAccountNotFound))
+}
+
+func TestFixtureVerifier_ReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bank_accounts.json")
+	writeFixture(t, path, `[]`)
+
+	verifier, err := NewFixtureVerifier(FixtureConfig{Path: path})
+	require.NoError(t, err)
+
+	_, err = verifier.Inquire(context.Background(), "Bank BNI", "5566778899")
+	assert.True(t, errors.Is(err, ErrAccountNotFound))
+
+	writeFixture(t, path, `[{"bank_name":"Bank BNI","account_number":"5566778899","account_holder_name":"JOKO WIRAUSAHA"}]`)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	account, err := verifier.Inquire(context.Background(), "Bank BNI", "5566778899")
+	require.NoError(t, err)
+	assert.Equal(t, "JOKO WIRAUSAHA", account.AccountHolderName)
+
+	// A broken file is an outage, not a missing account
+	writeFixture(t, path, `{`)
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	_, err = verifier.Inquire(context.Background(), "Bank BNI", "5566778899")
+	assert.True(t, errors.Is(err, ErrUnavailable))
+}
+
+func TestNew_RequiresFixture(t *testing.T) {
+	_, err := New(Config{Driver: "fixture", Fixture: FixtureConfig{Path: filepath.Join(t.TempDir(), "missing.json")}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Driver: "bca"})
+	assert.EqualError(t, err, "unknown bank verifier driver: bca")
+}
@@ -0,0 +1,94 @@
+package bankverify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+type FixtureConfig struct {
+	// Path of a JSON array of accounts, each with bank_name, account_number
+	// and account_holder_name
+	Path string
+}
+
+type fixtureVerifier struct {
+	path string
+	mu   sync.Mutex
+	// accounts by bank and account number, see fixtureKey
+	accounts map[string]Account
+	modTime  int64
+}
+
+// NewFixtureVerifier answers inquiries from the accounts in a local file. The
+// file is read again when it changes, so accounts can be added while the
+// service runs.
+func NewFixtureVerifier(config FixtureConfig) (BankVerifier, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("bank verifier fixture path is required")
+	}
+
+	v := &fixtureVerifier{path: config.Path}
+	if err := v.reload(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *fixtureVerifier) Name() string {
+	return DRIVER_FIXTURE
+}
+
+func (v *fixtureVerifier) Inquire(ctx context.Context, bankName, accountNumber string) (*Account, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.reload(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	account, ok := v.accounts[fixtureKey(bankName, accountNumber)]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	return &account, nil
+}
+
+// reload reads the file when it changed since the last read.
+func (v *fixtureVerifier) reload() error {
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to read bank account fixture: %w", err)
+	}
+	if v.accounts != nil && info.ModTime().UnixNano() == v.modTime {
+		return nil
+	}
+
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to read bank account fixture: %w", err)
+	}
+
+	var list []Account
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("invalid bank account fixture %s: %w", v.path, err)
+	}
+
+	accounts := make(map[string]Account, len(list))
+	for _, account := range list {
+		accounts[fixtureKey(account.BankName, account.AccountNumber)] = account
+	}
+	v.accounts = accounts
+	v.modTime = info.ModTime().UnixNano()
+	return nil
+}
+
+// fixtureKey ignores case and spacing in the bank name and separators in the
+// account number.
+func fixtureKey(bankName, accountNumber string) string {
+	number := strings.NewReplacer(" ", "", "-", "", ".", "").Replace(accountNumber)
+	return strings.ToUpper(strings.Join(strings.Fields(bankName), " ")) + "|" + number
+}
@@ -0,0 +1,82 @@
+package bankverify
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// nameTitles are honorifics banks add to or leave out of holder names.
+var nameTitles = map[string]bool{
+	"BPK": true, "BAPAK": true, "IBU": true, "SDR": true, "SDRI": true,
+	"TN": true, "NY": true, "NN": true, "H": true, "HJ": true,
+	"MR": true, "MRS": true, "MS": true, "DR": true, "IR": true,
+}
+
+// NameSimilarity scores how alike two person names are, from 0 for nothing in
+// common to 1 for the same name. Case, punctuation, titles and word order are
+// ignored, so "BPK. WIRAUSAHA JOKO" matches "Joko Wirausaha".
+func NameSimilarity(a, b string) float64 {
+	tokensA := nameTokens(a)
+	tokensB := nameTokens(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	score := similarity(strings.Join(tokensA, " "), strings.Join(tokensB, " "))
+
+	sort.Strings(tokensA)
+	sort.Strings(tokensB)
+	if sorted := similarity(strings.Join(tokensA, " "), strings.Join(tokensB, " ")); sorted > score {
+		score = sorted
+	}
+	return score
+}
+
+func nameTokens(name string) []string {
+	fields := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if !nameTitles[field] {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// similarity is one minus the edit distance relative to the longer string.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
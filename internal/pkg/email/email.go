@@ -0,0 +1,208 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const (
+	DRIVER_NONE = "none"
+	DRIVER_FILE = "file"
+	DRIVER_SMTP = "smtp"
+)
+
+var ErrNoRecipient = errors.New("message has no recipient")
+
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+type Message struct {
+	To      string
+	Subject string
+	// Body is plain text
+	Body        string
+	Attachments []Attachment
+}
+
+// Sender delivers email messages.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type Config struct {
+	Driver string
+	// From is the sender address, a bare address or "Name <address>"
+	From string
+	File FileConfig
+	SMTP SMTPConfig
+}
+
+type FileConfig struct {
+	// Dir receives one .eml file per message
+	Dir string
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// New returns the sender selected by cfg.Driver.
+func New(cfg Config) (Sender, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", DRIVER_NONE:
+		return NewNoopSender(), nil
+	case DRIVER_FILE:
+		return NewFileSender(cfg.From, cfg.File.Dir)
+	case DRIVER_SMTP:
+		return NewSMTPSender(cfg.From, cfg.SMTP)
+	default:
+		return nil, fmt.Errorf("unknown email driver: %s", cfg.Driver)
+	}
+}
+
+type noopSender struct{}
+
+// NewNoopSender drops every message.
+func NewNoopSender() Sender {
+	return noopSender{}
+}
+
+func (noopSender) Send(ctx context.Context, message Message) error {
+	if message.To == "" {
+		return ErrNoRecipient
+	}
+	return nil
+}
+
+func parseFrom(from string) (*mail.Address, error) {
+	if from == "" {
+		return nil, fmt.Errorf("email sender address is required")
+	}
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email sender address: %w", err)
+	}
+	return address, nil
+}
+
+// compose renders the message as RFC 5322 with MIME parts. Attachments make
+// it multipart/mixed, otherwise it is a single text part.
+func compose(from *mail.Address, message Message, date time.Time) ([]byte, error) {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+
+	if len(message.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, message.Body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": parts.Boundary()}))
+	buf.WriteString("\r\n")
+
+	text, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(text, message.Body); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range message.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": attachment.FileName})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, attachment.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 wraps lines at 76 characters as MIME requires.
+func writeBase64(w interface{ Write([]byte) (int, error) }, content []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+
+	return fmt.Sprintf("<%s@%s>", randomHex(12), domain)
+}
+
+func randomHex(n int) string {
+	random := make([]byte, n)
+	_, _ = rand.Read(random)
+	return hex.EncodeToString(random)
+}
@@ -0,0 +1,166 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_SelectsDriver(t *testing.T) {
+	sender, err := New(Config{})
+	require.NoError(t, err)
+	assert.Equal(t, noopSender{}, sender)
+
+	_, err = New(Config{Driver: "carrier-pigeon"})
+	assert.EqualError(t, err, "unknown email driver: carrier-pigeon")
+
+	_, err = New(Config{Driver: DRIVER_FILE, From: "not an address", File: FileConfig{Dir: t.TempDir()}})
+	assert.ErrorContains(t, err, "invalid email sender address")
+}
+
+func TestFileSender_WritesAttachments(t *testing.T) {
+	dir := t.TempDir()
+	sender, err := NewFileSender("Loan Engine <no-reply@loan-engine.local>", dir)
+	require.NoError(t, err)
+
+	pdf := []byte("%PDF-1.4\n" + strings.Repeat("x", 200))
+	err = sender.Send(context.Background(), Message{
+		To:      "Rina <rina.investor@gmail.com>",
+		Subject: "Perjanjian Investasi – Pinjaman",
+		Body:    "Yth. Rina,\nTerlampir perjanjian Anda.",
+		Attachments: []Attachment{
+			{FileName: "investment_agreement.pdf", ContentType: "application/pdf", Content: pdf},
+		},
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer raw.Close()
+
+	message, err := mail.ReadMessage(raw)
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Perjanjian Investasi – Pinjaman", subject)
+	assert.Equal(t, `"Rina" <rina.investor@gmail.com>`, message.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	text, err := parts.NextPart()
+	require.NoError(t, err)
+	body, err := io.ReadAll(text)
+	require.NoError(t, err)
+	assert.Equal(t, "Yth. Rina,\r\nTerlampir perjanjian Anda.", string(body))
+
+	attachment, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "investment_agreement.pdf", attachment.FileName())
+	assert.Equal(t, "base64", attachment.Header.Get("Content-Transfer-Encoding"))
+	content, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, attachment))
+	require.NoError(t, err)
+	assert.Equal(t, pdf, content)
+}
+
+func TestFileSender_RequiresRecipient(t *testing.T) {
+	sender, err := NewFileSender("no-reply@loan-engine.local", t.TempDir())
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, sender.Send(context.Background(), Message{Subject: "Hi"}), ErrNoRecipient)
+}
+
+// fakeSMTPServer accepts one message and returns what it received.
+func fakeSMTPServer(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 localhost ESMTP")
+
+		var transcript strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					transcript.WriteString(line)
+				}
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				received <- transcript.String()
+				return
+			default:
+				transcript.WriteString(line)
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestSMTPSender_DeliversMessage(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	portNumber, err := net.LookupPort("tcp", port)
+	require.NoError(t, err)
+
+	sender, err := NewSMTPSender("no-reply@loan-engine.local", SMTPConfig{Host: host, Port: portNumber})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), Message{To: "doni.kapital@gmail.com", Subject: "Pinjaman terdanai penuh", Body: "Pinjaman telah terdanai penuh."})
+	require.NoError(t, err)
+
+	transcript := <-received
+	assert.Contains(t, transcript, "MAIL FROM:<no-reply@loan-engine.local>")
+	assert.Contains(t, transcript, "RCPT TO:<doni.kapital@gmail.com>")
+	assert.Contains(t, transcript, "Subject: Pinjaman terdanai penuh")
+	assert.Contains(t, transcript, "Pinjaman telah terdanai penuh.")
+}
@@ -0,0 +1,52 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileSender struct {
+	from *mail.Address
+	dir  string
+}
+
+// NewFileSender writes every message to dir as an .eml file instead of
+// sending it, so mail can be opened in a mail client during development.
+func NewFileSender(from, dir string) (Sender, error) {
+	address, err := parseFrom(from)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("email directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create email directory: %w", err)
+	}
+
+	return &fileSender{from: address, dir: dir}, nil
+}
+
+func (s *fileSender) Send(ctx context.Context, message Message) error {
+	if message.To == "" {
+		return ErrNoRecipient
+	}
+
+	now := time.Now()
+	raw, err := compose(s.from, message, now)
+	if err != nil {
+		return err
+	}
+
+	// Names sort by time, the random part keeps messages in one instant apart
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), randomHex(4))
+	if err := os.WriteFile(filepath.Join(s.dir, name), raw, 0o600); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+type smtpSender struct {
+	from *mail.Address
+	addr string
+	auth smtp.Auth
+}
+
+// NewSMTPSender sends through an SMTP server, for example a local MailHog.
+// Credentials are optional; net/smtp only sends them over TLS or to
+// localhost.
+func NewSMTPSender(from string, cfg SMTPConfig) (Sender, error) {
+	address, err := parseFrom(from)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 25
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &smtpSender{
+		from: address,
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		auth: auth,
+	}, nil
+}
+
+func (s *smtpSender) Send(ctx context.Context, message Message) error {
+	if message.To == "" {
+		return ErrNoRecipient
+	}
+
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	raw, err := compose(s.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from.Address, []string{to.Address}, raw); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
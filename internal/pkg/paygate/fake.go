@@ -0,0 +1,86 @@
+package paygate
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FakeGateway plays the payment gateway locally: it signs notifications of
+// made-up payments the way the real one does and can send them.
+type FakeGateway struct {
+	secret string
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// NewPayment makes up a payment into virtualAccount paid now.
+func (g *FakeGateway) NewPayment(virtualAccount string, amount float64, payerName string) Payment {
+	idBytes := make([]byte, 8)
+	rand.Read(idBytes)
+
+	return Payment{
+		PaymentID:      "FAKE-" + hex.EncodeToString(idBytes),
+		VirtualAccount: virtualAccount,
+		Amount:         amount,
+		PayerName:      payerName,
+		PaidAt:         g.now().UTC().Truncate(time.Second),
+	}
+}
+
+// Notification is the body and headers notifying payment, signed at sentAt.
+func (g *FakeGateway) Notification(payment Payment, sentAt time.Time) ([]byte, http.Header, error) {
+	body, err := json.Marshal(payment)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode payment: %w", err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(HeaderTimestamp, strconv.FormatInt(sentAt.Unix(), 10))
+	header.Set(HeaderSignature, Sign(g.secret, sentAt.Unix(), body))
+	return body, header, nil
+}
+
+// Notify posts a notification of payment to url and returns the status code
+// and body of the response.
+func (g *FakeGateway) Notify(ctx context.Context, url string, payment Payment) (int, []byte, error) {
+	body, header, err := g.Notification(payment, g.now())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid notification request: %w", err)
+	}
+	req.Header = header
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp.StatusCode, response, nil
+}
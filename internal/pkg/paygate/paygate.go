@@ -0,0 +1,75 @@
+package paygate
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers the gateway signs its payment notifications with
+const (
+	HeaderTimestamp = "X-Callback-Timestamp"
+	HeaderSignature = "X-Callback-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+var (
+	ErrInvalidSignature = errors.New("invalid payment notification signature")
+	// ErrStaleTimestamp means the notification was signed too long ago, it is
+	// treated as a replay.
+	ErrStaleTimestamp = errors.New("payment notification timestamp out of tolerance")
+	ErrInvalidPayload = errors.New("invalid payment notification payload")
+)
+
+// Payment is the body of a notification: money paid into a virtual account.
+type Payment struct {
+	// PaymentID is the gateway's, unique per payment. Retried notifications of
+	// a payment repeat it.
+	PaymentID      string    `json:"payment_id"`
+	VirtualAccount string    `json:"virtual_account"`
+	Amount         float64   `json:"amount"`
+	PayerName      string    `json:"payer_name,omitempty"`
+	PaidAt         time.Time `json:"paid_at"`
+}
+
+// Sign is the signature header of body sent at timestamp (Unix seconds): the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the shared secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and the age of a notification and decodes it.
+// The timestamp may be at most tolerance away from now in either direction.
+func Verify(secret string, tolerance time.Duration, now time.Time, timestamp, signature string, body []byte) (*Payment, error) {
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || !strings.HasPrefix(signature, signaturePrefix) ||
+		!hmac.Equal([]byte(Sign(secret, sentAt, body)), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	age := now.Sub(time.Unix(sentAt, 0))
+	if age > tolerance || age < -tolerance {
+		return nil, ErrStaleTimestamp
+	}
+
+	var payment Payment
+	if err := json.Unmarshal(body, &payment); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if payment.PaymentID == "" || payment.VirtualAccount == "" || payment.Amount <= 0 || payment.PaidAt.IsZero() {
+		return nil, fmt.Errorf("%w: payment_id, virtual_account, amount and paid_at are required", ErrInvalidPayload)
+	}
+
+	return &payment, nil
+}
@@ -0,0 +1,95 @@
+package paygate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "gateway-shared-secret"
+
+func TestVerify_AcceptsSignedNotification(t *testing.T) {
+	gateway := NewFakeGateway(testSecret)
+	now := time.Date(2025, 7, 10, 9, 0, 0, 0, time.UTC)
+	payment := gateway.NewPayment("888100000000001", 450000, "SITI PEMINJAM")
+
+	body, header, err := gateway.Notification(payment, now)
+	require.NoError(t, err)
+
+	verified, err := Verify(testSecret, 5*time.Minute, now.Add(time.Minute), header.Get(HeaderTimestamp), header.Get(HeaderSignature), body)
+
+	require.NoError(t, err)
+	assert.Equal(t, payment.PaymentID, verified.PaymentID)
+	assert.Equal(t, "888100000000001", verified.VirtualAccount)
+	assert.Equal(t, 450000.0, verified.Amount)
+}
+
+func TestVerify_RejectsTampering(t *testing.T) {
+	gateway := NewFakeGateway(testSecret)
+	now := time.Date(2025, 7, 10, 9, 0, 0, 0, time.UTC)
+	body, header, err := gateway.Notification(gateway.NewPayment("888100000000001", 450000, ""), now)
+	require.NoError(t, err)
+
+	tampered := []byte(string(body[:len(body)-1]) + " ")
+	_, err = Verify(testSecret, 5*time.Minute, now, header.Get(HeaderTimestamp), header.Get(HeaderSignature), tampered)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	_, err = Verify("another-secret", 5*time.Minute, now, header.Get(HeaderTimestamp), header.Get(HeaderSignature), body)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	// Moving the timestamp breaks the signature
+	_, err = Verify(testSecret, 5*time.Minute, now, strconv.FormatInt(now.Unix()+60, 10), header.Get(HeaderSignature), body)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+}
+
+func TestVerify_RejectsReplayedNotification(t *testing.T) {
+	gateway := NewFakeGateway(testSecret)
+	sentAt := time.Date(2025, 7, 10, 9, 0, 0, 0, time.UTC)
+	body, header, err := gateway.Notification(gateway.NewPayment("888100000000001", 450000, ""), sentAt)
+	require.NoError(t, err)
+
+	_, err = Verify(testSecret, 5*time.Minute, sentAt.Add(6*time.Minute), header.Get(HeaderTimestamp), header.Get(HeaderSignature), body)
+
+	assert.True(t, errors.Is(err, ErrStaleTimestamp))
+}
+
+func TestVerify_RequiresPaymentFields(t *testing.T) {
+	now := time.Date(2025, 7, 10, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"payment_id":"P-1","virtual_account":"888100000000001","amount":0,"paid_at":"2025-07-10T09:00:00Z"}`)
+
+	_, err := Verify(testSecret, time.Minute, now, "1752138000", Sign(testSecret, 1752138000, body), body)
+
+	assert.True(t, errors.Is(err, ErrInvalidPayload))
+}
+
+func TestFakeGateway_Notify(t *testing.T) {
+	gateway := NewFakeGateway(testSecret)
+	var received *Payment
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payment, err := Verify(testSecret, time.Minute, time.Now(), r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = payment
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	status, response, err := gateway.Notify(context.Background(), server.URL, gateway.NewPayment("888100000000002", 100000, "JOKO"))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"ok":true}`, string(response))
+	require.NotNil(t, received)
+	assert.Equal(t, "888100000000002", received.VirtualAccount)
+}
@@ -0,0 +1,80 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DRIVER_SIMULATED = "simulated"
+)
+
+// Transfer states. A transfer is PENDING until the provider reports SUCCESS
+// or FAILED through the callback.
+const (
+	STATUS_PENDING = "PENDING"
+	STATUS_SUCCESS = "SUCCESS"
+	STATUS_FAILED  = "FAILED"
+)
+
+var (
+	ErrInvalidAccount = errors.New("transfer has no destination account")
+	ErrInvalidAmount  = errors.New("transfer amount must be positive")
+)
+
+// IsRejected reports whether Transfer failed because the provider refused the
+// request, so no transfer was made. Any other error leaves the outcome
+// unknown: the provider may have accepted the transfer before a timeout or a
+// lost connection.
+func IsRejected(err error) bool {
+	return errors.Is(err, ErrInvalidAccount) || errors.Is(err, ErrInvalidAmount)
+}
+
+type TransferRequest struct {
+	// Reference is ours, the provider makes one transfer per reference
+	Reference         string
+	Amount            float64
+	BankName          string
+	AccountNumber     string
+	AccountHolderName string
+	Description       string
+}
+
+type Transfer struct {
+	Reference         string
+	ProviderReference string
+	Status            string
+	FailureReason     string
+	UpdatedAt         time.Time
+}
+
+// Callback receives the outcome of a transfer. It may be called more than
+// once for the same transfer.
+type Callback func(ctx context.Context, transfer Transfer) error
+
+// Provider moves money to bank accounts. Transfers are asynchronous: Transfer
+// returns once the provider has accepted the request and the outcome arrives
+// later through the Callback the provider was built with.
+type Provider interface {
+	Name() string
+	Transfer(ctx context.Context, req TransferRequest) (*Transfer, error)
+}
+
+type Config struct {
+	Driver    string
+	Simulated SimulatedConfig
+}
+
+// New returns the provider selected by cfg.Driver. There is no bank
+// integration yet, the simulated provider stands in for one.
+func New(cfg Config, callback Callback) (Provider, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", DRIVER_SIMULATED:
+		return NewSimulatedProvider(cfg.Simulated, callback), nil
+	default:
+		return nil, fmt.Errorf("unknown payout driver: %s", cfg.Driver)
+	}
+}
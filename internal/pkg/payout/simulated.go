@@ -0,0 +1,109 @@
+package payout
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type SimulatedConfig struct {
+	// Delay before a transfer settles
+	Delay time.Duration
+	// FailAccounts are account numbers whose transfers are rejected, to try
+	// out failed disbursements
+	FailAccounts []string
+}
+
+type simulatedProvider struct {
+	mu        sync.Mutex
+	config    SimulatedConfig
+	callback  Callback
+	transfers map[string]*Transfer
+	now       func() time.Time
+}
+
+// NewSimulatedProvider accepts every transfer and settles it after
+// config.Delay, successfully unless the account is in config.FailAccounts.
+// Transfers only live in memory.
+func NewSimulatedProvider(config SimulatedConfig, callback Callback) Provider {
+	return &simulatedProvider{
+		config:    config,
+		callback:  callback,
+		transfers: map[string]*Transfer{},
+		now:       time.Now,
+	}
+}
+
+func (p *simulatedProvider) Name() string {
+	return DRIVER_SIMULATED
+}
+
+func (p *simulatedProvider) Transfer(ctx context.Context, req TransferRequest) (*Transfer, error) {
+	if req.AccountNumber == "" || req.BankName == "" {
+		return nil, ErrInvalidAccount
+	}
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A repeated request gets the transfer made the first time
+	if existing, ok := p.transfers[req.Reference]; ok {
+		transfer := *existing
+		return &transfer, nil
+	}
+
+	referenceBytes := make([]byte, 8)
+	if _, err := rand.Read(referenceBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate transfer reference: %w", err)
+	}
+
+	transfer := &Transfer{
+		Reference:         req.Reference,
+		ProviderReference: "SIM-" + hex.EncodeToString(referenceBytes),
+		Status:            STATUS_PENDING,
+		UpdatedAt:         p.now(),
+	}
+	p.transfers[req.Reference] = transfer
+
+	failed := false
+	for _, account := range p.config.FailAccounts {
+		if account == req.AccountNumber {
+			failed = true
+		}
+	}
+	time.AfterFunc(p.config.Delay, func() { p.settle(req.Reference, failed) })
+
+	accepted := *transfer
+	return &accepted, nil
+}
+
+func (p *simulatedProvider) settle(reference string, failed bool) {
+	p.mu.Lock()
+	transfer := p.transfers[reference]
+	transfer.Status = STATUS_SUCCESS
+	if failed {
+		transfer.Status = STATUS_FAILED
+		transfer.FailureReason = "account rejected by beneficiary bank"
+	}
+	transfer.UpdatedAt = p.now()
+	settled := *transfer
+	p.mu.Unlock()
+
+	if p.callback == nil {
+		return
+	}
+	if err := p.callback(context.Background(), settled); err != nil {
+		log.Error().Err(err).
+			Str("reference", settled.Reference).
+			Str("status", settled.Status).
+			Msg("Failed to handle payout callback")
+	}
+}
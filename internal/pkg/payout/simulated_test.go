@@ -0,0 +1,100 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRequest(reference, account string) TransferRequest {
+	return TransferRequest{
+		Reference:         reference,
+		Amount:            5000000,
+		BankName:          "BCA",
+		AccountNumber:     account,
+		AccountHolderName: "Budi Santoso",
+	}
+}
+
+// newTestProvider returns a provider whose callbacks arrive on the channel.
+func newTestProvider(t *testing.T, config SimulatedConfig) (Provider, chan Transfer) {
+	settled := make(chan Transfer, 4)
+	provider, err := New(Config{Driver: "simulated", Simulated: config}, func(ctx context.Context, transfer Transfer) error {
+		settled <- transfer
+		return nil
+	})
+	require.NoError(t, err)
+	return provider, settled
+}
+
+func waitForCallback(t *testing.T, settled chan Transfer) Transfer {
+	select {
+	case transfer := <-settled:
+		return transfer
+	case <-time.After(time.Second):
+		t.Fatal("transfer was not settled")
+		return Transfer{}
+	}
+}
+
+func TestSimulated_SettlesSuccessfully(t *testing.T) {
+	provider, settled := newTestProvider(t, SimulatedConfig{Delay: 10 * time.Millisecond})
+
+	transfer, err := provider.Transfer(context.Background(), testRequest("payout-1", "1234567890"))
+	require.NoError(t, err)
+	assert.Equal(t, STATUS_PENDING, transfer.Status)
+	assert.True(t, strings.HasPrefix(transfer.ProviderReference, "SIM-"))
+
+	result := waitForCallback(t, settled)
+	assert.Equal(t, "payout-1", result.Reference)
+	assert.Equal(t, transfer.ProviderReference, result.ProviderReference)
+	assert.Equal(t, STATUS_SUCCESS, result.Status)
+	assert.Empty(t, result.FailureReason)
+}
+
+func TestSimulated_FailsListedAccounts(t *testing.T) {
+	provider, settled := newTestProvider(t, SimulatedConfig{FailAccounts: []string{"0000000000"}})
+
+	_, err := provider.Transfer(context.Background(), testRequest("payout-2", "0000000000"))
+	require.NoError(t, err)
+
+	result := waitForCallback(t, settled)
+	assert.Equal(t, STATUS_FAILED, result.Status)
+	assert.Equal(t, "account rejected by beneficiary bank", result.FailureReason)
+}
+
+func TestSimulated_SameReferenceIsOneTransfer(t *testing.T) {
+	provider, settled := newTestProvider(t, SimulatedConfig{Delay: 50 * time.Millisecond})
+
+	first, err := provider.Transfer(context.Background(), testRequest("payout-3", "1234567890"))
+	require.NoError(t, err)
+	second, err := provider.Transfer(context.Background(), testRequest("payout-3", "1234567890"))
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ProviderReference, second.ProviderReference)
+	waitForCallback(t, settled)
+	select {
+	case <-settled:
+		t.Fatal("transfer was settled twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSimulated_RequiresAccount(t *testing.T) {
+	provider, _ := newTestProvider(t, SimulatedConfig{})
+
+	_, err := provider.Transfer(context.Background(), testRequest("payout-4", ""))
+
+	assert.True(t, errors.Is(err, ErrInvalidAccount))
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := New(Config{Driver: "wire"}, nil)
+
+	assert.EqualError(t, err, "unknown payout driver: wire")
+}
@@ -0,0 +1,103 @@
+package pdf
+
+import (
+	"fmt"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	auditLabelWidth = 62.0
+	auditTimeLayout = "2006-01-02 15:04:05 -0700"
+)
+
+// renderAuditPage closes an electronically signed agreement with the evidence
+// of the signature. It binds the signature to the agreement the borrower
+// reviewed by its verification ID and hash.
+func renderAuditPage(pdf *gofpdf.Fpdf, audit *models2.SignatureAudit) {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 8, "JEJAK AUDIT TANDA TANGAN ELEKTRONIK", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "I", 11)
+	pdf.CellFormat(0, 6, "Electronic Signature Audit Trail", "", 1, "C", false, 0, "")
+	pdf.Ln(6)
+
+	templateVersion := "-"
+	if audit.Agreement.TemplateVersion != nil {
+		templateVersion = fmt.Sprintf("%d", *audit.Agreement.TemplateVersion)
+	}
+
+	rows := []struct {
+		label string
+		value string
+	}{
+		{"Penandatangan / Signer", audit.BorrowerName},
+		{"Metode / Method", "OTP SMS ke nomor terdaftar / SMS OTP to the registered number"},
+		{"Nomor telepon / Phone number", audit.PhoneNumber},
+		{"ID sesi / Session ID", audit.SessionID.String()},
+		{"Perjanjian yang ditinjau / Reviewed agreement", audit.Agreement.VerificationID},
+		{"SHA-256 perjanjian / Agreement SHA-256", audit.Agreement.SHA256},
+		{"Versi templat / Template version", templateVersion},
+		{"Perjanjian dibuat / Agreement issued", audit.Agreement.CreatedAt.Format(auditTimeLayout)},
+		{"OTP dikirim / OTP sent", audit.OTPSentAt.Format(auditTimeLayout)},
+		{"Ditandatangani / Signed", audit.SignedAt.Format(auditTimeLayout)},
+		{"Alamat IP / IP address", audit.IPAddress},
+		{"Perangkat / User agent", audit.UserAgent},
+	}
+
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	valueWidth := pageWidth - left - right - auditLabelWidth
+
+	for _, row := range rows {
+		value := row.value
+		if value == "" {
+			value = "-"
+		}
+
+		// Labels and values may wrap, the row is as tall as the taller one
+		pdf.SetFont("Arial", "B", 9)
+		labelLines := pdf.SplitLines([]byte(tr(row.label)), auditLabelWidth-2)
+		pdf.SetFont("Arial", "", 9)
+		valueLines := pdf.SplitLines([]byte(tr(value)), valueWidth-2)
+		height := 5.0 * float64(max(len(labelLines), len(valueLines)))
+
+		_, pageHeight := pdf.GetPageSize()
+		_, _, _, bottom := pdf.GetMargins()
+		if pdf.GetY()+height > pageHeight-bottom {
+			pdf.AddPage()
+		}
+
+		y := pdf.GetY()
+		pdf.SetFont("Arial", "B", 9)
+		pdf.MultiCell(auditLabelWidth, 5, tr(row.label), "", "L", false)
+		pdf.SetXY(left+auditLabelWidth, y)
+		pdf.SetFont("Arial", "", 9)
+		pdf.MultiCell(valueWidth, 5, tr(value), "", "L", false)
+		pdf.SetXY(left, y+height+1.5)
+	}
+
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "", 10)
+	pdf.MultiCell(0, 5, tr(fmt.Sprintf(
+		"Peminjam menyetujui dan menandatangani perjanjian dengan SHA-256 di atas dengan memasukkan kode OTP yang dikirim ke nomor telepon terdaftar pada %s.",
+		audit.SignedAt.Format(auditTimeLayout))), "", "L", false)
+	pdf.Ln(2)
+	pdf.SetFont("Arial", "I", 10)
+	pdf.MultiCell(0, 5, tr(fmt.Sprintf(
+		"The borrower agreed to and signed the agreement with the SHA-256 above by entering the OTP sent to their registered phone number at %s.",
+		audit.SignedAt.Format(auditTimeLayout))), "", "L", false)
+}
+
+// signedAgreementGeneratedAt keeps the reviewed agreement's issue date on the
+// signed copy, so both carry the same text.
+func signedAgreementGeneratedAt(audit *models2.SignatureAudit) time.Time {
+	if audit.Agreement.CreatedAt.IsZero() {
+		return audit.SignedAt
+	}
+	return audit.Agreement.CreatedAt
+}
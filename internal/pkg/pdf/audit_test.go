@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"bytes"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderAuditPage_BindsSignatureToReviewedAgreement(t *testing.T) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+
+	version := 3
+	signedAt := time.Date(2025, 7, 4, 9, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+	audit := &models2.SignatureAudit{
+		SessionID:    uuid.MustParse("0f8e2a4c-3b1d-4e5f-9a7b-6c5d4e3f2a1b"),
+		BorrowerName: "Siti Rahma",
+		PhoneNumber:  "********7890",
+		Agreement: &models2.Document{
+			SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			VerificationID:  "MFRGGZDFMZTWQ2LK",
+			TemplateVersion: &version,
+			CreatedAt:       signedAt.Add(-48 * time.Hour),
+		},
+		OTPSentAt: signedAt.Add(-2 * time.Minute),
+		SignedAt:  signedAt,
+		IPAddress: "203.0.113.9",
+		UserAgent: "Mozilla/5.0",
+	}
+	renderAuditPage(pdf, audit)
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+	out := buf.String()
+
+	assert.Equal(t, 1, pdf.PageNo())
+	for _, want := range []string{
+		"JEJAK AUDIT TANDA TANGAN ELEKTRONIK",
+		"Siti Rahma",
+		"********7890",
+		"0f8e2a4c-3b1d-4e5f-9a7b-6c5d4e3f2a1b",
+		"MFRGGZDFMZTWQ2LK",
+		audit.Agreement.SHA256,
+		"2025-07-04 09:30:00 +0700",
+		"203.0.113.9",
+	} {
+		assert.Contains(t, out, want)
+	}
+}
+
+func TestSignedAgreementGeneratedAt_KeepsReviewedIssueDate(t *testing.T) {
+	issued := time.Date(2025, 7, 2, 8, 0, 0, 0, time.UTC)
+	signed := issued.Add(48 * time.Hour)
+
+	assert.Equal(t, issued, signedAgreementGeneratedAt(&models2.SignatureAudit{Agreement: &models2.Document{CreatedAt: issued}, SignedAt: signed}))
+	assert.Equal(t, signed, signedAgreementGeneratedAt(&models2.SignatureAudit{Agreement: &models2.Document{}, SignedAt: signed}))
+}
@@ -1,210 +1,217 @@
 package pdf
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
 	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
-	"path/filepath"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/pdfsign"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jung-kurt/gofpdf"
+	"github.com/rs/zerolog/log"
 )
 
 const (
-	URL_PATH_FILE = "uploads/agreements"
+	CONTENT_TYPE_PDF = "application/pdf"
 )
 
 type PDFGenerator interface {
-	GenerateLoanAgreement(loan *models2.LoanForApproval) (string, error)
-	GenerateInvestmentAgreement(investment *models2.Investment, loan *models2.LoanInvestmentInfo, investorName string) (string, error)
+	GenerateLoanAgreement(loan *models2.LoanForApproval, agreementTemplate *models2.AgreementTemplate) (*models2.Document, error)
+	GenerateInvestmentAgreement(investment *models2.Investment, loan *models2.LoanInvestmentInfo, investorName string, agreementTemplate *models2.AgreementTemplate) (*models2.Document, error)
+	// GenerateSignedLoanAgreement renders the reviewed loan agreement again
+	// with an audit page of the borrower's electronic signature.
+	GenerateSignedLoanAgreement(loan *models2.LoanForApproval, agreementTemplate *models2.AgreementTemplate, audit *models2.SignatureAudit) (*models2.Document, error)
+	// Discard deletes a generated document that was not saved after all.
+	Discard(ctx context.Context, document *models2.Document)
 }
 
-type realPDFGenerator struct{}
+// Config holds what agreements print besides the loan itself.
+type Config struct {
+	CompanyName string
+	// DefaultLocale is used for investment agreements and for borrowers
+	// without a preferred locale
+	DefaultLocale string
+	// VerifyBaseURL is the public verification endpoint the stamp links to
+	VerifyBaseURL string
+	// Signer applies the platform signature before documents are stored,
+	// agreements are left unsigned without one
+	Signer pdfsign.Signer
+}
 
-func NewPDFGenerator() PDFGenerator {
-	return &realPDFGenerator{}
+type realPDFGenerator struct {
+	store         storage.Store
+	company       Company
+	defaultLocale string
+	verifyBaseURL string
+	signer        pdfsign.Signer
 }
 
-func (r *realPDFGenerator) GenerateLoanAgreement(loan *models2.LoanForApproval) (string, error) {
-	agreementDir := "uploads/agreements"
+func NewPDFGenerator(store storage.Store, config Config) PDFGenerator {
+	signer := config.Signer
+	if signer == nil {
+		signer = pdfsign.NewNoopSigner()
+	}
+
+	return &realPDFGenerator{
+		store:         store,
+		company:       Company{Name: config.CompanyName},
+		defaultLocale: NormalizeLocale(config.DefaultLocale, constants.LOCALE_INDONESIAN),
+		verifyBaseURL: config.VerifyBaseURL,
+		signer:        signer,
+	}
+}
 
+func (r *realPDFGenerator) GenerateLoanAgreement(loan *models2.LoanForApproval, agreementTemplate *models2.AgreementTemplate) (*models2.Document, error) {
 	fileName := fmt.Sprintf("loan_agreement_%s.pdf", loan.ID.String())
-	filePath := filepath.Join(agreementDir, fileName)
 
 	totalAmount := loan.PrincipalAmount * (1 + loan.InterestRate/100)
 	monthlyPayment := totalAmount / float64(loan.LoanTermMonth)
 
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Header
-	pdf.SetFont("Arial", "B", 16)
-	pdf.Cell(0, 10, "LOAN AGREEMENT")
-	pdf.Ln(15)
-
-	// Basic info
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Loan ID: %s", loan.ID.String()))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Date: %s", time.Now().Format("2006-01-02")))
-	pdf.Ln(15)
-
-	// Borrower information
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "BORROWER INFORMATION:")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Name: %s", loan.BorrowerName))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Borrower ID: %s", loan.BorrowerID.String()))
-	pdf.Ln(15)
-
-	// Loan details
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "LOAN DETAILS:")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Principal Amount: Rp %.2f", loan.PrincipalAmount))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Interest Rate: %.2f%% per annum", loan.InterestRate))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("ROI Rate: %.2f%% per annum", loan.ROIRate))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Loan Term: %d months", loan.LoanTermMonth))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Total Amount: Rp %.2f", totalAmount))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Monthly Payment: Rp %.2f", monthlyPayment))
-	pdf.Ln(15)
-
-	// Terms and conditions
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "TERMS AND CONDITIONS:")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, "1. The borrower agrees to repay the loan in monthly installments")
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("2. Payment schedule: %d months total", loan.LoanTermMonth))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, "3. Interest is calculated on flat rate basis")
-	pdf.Ln(8)
-	pdf.Cell(0, 8, "4. Late payment may incur additional charges")
-	pdf.Ln(15)
-
-	// Approval information
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "APPROVAL INFORMATION:")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Survey Date: %s", loan.SurveyDate.Format("2006-01-02")))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Field Validator: %s", loan.FieldValidatorEmployeeID.String()))
-	pdf.Ln(15)
-
-	pdf.Cell(0, 8, fmt.Sprintf("This agreement is generated on %s", time.Now().Format("2006-01-02 15:04:05")))
-	pdf.Ln(20)
-
-	// Signature section
-	pdf.Cell(90, 8, "_________________________")
-	pdf.Cell(90, 8, "_________________________")
-	pdf.Ln(8)
-	pdf.Cell(90, 8, "Borrower Signature")
-	pdf.Cell(90, 8, "Amartha Representative")
-
-	err := pdf.OutputFileAndClose(filePath)
+	pdf, verificationID, err := r.newStampedPDF()
 	if err != nil {
-		return "", fmt.Errorf("failed to generate PDF: %w", err)
+		return nil, err
+	}
+	err = renderTemplate(pdf, agreementTemplate, LoanAgreementData{
+		Company:        r.company,
+		Loan:           loan,
+		TotalAmount:    totalAmount,
+		MonthlyPayment: monthlyPayment,
+		GeneratedAt:    time.Now(),
+	}, NormalizeLocale(loan.BorrowerLocale, r.defaultLocale))
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Sprintf("/%s/%s", URL_PATH_FILE, fileName), nil
+	return r.save(pdf, &models2.Document{
+		LoanID:          loan.ID,
+		DocumentType:    constants.DOCUMENT_LOAN_AGREEMENT,
+		FileName:        fileName,
+		TemplateVersion: &agreementTemplate.Version,
+		VerificationID:  verificationID,
+	})
 }
 
-func (r *realPDFGenerator) GenerateInvestmentAgreement(investment *models2.Investment, loan *models2.LoanInvestmentInfo, investorName string) (string, error) {
+func (r *realPDFGenerator) GenerateSignedLoanAgreement(loan *models2.LoanForApproval, agreementTemplate *models2.AgreementTemplate, audit *models2.SignatureAudit) (*models2.Document, error) {
+	fileName := fmt.Sprintf("signed_loan_agreement_%s.pdf", loan.ID.String())
+
+	totalAmount := loan.PrincipalAmount * (1 + loan.InterestRate/100)
+	monthlyPayment := totalAmount / float64(loan.LoanTermMonth)
+
+	pdf, verificationID, err := r.newStampedPDF()
+	if err != nil {
+		return nil, err
+	}
+	err = renderTemplate(pdf, agreementTemplate, LoanAgreementData{
+		Company:        r.company,
+		Loan:           loan,
+		TotalAmount:    totalAmount,
+		MonthlyPayment: monthlyPayment,
+		GeneratedAt:    signedAgreementGeneratedAt(audit),
+	}, NormalizeLocale(loan.BorrowerLocale, r.defaultLocale))
+	if err != nil {
+		return nil, err
+	}
+	renderAuditPage(pdf, audit)
+
+	return r.save(pdf, &models2.Document{
+		LoanID:          loan.ID,
+		DocumentType:    constants.DOCUMENT_SIGNED_AGREEMENT,
+		FileName:        fileName,
+		TemplateVersion: &agreementTemplate.Version,
+		VerificationID:  verificationID,
+	})
+}
+
+func (r *realPDFGenerator) GenerateInvestmentAgreement(investment *models2.Investment, loan *models2.LoanInvestmentInfo, investorName string, agreementTemplate *models2.AgreementTemplate) (*models2.Document, error) {
 	fileName := fmt.Sprintf("investment_agreement_%s_%s.pdf", investment.LoanID.String(), investment.InvestorID.String())
-	filePath := filepath.Join(URL_PATH_FILE, fileName)
 
 	// TODO: check this
 	investmentPeriod := 12 // months (could be from loan data)
 	totalReturn := investment.InvestmentAmount + investment.ExpectedReturn
 	monthlyReturn := totalReturn / float64(investmentPeriod)
 
+	pdf, verificationID, err := r.newStampedPDF()
+	if err != nil {
+		return nil, err
+	}
+	err = renderTemplate(pdf, agreementTemplate, InvestmentAgreementData{
+		Company:          r.company,
+		Investment:       investment,
+		Loan:             loan,
+		InvestorName:     investorName,
+		InvestmentPeriod: investmentPeriod,
+		TotalReturn:      totalReturn,
+		MonthlyReturn:    monthlyReturn,
+		GeneratedAt:      time.Now(),
+	}, r.defaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.save(pdf, &models2.Document{
+		LoanID:          investment.LoanID,
+		InvestmentID:    &investment.ID,
+		DocumentType:    constants.DOCUMENT_INVESTMENT_AGREEMENT,
+		FileName:        fileName,
+		TemplateVersion: &agreementTemplate.Version,
+		VerificationID:  verificationID,
+	})
+}
+
+func (r *realPDFGenerator) Discard(ctx context.Context, document *models2.Document) {
+	if err := r.store.Delete(ctx, document.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Error().Err(err).Str("storage_key", document.StorageKey).Msg("Failed to discard generated document")
+	}
+}
+
+// newStampedPDF starts an agreement whose pages carry a new verification ID.
+func (r *realPDFGenerator) newStampedPDF() (*gofpdf.Fpdf, string, error) {
+	verificationID, err := newVerificationID()
+	if err != nil {
+		return nil, "", err
+	}
+
 	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Header
-	pdf.SetFont("Arial", "B", 16)
-	pdf.Cell(0, 10, "INVESTMENT AGREEMENT")
-	pdf.Ln(15)
-
-	// Basic info
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Agreement ID: %s", investment.ID.String()))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Date: %s", time.Now().Format("2006-01-02")))
-	pdf.Ln(15)
-
-	// Investment information
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "INVESTMENT DETAILS:")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Investor Name: %s", investorName))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Investor ID: %s", investment.InvestorID.String()))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Loan ID: %s", investment.LoanID.String()))
-	pdf.Ln(15)
-
-	// Financial details
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "FINANCIAL TERMS:")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, fmt.Sprintf("Investment Amount: Rp %.2f", investment.InvestmentAmount))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Expected Return: Rp %.2f", investment.ExpectedReturn))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("ROI Rate: %.2f%% per annum", loan.ROIRate))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Investment Period: %d months", investmentPeriod))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Total Return: Rp %.2f", totalReturn))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Monthly Return: Rp %.2f", monthlyReturn))
-	pdf.Ln(15)
-
-	// Terms and conditions
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "TERMS AND CONDITIONS:")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(0, 8, "1. This investment is for peer-to-peer lending facilitated by Amartha")
-	pdf.Ln(8)
-	pdf.Cell(0, 8, "2. Returns are subject to borrower's ability to repay the loan")
-	pdf.Ln(8)
-	pdf.Cell(0, 8, "3. Amartha acts as facilitator and is not liable for borrower default")
-	pdf.Ln(8)
-	pdf.Cell(0, 8, "4. Returns will be paid monthly as per the loan repayment schedule")
-	pdf.Ln(8)
-	pdf.Cell(0, 8, "5. This agreement is governed by Indonesian law")
-	pdf.Ln(15)
-
-	// Investment date
-	pdf.Cell(0, 8, fmt.Sprintf("Investment Date: %s", investment.InvestmentDate.Format("2006-01-02")))
-	pdf.Ln(8)
-	pdf.Cell(0, 8, fmt.Sprintf("Agreement generated on: %s", time.Now().Format("2006-01-02 15:04:05")))
-	pdf.Ln(20)
-
-	// Signature section
-	pdf.Cell(90, 8, "_________________________")
-	pdf.Cell(90, 8, "_________________________")
-	pdf.Ln(8)
-	pdf.Cell(90, 8, "Investor Signature")
-	pdf.Cell(90, 8, "Amartha Representative")
-
-	err := pdf.OutputFileAndClose(filePath)
+	if err := stampPages(pdf, verificationID, VerificationURL(r.verifyBaseURL, verificationID)); err != nil {
+		return nil, "", err
+	}
+	return pdf, verificationID, nil
+}
+
+// save renders the document, writes it to the store and fills in what the
+// documents table records about it. Only the signed file is stored, so the
+// checksum of what is downloaded doubles as the registry entry the
+// verification endpoint compares presented copies with.
+func (r *realPDFGenerator) save(pdf *gofpdf.Fpdf, document *models2.Document) (*models2.Document, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	signed, err := r.signer.Sign(buf.Bytes(), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign PDF: %w", err)
+	}
+
+	document.ID = uuid.New()
+	document.StorageKey = fmt.Sprintf("%s/%s/%s.pdf", constants.DOCUMENT_KEY_PREFIX, document.LoanID, document.ID)
+	document.ContentType = CONTENT_TYPE_PDF
+	document.UploadedByType = constants.UPLOADER_SYSTEM
+	document.CreatedAt = time.Now()
+
+	document.SignerFingerprint = r.signer.Fingerprint()
+
+	size, checksum, err := storage.PutWithChecksum(context.Background(), r.store, document.StorageKey, bytes.NewReader(signed), int64(len(signed)), CONTENT_TYPE_PDF)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate PDF: %w", err)
+		return nil, fmt.Errorf("failed to store PDF: %w", err)
 	}
+	document.SizeBytes = &size
+	document.SHA256 = checksum
 
-	return fmt.Sprintf("/%s/%s", URL_PATH_FILE, fileName), nil
+	return document, nil
 }
@@ -0,0 +1,121 @@
+package pdf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markingSigner stands in for the PKCS#12 signer and appends a marker so the
+// stored bytes can be told apart from the rendered ones.
+type markingSigner struct{}
+
+func (markingSigner) Sign(document []byte, signingTime time.Time) ([]byte, error) {
+	return append(append([]byte(nil), document...), []byte("%signed\n")...), nil
+}
+
+func (markingSigner) Fingerprint() string {
+	return "ab12"
+}
+
+func TestGenerateLoanAgreement_StoresSignedDocument(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	generator := NewPDFGenerator(store, Config{CompanyName: "Test Lender", Signer: markingSigner{}})
+
+	body, err := DefaultTemplate(constants.DOCUMENT_LOAN_AGREEMENT)
+	require.NoError(t, err)
+	agreementTemplate := &models2.AgreementTemplate{TemplateType: constants.DOCUMENT_LOAN_AGREEMENT, Version: 1, Body: body}
+
+	loan := sampleLoanAgreementData().Loan
+	loan.ID = uuid.New()
+	document, err := generator.GenerateLoanAgreement(loan, agreementTemplate)
+	require.NoError(t, err)
+
+	reader, _, err := store.Get(context.Background(), document.StorageKey)
+	require.NoError(t, err)
+	defer reader.Close()
+	stored, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	// Only the signed file is kept, and the registry hash is of that file
+	assert.Contains(t, string(stored), "%signed\n")
+	sum := sha256.Sum256(stored)
+	assert.Equal(t, hex.EncodeToString(sum[:]), document.SHA256)
+	assert.Equal(t, int64(len(stored)), *document.SizeBytes)
+	assert.Equal(t, "ab12", document.SignerFingerprint)
+}
+
+func TestGenerateLoanAgreement_UnsignedWithoutSigner(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	generator := NewPDFGenerator(store, Config{CompanyName: "Test Lender"})
+
+	body, err := DefaultTemplate(constants.DOCUMENT_LOAN_AGREEMENT)
+	require.NoError(t, err)
+	agreementTemplate := &models2.AgreementTemplate{TemplateType: constants.DOCUMENT_LOAN_AGREEMENT, Version: 1, Body: body}
+
+	loan := sampleLoanAgreementData().Loan
+	loan.ID = uuid.New()
+	document, err := generator.GenerateLoanAgreement(loan, agreementTemplate)
+
+	require.NoError(t, err)
+	assert.Empty(t, document.SignerFingerprint)
+}
+
+func TestGenerateSignedLoanAgreement_StoresSignedAgreement(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	generator := NewPDFGenerator(store, Config{CompanyName: "Test Lender", Signer: markingSigner{}})
+
+	body, err := DefaultTemplate(constants.DOCUMENT_LOAN_AGREEMENT)
+	require.NoError(t, err)
+	agreementTemplate := &models2.AgreementTemplate{TemplateType: constants.DOCUMENT_LOAN_AGREEMENT, Version: 4, Body: body}
+
+	loan := sampleLoanAgreementData().Loan
+	loan.ID = uuid.New()
+	audit := &models2.SignatureAudit{
+		SessionID:    uuid.New(),
+		BorrowerName: loan.BorrowerName,
+		Agreement:    &models2.Document{SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		SignedAt:     time.Now(),
+	}
+	document, err := generator.GenerateSignedLoanAgreement(loan, agreementTemplate, audit)
+
+	require.NoError(t, err)
+	assert.Equal(t, constants.DOCUMENT_SIGNED_AGREEMENT, document.DocumentType)
+	assert.Equal(t, "signed_loan_agreement_"+loan.ID.String()+".pdf", document.FileName)
+	assert.Equal(t, 4, *document.TemplateVersion)
+	assert.NotEmpty(t, document.VerificationID)
+	assert.Equal(t, "ab12", document.SignerFingerprint)
+}
+
+func TestDiscard_DeletesStoredDocument(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	generator := NewPDFGenerator(store, Config{CompanyName: "Test Lender"})
+
+	body, err := DefaultTemplate(constants.DOCUMENT_LOAN_AGREEMENT)
+	require.NoError(t, err)
+	agreementTemplate := &models2.AgreementTemplate{TemplateType: constants.DOCUMENT_LOAN_AGREEMENT, Version: 1, Body: body}
+
+	loan := sampleLoanAgreementData().Loan
+	loan.ID = uuid.New()
+	document, err := generator.GenerateLoanAgreement(loan, agreementTemplate)
+	require.NoError(t, err)
+
+	generator.Discard(context.Background(), document)
+
+	_, err = store.Stat(context.Background(), document.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
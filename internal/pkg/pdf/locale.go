@@ -0,0 +1,200 @@
+package pdf
+
+import (
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Indonesian is the legal language of every agreement. Other locales add a
+// parallel column next to it.
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+var indonesianWeekdays = [...]string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+
+var indonesianDigits = [...]string{"", "satu", "dua", "tiga", "empat", "lima", "enam", "tujuh", "delapan", "sembilan"}
+
+var indonesianScales = [...]string{"", "ribu", "juta", "miliar", "triliun"}
+
+var englishOnes = [...]string{
+	"", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+}
+
+var englishTens = [...]string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+var englishScales = [...]string{"", "thousand", "million", "billion", "trillion"}
+
+// NormalizeLocale returns locale if agreements support it and fallback
+// otherwise.
+func NormalizeLocale(locale, fallback string) string {
+	switch locale {
+	case constants.LOCALE_INDONESIAN, constants.LOCALE_ENGLISH:
+		return locale
+	default:
+		return fallback
+	}
+}
+
+// localeFuncs are the template functions, formatting for one language.
+// Amounts are always printed the Indonesian way since they are in rupiah.
+func localeFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"rupiah":      FormatRupiah,
+		"rupiahWords": func(amount float64) string { return rupiahWords(amount, locale) },
+		"percent":     func(rate float64) string { return FormatPercent(rate, locale) },
+		"date":        func(t time.Time) string { return FormatDate(t, locale) },
+		"dateWords":   func(t time.Time) string { return dateWords(t, locale) },
+		"datetime":    func(t time.Time) string { return FormatDate(t, locale) + t.Format(" 15:04") },
+		"upper":       strings.ToUpper,
+	}
+}
+
+// FormatRupiah prints amounts like Rp 1.500.000,00.
+func FormatRupiah(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	cents := int64(math.Round(amount * 100))
+	return fmt.Sprintf("%sRp %s,%02d", sign, groupThousands(cents/100, "."), cents%100)
+}
+
+// FormatPercent uses the locale's decimal separator, 10,50% or 10.50%.
+func FormatPercent(rate float64, locale string) string {
+	formatted := fmt.Sprintf("%.2f%%", rate)
+	if locale == constants.LOCALE_INDONESIAN {
+		formatted = strings.Replace(formatted, ".", ",", 1)
+	}
+	return formatted
+}
+
+// FormatDate spells the month out, 15 Juni 2025 or 15 June 2025.
+func FormatDate(t time.Time, locale string) string {
+	if locale == constants.LOCALE_ENGLISH {
+		return t.Format("2 January 2006")
+	}
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// dateWords spells the whole date out the way deeds open, for example
+// "Minggu, tanggal lima belas bulan Juni tahun dua ribu dua puluh lima".
+func dateWords(t time.Time, locale string) string {
+	if locale == constants.LOCALE_ENGLISH {
+		return fmt.Sprintf("%s, %s %s %s",
+			t.Weekday(), numberWords(int64(t.Day()), locale), t.Month(), numberWords(int64(t.Year()), locale))
+	}
+	return fmt.Sprintf("%s, tanggal %s bulan %s tahun %s",
+		indonesianWeekdays[t.Weekday()], numberWords(int64(t.Day()), locale), indonesianMonths[t.Month()-1], numberWords(int64(t.Year()), locale))
+}
+
+// rupiahWords spells an amount out to the whole rupiah, as agreements state
+// amounts in figures and in words.
+func rupiahWords(amount float64, locale string) string {
+	rupiah := int64(math.Round(amount))
+	if rupiah < 0 {
+		rupiah = -rupiah
+	}
+	return numberWords(rupiah, locale) + " rupiah"
+}
+
+func groupThousands(n int64, separator string) string {
+	digits := fmt.Sprintf("%d", n)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(separator)
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+// numberWords spells out a non-negative integer below a quadrillion.
+func numberWords(n int64, locale string) string {
+	if n == 0 {
+		if locale == constants.LOCALE_ENGLISH {
+			return "zero"
+		}
+		return "nol"
+	}
+
+	var groups []string
+	for scale := 0; n > 0 && scale < len(indonesianScales); scale++ {
+		group := int(n % 1000)
+		n /= 1000
+		if group == 0 {
+			continue
+		}
+
+		var words string
+		if locale == constants.LOCALE_ENGLISH {
+			words = strings.TrimSpace(englishBelowThousand(group) + " " + englishScales[scale])
+		} else if scale == 1 && group == 1 {
+			words = "seribu"
+		} else {
+			words = strings.TrimSpace(indonesianBelowThousand(group) + " " + indonesianScales[scale])
+		}
+		groups = append([]string{words}, groups...)
+	}
+
+	return strings.Join(groups, " ")
+}
+
+func indonesianBelowThousand(n int) string {
+	var words []string
+
+	switch hundreds := n / 100; {
+	case hundreds == 1:
+		words = append(words, "seratus")
+	case hundreds > 1:
+		words = append(words, indonesianDigits[hundreds]+" ratus")
+	}
+
+	switch rest := n % 100; {
+	case rest == 0:
+	case rest < 10:
+		words = append(words, indonesianDigits[rest])
+	case rest == 10:
+		words = append(words, "sepuluh")
+	case rest == 11:
+		words = append(words, "sebelas")
+	case rest < 20:
+		words = append(words, indonesianDigits[rest-10]+" belas")
+	default:
+		words = append(words, indonesianDigits[rest/10]+" puluh")
+		if rest%10 > 0 {
+			words = append(words, indonesianDigits[rest%10])
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func englishBelowThousand(n int) string {
+	var words []string
+
+	if hundreds := n / 100; hundreds > 0 {
+		words = append(words, englishOnes[hundreds]+" hundred")
+	}
+
+	switch rest := n % 100; {
+	case rest == 0:
+	case rest < 20:
+		words = append(words, englishOnes[rest])
+	case rest%10 == 0:
+		words = append(words, englishTens[rest/10])
+	default:
+		words = append(words, englishTens[rest/10]+"-"+englishOnes[rest%10])
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,77 @@
+package pdf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatRupiah(t *testing.T) {
+	cases := map[float64]string{
+		0:            "Rp 0,00",
+		999:          "Rp 999,00",
+		1500000:      "Rp 1.500.000,00",
+		137500.5:     "Rp 137.500,50",
+		1234567.899:  "Rp 1.234.567,90",
+		-2500000:     "-Rp 2.500.000,00",
+		250000000000: "Rp 250.000.000.000,00",
+	}
+
+	for amount, expected := range cases {
+		assert.Equal(t, expected, FormatRupiah(amount))
+	}
+}
+
+func TestFormatPercent(t *testing.T) {
+	assert.Equal(t, "10,50%", FormatPercent(10.5, "id"))
+	assert.Equal(t, "10.50%", FormatPercent(10.5, "en"))
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "15 Juni 2025", FormatDate(date, "id"))
+	assert.Equal(t, "15 June 2025", FormatDate(date, "en"))
+	assert.Equal(t, "Minggu, tanggal lima belas bulan Juni tahun dua ribu dua puluh lima", dateWords(date, "id"))
+	assert.Equal(t, "Sunday, fifteen June two thousand twenty-five", dateWords(date, "en"))
+}
+
+func TestNumberWords(t *testing.T) {
+	cases := []struct {
+		n          int64
+		indonesian string
+		english    string
+	}{
+		{0, "nol", "zero"},
+		{1, "satu", "one"},
+		{10, "sepuluh", "ten"},
+		{11, "sebelas", "eleven"},
+		{17, "tujuh belas", "seventeen"},
+		{40, "empat puluh", "forty"},
+		{100, "seratus", "one hundred"},
+		{115, "seratus lima belas", "one hundred fifteen"},
+		{1000, "seribu", "one thousand"},
+		{1001, "seribu satu", "one thousand one"},
+		{21000, "dua puluh satu ribu", "twenty-one thousand"},
+		{101000, "seratus satu ribu", "one hundred one thousand"},
+		{1500000, "satu juta lima ratus ribu", "one million five hundred thousand"},
+		{2000000000, "dua miliar", "two billion"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.indonesian, numberWords(tc.n, "id"), "%d", tc.n)
+		assert.Equal(t, tc.english, numberWords(tc.n, "en"), "%d", tc.n)
+	}
+}
+
+func TestRupiahWords(t *testing.T) {
+	assert.Equal(t, "satu juta lima ratus ribu rupiah", rupiahWords(1500000.25, "id"))
+	assert.Equal(t, "one million five hundred thousand rupiah", rupiahWords(1500000.25, "en"))
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.Equal(t, "en", NormalizeLocale("en", "id"))
+	assert.Equal(t, "id", NormalizeLocale("", "id"))
+	assert.Equal(t, "id", NormalizeLocale("fr", "id"))
+}
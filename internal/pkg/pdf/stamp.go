@@ -0,0 +1,80 @@
+package pdf
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Every page of a generated agreement carries a verification stamp in the
+// bottom margin: a QR code linking to the public verification endpoint and
+// the verification ID printed next to it.
+const (
+	stampQRSize = 18.0
+	// stampMargin is kept free of content for the stamp
+	stampMargin = 32.0
+)
+
+var verificationEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newVerificationID returns a random, unguessable ID, so the public endpoint
+// cannot be used to enumerate agreements.
+func newVerificationID() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate verification ID: %w", err)
+	}
+	return verificationEncoding.EncodeToString(raw), nil
+}
+
+// VerificationURL is where the QR code of a stamped document points.
+func VerificationURL(baseURL, verificationID string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + verificationID
+}
+
+// stampPages prints the verification stamp on every page added afterwards.
+func stampPages(pdf *gofpdf.Fpdf, verificationID, verifyURL string) error {
+	code, err := qr.Encode(verifyURL, qr.M, qr.Auto)
+	if err != nil {
+		return fmt.Errorf("failed to encode verification QR code: %w", err)
+	}
+
+	pdf.SetAutoPageBreak(true, stampMargin)
+	pdf.SetFooterFunc(func() {
+		left, _, right, _ := pdf.GetMargins()
+		pageWidth, pageHeight := pdf.GetPageSize()
+		y := pageHeight - 10 - stampQRSize
+
+		drawQR(pdf, code, left, y, stampQRSize)
+
+		pdf.SetFont("Arial", "", 8)
+		pdf.SetXY(left+stampQRSize+4, y+3)
+		pdf.CellFormat(pageWidth-left-right-stampQRSize-4, 4, "ID Verifikasi / Verification ID: "+verificationID, "", 2, "L", false, 0, "")
+		pdf.SetX(left + stampQRSize + 4)
+		pdf.CellFormat(pageWidth-left-right-stampQRSize-4, 4, "Periksa keaslian dokumen / Verify this document: "+verifyURL, "", 2, "L", false, 0, "")
+		pdf.SetX(left + stampQRSize + 4)
+		pdf.CellFormat(pageWidth-left-right-stampQRSize-4, 4, fmt.Sprintf("Halaman / Page %d", pdf.PageNo()), "", 2, "L", false, 0, "")
+	})
+	return nil
+}
+
+// drawQR paints the dark modules as filled squares, which keeps the code
+// sharp at any zoom.
+func drawQR(pdf *gofpdf.Fpdf, code barcode.Barcode, x, y, size float64) {
+	bounds := code.Bounds()
+	module := size / float64(bounds.Dx())
+
+	pdf.SetFillColor(0, 0, 0)
+	for row := bounds.Min.Y; row < bounds.Max.Y; row++ {
+		for col := bounds.Min.X; col < bounds.Max.X; col++ {
+			if r, _, _, _ := code.At(col, row).RGBA(); r == 0 {
+				pdf.Rect(x+float64(col-bounds.Min.X)*module, y+float64(row-bounds.Min.Y)*module, module, module, "F")
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package pdf
+
+import (
+	"bytes"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVerificationID(t *testing.T) {
+	first, err := newVerificationID()
+	require.NoError(t, err)
+	second, err := newVerificationID()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 16)
+	assert.Equal(t, strings.ToUpper(first), first)
+	assert.NotEqual(t, first, second)
+}
+
+func TestVerificationURL(t *testing.T) {
+	assert.Equal(t, "https://example.com/api/v1/verify/ABC", VerificationURL("https://example.com/api/v1/verify/", "ABC"))
+}
+
+func TestStampPages_EveryPageCarriesVerificationID(t *testing.T) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	require.NoError(t, stampPages(pdf, "MFRGGZDFMZTWQ2LK", "https://example.com/api/v1/verify/MFRGGZDFMZTWQ2LK"))
+
+	// Long enough to run over several pages
+	body := "# LOAN AGREEMENT\n" + strings.Repeat("Paragraph {{.Loan.BorrowerName}}\n", 80)
+	agreementTemplate := &models2.AgreementTemplate{TemplateType: constants.DOCUMENT_LOAN_AGREEMENT, Body: body}
+	require.NoError(t, renderTemplate(pdf, agreementTemplate, sampleLoanAgreementData(), constants.LOCALE_INDONESIAN))
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+
+	pages := pdf.PageNo()
+	require.Greater(t, pages, 1)
+	assert.Equal(t, pages, strings.Count(buf.String(), "Verification ID: MFRGGZDFMZTWQ2LK"))
+}
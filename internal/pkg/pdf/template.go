@@ -0,0 +1,372 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Agreement templates are text/template sources. The output is laid out line
+// by line:
+//
+//	# Title                 large bold heading
+//	## Heading              section heading
+//	@signatures A | B       signature lines with a label under each
+//	(empty line)            vertical space
+//	anything else           paragraph, wrapped to the page width
+//
+// Bilingual templates put each language in a block named after its locale,
+// {{define "id"}} and {{define "en"}}. The Indonesian block is always printed,
+// the English one next to it for readers who prefer English, so both need the
+// same lines in the same order. A body without blocks is printed as it is.
+const (
+	directiveTitle      = "# "
+	directiveHeading    = "## "
+	directiveSignatures = "@signatures "
+)
+
+const (
+	lineBlank = iota
+	lineTitle
+	lineHeading
+	lineSignatures
+	lineParagraph
+)
+
+// Two column layout on A4 with the default 10 mm margins
+const (
+	columnWidth = 92.0
+	columnGap   = 6.0
+)
+
+type renderedLine struct {
+	kind int
+	text string
+}
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// Company is the lender named in agreements.
+type Company struct {
+	Name string
+}
+
+// LoanAgreementData is what loan agreement templates can refer to.
+type LoanAgreementData struct {
+	Company        Company
+	Loan           *models2.LoanForApproval
+	TotalAmount    float64
+	MonthlyPayment float64
+	GeneratedAt    time.Time
+}
+
+// InvestmentAgreementData is what investment agreement templates can refer to.
+type InvestmentAgreementData struct {
+	Company          Company
+	Investment       *models2.Investment
+	Loan             *models2.LoanInvestmentInfo
+	InvestorName     string
+	InvestmentPeriod int
+	TotalReturn      float64
+	MonthlyReturn    float64
+	GeneratedAt      time.Time
+}
+
+// DefaultTemplate returns the built-in body for a template type, published as
+// the first version when none exists yet.
+func DefaultTemplate(templateType string) (string, error) {
+	var name string
+	switch templateType {
+	case constants.DOCUMENT_LOAN_AGREEMENT:
+		name = "templates/loan_agreement.tmpl"
+	case constants.DOCUMENT_INVESTMENT_AGREEMENT:
+		name = "templates/investment_agreement.tmpl"
+	default:
+		return "", fmt.Errorf("unknown template type: %s", templateType)
+	}
+
+	body, err := defaultTemplates.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// ValidateTemplate parses the body and renders it against sample data in every
+// locale, so templates referring to missing fields or with language blocks
+// that do not line up are refused before they are published.
+func ValidateTemplate(templateType, body string) error {
+	var data interface{}
+	switch templateType {
+	case constants.DOCUMENT_LOAN_AGREEMENT:
+		data = sampleLoanAgreementData()
+	case constants.DOCUMENT_INVESTMENT_AGREEMENT:
+		data = sampleInvestmentAgreementData()
+	default:
+		return fmt.Errorf("unknown template type: %s", templateType)
+	}
+
+	for _, locale := range []string{constants.LOCALE_INDONESIAN, constants.LOCALE_ENGLISH} {
+		pdf := gofpdf.New("P", "mm", "A4", "")
+		if err := renderTemplate(pdf, &models2.AgreementTemplate{TemplateType: templateType, Body: body}, data, locale); err != nil {
+			return err
+		}
+		if err := pdf.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderTemplate executes the template for the reader's locale and lays the
+// result out on new pages.
+func renderTemplate(pdf *gofpdf.Fpdf, agreementTemplate *models2.AgreementTemplate, data interface{}, locale string) error {
+	indonesian, err := executeTemplate(agreementTemplate, data, constants.LOCALE_INDONESIAN)
+	if err != nil {
+		return err
+	}
+
+	var parallel []renderedLine
+	if locale != constants.LOCALE_INDONESIAN {
+		parallel, err = executeTemplate(agreementTemplate, data, locale)
+		if err != nil {
+			return err
+		}
+	}
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	if parallel == nil {
+		for _, line := range indonesian {
+			layoutLine(pdf, tr, line)
+		}
+		return nil
+	}
+
+	if err := matchLines(indonesian, parallel); err != nil {
+		return err
+	}
+	for i := range indonesian {
+		layoutColumns(pdf, tr, indonesian[i], parallel[i])
+	}
+	return nil
+}
+
+// executeTemplate renders the locale's block, with formatting for that
+// locale. It returns nil for a locale other than Indonesian that the template
+// has no block for.
+func executeTemplate(agreementTemplate *models2.AgreementTemplate, data interface{}, locale string) ([]renderedLine, error) {
+	tmpl, err := template.New(agreementTemplate.TemplateType).
+		Funcs(localeFuncs(locale)).
+		Option("missingkey=error").
+		Parse(agreementTemplate.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	name := locale
+	if tmpl.Lookup(constants.LOCALE_INDONESIAN) == nil {
+		if tmpl.Lookup(constants.LOCALE_ENGLISH) != nil {
+			return nil, fmt.Errorf("invalid template: no %q block", constants.LOCALE_INDONESIAN)
+		}
+		// A body without language blocks is Indonesian only
+		if locale != constants.LOCALE_INDONESIAN {
+			return nil, nil
+		}
+		name = agreementTemplate.TemplateType
+	} else if tmpl.Lookup(locale) == nil {
+		return nil, nil
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&rendered, name, data); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	var lines []renderedLine
+	scanner := bufio.NewScanner(&rendered)
+	for scanner.Scan() {
+		lines = append(lines, parseLine(scanner.Text()))
+	}
+	return lines, scanner.Err()
+}
+
+func parseLine(text string) renderedLine {
+	line := strings.TrimRight(text, " \t\r")
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case trimmed == "":
+		return renderedLine{kind: lineBlank}
+	case strings.HasPrefix(trimmed, directiveHeading):
+		return renderedLine{kind: lineHeading, text: strings.TrimPrefix(trimmed, directiveHeading)}
+	case strings.HasPrefix(trimmed, directiveTitle):
+		return renderedLine{kind: lineTitle, text: strings.TrimPrefix(trimmed, directiveTitle)}
+	case strings.HasPrefix(trimmed, directiveSignatures):
+		return renderedLine{kind: lineSignatures, text: strings.TrimPrefix(trimmed, directiveSignatures)}
+	default:
+		return renderedLine{kind: lineParagraph, text: line}
+	}
+}
+
+// matchLines checks the two language blocks can be printed side by side.
+func matchLines(indonesian, parallel []renderedLine) error {
+	if len(indonesian) != len(parallel) {
+		return fmt.Errorf("invalid template: language blocks have %d and %d lines", len(indonesian), len(parallel))
+	}
+	for i := range indonesian {
+		if indonesian[i].kind != parallel[i].kind {
+			return fmt.Errorf("invalid template: language blocks differ on line %d", i+1)
+		}
+		if indonesian[i].kind == lineSignatures &&
+			len(signatureLabels(indonesian[i].text)) != len(signatureLabels(parallel[i].text)) {
+			return fmt.Errorf("invalid template: language blocks differ on line %d", i+1)
+		}
+	}
+	return nil
+}
+
+func signatureLabels(text string) []string {
+	labels := strings.Split(text, "|")
+	for i := range labels {
+		labels[i] = strings.TrimSpace(labels[i])
+	}
+	return labels
+}
+
+func layoutLine(pdf *gofpdf.Fpdf, tr func(string) string, line renderedLine) {
+	switch line.kind {
+	case lineBlank:
+		pdf.Ln(7)
+	case lineHeading:
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(0, 8, tr(line.text))
+		pdf.Ln(10)
+	case lineTitle:
+		pdf.SetFont("Arial", "B", 16)
+		pdf.Cell(0, 10, tr(line.text))
+		pdf.Ln(8)
+	case lineSignatures:
+		layoutSignatures(pdf, tr, signatureLabels(line.text), 12)
+	default:
+		pdf.SetFont("Arial", "", 12)
+		pdf.MultiCell(0, 8, tr(line.text), "", "L", false)
+	}
+}
+
+// layoutColumns prints a line of each language next to each other, starting
+// a new page when the taller of the two does not fit.
+func layoutColumns(pdf *gofpdf.Fpdf, tr func(string) string, left, right renderedLine) {
+	switch left.kind {
+	case lineBlank:
+		pdf.Ln(7)
+		return
+	case lineSignatures:
+		labels := signatureLabels(left.text)
+		for i, label := range signatureLabels(right.text) {
+			labels[i] += " / " + label
+		}
+		layoutSignatures(pdf, tr, labels, 10)
+		return
+	case lineTitle:
+		pdf.SetFont("Arial", "B", 14)
+	case lineHeading:
+		pdf.SetFont("Arial", "B", 12)
+	default:
+		pdf.SetFont("Arial", "", 10)
+	}
+
+	lineHeight := 6.0
+	leftText, rightText := tr(left.text), tr(right.text)
+	height := lineHeight * float64(max(len(pdf.SplitLines([]byte(leftText), columnWidth)), len(pdf.SplitLines([]byte(rightText), columnWidth))))
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	if pdf.GetY()+height > pageHeight-bottomMargin {
+		pdf.AddPage()
+	}
+
+	leftMargin, _, _, _ := pdf.GetMargins()
+	y := pdf.GetY()
+	pdf.SetXY(leftMargin, y)
+	pdf.MultiCell(columnWidth, lineHeight, leftText, "", "L", false)
+	pdf.SetXY(leftMargin+columnWidth+columnGap, y)
+	pdf.MultiCell(columnWidth, lineHeight, rightText, "", "L", false)
+	pdf.SetXY(leftMargin, y+height)
+
+	if left.kind != lineParagraph {
+		pdf.Ln(2)
+	}
+}
+
+func layoutSignatures(pdf *gofpdf.Fpdf, tr func(string) string, labels []string, labelSize float64) {
+	pdf.SetFont("Arial", "", 12)
+	pdf.Ln(5)
+	for range labels {
+		pdf.Cell(90, 8, "_________________________")
+	}
+	pdf.Ln(8)
+	pdf.SetFont("Arial", "", labelSize)
+	for _, label := range labels {
+		pdf.Cell(90, 8, tr(label))
+	}
+	pdf.Ln(8)
+}
+
+func sampleLoanAgreementData() LoanAgreementData {
+	return LoanAgreementData{
+		Company: Company{Name: "Sample Company"},
+		Loan: &models2.LoanForApproval{
+			ID:                       uuid.New(),
+			BorrowerID:               uuid.New(),
+			BorrowerName:             "Sample Borrower",
+			PrincipalAmount:          1500000,
+			InterestRate:             10,
+			ROIRate:                  8,
+			LoanTermMonth:            12,
+			FieldValidatorEmployeeID: uuid.New(),
+			SurveyDate:               time.Now(),
+			RequiredApprovals:        1,
+		},
+		TotalAmount:    1650000,
+		MonthlyPayment: 137500,
+		GeneratedAt:    time.Now(),
+	}
+}
+
+func sampleInvestmentAgreementData() InvestmentAgreementData {
+	return InvestmentAgreementData{
+		Company: Company{Name: "Sample Company"},
+		Investment: &models2.Investment{
+			ID:               uuid.New(),
+			LoanID:           uuid.New(),
+			InvestorID:       uuid.New(),
+			Tranche:          1,
+			InvestmentAmount: 1000000,
+			ExpectedReturn:   80000,
+			InvestmentDate:   time.Now(),
+			CreatedAt:        time.Now(),
+		},
+		Loan: &models2.LoanInvestmentInfo{
+			ID:              uuid.New(),
+			PrincipalAmount: 1500000,
+			ROIRate:         8,
+		},
+		InvestorName:     "Sample Investor",
+		InvestmentPeriod: 12,
+		TotalReturn:      1080000,
+		MonthlyReturn:    90000,
+		GeneratedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"bytes"
+	"github.com/fajar-andriansyah/loan-engine/internal/app/constants"
+	models2 "github.com/fajar-andriansyah/loan-engine/internal/app/models"
+	"github.com/fajar-andriansyah/loan-engine/internal/pkg/storage"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTemplates_Validate(t *testing.T) {
+	for _, templateType := range []string{constants.DOCUMENT_LOAN_AGREEMENT, constants.DOCUMENT_INVESTMENT_AGREEMENT} {
+		t.Run(templateType, func(t *testing.T) {
+			body, err := DefaultTemplate(templateType)
+			require.NoError(t, err)
+
+			assert.NoError(t, ValidateTemplate(templateType, body))
+			assert.Contains(t, body, "{{.Company.Name}}")
+			assert.NotContains(t, body, "Amartha")
+		})
+	}
+}
+
+func TestValidateTemplate_RejectsBadTemplates(t *testing.T) {
+	cases := map[string]string{
+		"syntax error":        "# LOAN AGREEMENT\n{{.Loan.ID",
+		"unknown field":       "Borrower: {{.Loan.Nickname}}",
+		"unknown function":    "Amount: {{idr .TotalAmount}}",
+		"investment-only key": "Investor: {{.InvestorName}}",
+	}
+
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateTemplate(constants.DOCUMENT_LOAN_AGREEMENT, body)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid template")
+		})
+	}
+}
+
+func TestValidateTemplate_UnknownType(t *testing.T) {
+	err := ValidateTemplate("PROMISSORY_NOTE", "# NOTE")
+
+	assert.EqualError(t, err, "unknown template type: PROMISSORY_NOTE")
+}
+
+func TestGenerateLoanAgreement_RecordsTemplateVersion(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	generator := NewPDFGenerator(store, Config{CompanyName: "Test Lender"})
+
+	body, err := DefaultTemplate(constants.DOCUMENT_LOAN_AGREEMENT)
+	require.NoError(t, err)
+	agreementTemplate := &models2.AgreementTemplate{TemplateType: constants.DOCUMENT_LOAN_AGREEMENT, Version: 4, Body: body}
+
+	loan := sampleLoanAgreementData().Loan
+	loan.ID = uuid.New()
+	document, err := generator.GenerateLoanAgreement(loan, agreementTemplate)
+
+	require.NoError(t, err)
+	require.NotNil(t, document.TemplateVersion)
+	assert.Equal(t, 4, *document.TemplateVersion)
+	assert.Equal(t, loan.ID, document.LoanID)
+	assert.Equal(t, CONTENT_TYPE_PDF, document.ContentType)
+	assert.NotEmpty(t, document.SHA256)
+	assert.Len(t, document.VerificationID, 16)
+}
+
+func renderedText(t *testing.T, agreementTemplate *models2.AgreementTemplate, locale string) string {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	require.NoError(t, renderTemplate(pdf, agreementTemplate, sampleLoanAgreementData(), locale))
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+	return buf.String()
+}
+
+func TestRenderTemplate_EnglishAddsParallelColumn(t *testing.T) {
+	body, err := DefaultTemplate(constants.DOCUMENT_LOAN_AGREEMENT)
+	require.NoError(t, err)
+	agreementTemplate := &models2.AgreementTemplate{TemplateType: constants.DOCUMENT_LOAN_AGREEMENT, Body: body}
+
+	indonesian := renderedText(t, agreementTemplate, constants.LOCALE_INDONESIAN)
+	assert.Contains(t, indonesian, "PERJANJIAN PINJAMAN")
+	assert.Contains(t, indonesian, "Rp 1.500.000,00")
+	assert.NotContains(t, indonesian, "LOAN AGREEMENT")
+
+	bilingual := renderedText(t, agreementTemplate, constants.LOCALE_ENGLISH)
+	assert.Contains(t, bilingual, "PERJANJIAN PINJAMAN")
+	assert.Contains(t, bilingual, "LOAN AGREEMENT")
+}
+
+func TestRenderTemplate_BodyWithoutBlocksIsSingleLanguage(t *testing.T) {
+	agreementTemplate := &models2.AgreementTemplate{
+		TemplateType: constants.DOCUMENT_LOAN_AGREEMENT,
+		Body:         "# LOAN AGREEMENT\nPrincipal Amount: {{rupiah .Loan.PrincipalAmount}}",
+	}
+
+	text := renderedText(t, agreementTemplate, constants.LOCALE_ENGLISH)
+
+	assert.Contains(t, text, "LOAN AGREEMENT")
+	assert.Contains(t, text, "Rp 1.500.000,00")
+}
+
+func TestValidateTemplate_LanguageBlocksMustLineUp(t *testing.T) {
+	cases := map[string]string{
+		"line count differs": `{{define "id"}}# PERJANJIAN
+Nama: {{.Loan.BorrowerName}}{{end}}{{define "en"}}# AGREEMENT{{end}}`,
+		"line kind differs": `{{define "id"}}# PERJANJIAN{{end}}{{define "en"}}## AGREEMENT{{end}}`,
+		"signatures differ": `{{define "id"}}@signatures Peminjam | Pemberi Dana{{end}}{{define "en"}}@signatures Borrower{{end}}`,
+		"english only":      `{{define "en"}}# AGREEMENT{{end}}`,
+	}
+
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateTemplate(constants.DOCUMENT_LOAN_AGREEMENT, body)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid template")
+		})
+	}
+}
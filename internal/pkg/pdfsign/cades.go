@@ -0,0 +1,46 @@
+package pdfsign
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+
+	"github.com/digitorus/pkcs7"
+)
+
+// PAdES signatures are CAdES: besides the message digest the signed
+// attributes bind the signing certificate by its hash (RFC 5035).
+var oidSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+
+type essCertIDv2 struct {
+	// The hash algorithm defaults to SHA-256 and is left out
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// cadesDetached signs data and returns a detached CMS SignedData.
+func cadesDetached(data []byte, key crypto.Signer, certificate *x509.Certificate, chain []*x509.Certificate) ([]byte, error) {
+	signedData, err := pkcs7.NewSignedData(data)
+	if err != nil {
+		return nil, err
+	}
+	signedData.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+
+	certHash := sha256.Sum256(certificate.Raw)
+	err = signedData.AddSignerChain(certificate, key, chain, pkcs7.SignerInfoConfig{
+		ExtraSignedAttributes: []pkcs7.Attribute{{
+			Type:  oidSigningCertificateV2,
+			Value: signingCertificateV2{Certs: []essCertIDv2{{CertHash: certHash[:]}}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signedData.Detach()
+	return signedData.Finish()
+}
@@ -0,0 +1,237 @@
+package pdfsign
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// The signature is added as an incremental update, so the bytes of the
+// original document stay untouched: a signature dictionary, an invisible
+// signature field on the first page, and new versions of that page and of the
+// catalog linking them in. Only documents with a classic cross-reference
+// table, as the PDF generator writes them, are supported.
+
+// signatureSize is the space reserved for the CMS signature, enough for a
+// certificate chain of a few certificates
+const signatureSize = 16384
+
+const byteRangePlaceholder = "/ByteRange [0 0000000000 0000000000 0000000000]"
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	trailerPattern   = regexp.MustCompile(`(?s)trailer\s*<<(.*?)>>\s*startxref`)
+	sizePattern      = regexp.MustCompile(`/Size\s+(\d+)`)
+	rootPattern      = regexp.MustCompile(`/Root\s+(\d+)\s+0\s+R`)
+	infoPattern      = regexp.MustCompile(`/Info\s+(\d+)\s+0\s+R`)
+	pagePattern      = regexp.MustCompile(`(?:^|[\r\n])(\d+) 0 obj\s*<<\s*/Type\s*/Page[\s/>]`)
+)
+
+type signature struct {
+	name        string
+	reason      string
+	location    string
+	signingTime time.Time
+}
+
+type documentStructure struct {
+	size      int
+	root      int
+	info      int
+	page      int
+	startxref int
+}
+
+// signIncremental appends a signature to document. sign receives the signed
+// byte ranges and returns the DER encoded CMS signature.
+func signIncremental(document []byte, sig signature, sign func([]byte) ([]byte, error)) ([]byte, error) {
+	structure, err := parseStructure(document)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := objectDictionary(document, structure.root)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(catalog, "/AcroForm") {
+		return nil, fmt.Errorf("%w: document already has a form", ErrUnsupportedPDF)
+	}
+	page, err := objectDictionary(document, structure.page)
+	if err != nil {
+		return nil, err
+	}
+
+	sigObject := structure.size
+	fieldObject := structure.size + 1
+	offsets := map[int]int{}
+
+	var out bytes.Buffer
+	out.Write(document)
+	if !bytes.HasSuffix(document, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+
+	offsets[sigObject] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /ETSI.CAdES.detached ", sigObject)
+	byteRangeAt := out.Len()
+	out.WriteString(byteRangePlaceholder)
+	out.WriteString(" /Contents ")
+	contentsStart := out.Len()
+	out.WriteString("<" + strings.Repeat("0", signatureSize*2) + ">")
+	contentsEnd := out.Len()
+	fmt.Fprintf(&out, " /M %s", pdfString(pdfDate(sig.signingTime)))
+	for _, entry := range [][2]string{{"/Name", sig.name}, {"/Reason", sig.reason}, {"/Location", sig.location}} {
+		if entry[1] != "" {
+			fmt.Fprintf(&out, " %s %s", entry[0], pdfString(entry[1]))
+		}
+	}
+	out.WriteString(" >>\nendobj\n")
+
+	offsets[fieldObject] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<< /Type /Annot /Subtype /Widget /FT /Sig /T %s /V %d 0 R /F 132 /Rect [0 0 0 0] /P %d 0 R >>\nendobj\n",
+		fieldObject, pdfString(fmt.Sprintf("Signature%d", sigObject)), sigObject, structure.page)
+
+	offsets[structure.page] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", structure.page, addAnnotation(page, fieldObject))
+
+	offsets[structure.root] = out.Len()
+	catalog = strings.TrimSuffix(catalog, ">>") + fmt.Sprintf("\n/AcroForm << /Fields [%d 0 R] /SigFlags 3 >>", fieldObject)
+	if !strings.Contains(catalog, "/Version") {
+		catalog += "\n/Version /1.7"
+	}
+	fmt.Fprintf(&out, "%d 0 obj\n%s\n>>\nendobj\n", structure.root, catalog)
+
+	xrefAt := out.Len()
+	writeXref(&out, offsets)
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R", fieldObject+1, structure.root)
+	if structure.info > 0 {
+		fmt.Fprintf(&out, " /Info %d 0 R", structure.info)
+	}
+	fmt.Fprintf(&out, " /Prev %d >>\nstartxref\n%d\n%%%%EOF\n", structure.startxref, xrefAt)
+
+	signed := out.Bytes()
+	byteRange := fmt.Sprintf("/ByteRange [0 %d %d %d]", contentsStart, contentsEnd, len(signed)-contentsEnd)
+	copy(signed[byteRangeAt:], byteRange+strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange)))
+
+	signedBytes := make([]byte, 0, len(signed)-(contentsEnd-contentsStart))
+	signedBytes = append(signedBytes, signed[:contentsStart]...)
+	signedBytes = append(signedBytes, signed[contentsEnd:]...)
+
+	cms, err := sign(signedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign document: %w", err)
+	}
+	if len(cms) > signatureSize {
+		return nil, ErrSignatureTooLarge
+	}
+	copy(signed[contentsStart+1:], strings.ToUpper(hex.EncodeToString(cms)))
+
+	return signed, nil
+}
+
+func parseStructure(document []byte) (*documentStructure, error) {
+	startxref := startxrefPattern.FindSubmatch(document)
+	trailers := trailerPattern.FindAllSubmatch(document, -1)
+	if startxref == nil || len(trailers) == 0 {
+		return nil, fmt.Errorf("%w: no cross-reference table", ErrUnsupportedPDF)
+	}
+	trailer := trailers[len(trailers)-1][1]
+
+	structure := &documentStructure{}
+	structure.startxref, _ = strconv.Atoi(string(startxref[1]))
+	if match := sizePattern.FindSubmatch(trailer); match != nil {
+		structure.size, _ = strconv.Atoi(string(match[1]))
+	}
+	if match := rootPattern.FindSubmatch(trailer); match != nil {
+		structure.root, _ = strconv.Atoi(string(match[1]))
+	}
+	if match := infoPattern.FindSubmatch(trailer); match != nil {
+		structure.info, _ = strconv.Atoi(string(match[1]))
+	}
+	if match := pagePattern.FindSubmatch(document); match != nil {
+		structure.page, _ = strconv.Atoi(string(match[1]))
+	}
+
+	if structure.size == 0 || structure.root == 0 || structure.page == 0 {
+		return nil, fmt.Errorf("%w: missing trailer entries or pages", ErrUnsupportedPDF)
+	}
+	return structure, nil
+}
+
+// objectDictionary returns the latest definition of an object holding a
+// plain dictionary.
+func objectDictionary(document []byte, number int) (string, error) {
+	pattern := regexp.MustCompile(fmt.Sprintf(`(?s)(?:^|[\r\n])%d 0 obj\s*(.*?)\s*endobj`, number))
+	matches := pattern.FindAllSubmatch(document, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%w: object %d not found", ErrUnsupportedPDF, number)
+	}
+
+	body := string(matches[len(matches)-1][1])
+	if !strings.HasPrefix(body, "<<") || !strings.HasSuffix(body, ">>") || strings.Contains(body, "stream") {
+		return "", fmt.Errorf("%w: object %d is not a dictionary", ErrUnsupportedPDF, number)
+	}
+	return body, nil
+}
+
+func addAnnotation(page string, annotation int) string {
+	reference := fmt.Sprintf("%d 0 R", annotation)
+	if index := strings.Index(page, "/Annots ["); index >= 0 {
+		at := index + len("/Annots [")
+		return page[:at] + reference + " " + page[at:]
+	}
+	return strings.TrimSuffix(page, ">>") + "\n/Annots [" + reference + "]>>"
+}
+
+func writeXref(out *bytes.Buffer, offsets map[int]int) {
+	numbers := make([]int, 0, len(offsets))
+	for number := range offsets {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	out.WriteString("xref\n")
+	for _, number := range numbers {
+		// Each entry is exactly 20 bytes including the end of line
+		fmt.Fprintf(out, "%d 1\n%010d 00000 n\r\n", number, offsets[number])
+	}
+}
+
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("D:%s%s%02d'%02d'", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
+
+// pdfString writes ASCII text as a literal string and anything else as
+// UTF-16 with a byte order mark.
+func pdfString(value string) string {
+	ascii := true
+	for _, r := range value {
+		if r > 126 || r < 32 {
+			ascii = false
+			break
+		}
+	}
+
+	if ascii {
+		return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(value) + ")"
+	}
+
+	encoded := []byte{0xFE, 0xFF}
+	for _, unit := range utf16.Encode([]rune(value)) {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+	return "<" + strings.ToUpper(hex.EncodeToString(encoded)) + ">"
+}
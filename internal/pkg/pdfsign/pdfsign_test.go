@@ -0,0 +1,202 @@
+package pdfsign
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type testCertificate struct {
+	pfx         []byte
+	certificate *x509.Certificate
+	root        *x509.Certificate
+}
+
+// newTestCertificate issues a signing certificate from a throwaway root and
+// bundles both with the key as PKCS#12.
+func newTestCertificate(t *testing.T) testCertificate {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	root, err := x509.ParseCertificate(rootDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Loan Engine Signing", Organization: []string{"Loan Engine"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, root, &key.PublicKey, rootKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pfx, err := pkcs12.Modern.Encode(key, certificate, []*x509.Certificate{root}, "secret")
+	require.NoError(t, err)
+
+	return testCertificate{pfx: pfx, certificate: certificate, root: root}
+}
+
+func testDocument(t *testing.T, pages int) []byte {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Arial", "", 12)
+	for i := 0; i < pages; i++ {
+		pdf.AddPage()
+		pdf.Cell(0, 10, "Loan agreement")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, pdf.Output(&buf))
+	return buf.Bytes()
+}
+
+func TestSign_ProducesVerifiableSignature(t *testing.T) {
+	cert := newTestCertificate(t)
+	signer, err := NewPKCS12Signer(cert.pfx, Config{Password: "secret", Reason: "Loan agreement", Location: "Jakarta"})
+	require.NoError(t, err)
+
+	document := testDocument(t, 2)
+	signed, err := signer.Sign(document, time.Now())
+	require.NoError(t, err)
+
+	// The original bytes are kept as they were
+	assert.True(t, bytes.HasPrefix(signed, document))
+	assert.Contains(t, string(signed), "/SubFilter /ETSI.CAdES.detached")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.root)
+	result, err := Verify(signed, roots)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Loan Engine Signing", result.SignerName)
+	assert.Equal(t, Fingerprint(cert.certificate), result.Fingerprint)
+	assert.Equal(t, signer.Fingerprint(), result.Fingerprint)
+	assert.Equal(t, "ETSI.CAdES.detached", result.SubFilter)
+	assert.True(t, result.CoversWholeDocument)
+	assert.True(t, result.Trusted)
+	assert.WithinDuration(t, time.Now(), result.SigningTime, time.Minute)
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	cert := newTestCertificate(t)
+	signer, err := NewPKCS12Signer(cert.pfx, Config{Password: "secret"})
+	require.NoError(t, err)
+	signed, err := signer.Sign(testDocument(t, 1), time.Now())
+	require.NoError(t, err)
+
+	// Flip a bit in the binary comment of the header, which is covered by
+	// the first byte range but leaves the file parseable
+	tampered := append([]byte(nil), signed...)
+	tampered[11] ^= 0x01
+
+	_, err = Verify(tampered, nil)
+	assert.ErrorContains(t, err, "signature is invalid")
+}
+
+func TestVerify_ReportsContentAppendedAfterSigning(t *testing.T) {
+	cert := newTestCertificate(t)
+	signer, err := NewPKCS12Signer(cert.pfx, Config{Password: "secret"})
+	require.NoError(t, err)
+	signed, err := signer.Sign(testDocument(t, 1), time.Now())
+	require.NoError(t, err)
+
+	result, err := Verify(append(signed, []byte("\n% appended\n")...), nil)
+
+	require.NoError(t, err)
+	assert.False(t, result.CoversWholeDocument)
+	assert.False(t, result.Trusted)
+}
+
+func TestVerify_UntrustedRoot(t *testing.T) {
+	cert := newTestCertificate(t)
+	other := newTestCertificate(t)
+	signer, err := NewPKCS12Signer(cert.pfx, Config{Password: "secret"})
+	require.NoError(t, err)
+	signed, err := signer.Sign(testDocument(t, 1), time.Now())
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(other.root)
+	_, err = Verify(signed, roots)
+
+	assert.ErrorContains(t, err, "not trusted")
+}
+
+func TestVerify_UnsignedDocument(t *testing.T) {
+	_, err := Verify(testDocument(t, 1), nil)
+
+	assert.True(t, errors.Is(err, ErrNotSigned))
+}
+
+func TestSign_RefusesSignedDocument(t *testing.T) {
+	cert := newTestCertificate(t)
+	signer, err := NewPKCS12Signer(cert.pfx, Config{Password: "secret"})
+	require.NoError(t, err)
+	signed, err := signer.Sign(testDocument(t, 1), time.Now())
+	require.NoError(t, err)
+
+	_, err = signer.Sign(signed, time.Now())
+
+	assert.True(t, errors.Is(err, ErrUnsupportedPDF))
+}
+
+func TestSign_CertificateMustBeValidAtSigningTime(t *testing.T) {
+	cert := newTestCertificate(t)
+	signer, err := NewPKCS12Signer(cert.pfx, Config{Password: "secret"})
+	require.NoError(t, err)
+
+	_, err = signer.Sign(testDocument(t, 1), time.Now().Add(48*time.Hour))
+
+	assert.ErrorContains(t, err, "not valid at")
+}
+
+func TestNewPKCS12Signer_WrongPassword(t *testing.T) {
+	cert := newTestCertificate(t)
+
+	_, err := NewPKCS12Signer(cert.pfx, Config{Password: "wrong"})
+
+	assert.ErrorContains(t, err, "failed to decode signing certificate")
+}
+
+func TestNew_WithoutCertificateLeavesDocumentsUnsigned(t *testing.T) {
+	signer, err := New(Config{})
+	require.NoError(t, err)
+
+	document := testDocument(t, 1)
+	signed, err := signer.Sign(document, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, document, signed)
+	assert.Empty(t, signer.Fingerprint())
+}
+
+func TestPDFString(t *testing.T) {
+	assert.Equal(t, `(Loan \(Engine\))`, pdfString("Loan (Engine)"))
+	assert.Equal(t, "<FEFF004A00E9>", pdfString("J\u00e9"))
+}
@@ -0,0 +1,126 @@
+package pdfsign
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	ErrNotSigned          = errors.New("document is not signed")
+	ErrUnsupportedPDF     = errors.New("unsupported PDF structure")
+	ErrSignatureTooLarge  = errors.New("signature does not fit the reserved space")
+	ErrSignatureMalformed = errors.New("malformed signature")
+)
+
+// Signer applies the platform's digital signature to generated documents.
+type Signer interface {
+	Sign(document []byte, signingTime time.Time) ([]byte, error)
+	// Fingerprint is the SHA-256 of the signing certificate, empty when
+	// documents are left unsigned
+	Fingerprint() string
+}
+
+type Config struct {
+	// CertificatePath is a PKCS#12 file with the key, certificate and chain.
+	// Signing is off while it is empty.
+	CertificatePath string
+	Password        string
+	// Printed in the signature dictionary, shown by PDF readers
+	Reason   string
+	Location string
+}
+
+// New returns a PKCS#12 signer, or one that leaves documents unsigned when no
+// certificate is configured.
+func New(cfg Config) (Signer, error) {
+	if cfg.CertificatePath == "" {
+		return NewNoopSigner(), nil
+	}
+
+	pfx, err := os.ReadFile(cfg.CertificatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing certificate: %w", err)
+	}
+	return NewPKCS12Signer(pfx, cfg)
+}
+
+type noopSigner struct{}
+
+// NewNoopSigner leaves documents unsigned, for environments without a
+// signing certificate.
+func NewNoopSigner() Signer {
+	return noopSigner{}
+}
+
+func (noopSigner) Sign(document []byte, signingTime time.Time) ([]byte, error) {
+	return document, nil
+}
+
+func (noopSigner) Fingerprint() string {
+	return ""
+}
+
+type pkcs12Signer struct {
+	key         crypto.Signer
+	certificate *x509.Certificate
+	chain       []*x509.Certificate
+	reason      string
+	location    string
+}
+
+// NewPKCS12Signer signs with the key and certificate in a PKCS#12 bundle.
+func NewPKCS12Signer(pfx []byte, cfg Config) (Signer, error) {
+	key, certificate, chain, err := pkcs12.DecodeChain(pfx, cfg.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signing certificate: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	if certificate.KeyUsage != 0 && certificate.KeyUsage&(x509.KeyUsageDigitalSignature|x509.KeyUsageContentCommitment) == 0 {
+		return nil, fmt.Errorf("signing certificate is not valid for digital signatures")
+	}
+
+	return &pkcs12Signer{
+		key:         signer,
+		certificate: certificate,
+		chain:       chain,
+		reason:      cfg.Reason,
+		location:    cfg.Location,
+	}, nil
+}
+
+func (s *pkcs12Signer) Sign(document []byte, signingTime time.Time) ([]byte, error) {
+	if signingTime.After(s.certificate.NotAfter) || signingTime.Before(s.certificate.NotBefore) {
+		return nil, fmt.Errorf("signing certificate is not valid at %s", signingTime.Format(time.RFC3339))
+	}
+
+	return signIncremental(document, signature{
+		name:        s.certificate.Subject.CommonName,
+		reason:      s.reason,
+		location:    s.location,
+		signingTime: signingTime,
+	}, func(signedBytes []byte) ([]byte, error) {
+		return cadesDetached(signedBytes, s.key, s.certificate, s.chain)
+	})
+}
+
+func (s *pkcs12Signer) Fingerprint() string {
+	return Fingerprint(s.certificate)
+}
+
+// Fingerprint is the hex SHA-256 of the certificate's DER encoding.
+func Fingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package pdfsign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/digitorus/pkcs7"
+)
+
+var (
+	byteRangePattern = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+	subFilterPattern = regexp.MustCompile(`/SubFilter\s*/([A-Za-z0-9.]+)`)
+)
+
+// Result describes the last signature of a document.
+type Result struct {
+	SignerName  string    `json:"signer_name"`
+	Fingerprint string    `json:"fingerprint"`
+	SigningTime time.Time `json:"signing_time"`
+	SubFilter   string    `json:"sub_filter"`
+	// CoversWholeDocument is false when content was appended after signing
+	CoversWholeDocument bool `json:"covers_whole_document"`
+	// Trusted is set when the signer chains up to one of the given roots
+	Trusted bool `json:"trusted"`
+}
+
+// Verify checks the last signature of a document: the signed byte ranges
+// against the message digest, the signature against the signer certificate
+// and, for PAdES signatures, the signing certificate attribute. With roots the
+// certificate chain is verified as well.
+func Verify(document []byte, roots *x509.CertPool) (*Result, error) {
+	matches := byteRangePattern.FindAllSubmatchIndex(document, -1)
+	if len(matches) == 0 {
+		return nil, ErrNotSigned
+	}
+	match := matches[len(matches)-1]
+
+	var byteRange [4]int
+	for i := range byteRange {
+		byteRange[i], _ = strconv.Atoi(string(document[match[2+2*i]:match[3+2*i]]))
+	}
+	start, gapStart, gapEnd, tail := byteRange[0], byteRange[1], byteRange[2], byteRange[3]
+	if start != 0 || gapStart >= gapEnd || gapEnd+tail > len(document) ||
+		document[gapStart] != '<' || document[gapEnd-1] != '>' {
+		return nil, fmt.Errorf("%w: byte range does not frame the signature", ErrSignatureMalformed)
+	}
+
+	contents, err := hex.DecodeString(string(document[gapStart+1 : gapEnd-1]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureMalformed, err)
+	}
+	// The reserved space is padded with zeros after the DER value
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(contents, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureMalformed, err)
+	}
+
+	p7, err := pkcs7.Parse(raw.FullBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureMalformed, err)
+	}
+	signedBytes := make([]byte, 0, gapStart+tail)
+	signedBytes = append(signedBytes, document[:gapStart]...)
+	signedBytes = append(signedBytes, document[gapEnd:gapEnd+tail]...)
+	p7.Content = signedBytes
+
+	if err := p7.Verify(); err != nil {
+		return nil, fmt.Errorf("signature is invalid: %w", err)
+	}
+
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return nil, fmt.Errorf("%w: expected exactly one signer", ErrSignatureMalformed)
+	}
+
+	result := &Result{
+		SignerName:          signer.Subject.CommonName,
+		Fingerprint:         Fingerprint(signer),
+		CoversWholeDocument: gapEnd+tail == len(document),
+	}
+	_ = p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &result.SigningTime)
+
+	dictionaryStart := bytes.LastIndex(document[:gapStart], []byte(" obj"))
+	dictionaryEnd := bytes.Index(document[gapEnd:], []byte("endobj"))
+	if dictionaryStart >= 0 && dictionaryEnd >= 0 {
+		if match := subFilterPattern.FindSubmatch(document[dictionaryStart : gapEnd+dictionaryEnd]); match != nil {
+			result.SubFilter = string(match[1])
+		}
+	}
+
+	if result.SubFilter == "ETSI.CAdES.detached" {
+		var attribute signingCertificateV2
+		if err := p7.UnmarshalSignedAttribute(oidSigningCertificateV2, &attribute); err != nil || len(attribute.Certs) == 0 {
+			return nil, fmt.Errorf("signature is invalid: missing signing certificate attribute")
+		}
+		certHash := sha256.Sum256(signer.Raw)
+		if !bytes.Equal(attribute.Certs[0].CertHash, certHash[:]) {
+			return nil, fmt.Errorf("signature is invalid: signing certificate attribute does not match the signer")
+		}
+	}
+
+	if roots != nil {
+		intermediates := x509.NewCertPool()
+		for _, certificate := range p7.Certificates {
+			intermediates.AddCert(certificate)
+		}
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}
+		if !result.SigningTime.IsZero() {
+			opts.CurrentTime = result.SigningTime
+		}
+		if _, err := signer.Verify(opts); err != nil {
+			return nil, fmt.Errorf("signer certificate is not trusted: %w", err)
+		}
+		result.Trusted = true
+	}
+
+	return result, nil
+}
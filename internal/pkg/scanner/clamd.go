@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_CLAMD_NETWORK = "tcp"
+	DEFAULT_CLAMD_ADDRESS = "localhost:3310"
+	DEFAULT_CLAMD_TIMEOUT = 30 * time.Second
+
+	// clamdChunkSize stays well below clamd's default StreamMaxLength
+	clamdChunkSize = 64 << 10
+)
+
+// ClamdConfig points at a clamd daemon, either a unix socket such as
+// /var/run/clamav/clamd.ctl or a tcp address.
+type ClamdConfig struct {
+	Network string
+	Address string
+	Timeout time.Duration
+}
+
+type clamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func NewClamdScanner(cfg ClamdConfig) (Scanner, error) {
+	if cfg.Network == "" {
+		cfg.Network = DEFAULT_CLAMD_NETWORK
+	}
+	if cfg.Network != "tcp" && cfg.Network != "unix" {
+		return nil, fmt.Errorf("unsupported clamd network: %s", cfg.Network)
+	}
+	if cfg.Address == "" {
+		cfg.Address = DEFAULT_CLAMD_ADDRESS
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DEFAULT_CLAMD_TIMEOUT
+	}
+
+	return &clamdScanner{
+		network: cfg.Network,
+		address: cfg.Address,
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+// Scan streams the content with the INSTREAM command. Connection failures and
+// daemon errors are reported as ErrUnavailable.
+func (s *clamdScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	if err := s.stream(conn, r); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	return parseClamdReply(reply)
+}
+
+func (s *clamdScanner) stream(conn net.Conn, r io.Reader) error {
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, err := r.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	// A zero length chunk ends the stream
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// parseClamdReply reads "stream: OK", "stream: <signature> FOUND" or
+// "<message> ERROR".
+func parseClamdReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	status := strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case status == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return &Result{
+			Infected:  true,
+			Signature: strings.TrimSuffix(status, " FOUND"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: clamd replied %q", ErrUnavailable, reply)
+	}
+}
@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eicar is the standard antivirus test file, split so this source file is not
+// flagged itself.
+var eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$` + `EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd speaks enough of the clamd protocol to answer INSTREAM on a unix
+// socket, flagging content that contains the EICAR string.
+func fakeClamd(t *testing.T) string {
+	address := filepath.Join(t.TempDir(), "clamd.sock")
+	listener, err := net.Listen("unix", address)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveClamd(conn)
+		}
+	}()
+
+	return address
+}
+
+func serveClamd(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	command, err := reader.ReadString(0)
+	if err != nil || command != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+
+	var content bytes.Buffer
+	for {
+		var size uint32
+		if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&content, reader, int64(size)); err != nil {
+			return
+		}
+	}
+
+	if strings.Contains(content.String(), eicar) {
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		return
+	}
+	conn.Write([]byte("stream: OK\x00"))
+}
+
+func TestClamdScanner(t *testing.T) {
+	address := fakeClamd(t)
+	scanner, err := NewClamdScanner(ClamdConfig{Network: "unix", Address: address, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("clean survey photo ", 10000)))
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader("%PDF-1.4\n"+eicar))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+}
+
+func TestClamdScanner_DaemonDown(t *testing.T) {
+	scanner, err := NewClamdScanner(ClamdConfig{
+		Network: "unix",
+		Address: filepath.Join(t.TempDir(), "missing.sock"),
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader("file"))
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestParseClamdReply(t *testing.T) {
+	result, err := parseClamdReply("stream: OK\x00")
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = parseClamdReply("stream: Win.Test.EICAR_HDB-1 FOUND\x00")
+	require.NoError(t, err)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", result.Signature)
+
+	_, err = parseClamdReply("INSTREAM size limit exceeded. ERROR\x00")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+// TestClamdScanner_Daemon runs against a real clamd, for example
+//
+//	docker run -p 3310:3310 clamav/clamav
+//	CLAMD_TEST_ADDRESS=localhost:3310 go test ./internal/pkg/scanner/
+func TestClamdScanner_Daemon(t *testing.T) {
+	address := os.Getenv("CLAMD_TEST_ADDRESS")
+	if address == "" {
+		t.Skip("CLAMD_TEST_ADDRESS not set")
+	}
+
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	scanner, err := NewClamdScanner(ClamdConfig{Network: network, Address: address})
+	require.NoError(t, err)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(eicar))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+}
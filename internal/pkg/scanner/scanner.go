@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	DRIVER_NONE  = "none"
+	DRIVER_CLAMD = "clamd"
+)
+
+var (
+	// ErrUnavailable means the file could not be scanned and should be retried.
+	ErrUnavailable = errors.New("scanner unavailable")
+	ErrInfected    = errors.New("malware detected")
+)
+
+type Result struct {
+	Infected bool
+	// Signature names the detected malware
+	Signature string
+}
+
+// Scanner checks file content for malware before it is committed.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+type Config struct {
+	Driver string
+	Clamd  ClamdConfig
+}
+
+// New returns the scanner selected by cfg.Driver. Scanning is off by default.
+func New(cfg Config) (Scanner, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", DRIVER_NONE:
+		return NewNoopScanner(), nil
+	case DRIVER_CLAMD:
+		return NewClamdScanner(cfg.Clamd)
+	default:
+		return nil, fmt.Errorf("unknown scanner driver: %s", cfg.Driver)
+	}
+}
+
+type noopScanner struct{}
+
+// NewNoopScanner reports every file as clean, for environments without a
+// scanning daemon.
+func NewNoopScanner() Scanner {
+	return noopScanner{}
+}
+
+func (noopScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	return &Result{}, nil
+}
@@ -0,0 +1,116 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DRIVER_NONE = "none"
+	DRIVER_FILE = "file"
+)
+
+var ErrNoRecipient = errors.New("message has no recipient")
+
+type Message struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// Sender delivers text messages to phone numbers.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type Config struct {
+	Driver string
+	File   FileConfig
+}
+
+type FileConfig struct {
+	// Path of the JSON lines file messages are appended to
+	Path string
+}
+
+// New returns the sender selected by cfg.Driver. There is no SMS gateway yet,
+// the file driver stands in for one during development.
+func New(cfg Config) (Sender, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", DRIVER_NONE:
+		return NewNoopSender(), nil
+	case DRIVER_FILE:
+		return NewFileSender(cfg.File.Path)
+	default:
+		return nil, fmt.Errorf("unknown sms driver: %s", cfg.Driver)
+	}
+}
+
+type noopSender struct{}
+
+// NewNoopSender drops every message.
+func NewNoopSender() Sender {
+	return noopSender{}
+}
+
+func (noopSender) Send(ctx context.Context, message Message) error {
+	if message.To == "" {
+		return ErrNoRecipient
+	}
+	return nil
+}
+
+type fileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSender appends every message to a JSON lines file instead of sending
+// it, so codes can be read back in development and tests.
+func NewFileSender(path string) (Sender, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sms file path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create sms directory: %w", err)
+	}
+
+	return &fileSender{path: path}, nil
+}
+
+type fileRecord struct {
+	Message
+	SentAt time.Time `json:"sent_at"`
+}
+
+func (s *fileSender) Send(ctx context.Context, message Message) error {
+	if message.To == "" {
+		return ErrNoRecipient
+	}
+
+	line, err := json.Marshal(fileRecord{Message: message, SentAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open sms file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	return nil
+}
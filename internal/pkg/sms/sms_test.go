@@ -0,0 +1,56 @@
+package sms
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender_AppendsMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox", "sms.jsonl")
+	sender, err := New(Config{Driver: "file", File: FileConfig{Path: path}})
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(context.Background(), Message{To: "081234567890", Body: "first"}))
+	require.NoError(t, sender.Send(context.Background(), Message{To: "081234567891", Body: "second"}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []fileRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record fileRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "081234567890", records[0].To)
+	assert.Equal(t, "first", records[0].Body)
+	assert.Equal(t, "second", records[1].Body)
+	assert.False(t, records[1].SentAt.IsZero())
+}
+
+func TestSend_RequiresRecipient(t *testing.T) {
+	sender, err := New(Config{Driver: "file", File: FileConfig{Path: filepath.Join(t.TempDir(), "sms.jsonl")}})
+	require.NoError(t, err)
+
+	err = sender.Send(context.Background(), Message{Body: "no one"})
+
+	assert.True(t, errors.Is(err, ErrNoRecipient))
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := New(Config{Driver: "carrier-pigeon"})
+
+	assert.EqualError(t, err, "unknown sms driver: carrier-pigeon")
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	DRIVER_LOCAL = "local"
+	DRIVER_S3    = "s3"
+
+	// URL_PREFIX is where stored objects were historically served from. Keys are
+	// recorded in the database as URL_PREFIX + key.
+	URL_PREFIX = "/uploads/"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Store is the object storage used for uploads and generated documents. Keys
+// are slash separated paths such as "agreements/loan_agreement_<id>.pdf".
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+}
+
+type Config struct {
+	Driver string
+	Local  LocalConfig
+	S3     S3Config
+}
+
+func New(cfg Config) (Store, error) {
+	switch cfg.Driver {
+	case "", DRIVER_LOCAL:
+		return NewLocalStore(cfg.Local.Root)
+	case DRIVER_S3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+}
+
+// PutWithChecksum stores r and returns the number of bytes written with their
+// hex encoded SHA-256.
+func PutWithChecksum(ctx context.Context, store Store, key string, r io.Reader, size int64, contentType string) (int64, string, error) {
+	hash := sha256.New()
+	counter := &countingWriter{}
+
+	if err := store.Put(ctx, key, io.TeeReader(r, io.MultiWriter(hash, counter)), size, contentType); err != nil {
+		return 0, "", err
+	}
+
+	return counter.n, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// CleanKey normalises a key and rejects anything that could escape the store root.
+func CleanKey(key string) (string, error) {
+	slashed := strings.ReplaceAll(strings.TrimPrefix(key, URL_PREFIX), "\\", "/")
+	for _, segment := range strings.Split(slashed, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+
+	cleaned := strings.TrimPrefix(path.Clean("/"+slashed), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return cleaned, nil
+}
+
+// URLFor returns the path recorded for a stored key.
+func URLFor(key string) string {
+	return URL_PREFIX + key
+}
+
+// KeyFromURL reverses URLFor for paths stored before keys were recorded directly.
+func KeyFromURL(url string) (string, error) {
+	if !strings.HasPrefix(url, URL_PREFIX) {
+		return "", fmt.Errorf("not a stored object url: %q", url)
+	}
+	return CleanKey(strings.TrimPrefix(url, URL_PREFIX))
+}
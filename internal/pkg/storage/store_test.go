@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore exercises the contract every backend has to honour.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	key := "agreements/loan_agreement_" + uuid.NewString() + ".pdf"
+	content := "%PDF-1.4 test"
+
+	err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "application/pdf")
+	require.NoError(t, err)
+
+	info, err := store.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, key, info.Key)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, "application/pdf", info.ContentType)
+
+	// Recorded URLs resolve to the same object
+	object, info, err := store.Get(ctx, URLFor(key))
+	require.NoError(t, err)
+	data, err := io.ReadAll(object)
+	object.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, key, info.Key)
+
+	require.NoError(t, store.Delete(ctx, key))
+
+	_, err = store.Stat(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, _, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Delete(ctx, key), ErrNotFound)
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	testStore(t, store)
+}
+
+func TestLocalStore_RejectsEscapingKeys(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root + "/objects")
+	require.NoError(t, err)
+
+	err = store.Put(context.Background(), "../outside.txt", strings.NewReader("x"), 1, "text/plain")
+
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, statErr := os.Stat(root + "/outside.txt")
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+// TestS3Store runs against a MinIO or other S3-compatible server, for example
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	STORAGE_TEST_S3_ENDPOINT=localhost:9000 go test ./internal/pkg/storage/
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("STORAGE_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("STORAGE_TEST_S3_ENDPOINT not set")
+	}
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:     endpoint,
+		Region:       "us-east-1",
+		Bucket:       envOr("STORAGE_TEST_S3_BUCKET", "loan-engine-test"),
+		AccessKey:    envOr("STORAGE_TEST_S3_ACCESS_KEY", "minioadmin"),
+		SecretKey:    envOr("STORAGE_TEST_S3_SECRET_KEY", "minioadmin"),
+		UseSSL:       os.Getenv("STORAGE_TEST_S3_USE_SSL") == "true",
+		Prefix:       "test",
+		CreateBucket: true,
+	})
+	require.NoError(t, err)
+
+	testStore(t, store)
+}
+
+func TestCleanKey(t *testing.T) {
+	cases := map[string]string{
+		"/uploads/survey_documents/a.jpg": "survey_documents/a.jpg",
+		"agreements//b.pdf":               "agreements/b.pdf",
+		"agreements\\c.pdf":               "agreements/c.pdf",
+		"agreements/d..pdf":               "agreements/d..pdf",
+	}
+	for input, expected := range cases {
+		key, err := CleanKey(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, key, input)
+	}
+
+	for _, input := range []string{"", "/", "../etc/passwd", "agreements/../../x"} {
+		_, err := CleanKey(input)
+		assert.ErrorIs(t, err, ErrInvalidKey, input)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
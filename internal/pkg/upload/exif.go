@@ -0,0 +1,273 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	TAG_ORIENTATION          = 0x0112
+	TAG_DATE_TIME            = 0x0132
+	TAG_EXIF_IFD             = 0x8769
+	TAG_GPS_IFD              = 0x8825
+	TAG_DATE_TIME_ORIGINAL   = 0x9003
+	TAG_OFFSET_TIME_ORIGINAL = 0x9011
+
+	TAG_GPS_LATITUDE_REF  = 0x0001
+	TAG_GPS_LATITUDE      = 0x0002
+	TAG_GPS_LONGITUDE_REF = 0x0003
+	TAG_GPS_LONGITUDE     = 0x0004
+
+	// EXIF_TIME_LAYOUT is how EXIF writes date and time, without a zone
+	EXIF_TIME_LAYOUT = "2006:01:02 15:04:05"
+
+	// maxIFDEntries guards against corrupt counts
+	maxIFDEntries = 512
+)
+
+// exifHeader prefixes the TIFF structure inside a JPEG APP1 segment.
+var exifHeader = []byte("Exif\x00\x00")
+
+// typeSizes holds the byte size of each TIFF field type.
+var typeSizes = map[uint16]uint32{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8,
+}
+
+// ifdEntry keeps a field's value bytes in the byte order of the source file.
+type ifdEntry struct {
+	Tag   uint16
+	Type  uint16
+	Count uint32
+	Value []byte
+}
+
+type exifData struct {
+	order       binary.ByteOrder
+	orientation int
+	gps         []ifdEntry
+	// captureTime and captureOffset are kept as written, see Metadata
+	captureTime   string
+	captureOffset string
+}
+
+// parseExif reads the orientation, capture time and GPS directory from a TIFF
+// structure.
+func parseExif(tiff []byte) (*exifData, error) {
+	if len(tiff) < 8 {
+		return nil, fmt.Errorf("exif too short")
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("invalid exif header")
+	}
+
+	ifd0, err := readIFD(tiff, order, order.Uint32(tiff[4:8]))
+	if err != nil {
+		return nil, err
+	}
+
+	exif := &exifData{order: order, orientation: 1}
+	for _, entry := range ifd0 {
+		switch entry.Tag {
+		case TAG_ORIENTATION:
+			if entry.Type == 3 && entry.Count == 1 {
+				exif.orientation = int(order.Uint16(entry.Value))
+			}
+		case TAG_DATE_TIME:
+			if exif.captureTime == "" {
+				exif.captureTime = asciiValue(entry)
+			}
+		case TAG_EXIF_IFD:
+			if entry.Type != 4 || entry.Count != 1 {
+				continue
+			}
+			// A broken sub-directory only loses the capture time
+			sub, err := readIFD(tiff, order, order.Uint32(entry.Value))
+			if err != nil {
+				continue
+			}
+			for _, subEntry := range sub {
+				switch subEntry.Tag {
+				case TAG_DATE_TIME_ORIGINAL:
+					exif.captureTime = asciiValue(subEntry)
+				case TAG_OFFSET_TIME_ORIGINAL:
+					exif.captureOffset = asciiValue(subEntry)
+				}
+			}
+		case TAG_GPS_IFD:
+			if entry.Type != 4 || entry.Count != 1 {
+				continue
+			}
+			gps, err := readIFD(tiff, order, order.Uint32(entry.Value))
+			if err != nil {
+				return nil, err
+			}
+			exif.gps = gps
+		}
+	}
+
+	return exif, nil
+}
+
+// coordinates converts the GPS directory to signed decimal degrees.
+func (e *exifData) coordinates() (float64, float64, bool) {
+	var latRef, lonRef string
+	var lat, lon []byte
+	for _, entry := range e.gps {
+		switch entry.Tag {
+		case TAG_GPS_LATITUDE_REF:
+			latRef = asciiValue(entry)
+		case TAG_GPS_LONGITUDE_REF:
+			lonRef = asciiValue(entry)
+		case TAG_GPS_LATITUDE:
+			if entry.Type == 5 && entry.Count == 3 {
+				lat = entry.Value
+			}
+		case TAG_GPS_LONGITUDE:
+			if entry.Type == 5 && entry.Count == 3 {
+				lon = entry.Value
+			}
+		}
+	}
+
+	latitude, ok := e.degrees(lat)
+	if !ok || (latRef != "N" && latRef != "S") {
+		return 0, 0, false
+	}
+	longitude, ok := e.degrees(lon)
+	if !ok || (lonRef != "E" && lonRef != "W") {
+		return 0, 0, false
+	}
+
+	if latRef == "S" {
+		latitude = -latitude
+	}
+	if lonRef == "W" {
+		longitude = -longitude
+	}
+	if latitude > 90 || longitude > 180 {
+		return 0, 0, false
+	}
+	return latitude, longitude, true
+}
+
+// degrees reads three rationals holding degrees, minutes and seconds.
+func (e *exifData) degrees(value []byte) (float64, bool) {
+	if len(value) != 24 {
+		return 0, false
+	}
+
+	var parts [3]float64
+	for i := range parts {
+		numerator := e.order.Uint32(value[i*8:])
+		denominator := e.order.Uint32(value[i*8+4:])
+		if denominator == 0 {
+			return 0, false
+		}
+		parts[i] = float64(numerator) / float64(denominator)
+	}
+	return parts[0] + parts[1]/60 + parts[2]/3600, true
+}
+
+func asciiValue(entry ifdEntry) string {
+	if entry.Type != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(entry.Value), "\x00"))
+}
+
+func readIFD(tiff []byte, order binary.ByteOrder, offset uint32) ([]ifdEntry, error) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil, fmt.Errorf("exif directory out of range")
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	if count > maxIFDEntries || uint64(offset)+2+uint64(count)*12 > uint64(len(tiff)) {
+		return nil, fmt.Errorf("exif directory out of range")
+	}
+
+	entries := make([]ifdEntry, 0, count)
+	for i := 0; i < count; i++ {
+		raw := tiff[offset+2+uint32(i)*12:]
+		entry := ifdEntry{
+			Tag:   order.Uint16(raw[0:2]),
+			Type:  order.Uint16(raw[2:4]),
+			Count: order.Uint32(raw[4:8]),
+		}
+
+		size, ok := typeSizes[entry.Type]
+		if !ok {
+			continue
+		}
+		total := uint64(size) * uint64(entry.Count)
+		if total <= 4 {
+			entry.Value = append([]byte(nil), raw[8:8+total]...)
+		} else {
+			valueOffset := uint64(order.Uint32(raw[8:12]))
+			if valueOffset+total > uint64(len(tiff)) {
+				continue
+			}
+			entry.Value = append([]byte(nil), tiff[valueOffset:valueOffset+total]...)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// buildGPSExif writes a TIFF structure holding only the GPS directory.
+func buildGPSExif(order binary.ByteOrder, gps []ifdEntry) []byte {
+	entries := append([]ifdEntry(nil), gps...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Tag < entries[j].Tag })
+
+	const gpsOffset = 8 + 2 + 12 + 4
+	dataOffset := uint32(gpsOffset + 2 + 12*len(entries) + 4)
+
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II*\x00")
+	} else {
+		buf.WriteString("MM\x00*")
+	}
+	binary.Write(&buf, order, uint32(8))
+
+	// IFD0 only points at the GPS directory
+	binary.Write(&buf, order, uint16(1))
+	binary.Write(&buf, order, uint16(TAG_GPS_IFD))
+	binary.Write(&buf, order, uint16(4))
+	binary.Write(&buf, order, uint32(1))
+	binary.Write(&buf, order, uint32(gpsOffset))
+	binary.Write(&buf, order, uint32(0))
+
+	var data bytes.Buffer
+	binary.Write(&buf, order, uint16(len(entries)))
+	for _, entry := range entries {
+		binary.Write(&buf, order, entry.Tag)
+		binary.Write(&buf, order, entry.Type)
+		binary.Write(&buf, order, entry.Count)
+		if len(entry.Value) <= 4 {
+			value := make([]byte, 4)
+			copy(value, entry.Value)
+			buf.Write(value)
+			continue
+		}
+		binary.Write(&buf, order, dataOffset+uint32(data.Len()))
+		data.Write(entry.Value)
+		if data.Len()%2 == 1 {
+			data.WriteByte(0)
+		}
+	}
+	binary.Write(&buf, order, uint32(0))
+	buf.Write(data.Bytes())
+
+	return buf.Bytes()
+}
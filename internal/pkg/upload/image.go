@@ -0,0 +1,203 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+const JPEG_QUALITY = 90
+
+// sanitizeJPEG re-encodes the image, which drops every metadata segment, then
+// writes back an EXIF block holding only the GPS directory. The orientation
+// tag is applied to the pixels since it does not survive.
+func sanitizeJPEG(data []byte) ([]byte, error) {
+	img, err := decodeImage(data, jpeg.DecodeConfig, jpeg.Decode)
+	if err != nil {
+		return nil, err
+	}
+
+	exif := jpegExif(data)
+	if exif != nil {
+		img = applyOrientation(img, exif.orientation)
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: JPEG_QUALITY}); err != nil {
+		return nil, err
+	}
+	encoded := out.Bytes()
+
+	if exif == nil || len(exif.gps) == 0 {
+		return encoded, nil
+	}
+
+	segment := append(append([]byte(nil), exifHeader...), buildGPSExif(exif.order, exif.gps)...)
+	if len(segment)+2 > 0xFFFF {
+		return encoded, nil
+	}
+
+	result := make([]byte, 0, len(encoded)+len(segment)+4)
+	result = append(result, encoded[:2]...)
+	result = append(result, 0xFF, 0xE1)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(segment)+2))
+	result = append(result, segment...)
+	result = append(result, encoded[2:]...)
+	return result, nil
+}
+
+// sanitizePNG re-encodes the image without ancillary chunks, keeping GPS in a
+// fresh eXIf chunk.
+func sanitizePNG(data []byte) ([]byte, error) {
+	img, err := decodeImage(data, png.DecodeConfig, png.Decode)
+	if err != nil {
+		return nil, err
+	}
+
+	exif := pngExif(data)
+	if exif != nil {
+		img = applyOrientation(img, exif.orientation)
+	}
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		return nil, err
+	}
+	encoded := out.Bytes()
+
+	if exif == nil || len(exif.gps) == 0 {
+		return encoded, nil
+	}
+
+	// The chunk goes straight after the signature and IHDR
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	chunk := pngChunk("eXIf", buildGPSExif(exif.order, exif.gps))
+
+	result := make([]byte, 0, len(encoded)+len(chunk))
+	result = append(result, encoded[:ihdrEnd]...)
+	result = append(result, chunk...)
+	result = append(result, encoded[ihdrEnd:]...)
+	return result, nil
+}
+
+func decodeImage(data []byte, decodeConfig func(r io.Reader) (image.Config, error), decode func(r io.Reader) (image.Image, error)) (image.Image, error) {
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if int64(config.Width)*int64(config.Height) > MAX_IMAGE_PIXELS {
+		return nil, ErrImageTooLarge
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	return img, nil
+}
+
+// jpegExif finds the APP1 EXIF segment. Unreadable metadata is treated as
+// absent, it is dropped either way.
+func jpegExif(data []byte) *exifData {
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			i++
+			continue
+		}
+		// Start of scan, metadata segments come before it
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			i += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+		payload := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(payload, exifHeader) {
+			exif, err := parseExif(payload[len(exifHeader):])
+			if err != nil {
+				return nil
+			}
+			return exif
+		}
+		i += 2 + length
+	}
+	return nil
+}
+
+func pngExif(data []byte) *exifData {
+	for i := 8; i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		if length < 0 || i+12+length > len(data) {
+			return nil
+		}
+		if string(data[i+4:i+8]) == "eXIf" {
+			exif, err := parseExif(data[i+8 : i+8+length])
+			if err != nil {
+				return nil
+			}
+			return exif
+		}
+		i += 12 + length
+	}
+	return nil
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// applyOrientation turns the pixels upright for EXIF orientations 2 to 8.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,115 @@
+package upload
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strconv"
+)
+
+// maxInflatedPDF caps how much compressed stream data is expanded while
+// looking for hidden names.
+const maxInflatedPDF = 64 << 20
+
+// inspectPDF rejects encrypted documents and documents with JavaScript. Names
+// are checked in the file body and inside compressed streams, where object
+// streams can hide them, after decoding #xx escapes.
+func inspectPDF(data []byte) error {
+	names := pdfNames(data)
+	if names["Encrypt"] {
+		return ErrPDFEncrypted
+	}
+	if names["JavaScript"] || names["JS"] {
+		return ErrPDFJavaScript
+	}
+
+	budget := int64(maxInflatedPDF)
+	for _, stream := range pdfStreams(data) {
+		if budget <= 0 {
+			break
+		}
+		reader, err := zlib.NewReader(bytes.NewReader(stream))
+		if err != nil {
+			continue
+		}
+		inflated, _ := io.ReadAll(io.LimitReader(reader, budget))
+		reader.Close()
+		budget -= int64(len(inflated))
+
+		names := pdfNames(inflated)
+		if names["JavaScript"] || names["JS"] {
+			return ErrPDFJavaScript
+		}
+	}
+
+	return nil
+}
+
+// pdfNames collects every /Name token with escapes decoded.
+func pdfNames(data []byte) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < len(data); i++ {
+		if data[i] != '/' {
+			continue
+		}
+
+		var name []byte
+		j := i + 1
+		for ; j < len(data) && !isPDFDelimiter(data[j]); j++ {
+			if data[j] == '#' && j+2 < len(data) {
+				if value, err := strconv.ParseUint(string(data[j+1:j+3]), 16, 8); err == nil {
+					name = append(name, byte(value))
+					j += 2
+					continue
+				}
+			}
+			name = append(name, data[j])
+		}
+
+		if len(name) > 0 {
+			names[string(name)] = true
+		}
+		i = j - 1
+	}
+	return names
+}
+
+// pdfStreams returns the raw bytes between each stream and endstream keyword.
+func pdfStreams(data []byte) [][]byte {
+	var streams [][]byte
+	for offset := 0; ; {
+		start := bytes.Index(data[offset:], []byte("stream"))
+		if start < 0 {
+			return streams
+		}
+		start += offset + len("stream")
+
+		// endstream also contains the keyword, skip it
+		if start-len("endstream") >= 0 && bytes.HasSuffix(data[:start], []byte("endstream")) {
+			offset = start
+			continue
+		}
+
+		if start < len(data) && data[start] == '\r' {
+			start++
+		}
+		if start < len(data) && data[start] == '\n' {
+			start++
+		}
+
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			return streams
+		}
+		streams = append(streams, data[start:start+end])
+		offset = start + end + len("endstream")
+	}
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
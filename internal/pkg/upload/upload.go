@@ -0,0 +1,157 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	CONTENT_TYPE_JPEG = "image/jpeg"
+	CONTENT_TYPE_PNG  = "image/png"
+	CONTENT_TYPE_PDF  = "application/pdf"
+
+	// MAX_IMAGE_PIXELS bounds decoding so a small file cannot expand into a huge bitmap
+	MAX_IMAGE_PIXELS = 50_000_000
+)
+
+var (
+	ErrTooLarge         = errors.New("file size exceeds maximum limit")
+	ErrUnsupportedType  = errors.New("unsupported file content")
+	ErrTypeMismatch     = errors.New("file content does not match extension")
+	ErrInvalidImage     = errors.New("invalid image")
+	ErrImageTooLarge    = errors.New("image dimensions exceed maximum")
+	ErrPDFJavaScript    = errors.New("pdf contains javascript")
+	ErrPDFEncrypted     = errors.New("pdf is encrypted")
+	ErrInvalidExtension = errors.New("invalid file type")
+)
+
+// extensionTypes maps each accepted extension to the content it must hold.
+var extensionTypes = map[string]string{
+	".jpg":  CONTENT_TYPE_JPEG,
+	".jpeg": CONTENT_TYPE_JPEG,
+	".png":  CONTENT_TYPE_PNG,
+	".pdf":  CONTENT_TYPE_PDF,
+}
+
+// File is an upload that passed inspection, ready to be stored.
+type File struct {
+	Data        []byte
+	ContentType string
+	// Metadata is read from the original image before it is stripped
+	Metadata Metadata
+}
+
+// Metadata holds what survey checks need from a photo's EXIF block.
+type Metadata struct {
+	Latitude  *float64
+	Longitude *float64
+	// CaptureTime is EXIF's local date and time, CaptureOffset its zone when
+	// the camera recorded one
+	CaptureTime   string
+	CaptureOffset string
+}
+
+// CapturedAt resolves the capture time, in loc when the photo has no offset.
+func (m Metadata) CapturedAt(loc *time.Location) (*time.Time, bool) {
+	if m.CaptureTime == "" {
+		return nil, false
+	}
+
+	if m.CaptureOffset != "" {
+		if captured, err := time.Parse(EXIF_TIME_LAYOUT+"-07:00", m.CaptureTime+m.CaptureOffset); err == nil {
+			return &captured, true
+		}
+	}
+
+	captured, err := time.ParseInLocation(EXIF_TIME_LAYOUT, m.CaptureTime, loc)
+	if err != nil {
+		return nil, false
+	}
+	return &captured, true
+}
+
+func (f *File) Size() int64 {
+	return int64(len(f.Data))
+}
+
+func (f *File) Reader() io.Reader {
+	return bytes.NewReader(f.Data)
+}
+
+// Inspect reads at most maxSize bytes, checks the content against the file
+// name's extension and returns a sanitized copy. Images are re-encoded without
+// metadata other than GPS, PDFs with JavaScript or encryption are rejected.
+func Inspect(r io.Reader, fileName string, maxSize int64) (*File, error) {
+	expected, ok := extensionTypes[strings.ToLower(filepath.Ext(fileName))]
+	if !ok {
+		return nil, ErrInvalidExtension
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	detected := Sniff(data)
+	if detected == "" {
+		return nil, ErrUnsupportedType
+	}
+	if detected != expected {
+		return nil, ErrTypeMismatch
+	}
+
+	var metadata Metadata
+	switch detected {
+	case CONTENT_TYPE_JPEG:
+		metadata = imageMetadata(jpegExif(data))
+		data, err = sanitizeJPEG(data)
+	case CONTENT_TYPE_PNG:
+		metadata = imageMetadata(pngExif(data))
+		data, err = sanitizePNG(data)
+	case CONTENT_TYPE_PDF:
+		err = inspectPDF(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{Data: data, ContentType: detected, Metadata: metadata}, nil
+}
+
+func imageMetadata(exif *exifData) Metadata {
+	if exif == nil {
+		return Metadata{}
+	}
+
+	metadata := Metadata{
+		CaptureTime:   exif.captureTime,
+		CaptureOffset: exif.captureOffset,
+	}
+	if latitude, longitude, ok := exif.coordinates(); ok {
+		metadata.Latitude = &latitude
+		metadata.Longitude = &longitude
+	}
+	return metadata
+}
+
+// Sniff returns the content type from the file's magic bytes, or "" when it is
+// not an accepted type.
+func Sniff(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return CONTENT_TYPE_JPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return CONTENT_TYPE_PNG
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return CONTENT_TYPE_PDF
+	}
+	return ""
+}
@@ -0,0 +1,284 @@
+package upload
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 40), G: uint8(y * 40), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// cameraExif builds a little-endian TIFF with a camera make, an orientation,
+// a capture time and a GPS directory, the way phones write survey photos. The
+// coordinates are 6°12'34.56"S 106°49'12"E.
+func cameraExif(orientation uint16) []byte {
+	le := binary.LittleEndian
+	rationals := func(values ...uint32) []byte {
+		buf := make([]byte, 4*len(values))
+		for i, value := range values {
+			le.PutUint32(buf[i*4:], value)
+		}
+		return buf
+	}
+	short := make([]byte, 2)
+	le.PutUint16(short, orientation)
+
+	ifd0 := []ifdEntry{
+		{Tag: 0x010F, Type: 2, Count: 6, Value: []byte("Canon\x00")},
+		{Tag: TAG_ORIENTATION, Type: 3, Count: 1, Value: short},
+	}
+	exif := []ifdEntry{
+		{Tag: TAG_DATE_TIME_ORIGINAL, Type: 2, Count: 20, Value: []byte("2025:06:15 10:30:00\x00")},
+		{Tag: TAG_OFFSET_TIME_ORIGINAL, Type: 2, Count: 7, Value: []byte("+07:00\x00")},
+	}
+	gps := []ifdEntry{
+		{Tag: TAG_GPS_LATITUDE_REF, Type: 2, Count: 2, Value: []byte("S\x00")},
+		{Tag: TAG_GPS_LATITUDE, Type: 5, Count: 3, Value: rationals(6, 1, 12, 1, 3456, 100)},
+		{Tag: TAG_GPS_LONGITUDE_REF, Type: 2, Count: 2, Value: []byte("E\x00")},
+		{Tag: TAG_GPS_LONGITUDE, Type: 5, Count: 3, Value: rationals(106, 1, 49, 1, 12, 1)},
+	}
+	return buildTIFF(le, ifd0, exif, gps)
+}
+
+// buildTIFF lays out IFD0 with pointers to the Exif and GPS directories,
+// followed by the values that do not fit in an entry.
+func buildTIFF(order binary.ByteOrder, ifd0, exif, gps []ifdEntry) []byte {
+	ifdSize := func(entries []ifdEntry) int { return 2 + 12*len(entries) + 4 }
+	ifd0Offset := 8
+	exifOffset := ifd0Offset + ifdSize(ifd0) + 24
+	gpsOffset := exifOffset + ifdSize(exif)
+	dataOffset := gpsOffset + ifdSize(gps)
+
+	long := func(value int) []byte {
+		buf := make([]byte, 4)
+		order.PutUint32(buf, uint32(value))
+		return buf
+	}
+	ifd0 = append(append([]ifdEntry(nil), ifd0...),
+		ifdEntry{Tag: TAG_EXIF_IFD, Type: 4, Count: 1, Value: long(exifOffset)},
+		ifdEntry{Tag: TAG_GPS_IFD, Type: 4, Count: 1, Value: long(gpsOffset)},
+	)
+
+	var buf, data bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II*\x00")
+	} else {
+		buf.WriteString("MM\x00*")
+	}
+	binary.Write(&buf, order, uint32(ifd0Offset))
+
+	for _, entries := range [][]ifdEntry{ifd0, exif, gps} {
+		binary.Write(&buf, order, uint16(len(entries)))
+		for _, entry := range entries {
+			binary.Write(&buf, order, []uint16{entry.Tag, entry.Type})
+			binary.Write(&buf, order, entry.Count)
+			if len(entry.Value) <= 4 {
+				value := make([]byte, 4)
+				copy(value, entry.Value)
+				buf.Write(value)
+				continue
+			}
+			binary.Write(&buf, order, uint32(dataOffset+data.Len()))
+			data.Write(entry.Value)
+		}
+		binary.Write(&buf, order, uint32(0))
+	}
+
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+func withJPEGExif(encoded, tiff []byte) []byte {
+	segment := append(append([]byte(nil), exifHeader...), tiff...)
+	result := append([]byte(nil), encoded[:2]...)
+	result = append(result, 0xFF, 0xE1)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(segment)+2))
+	result = append(result, segment...)
+	return append(result, encoded[2:]...)
+}
+
+func TestInspect_JPEGKeepsOnlyGPS(t *testing.T) {
+	data := withJPEGExif(encodeJPEG(t, testImage(4, 2)), cameraExif(6))
+
+	file, err := Inspect(bytes.NewReader(data), "survey.JPG", 1<<20)
+
+	require.NoError(t, err)
+	assert.Equal(t, CONTENT_TYPE_JPEG, file.ContentType)
+	assert.False(t, bytes.Contains(file.Data, []byte("Canon")))
+
+	exif := jpegExif(file.Data)
+	require.NotNil(t, exif)
+	assert.Equal(t, 1, exif.orientation)
+	require.Len(t, exif.gps, 4)
+	assert.Equal(t, []byte("S\x00"), exif.gps[0].Value)
+	assert.Len(t, exif.gps[1].Value, 24)
+	assert.Empty(t, exif.captureTime)
+
+	// Orientation 6 was applied to the pixels
+	config, err := jpeg.DecodeConfig(bytes.NewReader(file.Data))
+	require.NoError(t, err)
+	assert.Equal(t, 2, config.Width)
+	assert.Equal(t, 4, config.Height)
+}
+
+func TestInspect_PNGKeepsOnlyGPS(t *testing.T) {
+	encoded := encodePNG(t, testImage(3, 3))
+	const ihdrEnd = 33
+	data := append(append(append([]byte(nil), encoded[:ihdrEnd]...), pngChunk("eXIf", cameraExif(1))...), encoded[ihdrEnd:]...)
+	data = append(data[:len(data)-12], append(pngChunk("tEXt", []byte("Author\x00someone")), data[len(data)-12:]...)...)
+
+	file, err := Inspect(bytes.NewReader(data), "survey.png", 1<<20)
+
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(file.Data, []byte("Canon")))
+	assert.False(t, bytes.Contains(file.Data, []byte("someone")))
+	exif := pngExif(file.Data)
+	require.NotNil(t, exif)
+	assert.Len(t, exif.gps, 4)
+
+	_, err = png.Decode(bytes.NewReader(file.Data))
+	assert.NoError(t, err)
+}
+
+func TestInspect_ReadsSurveyMetadata(t *testing.T) {
+	data := withJPEGExif(encodeJPEG(t, testImage(2, 2)), cameraExif(1))
+
+	file, err := Inspect(bytes.NewReader(data), "survey.jpg", 1<<20)
+
+	require.NoError(t, err)
+	require.NotNil(t, file.Metadata.Latitude)
+	require.NotNil(t, file.Metadata.Longitude)
+	assert.InDelta(t, -6.2096, *file.Metadata.Latitude, 0.0001)
+	assert.InDelta(t, 106.82, *file.Metadata.Longitude, 0.0001)
+
+	// The recorded offset wins over the fallback zone
+	captured, ok := file.Metadata.CapturedAt(time.UTC)
+	require.True(t, ok)
+	assert.True(t, captured.Equal(time.Date(2025, 6, 15, 3, 30, 0, 0, time.UTC)))
+
+	file.Metadata.CaptureOffset = ""
+	jakarta := time.FixedZone("WIB", 7*3600)
+	captured, ok = file.Metadata.CapturedAt(jakarta)
+	require.True(t, ok)
+	assert.True(t, captured.Equal(time.Date(2025, 6, 15, 10, 30, 0, 0, jakarta)))
+}
+
+func TestInspect_PhotoWithoutExifHasNoMetadata(t *testing.T) {
+	file, err := Inspect(bytes.NewReader(encodeJPEG(t, testImage(2, 2))), "survey.jpg", 1<<20)
+
+	require.NoError(t, err)
+	assert.Nil(t, file.Metadata.Latitude)
+	_, ok := file.Metadata.CapturedAt(time.UTC)
+	assert.False(t, ok)
+}
+
+func TestInspect_ContentMustMatchExtension(t *testing.T) {
+	data := encodePNG(t, testImage(2, 2))
+
+	_, err := Inspect(bytes.NewReader(data), "survey.jpg", 1<<20)
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+
+	_, err = Inspect(strings.NewReader("%PDF-1.4\n%%EOF"), "survey.png", 1<<20)
+	assert.ErrorIs(t, err, ErrTypeMismatch)
+
+	_, err = Inspect(strings.NewReader("GIF89a......"), "survey.png", 1<<20)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Inspect(bytes.NewReader(data), "survey.exe", 1<<20)
+	assert.ErrorIs(t, err, ErrInvalidExtension)
+}
+
+func TestInspect_SizeLimitEnforcedWhileReading(t *testing.T) {
+	data := "%PDF-1.4\n" + strings.Repeat("x", 100)
+
+	_, err := Inspect(strings.NewReader(data), "agreement.pdf", int64(len(data)-1))
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Inspect(strings.NewReader(data), "agreement.pdf", int64(len(data)))
+	assert.NoError(t, err)
+}
+
+func TestInspect_CorruptImageRejected(t *testing.T) {
+	data := encodeJPEG(t, testImage(2, 2))
+
+	_, err := Inspect(bytes.NewReader(data[:20]), "survey.jpg", 1<<20)
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestInspect_PDF(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte("1 0 obj << /Type /Action /S /JavaScript /JS (app.alert(1)) >> endobj"))
+	writer.Close()
+
+	cases := map[string]struct {
+		body     string
+		expected error
+	}{
+		"clean": {
+			body: "1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\ntrailer << /Root 1 0 R >>",
+		},
+		"javascript action": {
+			body:     "1 0 obj << /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >> endobj",
+			expected: ErrPDFJavaScript,
+		},
+		"escaped name": {
+			body:     "1 0 obj << /OpenAction << /S /J#61vaScript /J#53 (x) >> >> endobj",
+			expected: ErrPDFJavaScript,
+		},
+		"compressed object stream": {
+			body:     "5 0 obj << /Type /ObjStm /Filter /FlateDecode >>\nstream\n" + compressed.String() + "\nendstream\nendobj",
+			expected: ErrPDFJavaScript,
+		},
+		"encrypted": {
+			body:     "trailer << /Root 1 0 R /Encrypt 5 0 R >>",
+			expected: ErrPDFEncrypted,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data := "%PDF-1.7\n" + tc.body + "\n%%EOF"
+
+			file, err := Inspect(strings.NewReader(data), "agreement.pdf", 1<<20)
+
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, CONTENT_TYPE_PDF, file.ContentType)
+			assert.Equal(t, data, string(file.Data))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+CREATE TABLE user_mfa (
+                          id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                          user_id UUID NOT NULL,
+                          user_type VARCHAR(20) NOT NULL CHECK (user_type IN ('employee', 'investor')),
+                          totp_secret VARCHAR(64) NOT NULL,
+                          is_enabled BOOLEAN NOT NULL DEFAULT false,
+                          enabled_at TIMESTAMP,
+                          last_used_step BIGINT NOT NULL DEFAULT 0,
+                          failed_attempts INTEGER NOT NULL DEFAULT 0,
+                          locked_until TIMESTAMP,
+                          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                          updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+                          UNIQUE(user_id, user_type)
+);
+
+CREATE TABLE user_mfa_recovery_codes (
+                                         id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                         user_id UUID NOT NULL,
+                                         user_type VARCHAR(20) NOT NULL,
+                                         code_hash VARCHAR(64) NOT NULL,
+                                         used_at TIMESTAMP,
+                                         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_mfa_recovery_codes_user ON user_mfa_recovery_codes(user_id, user_type);
@@ -0,0 +1,4 @@
+ALTER TABLE loans DROP COLUMN IF EXISTS assigned_validator_employee_id;
+ALTER TABLE borrowers DROP COLUMN IF EXISTS branch;
+ALTER TABLE employees DROP COLUMN IF EXISTS branch;
+DROP TABLE IF EXISTS role_permissions;
@@ -0,0 +1,26 @@
+CREATE TABLE role_permissions (
+                                  role VARCHAR(50) NOT NULL,
+                                  permission VARCHAR(100) NOT NULL,
+                                  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+                                  PRIMARY KEY (role, permission)
+);
+
+-- Employee roles use employee_role_enum values, other principals use their user type
+INSERT INTO role_permissions (role, permission) VALUES
+    ('FIELD_VALIDATOR', 'survey:upload'),
+    ('FIELD_OFFICER', 'loan:approve'),
+    ('FIELD_OFFICER', 'loan:disburse'),
+    ('FIELD_OFFICER', 'loan:assign'),
+    ('ADMIN', 'loan:assign'),
+    ('ADMIN', 'loan:all_branches'),
+    ('borrower', 'loan:create'),
+    ('investor', 'investment:create');
+
+ALTER TABLE employees ADD COLUMN branch VARCHAR(50);
+ALTER TABLE borrowers ADD COLUMN branch VARCHAR(50);
+ALTER TABLE loans ADD COLUMN assigned_validator_employee_id UUID REFERENCES employees(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_employees_branch ON employees(branch);
+CREATE INDEX idx_borrowers_branch ON borrowers(branch);
+CREATE INDEX idx_loans_assigned_validator_employee_id ON loans(assigned_validator_employee_id);
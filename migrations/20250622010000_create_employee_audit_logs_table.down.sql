@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission = 'employee:manage';
+DROP TABLE IF EXISTS employee_audit_logs;
@@ -0,0 +1,14 @@
+CREATE TABLE employee_audit_logs (
+                                     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                     employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+                                     actor_employee_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                                     action VARCHAR(50) NOT NULL,
+                                     changes JSONB,
+                                     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_employee_audit_logs_employee_id ON employee_audit_logs(employee_id);
+CREATE INDEX idx_employee_audit_logs_actor_employee_id ON employee_audit_logs(actor_employee_id);
+CREATE INDEX idx_employee_audit_logs_created_at ON employee_audit_logs(created_at);
+
+INSERT INTO role_permissions (role, permission) VALUES ('ADMIN', 'employee:manage');
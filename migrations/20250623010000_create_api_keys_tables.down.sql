@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission = 'service:manage';
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS service_principals;
@@ -0,0 +1,27 @@
+CREATE TABLE service_principals (
+                                    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                    name VARCHAR(50) NOT NULL UNIQUE,
+                                    description VARCHAR(255),
+                                    is_active BOOLEAN NOT NULL DEFAULT true,
+                                    created_by_employee_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                                    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE api_keys (
+                          id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                          service_principal_id UUID NOT NULL REFERENCES service_principals(id) ON DELETE CASCADE,
+                          key_prefix VARCHAR(16) NOT NULL UNIQUE,
+                          key_hash VARCHAR(64) NOT NULL,
+                          scopes TEXT[] NOT NULL DEFAULT '{}',
+                          expires_at TIMESTAMP NOT NULL,
+                          revoked_at TIMESTAMP,
+                          last_used_at TIMESTAMP,
+                          rotated_from_id UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+                          created_by_employee_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_service_principal_id ON api_keys(service_principal_id);
+
+INSERT INTO role_permissions (role, permission) VALUES ('ADMIN', 'service:manage');
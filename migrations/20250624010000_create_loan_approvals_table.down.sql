@@ -0,0 +1,2 @@
+ALTER TABLE loans DROP COLUMN IF EXISTS required_approvals;
+DROP TABLE IF EXISTS loan_approvals;
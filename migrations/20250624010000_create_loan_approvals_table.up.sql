@@ -0,0 +1,15 @@
+CREATE TABLE loan_approvals (
+                                id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+                                employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE RESTRICT,
+                                employee_role employee_role_enum NOT NULL,
+                                approval_notes TEXT,
+                                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+                                UNIQUE(loan_id, employee_id)
+);
+
+CREATE INDEX idx_loan_approvals_loan_id ON loan_approvals(loan_id);
+CREATE INDEX idx_loan_approvals_employee_id ON loan_approvals(employee_id);
+
+ALTER TABLE loans ADD COLUMN required_approvals INTEGER NOT NULL DEFAULT 1 CHECK (required_approvals > 0);
@@ -0,0 +1,7 @@
+-- Downloads through GET /files/{file_id}. Borrowers and investors are checked
+-- against the loan instead of through a permission.
+INSERT INTO role_permissions (role, permission) VALUES
+    ('FIELD_VALIDATOR', 'document:read'),
+    ('FIELD_OFFICER', 'document:read'),
+    ('ADMIN', 'document:read')
+ON CONFLICT DO NOTHING;
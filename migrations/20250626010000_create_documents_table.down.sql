@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission = 'document:delete';
+DROP TABLE IF EXISTS documents;
+DROP TYPE IF EXISTS document_type_enum;
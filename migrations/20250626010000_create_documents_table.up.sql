@@ -0,0 +1,60 @@
+CREATE TYPE document_type_enum AS ENUM (
+    'SURVEY_PHOTO',
+    'SURVEY_DOCUMENT',
+    'SUPPORTING_DOCUMENT',
+    'LOAN_AGREEMENT',
+    'SIGNED_AGREEMENT',
+    'INVESTMENT_AGREEMENT'
+    );
+
+CREATE TABLE documents (
+                           id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                           loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE RESTRICT,
+                           investment_id UUID REFERENCES investments(id) ON DELETE RESTRICT,
+                           document_type document_type_enum NOT NULL,
+                           file_name VARCHAR(255) NOT NULL,
+                           storage_key TEXT NOT NULL UNIQUE,
+                           content_type VARCHAR(100) NOT NULL,
+    -- Size and checksum are unknown for files recorded before this table existed
+                           size_bytes BIGINT CHECK (size_bytes >= 0),
+                           sha256 CHAR(64),
+                           version INTEGER NOT NULL CHECK (version > 0),
+                           uploaded_by_id UUID,
+                           uploaded_by_type VARCHAR(20) NOT NULL,
+                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+                           deleted_at TIMESTAMP,
+                           deleted_by_id UUID,
+                           deleted_reason TEXT
+);
+
+CREATE UNIQUE INDEX idx_documents_version ON documents(loan_id, document_type, COALESCE(investment_id, '00000000-0000-0000-0000-000000000000'), version);
+CREATE INDEX idx_documents_loan_id ON documents(loan_id);
+CREATE INDEX idx_documents_investment_id ON documents(investment_id);
+
+-- Existing single-file columns become the first version of each document
+INSERT INTO documents (loan_id, document_type, file_name, storage_key, content_type, version, uploaded_by_id, uploaded_by_type, created_at)
+SELECT id, 'SURVEY_PHOTO', regexp_replace(field_visit_proof_url, '^.*/', ''), substring(field_visit_proof_url FROM length('/uploads/') + 1),
+       'application/octet-stream', 1, field_validator_employee_id, 'employee', updated_at
+FROM loans WHERE field_visit_proof_url LIKE '/uploads/%';
+
+INSERT INTO documents (loan_id, document_type, file_name, storage_key, content_type, version, uploaded_by_id, uploaded_by_type, created_at)
+SELECT id, 'LOAN_AGREEMENT', regexp_replace(loan_agreement_pdf_url, '^.*/', ''), substring(loan_agreement_pdf_url FROM length('/uploads/') + 1),
+       'application/pdf', 1, NULL, 'system', COALESCE(approval_date, updated_at)
+FROM loans WHERE loan_agreement_pdf_url LIKE '/uploads/%';
+
+INSERT INTO documents (loan_id, document_type, file_name, storage_key, content_type, version, uploaded_by_id, uploaded_by_type, created_at)
+SELECT id, 'SIGNED_AGREEMENT', regexp_replace(signed_agreement_url, '^.*/', ''), substring(signed_agreement_url FROM length('/uploads/') + 1),
+       'application/octet-stream', 1, field_officer_employee_id, 'employee', COALESCE(disbursement_date, updated_at)
+FROM loans WHERE signed_agreement_url LIKE '/uploads/%';
+
+INSERT INTO documents (loan_id, investment_id, document_type, file_name, storage_key, content_type, version, uploaded_by_id, uploaded_by_type, created_at)
+SELECT loan_id, id, 'INVESTMENT_AGREEMENT', regexp_replace(agreement_url, '^.*/', ''), substring(agreement_url FROM length('/uploads/') + 1),
+       'application/pdf', 1, NULL, 'system', created_at
+FROM investments WHERE agreement_url LIKE '/uploads/%';
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('FIELD_VALIDATOR', 'document:delete'),
+    ('FIELD_OFFICER', 'document:delete'),
+    ('ADMIN', 'document:delete')
+ON CONFLICT DO NOTHING;
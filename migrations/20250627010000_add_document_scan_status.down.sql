@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_documents_scan_pending;
+
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS scanned_at,
+    DROP COLUMN IF EXISTS scan_signature,
+    DROP COLUMN IF EXISTS scan_status;
+
+DROP TYPE IF EXISTS scan_status_enum;
@@ -0,0 +1,13 @@
+CREATE TYPE scan_status_enum AS ENUM (
+    'CLEAN',
+    'PENDING',
+    'INFECTED'
+    );
+
+-- Files stored before scanning existed are treated as clean
+ALTER TABLE documents
+    ADD COLUMN scan_status scan_status_enum NOT NULL DEFAULT 'CLEAN',
+    ADD COLUMN scan_signature VARCHAR(255),
+    ADD COLUMN scanned_at TIMESTAMP;
+
+CREATE INDEX idx_documents_scan_pending ON documents(created_at) WHERE scan_status = 'PENDING';
@@ -0,0 +1,12 @@
+ALTER TABLE loan_approvals DROP COLUMN IF EXISTS survey_flags_acknowledged;
+
+DROP TABLE IF EXISTS survey_flags;
+
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS captured_at,
+    DROP COLUMN IF EXISTS gps_longitude,
+    DROP COLUMN IF EXISTS gps_latitude;
+
+ALTER TABLE borrowers
+    DROP COLUMN IF EXISTS address_longitude,
+    DROP COLUMN IF EXISTS address_latitude;
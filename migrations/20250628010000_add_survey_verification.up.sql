@@ -0,0 +1,23 @@
+-- Coordinates of the registered address, survey photos are checked against them
+ALTER TABLE borrowers
+    ADD COLUMN address_latitude DECIMAL(9,6),
+    ADD COLUMN address_longitude DECIMAL(9,6);
+
+-- Geotag and capture time read from the uploaded photo
+ALTER TABLE documents
+    ADD COLUMN gps_latitude DOUBLE PRECISION,
+    ADD COLUMN gps_longitude DOUBLE PRECISION,
+    ADD COLUMN captured_at TIMESTAMP;
+
+CREATE TABLE survey_flags (
+                              id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                              loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+                              document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+                              flag_type VARCHAR(50) NOT NULL,
+                              detail TEXT NOT NULL,
+                              created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_survey_flags_loan_id ON survey_flags(loan_id);
+
+ALTER TABLE loan_approvals ADD COLUMN survey_flags_acknowledged BOOLEAN NOT NULL DEFAULT FALSE;
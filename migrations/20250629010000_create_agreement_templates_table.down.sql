@@ -0,0 +1,5 @@
+DELETE FROM role_permissions WHERE permission = 'template:manage';
+
+ALTER TABLE documents DROP COLUMN IF EXISTS template_version;
+
+DROP TABLE IF EXISTS agreement_templates;
@@ -0,0 +1,18 @@
+CREATE TABLE agreement_templates (
+                                     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                     template_type VARCHAR(50) NOT NULL CHECK (template_type IN ('LOAN_AGREEMENT', 'INVESTMENT_AGREEMENT')),
+                                     version INTEGER NOT NULL CHECK (version > 0),
+                                     body TEXT NOT NULL,
+                                     change_notes TEXT,
+                                     created_by_id UUID REFERENCES employees(id) ON DELETE RESTRICT,
+                                     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+                                     UNIQUE(template_type, version)
+);
+
+-- Generated agreements remember their template, older ones predate templates
+ALTER TABLE documents ADD COLUMN template_version INTEGER;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'template:manage')
+ON CONFLICT DO NOTHING;
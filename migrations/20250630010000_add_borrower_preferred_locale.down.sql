@@ -0,0 +1 @@
+ALTER TABLE borrowers DROP COLUMN IF EXISTS preferred_locale;
@@ -0,0 +1,3 @@
+-- Agreements are in Indonesian, borrowers preferring English get a parallel column
+ALTER TABLE borrowers
+    ADD COLUMN preferred_locale VARCHAR(5) NOT NULL DEFAULT 'id' CHECK (preferred_locale IN ('id', 'en'));
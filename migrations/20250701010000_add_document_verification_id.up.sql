@@ -0,0 +1,2 @@
+-- Stamped on every page of generated agreements, looked up by the public verification endpoint
+ALTER TABLE documents ADD COLUMN verification_id VARCHAR(32) UNIQUE;
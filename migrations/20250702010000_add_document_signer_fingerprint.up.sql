@@ -0,0 +1,2 @@
+-- SHA-256 of the certificate generated agreements were signed with
+ALTER TABLE documents ADD COLUMN signer_fingerprint CHAR(64);
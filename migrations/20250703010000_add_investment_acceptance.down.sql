@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_investments_acceptance_deadline;
+DROP INDEX IF EXISTS idx_investments_active_investor;
+
+-- The old constraint allows one investment per investor and loan
+DELETE FROM documents WHERE investment_id IN (SELECT id FROM investments WHERE status = 'CANCELLED');
+DELETE FROM investments WHERE status = 'CANCELLED';
+ALTER TABLE investments ADD CONSTRAINT investments_loan_id_investor_id_key UNIQUE (loan_id, investor_id);
+
+ALTER TABLE investments
+    DROP COLUMN IF EXISTS cancellation_reason,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS agreement_sha256,
+    DROP COLUMN IF EXISTS agreement_document_id,
+    DROP COLUMN IF EXISTS agreement_signed_user_agent,
+    DROP COLUMN IF EXISTS agreement_signed_ip,
+    DROP COLUMN IF EXISTS agreement_signed_at,
+    DROP COLUMN IF EXISTS acceptance_deadline,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,24 @@
+ALTER TABLE investments
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'PENDING_ACCEPTANCE'
+        CHECK (status IN ('PENDING_ACCEPTANCE', 'ACCEPTED', 'CANCELLED')),
+    ADD COLUMN acceptance_deadline TIMESTAMP,
+    -- Click-to-accept evidence
+    ADD COLUMN agreement_signed_at TIMESTAMP,
+    ADD COLUMN agreement_signed_ip TEXT,
+    ADD COLUMN agreement_signed_user_agent TEXT,
+    ADD COLUMN agreement_document_id UUID REFERENCES documents(id) ON DELETE RESTRICT,
+    ADD COLUMN agreement_sha256 CHAR(64),
+    ADD COLUMN cancelled_at TIMESTAMP,
+    ADD COLUMN cancellation_reason TEXT;
+
+UPDATE investments SET status = 'ACCEPTED' WHERE agreement_signed;
+
+-- Investments made before acceptance existed get a fresh window
+UPDATE investments SET acceptance_deadline = CURRENT_TIMESTAMP + INTERVAL '3 days'
+WHERE status = 'PENDING_ACCEPTANCE';
+
+-- A cancelled investment does not stop the investor from investing again
+ALTER TABLE investments DROP CONSTRAINT investments_loan_id_investor_id_key;
+CREATE UNIQUE INDEX idx_investments_active_investor ON investments(loan_id, investor_id) WHERE status <> 'CANCELLED';
+
+CREATE INDEX idx_investments_acceptance_deadline ON investments(acceptance_deadline) WHERE status = 'PENDING_ACCEPTANCE';
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission = 'agreement:sign';
+DROP TABLE IF EXISTS signing_sessions;
@@ -0,0 +1,28 @@
+CREATE TABLE signing_sessions (
+                                  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                  loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE RESTRICT,
+                                  borrower_id UUID NOT NULL REFERENCES borrowers(id) ON DELETE RESTRICT,
+    -- The agreement the borrower reviewed and the OTP was sent for
+                                  agreement_document_id UUID NOT NULL REFERENCES documents(id) ON DELETE RESTRICT,
+                                  agreement_sha256 CHAR(64),
+                                  phone_number VARCHAR(15) NOT NULL,
+                                  otp_hash CHAR(64) NOT NULL,
+                                  expires_at TIMESTAMP NOT NULL,
+                                  failed_attempts INTEGER NOT NULL DEFAULT 0,
+                                  status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SIGNED', 'FAILED', 'SUPERSEDED')),
+                                  requested_ip TEXT,
+                                  requested_user_agent TEXT,
+                                  signed_at TIMESTAMP,
+                                  signed_ip TEXT,
+                                  signed_user_agent TEXT,
+                                  signed_document_id UUID REFERENCES documents(id) ON DELETE RESTRICT,
+                                  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_signing_sessions_loan_id ON signing_sessions(loan_id, created_at);
+-- A loan's agreement is signed electronically once
+CREATE UNIQUE INDEX idx_signing_sessions_signed ON signing_sessions(loan_id) WHERE status = 'SIGNED';
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('borrower', 'agreement:sign')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+CREATE TABLE notification_preferences (
+                                          user_id UUID NOT NULL,
+                                          user_type VARCHAR(20) NOT NULL,
+                                          notification_type VARCHAR(50) NOT NULL,
+                                          email_enabled BOOLEAN NOT NULL,
+                                          sms_enabled BOOLEAN NOT NULL,
+                                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                          PRIMARY KEY (user_type, user_id, notification_type)
+);
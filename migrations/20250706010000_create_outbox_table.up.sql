@@ -0,0 +1,18 @@
+CREATE TABLE outbox (
+                        id BIGSERIAL PRIMARY KEY,
+                        event_id UUID NOT NULL UNIQUE,
+                        aggregate_type VARCHAR(50) NOT NULL,
+                        aggregate_id UUID NOT NULL,
+                        event_type VARCHAR(50) NOT NULL,
+                        payload JSONB NOT NULL,
+                        occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                        created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                        published_at TIMESTAMP WITH TIME ZONE,
+                        attempts INTEGER NOT NULL DEFAULT 0,
+                        next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                        locked_until TIMESTAMP WITH TIME ZONE,
+                        last_error TEXT
+);
+
+-- The relay only looks at unpublished events, oldest first per loan
+CREATE INDEX idx_outbox_pending ON outbox(aggregate_id, id) WHERE published_at IS NULL;
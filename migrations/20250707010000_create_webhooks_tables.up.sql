@@ -0,0 +1,49 @@
+CREATE TABLE webhook_subscriptions (
+                                       id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                       url VARCHAR(2048) NOT NULL,
+                                       event_types TEXT[] NOT NULL,
+                                       secret VARCHAR(128) NOT NULL,
+                                       description VARCHAR(255),
+                                       is_active BOOLEAN NOT NULL DEFAULT true,
+                                       created_by_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                                       created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                       updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                       deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE webhook_deliveries (
+                                    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+                                    event_id UUID NOT NULL,
+                                    event_type VARCHAR(50) NOT NULL,
+                                    body JSONB NOT NULL,
+                                    status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'RETRYING', 'DELIVERED', 'DEAD')),
+                                    attempts INTEGER NOT NULL DEFAULT 0,
+                                    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                    locked_until TIMESTAMP WITH TIME ZONE,
+                                    last_status_code INTEGER,
+                                    last_error TEXT,
+                                    delivered_at TIMESTAMP WITH TIME ZONE,
+                                    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+                                    -- The outbox may publish an event twice, subscribers get it once
+                                    UNIQUE(subscription_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status IN ('PENDING', 'RETRYING');
+CREATE INDEX idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
+
+CREATE TABLE webhook_delivery_attempts (
+                                           id BIGSERIAL PRIMARY KEY,
+                                           delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+                                           attempted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                           status_code INTEGER,
+                                           error TEXT,
+                                           duration_ms INTEGER NOT NULL
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts(delivery_id, id);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'webhook:manage')
+ON CONFLICT DO NOTHING;
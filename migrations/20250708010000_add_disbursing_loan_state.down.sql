@@ -0,0 +1,2 @@
+-- Enum values cannot be dropped, loans are moved back to INVESTED instead
+UPDATE loans SET current_state = 'INVESTED' WHERE current_state = 'DISBURSING';
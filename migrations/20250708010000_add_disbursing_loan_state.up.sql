@@ -0,0 +1 @@
+ALTER TYPE loan_state_enum ADD VALUE 'DISBURSING' BEFORE 'DISBURSED';
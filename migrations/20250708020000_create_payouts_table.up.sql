@@ -0,0 +1,21 @@
+CREATE TABLE payouts (
+                         id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                         loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE RESTRICT,
+                         amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+                         bank_name VARCHAR(100) NOT NULL,
+                         bank_account_number VARCHAR(50) NOT NULL,
+                         account_holder_name VARCHAR(100) NOT NULL,
+                         provider VARCHAR(50) NOT NULL,
+                         provider_reference VARCHAR(100),
+                         status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED')),
+                         failure_reason TEXT,
+                         requested_by_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                         updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                         completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_payouts_loan_id ON payouts(loan_id, created_at);
+
+-- A loan has at most one payout in flight
+CREATE UNIQUE INDEX idx_payouts_pending_loan ON payouts(loan_id) WHERE status = 'PENDING';
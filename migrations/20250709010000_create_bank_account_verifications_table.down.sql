@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission = 'bank:override';
+
+DROP TABLE IF EXISTS bank_account_verifications;
@@ -0,0 +1,24 @@
+CREATE TABLE bank_account_verifications (
+                                            id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                            loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+                                            borrower_id UUID NOT NULL REFERENCES borrowers(id) ON DELETE CASCADE,
+                                            bank_name VARCHAR(100) NOT NULL,
+                                            bank_account_number VARCHAR(50) NOT NULL,
+                                            borrower_name VARCHAR(255) NOT NULL,
+                                            account_holder_name VARCHAR(255),
+                                            match_score DECIMAL(5,4) NOT NULL DEFAULT 0,
+                                            match_threshold DECIMAL(5,4) NOT NULL,
+                                            status VARCHAR(20) NOT NULL CHECK (status IN ('MATCHED', 'MISMATCHED', 'NOT_FOUND', 'OVERRIDDEN')),
+                                            provider VARCHAR(50) NOT NULL,
+                                            checked_by_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                                            override_reason TEXT,
+                                            overridden_by_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                                            overridden_at TIMESTAMP WITH TIME ZONE,
+                                            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bank_account_verifications_loan ON bank_account_verifications(loan_id, created_at);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'bank:override')
+ON CONFLICT DO NOTHING;
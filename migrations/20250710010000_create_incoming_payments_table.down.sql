@@ -0,0 +1,7 @@
+DELETE FROM role_permissions WHERE permission = 'payment:reconcile';
+
+DROP TABLE IF EXISTS incoming_payments;
+
+ALTER TABLE borrowers DROP COLUMN IF EXISTS virtual_account_number;
+
+DROP SEQUENCE IF EXISTS borrower_virtual_account_seq;
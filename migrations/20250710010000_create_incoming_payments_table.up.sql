@@ -0,0 +1,30 @@
+CREATE SEQUENCE borrower_virtual_account_seq;
+
+ALTER TABLE borrowers ADD COLUMN virtual_account_number VARCHAR(30) UNIQUE;
+
+CREATE TABLE incoming_payments (
+                                   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                   gateway VARCHAR(50) NOT NULL,
+                                   gateway_payment_id VARCHAR(100) NOT NULL,
+                                   virtual_account_number VARCHAR(30) NOT NULL,
+                                   amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+                                   payer_name VARCHAR(255),
+                                   paid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                   payload JSONB NOT NULL,
+                                   status VARCHAR(20) NOT NULL CHECK (status IN ('MATCHED', 'SUSPENSE', 'RESOLVED', 'RETURNED')),
+                                   borrower_id UUID REFERENCES borrowers(id) ON DELETE SET NULL,
+                                   loan_id UUID REFERENCES loans(id) ON DELETE RESTRICT,
+                                   suspense_reason VARCHAR(100),
+                                   resolved_by_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+                                   resolved_at TIMESTAMP WITH TIME ZONE,
+                                   resolution_note TEXT,
+                                   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                   UNIQUE (gateway, gateway_payment_id)
+);
+
+CREATE INDEX idx_incoming_payments_loan ON incoming_payments(loan_id, paid_at);
+CREATE INDEX idx_incoming_payments_status ON incoming_payments(status, created_at);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('ADMIN', 'payment:reconcile')
+ON CONFLICT DO NOTHING;
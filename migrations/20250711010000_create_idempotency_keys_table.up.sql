@@ -0,0 +1,15 @@
+CREATE TABLE idempotency_keys (
+                                  principal VARCHAR(100) NOT NULL,
+                                  idempotency_key VARCHAR(255) NOT NULL,
+                                  fingerprint VARCHAR(64) NOT NULL,
+                                  status VARCHAR(20) NOT NULL CHECK (status IN ('PROCESSING', 'COMPLETED')),
+                                  response_status INTEGER,
+                                  response_content_type VARCHAR(100),
+                                  response_body BYTEA,
+                                  locked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                  PRIMARY KEY (principal, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,5 @@
+-- Fails while an investor holds several tranches of a loan
+DROP INDEX IF EXISTS idx_investments_active_tranche;
+CREATE UNIQUE INDEX idx_investments_active_investor ON investments(loan_id, investor_id) WHERE status <> 'CANCELLED';
+
+ALTER TABLE investments DROP COLUMN IF EXISTS tranche;
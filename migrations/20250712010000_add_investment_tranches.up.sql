@@ -0,0 +1,6 @@
+-- Investors top up a loan they back with another investment, numbered as a
+-- tranche of their position in the loan
+ALTER TABLE investments ADD COLUMN tranche INTEGER NOT NULL DEFAULT 1 CHECK (tranche > 0);
+
+DROP INDEX IF EXISTS idx_investments_active_investor;
+CREATE UNIQUE INDEX idx_investments_active_tranche ON investments(loan_id, investor_id, tranche) WHERE status <> 'CANCELLED';